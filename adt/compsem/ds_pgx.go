@@ -45,5 +45,5 @@ func (dao *pgxDAO) TouchRef(source db.Source, ref SemRef) error {
 }
 
 func errConcurrentModification(got SemRef) error {
-	return fmt.Errorf("concurrent modification: %v", got)
+	return fmt.Errorf("%w: %v", db.ErrConcurrentModification, got)
 }
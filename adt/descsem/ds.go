@@ -1,6 +1,8 @@
 package descsem
 
 import (
+	"log/slog"
+
 	"orglang/go-engine/lib/db"
 )
 
@@ -8,6 +10,16 @@ type Repo interface {
 	AddRec(db.Source, SemRec) error
 }
 
+// NewDialectDAO выбирает DAO по диалекту хранилища
+func NewDialectDAO(table string) func(dialect db.Dialect, log *slog.Logger) Repo {
+	return func(dialect db.Dialect, log *slog.Logger) Repo {
+		if dialect == db.Memory {
+			return newMemDAO(table, log)
+		}
+		return NewPgxDAO(table)(log)
+	}
+}
+
 type SemRecDS struct {
 	DescQN string `db:"desc_qn"`
 	DescID string `db:"desc_id"`
@@ -0,0 +1,37 @@
+package descsem
+
+import (
+	"log/slog"
+	"reflect"
+
+	"orglang/go-engine/lib/db"
+)
+
+// Adapter
+//
+// связки хранятся по синониму, который в таблице уникален
+type memDAO struct {
+	table string
+	log   *slog.Logger
+}
+
+func newMemDAO(table string, log *slog.Logger) *memDAO {
+	name := slog.String("name", reflect.TypeFor[memDAO]().Name())
+	return &memDAO{table, log.With(name)}
+}
+
+func (dao *memDAO) AddRec(source db.Source, rec SemRec) error {
+	ds := db.MustConform[db.SourceMem](source)
+	recAttr := slog.Any("rec", rec)
+	dto, convErr := DataFromRec(rec)
+	if convErr != nil {
+		dao.log.Error("model conversion failed", recAttr)
+		return convErr
+	}
+	insertErr := db.TableOf[string, SemRecDS](ds, dao.table).Insert(dto.DescQN, dto)
+	if insertErr != nil {
+		dao.log.Error("insertion failed", recAttr)
+		return insertErr
+	}
+	return nil
+}
@@ -17,4 +17,5 @@ const (
 	unkKind implKind = iota
 	CompKind
 	CommKind
+	TermKind
 )
@@ -16,9 +16,5 @@ func New(str string) ADT {
 }
 
 func (a ADT) Key() valkey.ADT {
-	key := 0
-	for _, runeValue := range string(a) {
-		key += int(runeValue)
-	}
-	return valkey.ADT(key)
+	return valkey.FromString(string(a))
 }
@@ -84,5 +84,5 @@ func (dao *pgxDAO) GetRefsByQNs(source db.Source, typeQNs []uniqsym.ADT) (_ map[
 }
 
 func errConcurrentModification(got SemRef) error {
-	return fmt.Errorf("concurrent modification: %v", got)
+	return fmt.Errorf("%w: %v", db.ErrConcurrentModification, got)
 }
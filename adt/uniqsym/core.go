@@ -1,23 +1,26 @@
 package uniqsym
 
 import (
-	"hash/fnv"
-
 	"orglang/go-engine/adt/symbol"
 	"orglang/go-engine/adt/valkey"
 )
 
+// пространство имен хранится строкой, поэтому равные имена
+// равны и по ==, и как ключи отображений
 type ADT struct {
 	sym symbol.ADT
-	ns  *ADT
+	ns  string
 }
 
 func New(sym symbol.ADT) ADT {
-	return ADT{sym, nil}
+	return ADT{sym, ""}
 }
 
 func (ns ADT) New(sym symbol.ADT) ADT {
-	return ADT{sym, &ns}
+	if ns == empty {
+		return ADT{sym, ""}
+	}
+	return ADT{sym, ConvertToString(ns)}
 }
 
 // symbol
@@ -27,32 +30,25 @@ func (a ADT) Sym() symbol.ADT {
 
 // namespace
 func (a ADT) NS() ADT {
-	if a.ns == nil {
+	if a.ns == "" {
 		return empty
 	}
-	return *a.ns
+	ns, err := ConvertFromString(a.ns)
+	if err != nil {
+		panic(err)
+	}
+	return ns
 }
 
 func (a ADT) Key() (valkey.ADT, error) {
 	if a == empty {
 		panic("invalid value")
 	}
-	h := fnv.New32a()
-	_, err := h.Write([]byte(ConvertToString(a)))
-	if err != nil {
-		return valkey.Zero, err
-	}
-	return valkey.ADT(h.Sum32()), nil
+	return valkey.FromString(ConvertToString(a)), nil
 }
 
 func (a ADT) Equal(b ADT) bool {
-	if a.sym == b.sym && a.ns == b.ns {
-		return true
-	}
-	if a.ns == nil || b.ns == nil {
-		return false
-	}
-	return a.ns.Equal(*b.ns)
+	return a == b
 }
 
 func (a ADT) String() string {
@@ -18,17 +18,18 @@ func ConvertFromString(str string) (ADT, error) {
 	}
 	idx := strings.LastIndex(str, sep)
 	if idx < 0 {
-		return ADT{symbol.New(str), nil}, nil
+		return ADT{symbol.New(str), ""}, nil
 	}
 	sym, err := symbol.ConvertFromString(str[idx+1:])
 	if err != nil {
 		return empty, err
 	}
-	ns, err := ConvertFromString(str[:idx])
+	// пространство имен проверяется посегментно
+	_, err = ConvertFromString(str[:idx])
 	if err != nil {
 		return empty, err
 	}
-	return ADT{sym, &ns}, nil
+	return ADT{sym, str[:idx]}, nil
 }
 
 func ConvertFromNullString(str sql.NullString) (ADT, error) {
@@ -37,17 +38,17 @@ func ConvertFromNullString(str sql.NullString) (ADT, error) {
 	}
 	idx := strings.LastIndex(str.String, sep)
 	if idx < 0 {
-		return ADT{symbol.New(str.String), nil}, nil
+		return ADT{symbol.New(str.String), ""}, nil
 	}
 	sym, err := symbol.ConvertFromString(str.String[idx+1:])
 	if err != nil {
 		return empty, err
 	}
-	ns, err := ConvertFromString(str.String[:idx])
+	_, err = ConvertFromString(str.String[:idx])
 	if err != nil {
 		return empty, err
 	}
-	return ADT{sym, &ns}, nil
+	return ADT{sym, str.String[:idx]}, nil
 }
 
 func ConvertToString(adt ADT) string {
@@ -55,11 +56,10 @@ func ConvertToString(adt ADT) string {
 		panic("invalid value")
 	}
 	sym := symbol.ConvertToString(adt.sym)
-	if adt.ns == nil {
+	if adt.ns == "" {
 		return sym
 	}
-	ns := ConvertToString(*adt.ns)
-	return ns + sep + sym
+	return adt.ns + sep + sym
 }
 
 func ConvertToNullString(adt ADT) sql.NullString {
@@ -67,9 +67,8 @@ func ConvertToNullString(adt ADT) sql.NullString {
 		return sql.NullString{}
 	}
 	sym := symbol.ConvertToString(adt.sym)
-	if adt.ns == nil {
+	if adt.ns == "" {
 		return sql.NullString{String: sym, Valid: true}
 	}
-	ns := ConvertToString(*adt.ns)
-	return sql.NullString{String: ns + sep + sym, Valid: true}
+	return sql.NullString{String: adt.ns + sep + sym, Valid: true}
 }
@@ -9,9 +9,9 @@ var sunnyTests = []struct {
 	str  string
 	adt  ADT
 }{
-	{"sym only", "a", ADT{"a", nil}},
-	{"sym and single-segment ns", "b.a", ADT{"a", &ADT{"b", nil}}},
-	{"sym and two-segment ns", "c.b.a", ADT{"a", &ADT{"b", &ADT{"c", nil}}}},
+	{"sym only", "a", ADT{"a", ""}},
+	{"sym and single-segment ns", "b.a", ADT{"a", "b"}},
+	{"sym and two-segment ns", "c.b.a", ADT{"a", "c.b"}},
 }
 
 func TestConvertFromStringSuccess(t *testing.T) {
@@ -55,3 +55,30 @@ func TestConvertFromStringError(t *testing.T) {
 		})
 	}
 }
+
+func TestConvertFromStringComparable(t *testing.T) {
+	for _, test := range sunnyTests {
+		t.Run(test.name, func(t *testing.T) {
+			// независимые разборы дают один ключ отображения
+			first, _ := ConvertFromString(test.str)
+			second, _ := ConvertFromString(test.str)
+			if first != second {
+				t.Errorf("got %+v != %+v", first, second)
+			}
+			seen := map[ADT]bool{first: true}
+			if !seen[second] {
+				t.Errorf("key %+v missing", second)
+			}
+		})
+	}
+}
+
+func TestNewNS(t *testing.T) {
+	got := New("c").New("b").New("a")
+	if got != sunnyTests[2].adt {
+		t.Errorf("got %+v, want %+v", got, sunnyTests[2].adt)
+	}
+	if got.NS() != New("c").New("b") {
+		t.Errorf("got ns %+v", got.NS())
+	}
+}
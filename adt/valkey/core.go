@@ -1,9 +1,9 @@
 package valkey
 
 import (
+	"crypto/sha256"
 	"encoding/binary"
-	"hash/fnv"
-	"slices"
+	"hash"
 )
 
 const (
@@ -23,14 +23,55 @@ func (key ADT) Invert() ADT {
 	return -key
 }
 
-func Compose(keys ...ADT) (ADT, error) {
-	slices.Sort(keys)
-	h := fnv.New32a()
-	for _, key := range keys {
-		err := binary.Write(h, binary.LittleEndian, uint32(key))
-		if err != nil {
-			return Zero, err
-		}
-	}
-	return ADT(h.Sum32()), nil
+// Метка версии схемы ключей.
+//
+// Структурные ключи всегда попадают в диапазон [2^62, 2^63),
+// поэтому не пересекаются ни с константами, ни с 32-битными
+// ключами предыдущей схемы.
+const (
+	digestMask ADT = 1<<62 - 1
+	digestMark ADT = 1 << 62
+)
+
+// Hasher accumulates collision-resistant structural key
+//
+// Каждое поле кодируется однозначно: ключи фиксированной длины,
+// строки с префиксом длины. Порядок записи полей значим.
+type Hasher struct {
+	h hash.Hash
+}
+
+// NewHasher starts key with constructor tag
+func NewHasher(tag int16) *Hasher {
+	h := sha256.New()
+	_ = binary.Write(h, binary.BigEndian, tag)
+	return &Hasher{h}
+}
+
+func (h *Hasher) WriteKey(key ADT) *Hasher {
+	_ = binary.Write(h.h, binary.BigEndian, int64(key))
+	return h
+}
+
+func (h *Hasher) WriteString(str string) *Hasher {
+	_ = binary.Write(h.h, binary.BigEndian, int32(len(str)))
+	_, _ = h.h.Write([]byte(str))
+	return h
+}
+
+// Sum truncates SHA-256 digest to positive key
+//
+// От дайджеста остается 62 бита, поэтому среди n ключей коллизия
+// случается с вероятностью около n^2/2^63: для миллиона выражений
+// порядка 10^-7. Хранилища сверяют спецификацию под занятым ключом
+// и отказывают во вставке при расхождении.
+func (h *Hasher) Sum() ADT {
+	digest := h.h.Sum(nil)
+	key := ADT(binary.BigEndian.Uint64(digest[:8]))
+	return key&digestMask | digestMark
+}
+
+// FromString keys untagged string
+func FromString(str string) ADT {
+	return NewHasher(0).WriteString(str).Sum()
 }
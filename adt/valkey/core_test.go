@@ -0,0 +1,68 @@
+package valkey
+
+import (
+	"testing"
+)
+
+func TestHasherSum(t *testing.T) {
+	var tests = []struct {
+		name string
+		key  ADT
+		want ADT
+	}{
+		// эталон для миграции ключей на стороне базы
+		{"empty string", FromString(""), 8139810871848473990},
+		{"tagged key", NewHasher(3).WriteKey(One).WriteKey(Two).Sum(), 4711821241320258758},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.key != test.want {
+				t.Errorf("got %v, want %v", test.key, test.want)
+			}
+		})
+	}
+}
+
+func TestHasherRange(t *testing.T) {
+	for i := range 1000 {
+		key := NewHasher(int16(i)).WriteKey(ADT(i)).Sum()
+		if key < digestMark {
+			t.Fatalf("key out of range: %v", key)
+		}
+	}
+}
+
+func TestHasherDistinct(t *testing.T) {
+	var tests = []struct {
+		name string
+		a, b ADT
+	}{
+		{
+			"tag",
+			NewHasher(3).WriteKey(One).WriteKey(Two).Sum(),
+			NewHasher(4).WriteKey(One).WriteKey(Two).Sum(),
+		},
+		{
+			"order",
+			NewHasher(3).WriteKey(One).WriteKey(Two).Sum(),
+			NewHasher(3).WriteKey(Two).WriteKey(One).Sum(),
+		},
+		{
+			"string boundary",
+			NewHasher(5).WriteString("ab").WriteString("c").Sum(),
+			NewHasher(5).WriteString("a").WriteString("bc").Sum(),
+		},
+		{
+			"string anagram",
+			FromString("ab"),
+			FromString("ba"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.a == test.b {
+				t.Errorf("got collision %v", test.a)
+			}
+		})
+	}
+}
@@ -1,23 +1,31 @@
 package main
 
 import (
+	"flag"
+
 	"go.uber.org/fx"
 
 	"orglang/go-engine/lib/db"
 	"orglang/go-engine/lib/kv"
 	"orglang/go-engine/lib/lf"
+	"orglang/go-engine/lib/mc"
 	"orglang/go-engine/lib/wp"
 	"orglang/go-engine/lib/ws"
 
 	"orglang/go-engine/pool/commexch"
+	"orglang/go-engine/pool/commlock"
 	"orglang/go-engine/pool/commturn"
 	poolconfexec "orglang/go-engine/pool/compexec"
+	poolcompstep "orglang/go-engine/pool/compstep"
 	"orglang/go-engine/pool/compvar"
+	"orglang/go-engine/pool/labormkt"
 	pooltermdef "orglang/go-engine/pool/termdef"
 	pooltypedef "orglang/go-engine/pool/typedef"
 	pooltypeexp "orglang/go-engine/pool/typeexp"
 	proccommexch "orglang/go-engine/proc/commexch"
+	proccommturn "orglang/go-engine/proc/commturn"
 	"orglang/go-engine/proc/compexec"
+	"orglang/go-engine/proc/compfuel"
 	"orglang/go-engine/proc/termdec"
 	"orglang/go-engine/proc/termdef"
 	"orglang/go-engine/proc/typedef"
@@ -27,11 +35,22 @@ import (
 )
 
 func main() {
+	migrateOnly := flag.Bool("migrate-only", false, "apply schema migrations and exit")
+	flag.Parse()
+	if *migrateOnly {
+		fx.New(
+			db.MigrateOnly,
+			kv.Module,
+			lf.Module,
+		).Run()
+		return
+	}
 	fx.New(
 		// lib
 		db.Module,
 		kv.Module,
 		lf.Module,
+		mc.Module,
 		wp.Module,
 		ws.Module,
 		// adt
@@ -40,14 +59,19 @@ func main() {
 		commexch.Module,
 		pooltermdef.Module,
 		poolconfexec.Module,
+		poolcompstep.Module,
+		labormkt.Module,
+		commlock.Module,
 		commturn.Module,
 		compvar.Module,
 		typedef.Module,
 		typeexp.Module,
 		proccommexch.Module,
+		proccommturn.Module,
 		termdef.Module,
 		termdec.Module,
 		compexec.Module,
+		compfuel.Module,
 		// app
 		web.Module,
 	).Run()
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"orglang/go-engine/adt/symbol"
+	"orglang/go-engine/adt/termsem"
+	"orglang/go-engine/adt/termvar"
+	"orglang/go-engine/adt/uniqsym"
+	"orglang/go-engine/adt/valkey"
+	"orglang/go-engine/proc/compexec"
+	"orglang/go-engine/proc/syntax"
+	"orglang/go-engine/proc/termdec"
+	"orglang/go-engine/proc/termdef"
+	"orglang/go-engine/proc/typedef"
+	"orglang/go-engine/proc/typeexp"
+)
+
+func runCheck(args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	mod, err := readModules(args)
+	if err != nil {
+		return err
+	}
+	_, err = elaborate(mod)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%v types, %v declarations, %v definitions checked\n",
+		len(mod.TypeDefs), len(mod.TermDecs), len(mod.TermDefs))
+	return nil
+}
+
+// читает и объединяет модули; позиции хранят имя файла,
+// поэтому диагностика различает файлы и после объединения
+func readModules(paths []string) (syntax.Module, error) {
+	var mod syntax.Module
+	var errs []error
+	for _, path := range paths {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return syntax.Module{}, err
+		}
+		part, err := syntax.ParseFile(path, string(src))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		mod.TypeDefs = append(mod.TypeDefs, part.TypeDefs...)
+		mod.TermDecs = append(mod.TermDecs, part.TermDecs...)
+		mod.TermDefs = append(mod.TermDefs, part.TermDefs...)
+	}
+	return mod, errors.Join(errs...)
+}
+
+// elaborate переводит модуль в окружение исполнителя и проверяет его
+// так же, как это делают сервисы при создании и исполнении
+func elaborate(mod syntax.Module) (compexec.Env, error) {
+	env := compexec.Env{
+		TypeExps: make(map[valkey.ADT]typeexp.ExpRec, len(mod.TypeDefs)),
+		TermDecs: make(map[uniqsym.ADT]termdec.DecRec, len(mod.TermDecs)),
+		TermDefs: make(map[uniqsym.ADT]termdef.DefRec, len(mod.TermDefs)),
+		TypeDefs: make(typeexp.Defs, len(mod.TypeDefs)),
+	}
+	var errs []error
+	for _, def := range mod.TypeDefs {
+		if _, ok := env.TypeDefs[def.Spec.TypeQN]; ok {
+			errs = append(errs, errAt(def.Pos, fmt.Errorf("type %v already defined", def.Spec.TypeQN)))
+			continue
+		}
+		rec, err := typeexp.ConvertSpecToRec(def.Spec.TypeExp)
+		if err != nil {
+			errs = append(errs, errAt(def.Pos, err))
+			continue
+		}
+		env.TypeDefs[def.Spec.TypeQN] = rec
+		env.TypeExps[rec.Key()] = rec
+	}
+	for _, def := range mod.TypeDefs {
+		rec, ok := env.TypeDefs[def.Spec.TypeQN]
+		if !ok {
+			continue
+		}
+		err := typeexp.CheckDef(env.TypeDefs, def.Spec.TypeQN, rec)
+		if err != nil {
+			errs = append(errs, errAt(def.Pos, err))
+		}
+	}
+	for _, dec := range mod.TermDecs {
+		if _, ok := env.TermDecs[dec.Spec.TermQN]; ok {
+			errs = append(errs, errAt(dec.Pos, fmt.Errorf("process %v already declared", dec.Spec.TermQN)))
+			continue
+		}
+		rec, err := convertDecSpec(env.TypeDefs, dec.Spec)
+		if err != nil {
+			errs = append(errs, errAt(dec.Pos, err))
+			continue
+		}
+		env.TermDecs[dec.Spec.TermQN] = rec
+	}
+	for _, def := range mod.TermDefs {
+		if _, ok := env.TermDefs[def.Spec.ProcQN]; ok {
+			errs = append(errs, errAt(def.Pos, fmt.Errorf("process %v already defined", def.Spec.ProcQN)))
+			continue
+		}
+		env.TermDefs[def.Spec.ProcQN] = termdef.DefRec{ProcES: def.Spec.ProcES}
+	}
+	// тела проверяются после сбора окружения, так как могут ссылаться друг на друга
+	for _, def := range mod.TermDefs {
+		dec, ok := env.TermDecs[def.Spec.ProcQN]
+		if !ok {
+			errs = append(errs, errAt(def.Pos, termdec.ErrSymMissingInEnv(def.Spec.ProcQN)))
+			continue
+		}
+		err := compexec.CheckExp(env, convertDecToCtx(env, dec), def.Spec.ProcES)
+		if err != nil {
+			pos := def.Pos
+			var expErr *compexec.ExpError
+			if errors.As(err, &expErr) {
+				pos = def.PosOf(expErr.Exp)
+			}
+			errs = append(errs, errAt(pos, fmt.Errorf("process %v: %w", def.Spec.ProcQN, err)))
+		}
+	}
+	return env, errors.Join(errs...)
+}
+
+func convertDecSpec(typeDefs typeexp.Defs, spec termdec.DecSpec) (termdec.DecRec, error) {
+	liabVar, err := convertVarSpec(typeDefs, spec.LiabVar)
+	if err != nil {
+		return termdec.DecRec{}, err
+	}
+	assetVars := make([]termvar.VarRec, 0, len(spec.AssetVars))
+	for _, assetVar := range spec.AssetVars {
+		rec, err := convertVarSpec(typeDefs, assetVar)
+		if err != nil {
+			return termdec.DecRec{}, err
+		}
+		assetVars = append(assetVars, rec)
+	}
+	return termdec.DecRec{TermRef: termsem.New(), TermQN: spec.TermQN, LiabVar: liabVar, AssetVars: assetVars}, nil
+}
+
+func convertVarSpec(typeDefs typeexp.Defs, spec termvar.VarSpec) (termvar.VarRec, error) {
+	rec, ok := typeDefs[spec.TypeQN]
+	if !ok {
+		return termvar.VarRec{}, typedef.ErrSymMissingInEnv(spec.TypeQN)
+	}
+	return termvar.VarRec{ChnlPH: spec.ChnlPH, ExpVK: rec.Key()}, nil
+}
+
+func convertDecToCtx(env compexec.Env, dec termdec.DecRec) typedef.Context {
+	assets := make(map[symbol.ADT]typeexp.ExpRec, len(dec.AssetVars))
+	for _, assetVar := range dec.AssetVars {
+		assets[assetVar.ChnlPH] = env.TypeExps[assetVar.ExpVK]
+	}
+	liabs := map[symbol.ADT]typeexp.ExpRec{
+		dec.LiabVar.ChnlPH: env.TypeExps[dec.LiabVar.ExpVK],
+	}
+	return typedef.Context{Assets: assets, Liabs: liabs}
+}
+
+func errAt(pos syntax.Pos, err error) error {
+	return &syntax.Error{Pos: pos, Msg: err.Error()}
+}
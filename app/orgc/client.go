@@ -0,0 +1,49 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/go-resty/resty/v2"
+	"go.uber.org/fx"
+
+	"orglang/go-engine/lib/e2e"
+	"orglang/go-engine/lib/kv"
+)
+
+type client struct {
+	fx.In
+	Resty       *resty.Client
+	PoolExecAPI e2e.PoolExecAPI
+	ProcDecAPI  e2e.ProcDecAPI
+	ProcDefAPI  e2e.ProcDefAPI
+	TypeDefAPI  e2e.TypeDefAPI
+}
+
+// newClient собирает клиентов движка по конфигурации из reference.yaml
+// и application.yaml текущего каталога
+func newClient() (client, error) {
+	var c client
+	app := fx.New(
+		kv.Module,
+		e2e.Module,
+		fx.Provide(
+			newSlogLogger,
+			newClientCS,
+			newRestyClient,
+		),
+		fx.Populate(&c),
+		fx.NopLogger,
+	)
+	return c, app.Err()
+}
+
+// вывод команд идет в stdout, поэтому журнал пишется в stderr
+func newSlogLogger() *slog.Logger {
+	opts := &slog.HandlerOptions{Level: slog.LevelWarn}
+	return slog.New(slog.NewTextHandler(os.Stderr, opts))
+}
+
+func newRestyClient(dto clientCS) *resty.Client {
+	return resty.New().SetBaseURL(dto.HTTP.URL)
+}
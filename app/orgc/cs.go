@@ -0,0 +1,26 @@
+package main
+
+import (
+	"orglang/go-engine/lib/kv"
+)
+
+func newClientCS(loader kv.Loader) (clientCS, error) {
+	dto := new(clientCS)
+	loadingErr := loader.Load("client", dto)
+	if loadingErr != nil {
+		return clientCS{}, loadingErr
+	}
+	validateErr := dto.Validate()
+	if validateErr != nil {
+		return clientCS{}, validateErr
+	}
+	return *dto, nil
+}
+
+type clientCS struct {
+	HTTP httpCS `mapstructure:"http"`
+}
+
+type httpCS struct {
+	URL string `mapstructure:"url"`
+}
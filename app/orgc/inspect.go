@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"orglang/go-engine/adt/compsem"
+	"orglang/go-engine/adt/identity"
+)
+
+func runInspect(args []string) error {
+	fs := flag.NewFlagSet("inspect", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	pool := fs.Bool("pool", false, "inspect pool instead of process")
+	err := fs.Parse(args)
+	if err != nil || fs.NArg() != 1 {
+		return errUsage
+	}
+	compID, err := identity.ConvertFromString(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	if *pool {
+		snap, err := c.PoolExecAPI.Retrieve(compsem.MsgFromRef(compsem.SemRef{CompID: compID}))
+		if err != nil {
+			return err
+		}
+		return printJSON(snap)
+	}
+	resp, err := c.Resty.R().
+		SetPathParam("id", identity.ConvertToString(compID)).
+		Get("/api/v1/procs/{id}")
+	if err != nil {
+		return err
+	}
+	if resp.IsError() {
+		return fmt.Errorf("retrieval failed: %v %s", resp.Status(), resp.Body())
+	}
+	var out bytes.Buffer
+	err = json.Indent(&out, resp.Body(), "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(out.String())
+	return nil
+}
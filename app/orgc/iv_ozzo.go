@@ -0,0 +1,17 @@
+package main
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+func (dto clientCS) Validate() error {
+	return validation.ValidateStruct(&dto,
+		validation.Field(&dto.HTTP, validation.Required),
+	)
+}
+
+func (dto httpCS) Validate() error {
+	return validation.ValidateStruct(&dto,
+		validation.Field(&dto.URL, validation.Required),
+	)
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+
+	"orglang/go-engine/adt/uniqsym"
+	"orglang/go-engine/proc/syntax"
+	"orglang/go-engine/proc/termdec"
+	"orglang/go-engine/proc/termdef"
+	"orglang/go-engine/proc/typedef"
+	"orglang/go-engine/proc/typeexp"
+)
+
+func runLoad(args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	mod, err := readModules(args)
+	if err != nil {
+		return err
+	}
+	// модуль проверяется целиком до первой загрузки
+	env, err := elaborate(mod)
+	if err != nil {
+		return err
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	for _, group := range sortTypeDefs(mod.TypeDefs, env.TypeDefs) {
+		err := loadTypeDefs(c, group)
+		if err != nil {
+			return err
+		}
+	}
+	for _, dec := range mod.TermDecs {
+		_, err := c.ProcDecAPI.Create(termdec.MsgFromDecSpec(dec.Spec))
+		if err != nil {
+			return fmt.Errorf("decl %v: %w", dec.Spec.TermQN, err)
+		}
+		fmt.Printf("decl %v loaded\n", dec.Spec.TermQN)
+	}
+	// определения ссылаются только на декларации, поэтому идут последними
+	for _, def := range mod.TermDefs {
+		_, err := c.ProcDefAPI.Create(termdef.MsgFromDefSpec(def.Spec))
+		if err != nil {
+			return fmt.Errorf("proc %v: %w", def.Spec.ProcQN, err)
+		}
+		fmt.Printf("proc %v loaded\n", def.Spec.ProcQN)
+	}
+	return nil
+}
+
+// loadTypeDefs создает одиночный тип обычным вызовом,
+// а группу взаимно рекурсивных типов одним пакетом
+func loadTypeDefs(c client, group []syntax.TypeDef) error {
+	if len(group) == 1 {
+		def := group[0]
+		_, err := c.TypeDefAPI.Create(typedef.MsgFromDefSpec(def.Spec))
+		if err != nil {
+			return fmt.Errorf("type %v: %w", def.Spec.TypeQN, err)
+		}
+		fmt.Printf("type %v loaded\n", def.Spec.TypeQN)
+		return nil
+	}
+	specs := make([]typedef.DefSpec, 0, len(group))
+	for _, def := range group {
+		specs = append(specs, def.Spec)
+	}
+	resp, err := c.Resty.R().
+		SetBody(typedef.MsgFromDefSpecs(specs)).
+		Post("/api/v1/types/batch")
+	if err != nil {
+		return err
+	}
+	if resp.IsError() {
+		return fmt.Errorf("types %v: creation failed: %v %s", collectTypeQNs(group), resp.Status(), resp.Body())
+	}
+	fmt.Printf("types %v loaded\n", collectTypeQNs(group))
+	return nil
+}
+
+func collectTypeQNs(defs []syntax.TypeDef) []uniqsym.ADT {
+	typeQNs := make([]uniqsym.ADT, 0, len(defs))
+	for _, def := range defs {
+		typeQNs = append(typeQNs, def.Spec.TypeQN)
+	}
+	return typeQNs
+}
+
+// sortTypeDefs разбивает типы на группы взаимной рекурсии (алгоритм Тарьяна)
+//
+// Группы выдаются в порядке зависимостей: ссылки из группы указывают
+// на саму группу или на уже загруженные типы.
+func sortTypeDefs(defs []syntax.TypeDef, recs typeexp.Defs) [][]syntax.TypeDef {
+	byQN := make(map[uniqsym.ADT]syntax.TypeDef, len(defs))
+	for _, def := range defs {
+		byQN[def.Spec.TypeQN] = def
+	}
+	indices := make(map[uniqsym.ADT]int, len(defs))
+	lows := make(map[uniqsym.ADT]int, len(defs))
+	onStack := make(map[uniqsym.ADT]bool, len(defs))
+	var stack []syntax.TypeDef
+	var groups [][]syntax.TypeDef
+	var visit func(syntax.TypeDef)
+	visit = func(def syntax.TypeDef) {
+		typeQN := def.Spec.TypeQN
+		indices[typeQN] = len(indices)
+		lows[typeQN] = indices[typeQN]
+		stack = append(stack, def)
+		onStack[typeQN] = true
+		for _, linkQN := range typeexp.CollectLinks(slices.Values([]typeexp.ExpRec{recs[typeQN]})) {
+			linkDef, ok := byQN[linkQN]
+			if !ok {
+				continue
+			}
+			_, seen := indices[linkQN]
+			switch {
+			case !seen:
+				visit(linkDef)
+				lows[typeQN] = min(lows[typeQN], lows[linkQN])
+			case onStack[linkQN]:
+				lows[typeQN] = min(lows[typeQN], indices[linkQN])
+			}
+		}
+		if lows[typeQN] != indices[typeQN] {
+			return
+		}
+		var group []syntax.TypeDef
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top.Spec.TypeQN] = false
+			group = append(group, top)
+			if top.Spec.TypeQN == typeQN {
+				break
+			}
+		}
+		slices.Reverse(group)
+		groups = append(groups, group)
+	}
+	for _, def := range defs {
+		if _, seen := indices[def.Spec.TypeQN]; !seen {
+			visit(def)
+		}
+	}
+	return groups
+}
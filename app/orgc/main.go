@@ -0,0 +1,64 @@
+// Command orgc loads and runs Orglang modules against the engine.
+//
+// Usage:
+//
+//	orgc check FILE...
+//	orgc load FILE...
+//	orgc run -term QN -liab PH=QN [-asset PH=QN]...
+//	orgc inspect [-pool] ID
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+)
+
+type command struct {
+	usage string
+	run   func(args []string) error
+}
+
+var commands = map[string]command{
+	"check":   {"check FILE...", runCheck},
+	"load":    {"load FILE...", runLoad},
+	"run":     {"run -term QN -liab PH=QN [-asset PH=QN]...", runRun},
+	"inspect": {"inspect [-pool] ID", runInspect},
+}
+
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "orgc: unknown command %q\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+	err := cmd.run(os.Args[2:])
+	if errors.Is(err, errUsage) {
+		fmt.Fprintf(os.Stderr, "usage: orgc %v\n", cmd.usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintln(os.Stderr, "usage:")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  orgc %v\n", commands[name].usage)
+	}
+}
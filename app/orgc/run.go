@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/orglang/go-sdk/adt/compvar"
+	"github.com/orglang/go-sdk/pool/compexec"
+)
+
+func runRun(args []string) error {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	termQN := fs.String("term", "", "pool term qualified name")
+	var liabVars, assetVars varFlags
+	fs.Var(&liabVars, "liab", "provided channel as PH=QN")
+	fs.Var(&assetVars, "asset", "consumed channel as PH=QN, repeatable")
+	interval := fs.Duration("interval", time.Second, "snapshot polling interval")
+	err := fs.Parse(args)
+	if err != nil || *termQN == "" || len(liabVars) != 1 || fs.NArg() > 0 {
+		return errUsage
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	compRef, err := c.PoolExecAPI.Create(compexec.ExecSpec{
+		TermQN:    *termQN,
+		LiabVar:   liabVars[0],
+		AssetVars: assetVars,
+	})
+	if err != nil {
+		return err
+	}
+	err = printJSON(compRef)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return streamSnaps(ctx, *interval, func() (any, error) {
+		return c.PoolExecAPI.Retrieve(compRef)
+	})
+}
+
+// streamSnaps печатает снимок вычисления при каждом его изменении,
+// пока не будет прерван
+func streamSnaps(ctx context.Context, interval time.Duration, retrieve func() (any, error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var prev []byte
+	for {
+		snap, err := retrieve()
+		if err != nil {
+			return err
+		}
+		next, err := json.Marshal(snap)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(prev, next) {
+			fmt.Println(string(next))
+			prev = next
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+type varFlags []compvar.VarSpec
+
+func (f *varFlags) String() string {
+	vars := make([]string, 0, len(*f))
+	for _, v := range *f {
+		vars = append(vars, v.ChnlPH+"="+v.TermQN)
+	}
+	return strings.Join(vars, ",")
+}
+
+func (f *varFlags) Set(s string) error {
+	chnlPH, termQN, ok := strings.Cut(s, "=")
+	if !ok || chnlPH == "" || termQN == "" {
+		return fmt.Errorf("invalid variable: want PH=QN, got %q", s)
+	}
+	*f = append(*f, compvar.VarSpec{ChnlPH: chnlPH, TermQN: termQN})
+	return nil
+}
+
+func printJSON(v any) error {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
@@ -0,0 +1,12 @@
+package postgres
+
+import (
+	"embed"
+)
+
+// Migrations версионированные миграции схемы: номер версии
+// задает префикс имени файла, а остаток совпадает с changeSet
+// Liquibase, которым схема накатывалась раньше
+//
+//go:embed migrations/*.sql
+var Migrations embed.FS
@@ -1,3 +1,7 @@
+-- схема названа именем владельца и потому первой стоит в search_path
+CREATE SCHEMA IF NOT EXISTS AUTHORIZATION CURRENT_USER;
+CREATE EXTENSION IF NOT EXISTS ltree;
+
 -- связка описаний с квалифицированными синонимами 
 CREATE TABLE pool_desc_binds (
 	desc_qn ltree UNIQUE,
@@ -102,6 +106,14 @@ CREATE TABLE proc_term_decs (
     asset_vars jsonb
 );
 
+-- ревизии тел процессов
+CREATE TABLE proc_term_defs (
+	term_id varchar,
+	term_rn bigint,
+	proc_es jsonb,
+	UNIQUE (term_id, term_rn)
+);
+
 -- связка воплощений с квалифицированными синонимами 
 CREATE TABLE proc_impl_binds (
 	impl_qn ltree UNIQUE,
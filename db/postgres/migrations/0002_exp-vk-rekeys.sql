@@ -0,0 +1,167 @@
+-- перевод exp_vk на структурные ключи (valkey.Hasher)
+--
+-- ключ = sha256(тег || поля), первые 8 байт, диапазон [2^62, 2^63);
+-- тег совпадает с kind, ключи полей по 8 байт, строки с префиксом длины
+
+CREATE FUNCTION rekey_sum(data bytea) RETURNS bigint AS $$
+	SELECT (('x' || encode(substr(sha256(data), 1, 8), 'hex'))::bit(64)::bigint
+		& 4611686018427387903) | 4611686018427387904
+$$ LANGUAGE sql IMMUTABLE STRICT;
+
+CREATE FUNCTION rekey_str(str text) RETURNS bytea AS $$
+	SELECT int4send(length(convert_to(str, 'UTF8'))) || convert_to(str, 'UTF8')
+$$ LANGUAGE sql IMMUTABLE STRICT;
+
+CREATE FUNCTION rekey_proc_exp(old_vk bigint) RETURNS bigint AS $$
+DECLARE
+	st proc_type_exps;
+BEGIN
+	SELECT * INTO STRICT st FROM proc_type_exps WHERE exp_vk = old_vk;
+	CASE st.kind
+	WHEN 1 THEN
+		RETURN 1;
+	WHEN 2 THEN
+		RETURN rekey_sum(int2send(st.kind) || rekey_str(st.spec->>'link'));
+	WHEN 3, 4 THEN
+		RETURN rekey_sum(int2send(st.kind)
+			|| int8send(rekey_proc_exp((st.spec->(CASE st.kind WHEN 3 THEN 'tensor' ELSE 'lolli' END)->>'on')::bigint))
+			|| int8send(rekey_proc_exp((st.spec->(CASE st.kind WHEN 3 THEN 'tensor' ELSE 'lolli' END)->>'to')::bigint)));
+	WHEN 5, 6 THEN
+		RETURN rekey_sum(int2send(st.kind) || coalesce((
+			SELECT string_agg(
+				rekey_str(ch->>'on') || int8send(rekey_proc_exp((ch->>'to')::bigint)),
+				''::bytea ORDER BY ch->>'on' COLLATE "C")
+			FROM jsonb_array_elements(st.spec->(CASE st.kind WHEN 5 THEN 'plus' ELSE 'with' END)) ch
+		), ''::bytea));
+	WHEN 7, 8 THEN
+		RETURN rekey_sum(int2send(st.kind)
+			|| int8send(rekey_proc_exp((st.spec->(CASE st.kind WHEN 7 THEN 'up' ELSE 'down' END)->>'to')::bigint)));
+	ELSE
+		RAISE EXCEPTION 'unexpected kind %', st.kind;
+	END CASE;
+END
+$$ LANGUAGE plpgsql STABLE STRICT;
+
+CREATE FUNCTION rekey_pool_exp(old_vk bigint) RETURNS bigint AS $$
+DECLARE
+	st pool_type_exps;
+	sum_spec jsonb;
+BEGIN
+	SELECT * INTO STRICT st FROM pool_type_exps WHERE exp_vk = old_vk;
+	CASE st.kind
+	WHEN 1 THEN
+		RETURN 1;
+	WHEN 2 THEN
+		RETURN rekey_sum(int2send(st.kind) || rekey_str(st.spec->>'link'));
+	WHEN 5, 6 THEN
+		sum_spec := st.spec->(CASE st.kind WHEN 5 THEN 'plus' ELSE 'with' END);
+		RETURN rekey_sum(int2send(st.kind)
+			|| int8send(rekey_pool_exp((sum_spec->>'to')::bigint))
+			|| coalesce((
+				SELECT string_agg(rekey_str(qn), ''::bytea ORDER BY nr)
+				FROM jsonb_array_elements_text(sum_spec->'on') WITH ORDINALITY AS on_qns(qn, nr)
+			), ''::bytea));
+	WHEN 7, 8 THEN
+		RETURN rekey_sum(int2send(st.kind)
+			|| int8send(rekey_pool_exp((st.spec->(CASE st.kind WHEN 7 THEN 'up' ELSE 'down' END)->>'to')::bigint)));
+	ELSE
+		RAISE EXCEPTION 'unexpected kind %', st.kind;
+	END CASE;
+END
+$$ LANGUAGE plpgsql STABLE STRICT;
+
+-- отображение строится целиком до изменения таблиц
+CREATE TEMPORARY TABLE proc_exp_rekeys AS
+SELECT exp_vk AS old_vk, rekey_proc_exp(exp_vk) AS new_vk FROM proc_type_exps;
+
+CREATE TEMPORARY TABLE pool_exp_rekeys AS
+SELECT exp_vk AS old_vk, rekey_pool_exp(exp_vk) AS new_vk FROM pool_type_exps;
+
+CREATE FUNCTION rekey_proc_vk(vk jsonb) RETURNS jsonb AS $$
+	SELECT to_jsonb(new_vk) FROM proc_exp_rekeys WHERE old_vk = vk::bigint
+$$ LANGUAGE sql STABLE STRICT;
+
+CREATE FUNCTION rekey_pool_vk(vk jsonb) RETURNS jsonb AS $$
+	SELECT to_jsonb(new_vk) FROM pool_exp_rekeys WHERE old_vk = vk::bigint
+$$ LANGUAGE sql STABLE STRICT;
+
+CREATE FUNCTION rekey_proc_var(var jsonb) RETURNS jsonb AS $$
+	SELECT CASE WHEN var ? 'vk'
+		THEN jsonb_set(var, '{vk}', coalesce(rekey_proc_vk(var->'vk'), var->'vk'))
+		ELSE var
+	END
+$$ LANGUAGE sql STABLE STRICT;
+
+CREATE FUNCTION rekey_pool_var(var jsonb) RETURNS jsonb AS $$
+	SELECT CASE WHEN var ? 'vk'
+		THEN jsonb_set(var, '{vk}', coalesce(rekey_pool_vk(var->'vk'), var->'vk'))
+		ELSE var
+	END
+$$ LANGUAGE sql STABLE STRICT;
+
+-- ссылки внутри спецификаций
+UPDATE proc_type_exps SET spec = CASE kind
+	WHEN 3 THEN jsonb_build_object('tensor', jsonb_build_object(
+		'on', rekey_proc_vk(spec->'tensor'->'on'),
+		'to', rekey_proc_vk(spec->'tensor'->'to')))
+	WHEN 4 THEN jsonb_build_object('lolli', jsonb_build_object(
+		'on', rekey_proc_vk(spec->'lolli'->'on'),
+		'to', rekey_proc_vk(spec->'lolli'->'to')))
+	WHEN 5 THEN jsonb_build_object('plus', (
+		SELECT jsonb_agg(jsonb_build_object('on', ch->'on', 'to', rekey_proc_vk(ch->'to')))
+		FROM jsonb_array_elements(spec->'plus') ch))
+	WHEN 6 THEN jsonb_build_object('with', (
+		SELECT jsonb_agg(jsonb_build_object('on', ch->'on', 'to', rekey_proc_vk(ch->'to')))
+		FROM jsonb_array_elements(spec->'with') ch))
+	WHEN 7 THEN jsonb_build_object('up', jsonb_build_object('to', rekey_proc_vk(spec->'up'->'to')))
+	WHEN 8 THEN jsonb_build_object('down', jsonb_build_object('to', rekey_proc_vk(spec->'down'->'to')))
+	ELSE spec
+END;
+
+UPDATE pool_type_exps SET spec = CASE kind
+	WHEN 5 THEN jsonb_build_object('plus', jsonb_set(spec->'plus', '{to}', rekey_pool_vk(spec->'plus'->'to')))
+	WHEN 6 THEN jsonb_build_object('with', jsonb_set(spec->'with', '{to}', rekey_pool_vk(spec->'with'->'to')))
+	WHEN 7 THEN jsonb_build_object('up', jsonb_build_object('to', rekey_pool_vk(spec->'up'->'to')))
+	WHEN 8 THEN jsonb_build_object('down', jsonb_build_object('to', rekey_pool_vk(spec->'down'->'to')))
+	ELSE spec
+END;
+
+-- старые ключи 32-битные, новые не меньше 2^62, поэтому UNIQUE не нарушается;
+-- от коллизий новых ключей UNIQUE (exp_vk) не защищает: значащих бит 62,
+-- и среди n выражений коллизия случается с вероятностью около n^2/2^63
+-- (для миллиона выражений порядка 10^-7), а вставка с ON CONFLICT DO NOTHING
+-- молча склеит два разных выражения
+UPDATE proc_type_exps e SET exp_vk = m.new_vk FROM proc_exp_rekeys m WHERE e.exp_vk = m.old_vk;
+UPDATE proc_type_exps e SET sup_exp_vk = m.new_vk FROM proc_exp_rekeys m WHERE e.sup_exp_vk = m.old_vk;
+UPDATE proc_type_defs d SET exp_vk = m.new_vk FROM proc_exp_rekeys m WHERE d.exp_vk = m.old_vk;
+-- знак ключа переменной означает получение или лишение
+UPDATE proc_comp_vars v SET exp_vk = sign(v.exp_vk) * m.new_vk FROM proc_exp_rekeys m WHERE abs(v.exp_vk) = m.old_vk;
+
+UPDATE pool_type_exps e SET exp_vk = m.new_vk FROM pool_exp_rekeys m WHERE e.exp_vk = m.old_vk;
+UPDATE pool_type_exps e SET sup_exp_vk = m.new_vk FROM pool_exp_rekeys m WHERE e.sup_exp_vk = m.old_vk;
+UPDATE pool_type_defs d SET exp_vk = m.new_vk FROM pool_exp_rekeys m WHERE d.exp_vk = m.old_vk;
+UPDATE pool_comp_vars v SET exp_vk = sign(v.exp_vk) * m.new_vk FROM pool_exp_rekeys m WHERE abs(v.exp_vk) = m.old_vk;
+
+-- ключи переменных внутри объявлений (termvar.VarRecDS)
+UPDATE proc_term_decs SET
+	liab_var = rekey_proc_var(liab_var),
+	asset_vars = CASE jsonb_typeof(asset_vars)
+		WHEN 'array' THEN coalesce((
+			SELECT jsonb_agg(rekey_proc_var(av) ORDER BY nr)
+			FROM jsonb_array_elements(asset_vars) WITH ORDINALITY AS a(av, nr)
+		), '[]'::jsonb)
+		ELSE asset_vars
+	END;
+
+UPDATE pool_term_decs SET
+	liab_var = rekey_pool_var(liab_var),
+	asset_vars = CASE jsonb_typeof(asset_vars)
+		WHEN 'array' THEN coalesce((
+			SELECT jsonb_agg(rekey_pool_var(av) ORDER BY nr)
+			FROM jsonb_array_elements(asset_vars) WITH ORDINALITY AS a(av, nr)
+		), '[]'::jsonb)
+		ELSE asset_vars
+	END;
+
+DROP FUNCTION rekey_proc_var, rekey_pool_var, rekey_proc_vk, rekey_pool_vk, rekey_proc_exp, rekey_pool_exp, rekey_str, rekey_sum;
+DROP TABLE proc_exp_rekeys, pool_exp_rekeys;
@@ -0,0 +1,14 @@
+-- исходящие шаги пулов (outbox)
+-- пишутся в транзакции шага, удаляются после успешного исполнения
+CREATE TABLE pool_comp_steps (
+	step_id varchar PRIMARY KEY,
+	comp_id varchar,
+	spec jsonb,
+	-- 1 - ожидает, 2 - исчерпал попытки
+	status smallint,
+	attempts integer DEFAULT 0,
+	due_at timestamptz DEFAULT now(),
+	reason text
+);
+
+CREATE INDEX pool_comp_steps_due ON pool_comp_steps (due_at) WHERE status = 1;
@@ -0,0 +1,9 @@
+-- закрепление вычислений за узлами
+-- истекшая аренда означает, что узел умер, и вычисление может забрать другой
+CREATE TABLE pool_comp_leases (
+	comp_id varchar PRIMARY KEY,
+	node_id varchar,
+	expires_at timestamptz
+);
+
+CREATE INDEX pool_comp_leases_node ON pool_comp_leases (node_id);
@@ -0,0 +1,9 @@
+-- журнал шагов вычислений для воспроизведения
+-- шаг хранится вместе с обменами, какими он их увидел
+CREATE TABLE proc_comp_journal (
+	comp_id varchar,
+	comp_rn bigint,
+	exp jsonb,
+	exchs jsonb,
+	PRIMARY KEY (comp_id, comp_rn)
+);
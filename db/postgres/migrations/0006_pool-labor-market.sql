@@ -0,0 +1,28 @@
+-- открытые вакансии и заявки рынка труда
+-- строка удаляется, когда размещение сведено со встречным
+CREATE TABLE pool_labor_posts (
+	post_id varchar PRIMARY KEY,
+	-- 1 - вакансия, 2 - заявка
+	side smallint,
+	comp_id varchar,
+	comm_id varchar,
+	term_qn varchar,
+	comm_var jsonb,
+	next_vk bigint,
+	cont_exp jsonb,
+	posted_at timestamptz
+);
+
+CREATE INDEX pool_labor_posts_side ON pool_labor_posts (side, posted_at);
+
+-- наймы по сведенным размещениям
+-- нужны политикам, которые учитывают историю и загрузку соискателей
+CREATE TABLE pool_labor_hires (
+	hire_id varchar PRIMARY KEY,
+	comm_id varchar,
+	comp_id varchar,
+	hired_at timestamptz,
+	fired_at timestamptz
+);
+
+CREATE INDEX pool_labor_hires_comp ON pool_labor_hires (comp_id);
@@ -0,0 +1,21 @@
+-- пометки зависших вычислений, по строке на ожидание
+-- заменяются при каждом обнаружении; detected_at хранит,
+-- когда ожидание было замечено впервые
+CREATE TABLE proc_comm_locks (
+	lock_id varchar,
+	comp_id varchar,
+	comp_qn varchar,
+	comm_id varchar,
+	chnl_id varchar,
+	chnl_ph varchar,
+	holder_id varchar,
+	-- 1 - цикл, 2 - сиротское ожидание
+	kind smallint,
+	detected_at timestamptz,
+	seen_at timestamptz,
+	PRIMARY KEY (comp_id, chnl_id)
+);
+
+CREATE TABLE pool_comm_locks (
+	LIKE proc_comm_locks INCLUDING ALL
+);
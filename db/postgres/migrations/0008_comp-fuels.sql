@@ -0,0 +1,26 @@
+-- запасы шагов вычислений, списываются на каждом шаге;
+-- вычисление без строки еще не начинало тратить запас по умолчанию
+CREATE TABLE proc_comp_fuels (
+	comp_id varchar PRIMARY KEY,
+	-- пул, общий запас которого тратит вычисление
+	pool_id varchar,
+	fuel_nr bigint
+);
+
+CREATE INDEX proc_comp_fuels_pool ON proc_comp_fuels (pool_id);
+
+CREATE TABLE pool_comp_fuels (
+	comp_id varchar PRIMARY KEY,
+	fuel_nr bigint
+);
+
+-- шаги процессов, остановленные без запаса; возобновляются пополнением
+-- (шаги пулов остаются в pool_comp_steps со статусом 3)
+CREATE TABLE proc_comp_halts (
+	halt_id varchar PRIMARY KEY,
+	comp_id varchar,
+	exp jsonb,
+	halted_at timestamptz
+);
+
+CREATE INDEX proc_comp_halts_comp ON proc_comp_halts (comp_id);
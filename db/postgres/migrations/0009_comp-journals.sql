@@ -0,0 +1,14 @@
+-- окружение шага: ключи и ревизии определений, на которых он взят;
+-- у шагов, взятых раньше, окружения нет
+ALTER TABLE proc_comp_journal ADD COLUMN env jsonb;
+
+-- журнал шагов пулов для воспроизведения
+-- переменные пулов не хранят ревизию шага, поэтому снепшот
+-- хранит и конфигурацию, и записанные шагом переменные
+CREATE TABLE pool_comp_journal (
+	comp_id varchar,
+	comp_rn bigint,
+	exp jsonb,
+	snap jsonb,
+	PRIMARY KEY (comp_id, comp_rn)
+);
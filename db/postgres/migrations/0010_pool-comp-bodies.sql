@@ -0,0 +1,3 @@
+-- тела процессов, порожденных пулами, ждут в очереди пулов;
+-- у таких шагов spec пуст, а тело хранится в своем виде
+ALTER TABLE pool_comp_steps ADD COLUMN body jsonb;
@@ -1,2 +0,0 @@
-CREATE SCHEMA ${schema.name};
-CREATE EXTENSION ltree;
@@ -0,0 +1,11 @@
+package sqlite
+
+import (
+	"embed"
+)
+
+// Migrations версионированные миграции встроенного хранилища:
+// версии и имена совпадают с миграциями postgres
+//
+//go:embed migrations/*.sql
+var Migrations embed.FS
@@ -0,0 +1,182 @@
+-- схема встроенного хранилища повторяет db/postgres/migrations
+-- версиями и именами миграций, поэтому обе схемы сверяются по schema_versions
+--
+-- вместо ltree квалифицированные имена хранятся текстом:
+-- поиск по ним идет только на равенство, и уникального индекса достаточно
+-- вместо наследования таблиц переменные объединяются представлением
+-- вместо jsonb документы хранятся текстом
+
+-- связка описаний с квалифицированными синонимами
+CREATE TABLE pool_desc_binds (
+	desc_qn text UNIQUE,
+	desc_id text,
+	kind integer
+);
+
+CREATE TABLE pool_type_defs (
+	type_id text UNIQUE,
+	type_rn integer,
+	exp_vk integer
+);
+
+CREATE TABLE pool_type_exps (
+	exp_vk integer UNIQUE,
+	sup_exp_vk integer,
+	kind integer,
+	spec text
+);
+
+CREATE TABLE pool_term_decs (
+	term_id text UNIQUE,
+	term_rn integer,
+	liab_var text,
+	asset_vars text
+);
+
+-- связка воплощений с квалифицированными синонимами
+CREATE TABLE pool_impl_binds (
+	impl_qn text UNIQUE,
+	impl_id text,
+	kind integer
+);
+
+CREATE TABLE pool_comp_execs (
+	comp_id text UNIQUE,
+	comp_rn integer,
+	liab_mode integer
+);
+
+CREATE TABLE pool_struct_vars (
+	comp_id text,
+	comp_rn integer, -- только для сортировки
+	comm_id text,
+	chnl_id text,
+	chnl_ph text,
+	exp_vk integer,
+	side integer
+);
+
+CREATE TABLE pool_linear_vars (
+	comp_id text,
+	comp_rn integer, -- только для сортировки
+	comm_id text,
+	chnl_id text,
+	chnl_ph text,
+	exp_vk integer,
+	side integer
+);
+
+CREATE VIEW pool_comp_vars AS
+	SELECT * FROM pool_struct_vars
+	UNION ALL
+	SELECT * FROM pool_linear_vars;
+
+CREATE TABLE pool_comm_exchs (
+	comm_id text UNIQUE,
+	comm_rn integer,
+	offset_nr integer
+);
+
+CREATE TABLE pool_comm_turns (
+	comm_id text,
+	comm_rn integer,
+	comp_id text,
+	chnl_id text,
+	kind integer,
+	exp text
+);
+
+-- связка описаний с квалифицированными синонимами
+CREATE TABLE proc_desc_binds (
+	desc_qn text UNIQUE,
+	desc_id text,
+	kind integer
+);
+
+CREATE TABLE proc_type_defs (
+	type_id text UNIQUE,
+	type_rn integer,
+	exp_vk integer
+);
+
+CREATE TABLE proc_type_exps (
+	exp_vk integer UNIQUE,
+	sup_exp_vk integer,
+	kind integer,
+	spec text
+);
+
+CREATE TABLE proc_term_decs (
+	term_id text UNIQUE,
+	term_rn integer,
+	liab_var text,
+	asset_vars text
+);
+
+-- ревизии тел процессов
+CREATE TABLE proc_term_defs (
+	term_id text,
+	term_rn integer,
+	proc_es text,
+	UNIQUE (term_id, term_rn)
+);
+
+-- связка воплощений с квалифицированными синонимами
+CREATE TABLE proc_impl_binds (
+	impl_qn text UNIQUE,
+	impl_id text,
+	kind integer
+);
+
+CREATE TABLE proc_comp_execs (
+	comp_id text UNIQUE,
+	comp_rn integer,
+	liab_mode integer
+);
+
+CREATE TABLE proc_struct_vars (
+	comp_id text,
+	comp_rn integer, -- только для сортировки
+	comm_id text,
+	chnl_id text,
+	chnl_ph text,
+	exp_vk integer,
+	side integer
+);
+
+CREATE TABLE proc_linear_vars (
+	comp_id text,
+	comp_rn integer, -- только для сортировки
+	comm_id text,
+	chnl_id text,
+	chnl_ph text,
+	exp_vk integer,
+	side integer
+);
+
+CREATE VIEW proc_comp_vars AS
+	SELECT * FROM proc_struct_vars
+	UNION ALL
+	SELECT * FROM proc_linear_vars;
+
+CREATE TABLE proc_comm_exchs (
+	comm_id text UNIQUE,
+	comm_rn integer,
+	offset_nr integer
+);
+
+CREATE TABLE proc_comm_turns (
+	comm_id text,
+	comm_rn integer,
+	comp_id text,
+	chnl_id text,
+	kind integer,
+	exp text
+);
+
+-- ревизию хода назначает обмен: в postgres это делает сам запрос вставки,
+-- а SQLite не допускает изменений в WITH
+CREATE TRIGGER proc_comm_turns_rn AFTER INSERT ON proc_comm_turns
+BEGIN
+	UPDATE proc_comm_exchs SET comm_rn = NEW.comm_rn WHERE comm_id = NEW.comm_id;
+END;
@@ -0,0 +1,3 @@
+-- встроенное хранилище появилось после перевода exp_vk на структурные ключи,
+-- поэтому версия только занимает свое место в schema_versions
+SELECT 1;
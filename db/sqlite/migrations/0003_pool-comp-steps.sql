@@ -0,0 +1,14 @@
+-- исходящие шаги пулов (outbox)
+-- пишутся в транзакции шага, удаляются после успешного исполнения
+CREATE TABLE pool_comp_steps (
+	step_id text PRIMARY KEY,
+	comp_id text,
+	spec blob,
+	-- 1 - ожидает, 2 - исчерпал попытки, 3 - остановлен без запаса
+	status integer,
+	attempts integer DEFAULT 0,
+	due_at timestamp DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+	reason text
+);
+
+CREATE INDEX pool_comp_steps_due ON pool_comp_steps (due_at) WHERE status = 1;
@@ -0,0 +1,10 @@
+-- закрепление вычислений за узлами
+-- встроенное хранилище обслуживает один узел, но таблица нужна
+-- для продления и освобождения аренды
+CREATE TABLE pool_comp_leases (
+	comp_id text PRIMARY KEY,
+	node_id text,
+	expires_at timestamp
+);
+
+CREATE INDEX pool_comp_leases_node ON pool_comp_leases (node_id);
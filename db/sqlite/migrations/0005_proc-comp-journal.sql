@@ -0,0 +1,8 @@
+-- журнал шагов вычислений для воспроизведения
+CREATE TABLE proc_comp_journal (
+	comp_id text,
+	comp_rn integer,
+	exp text,
+	exchs text,
+	PRIMARY KEY (comp_id, comp_rn)
+);
@@ -0,0 +1,26 @@
+-- открытые вакансии и заявки рынка труда
+CREATE TABLE pool_labor_posts (
+	post_id text PRIMARY KEY,
+	-- 1 - вакансия, 2 - заявка
+	side integer,
+	comp_id text,
+	comm_id text,
+	term_qn text,
+	comm_var text,
+	next_vk integer,
+	cont_exp text,
+	posted_at timestamp
+);
+
+CREATE INDEX pool_labor_posts_side ON pool_labor_posts (side, posted_at);
+
+-- наймы по сведенным размещениям
+CREATE TABLE pool_labor_hires (
+	hire_id text PRIMARY KEY,
+	comm_id text,
+	comp_id text,
+	hired_at timestamp,
+	fired_at timestamp
+);
+
+CREATE INDEX pool_labor_hires_comp ON pool_labor_hires (comp_id);
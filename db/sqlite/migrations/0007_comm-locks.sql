@@ -0,0 +1,30 @@
+-- пометки зависших вычислений, по строке на ожидание
+CREATE TABLE proc_comm_locks (
+	lock_id text,
+	comp_id text,
+	comp_qn text,
+	comm_id text,
+	chnl_id text,
+	chnl_ph text,
+	holder_id text,
+	-- 1 - цикл, 2 - сиротское ожидание
+	kind integer,
+	detected_at timestamp,
+	seen_at timestamp,
+	PRIMARY KEY (comp_id, chnl_id)
+);
+
+CREATE TABLE pool_comm_locks (
+	lock_id text,
+	comp_id text,
+	comp_qn text,
+	comm_id text,
+	chnl_id text,
+	chnl_ph text,
+	holder_id text,
+	-- 1 - цикл, 2 - сиротское ожидание
+	kind integer,
+	detected_at timestamp,
+	seen_at timestamp,
+	PRIMARY KEY (comp_id, chnl_id)
+);
@@ -0,0 +1,24 @@
+-- запасы шагов вычислений
+CREATE TABLE proc_comp_fuels (
+	comp_id text PRIMARY KEY,
+	pool_id text,
+	fuel_nr integer
+);
+
+CREATE INDEX proc_comp_fuels_pool ON proc_comp_fuels (pool_id);
+
+-- запасы шагов пулов
+CREATE TABLE pool_comp_fuels (
+	comp_id text PRIMARY KEY,
+	fuel_nr integer
+);
+
+-- шаги процессов, остановленные без запаса
+CREATE TABLE proc_comp_halts (
+	halt_id text PRIMARY KEY,
+	comp_id text,
+	exp text,
+	halted_at timestamp
+);
+
+CREATE INDEX proc_comp_halts_comp ON proc_comp_halts (comp_id);
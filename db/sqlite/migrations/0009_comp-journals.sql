@@ -0,0 +1,11 @@
+-- окружение шага
+ALTER TABLE proc_comp_journal ADD COLUMN env text;
+
+-- журнал шагов пулов для воспроизведения
+CREATE TABLE pool_comp_journal (
+	comp_id text,
+	comp_rn integer,
+	exp text,
+	snap text,
+	PRIMARY KEY (comp_id, comp_rn)
+);
@@ -0,0 +1,2 @@
+-- тела процессов, порожденных пулами
+ALTER TABLE pool_comp_steps ADD COLUMN body text;
@@ -4,13 +4,32 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/fx"
 
 	"orglang/go-engine/adt/identity"
 )
 
+// ErrNotFound означает, что запрошенной сущности нет в хранилище;
+// DAO оборачивают им признак отсутствия строки своего драйвера
+var ErrNotFound = errors.New("entity not found")
+
+// ConvertNoRows переводит отсутствие строки в ErrNotFound
+func ConvertNoRows(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+	return err
+}
+
+// ErrConcurrentModification означает, что ревизия сущности изменилась
+// между чтением и записью; операцию можно повторить на свежем снепшоте
+var ErrConcurrentModification = errors.New("entity concurrent modification")
+
 type Selector interface {
 	Select(id identity.ADT)
 }
@@ -21,30 +40,125 @@ type Source interface {
 
 type SourcePgx struct {
 	Ctx  context.Context
-	Conn *pgx.Conn
+	Conn Conn
+	// действия, отложенные до фиксации явной транзакции
+	commits *[]func()
 }
 
+// Conn часть *pgx.Conn, на которую опираются DAO; ее же реализует
+// адаптер встроенного хранилища
+type Conn interface {
+	Exec(context.Context, string, ...any) (pgconn.CommandTag, error)
+	Query(context.Context, string, ...any) (pgx.Rows, error)
+	QueryRow(context.Context, string, ...any) pgx.Row
+	SendBatch(context.Context, *pgx.Batch) pgx.BatchResults
+}
+
+// Dialect различает SQL хранилищ там, где переносимого запроса нет;
+// хранилищу в памяти SQL не нужен вовсе, и DAO заменяются целиком
+type Dialect uint8
+
+const (
+	Postgres Dialect = iota
+	SQLite
+	Memory
+)
+
 func (SourcePgx) source() {}
 
 type Operator interface {
-	Explicit(context.Context, func(Source) error) error
+	Explicit(context.Context, func(Source) error, ...TxOption) error
 	Implicit(context.Context, func(Source) error) error
 }
 
+// Isolation задает уровень изоляции явной транзакции
+type Isolation uint8
+
+const (
+	ReadCommitted Isolation = iota
+	// все чтения транзакции видят один снепшот
+	RepeatableRead
+	Serializable
+)
+
+type TxOptions struct {
+	Isolation Isolation
+	ReadOnly  bool
+}
+
+type TxOption func(*TxOptions)
+
+func WithIsolation(level Isolation) TxOption {
+	return func(opts *TxOptions) {
+		opts.Isolation = level
+	}
+}
+
+func WithReadOnly() TxOption {
+	return func(opts *TxOptions) {
+		opts.ReadOnly = true
+	}
+}
+
+func NewTxOptions(opts ...TxOption) TxOptions {
+	var txOpts TxOptions
+	for _, opt := range opts {
+		opt(&txOpts)
+	}
+	return txOpts
+}
+
+// AfterCommit откладывает действие до фиксации явной транзакции
+// источника; вне ее действие выполняется сразу
+func AfterCommit(source Source, fn func()) {
+	switch ds := source.(type) {
+	case SourcePgx:
+		if ds.commits != nil {
+			*ds.commits = append(*ds.commits, fn)
+			return
+		}
+	case SourceMem:
+		ds.tx.commits = append(ds.tx.commits, fn)
+		return
+	}
+	fn()
+}
+
+func runCommits(commits []func()) {
+	for _, fn := range commits {
+		fn()
+	}
+}
+
+// Notifier будит другие узлы после фиксации транзакции
+type Notifier interface {
+	// Notify ставит уведомление в транзакцию источника
+	Notify(Source, string, string) error
+	// Listen доставляет полезную нагрузку уведомлений канала,
+	// пока не будет отменен контекст
+	Listen(context.Context, string) (<-chan string, error)
+}
+
 type OperatorPgx struct {
 	pool *pgxpool.Pool
 }
 
-func (o *OperatorPgx) Explicit(ctx context.Context, op func(Source) error) error {
-	tx, err := o.pool.Begin(ctx)
+func (o *OperatorPgx) Explicit(ctx context.Context, op func(Source) error, opts ...TxOption) error {
+	tx, err := o.pool.BeginTx(ctx, convertTxOptions(NewTxOptions(opts...)))
 	if err != nil {
 		return err
 	}
-	err = op(SourcePgx{Ctx: ctx, Conn: tx.Conn()})
+	var commits []func()
+	err = op(SourcePgx{Ctx: ctx, Conn: tx.Conn(), commits: &commits})
 	if err != nil {
-		return errors.Join(err, tx.Rollback(ctx))
+		return convertTxErr(errors.Join(err, tx.Rollback(ctx)))
 	}
-	return tx.Commit(ctx)
+	err = tx.Commit(ctx)
+	if err != nil {
+		return convertTxErr(err)
+	}
+	runCommits(commits)
+	return nil
 }
 
 func (o *OperatorPgx) Implicit(ctx context.Context, op func(Source) error) error {
@@ -56,6 +170,25 @@ func (o *OperatorPgx) Implicit(ctx context.Context, op func(Source) error) error
 	return op(SourcePgx{Ctx: ctx, Conn: conn.Conn()})
 }
 
+// хранилище выбирается протоколом из конфигурации
+func newStorage(dto storageCS, log *slog.Logger, lc fx.Lifecycle) (Operator, Notifier, error) {
+	switch dto.Protocol.Mode {
+	case sqliteProto:
+		return newSQLiteStorage(dto, log, lc)
+	default:
+		return newPgxStorage(dto, log, lc)
+	}
+}
+
+func newDialect(dto storageCS) Dialect {
+	switch dto.Protocol.Mode {
+	case sqliteProto:
+		return SQLite
+	default:
+		return Postgres
+	}
+}
+
 func MustConform[T Source](got Source) T {
 	ds, ok := got.(T)
 	if !ok {
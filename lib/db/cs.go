@@ -25,6 +25,7 @@ type storageCS struct {
 type protocolCS struct {
 	Mode     protoModeCS `mapstructure:"mode"`
 	Postgres postgresCS  `mapstructure:"postgres"`
+	SQLite   sqliteCS    `mapstructure:"sqlite"`
 }
 
 type driverCS struct {
@@ -33,7 +34,13 @@ type driverCS struct {
 }
 
 type postgresCS struct {
-	URL string `mapstructure:"url"`
+	URL       string          `mapstructure:"url"`
+	Migration migrationModeCS `mapstructure:"migration"`
+}
+
+// встроенное хранилище в файле; ":memory:" держит базу в памяти процесса
+type sqliteCS struct {
+	Path string `mapstructure:"path"`
 }
 
 type pgxCS struct {
@@ -44,10 +51,27 @@ type protoModeCS string
 
 const (
 	postgresProto protoModeCS = "postgres"
+	sqliteProto   protoModeCS = "sqlite"
+)
+
+// check только сверяет версию схемы, оставляя миграции запуску
+// с --migrate-only
+type migrationModeCS string
+
+const (
+	applyMigration migrationModeCS = "apply"
+	checkMigration migrationModeCS = "check"
 )
 
 type driverModeCS string
 
 const (
-	pgxDriver driverModeCS = "pgx"
+	pgxDriver     driverModeCS = "pgx"
+	moderncDriver driverModeCS = "modernc"
 )
+
+// каждый протокол обслуживается своим драйвером
+var protoDrivers = map[protoModeCS]driverModeCS{
+	postgresProto: pgxDriver,
+	sqliteProto:   moderncDriver,
+}
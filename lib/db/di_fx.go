@@ -6,11 +6,29 @@ import (
 
 var Module = fx.Module("lib/db",
 	fx.Provide(
-		newPgxDriver,
-		fx.Annotate(newOperator, fx.As(new(Operator))),
+		newDialect,
+		newStorage,
 	),
 	fx.Provide(
 		fx.Private,
 		newStorageCS,
 	),
 )
+
+// MemoryModule заменяет Module в тестах: DAO в памяти есть не у всех
+// модулей, поэтому конфигурацией такое хранилище не выбирается
+var MemoryModule = fx.Module("lib/db",
+	fx.Supply(Memory),
+	fx.Provide(newMemStorage),
+)
+
+// MigrateOnly заменяет Module, когда движок запущен только для миграции схемы
+var MigrateOnly = fx.Module("lib/db",
+	fx.Provide(
+		fx.Private,
+		newStorageCS,
+	),
+	fx.Invoke(
+		migrateOnly,
+	),
+)
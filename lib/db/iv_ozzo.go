@@ -7,26 +7,41 @@ import (
 func (dto storageCS) Validate() error {
 	return validation.ValidateStruct(&dto,
 		validation.Field(&dto.Protocol, validation.Required),
-		validation.Field(&dto.Driver, validation.Required),
+		validation.Field(&dto.Driver, validation.Required, validation.By(dto.fitDriver)),
 	)
 }
 
+func (dto storageCS) fitDriver(any) error {
+	if protoDrivers[dto.Protocol.Mode] != dto.Driver.Mode {
+		return validation.NewError("validation_driver_unfit", "driver does not fit protocol")
+	}
+	return nil
+}
+
 func (dto protocolCS) Validate() error {
 	return validation.ValidateStruct(&dto,
-		validation.Field(&dto.Mode, validation.Required, validation.In(postgresProto)),
-		validation.Field(&dto.Postgres, validation.Required.When(dto.Mode == postgresProto)),
+		validation.Field(&dto.Mode, validation.Required, validation.In(postgresProto, sqliteProto)),
+		validation.Field(&dto.Postgres, validation.Skip.When(dto.Mode != postgresProto), validation.Required),
+		validation.Field(&dto.SQLite, validation.Skip.When(dto.Mode != sqliteProto), validation.Required),
 	)
 }
 
 func (dto postgresCS) Validate() error {
 	return validation.ValidateStruct(&dto,
 		validation.Field(&dto.URL, validation.Required),
+		validation.Field(&dto.Migration, validation.Required, validation.In(applyMigration, checkMigration)),
+	)
+}
+
+func (dto sqliteCS) Validate() error {
+	return validation.ValidateStruct(&dto,
+		validation.Field(&dto.Path, validation.Required),
 	)
 }
 
 func (dto driverCS) Validate() error {
 	return validation.ValidateStruct(&dto,
-		validation.Field(&dto.Mode, validation.Required, validation.In(pgxDriver)),
+		validation.Field(&dto.Mode, validation.Required, validation.In(pgxDriver, moderncDriver)),
 		// validation.Field(&dto.Pgx, validation.Required.When(dto.Mode == pgxMode)),
 	)
 }
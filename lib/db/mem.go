@@ -0,0 +1,194 @@
+package db
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ErrDuplicateKey означает нарушение первичного ключа таблицы в памяти
+var ErrDuplicateKey = errors.New("duplicate key value")
+
+// OperatorMem держит таблицы в памяти процесса: транзакции
+// сериализуются общей блокировкой, а откат возвращает прежние строки
+type OperatorMem struct {
+	mu     sync.RWMutex
+	tables map[string]any
+}
+
+func NewOperatorMem() *OperatorMem {
+	return &OperatorMem{tables: make(map[string]any)}
+}
+
+func newMemStorage() (Operator, Notifier) {
+	return NewOperatorMem(), NewNotifierLocal()
+}
+
+// SourceMem транзакция хранилища в памяти; вложенные транзакции
+// не поддерживаются, поэтому DAO работают только через переданный источник
+type SourceMem struct {
+	Ctx context.Context
+	tx  *txMem
+}
+
+func (SourceMem) source() {}
+
+type txMem struct {
+	op       *OperatorMem
+	readOnly bool
+	undos    []func()
+	commits  []func()
+}
+
+func (o *OperatorMem) Explicit(ctx context.Context, op func(Source) error, opts ...TxOption) error {
+	if NewTxOptions(opts...).ReadOnly {
+		o.mu.RLock()
+		defer o.mu.RUnlock()
+		tx := &txMem{op: o, readOnly: true}
+		err := op(SourceMem{ctx, tx})
+		if err == nil {
+			runCommits(tx.commits)
+		}
+		return err
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	tx := &txMem{op: o}
+	err := op(SourceMem{ctx, tx})
+	if err != nil {
+		tx.rollback()
+		return err
+	}
+	runCommits(tx.commits)
+	return nil
+}
+
+// в отличие от postgres, неявная транзакция тоже откатывается целиком
+func (o *OperatorMem) Implicit(ctx context.Context, op func(Source) error) error {
+	return o.Explicit(ctx, op)
+}
+
+func (tx *txMem) rollback() {
+	for _, undo := range slices.Backward(tx.undos) {
+		undo()
+	}
+	tx.undos = nil
+}
+
+func (tx *txMem) mustWrite() {
+	if tx.readOnly {
+		panic("write in read-only transaction")
+	}
+}
+
+// TableMem таблица хранилища в памяти; строки выдаются в порядке вставки
+type TableMem[K comparable, V any] struct {
+	name string
+	tx   *txMem
+	data *tableData[K, V]
+}
+
+type tableData[K comparable, V any] struct {
+	rows map[K]rowMem[V]
+	seq  uint64
+}
+
+type rowMem[V any] struct {
+	val V
+	seq uint64
+}
+
+// TableOf находит таблицу по имени или заводит ее; имена совпадают
+// с таблицами postgres, чтобы DAO разных пакетов видели общие данные
+func TableOf[K comparable, V any](ds SourceMem, name string) TableMem[K, V] {
+	name = strings.TrimSpace(name)
+	tables := ds.tx.op.tables
+	table, ok := tables[name]
+	if !ok {
+		data := &tableData[K, V]{rows: make(map[K]rowMem[V])}
+		// заведение таблицы не откатывается, как и схема, а читающая
+		// транзакция видит пустую таблицу, не заводя ее
+		if !ds.tx.readOnly {
+			tables[name] = data
+		}
+		return TableMem[K, V]{name, ds.tx, data}
+	}
+	data, ok := table.(*tableData[K, V])
+	if !ok {
+		panic(fmt.Sprintf("table %s types mismatch: %T", name, table))
+	}
+	return TableMem[K, V]{name, ds.tx, data}
+}
+
+func (t TableMem[K, V]) Get(key K) (V, bool) {
+	row, ok := t.data.rows[key]
+	return row.val, ok
+}
+
+// Find ведет себя как выборка ровно одной строки
+func (t TableMem[K, V]) Find(key K) (V, error) {
+	row, ok := t.data.rows[key]
+	if !ok {
+		return row.val, ErrNotFound
+	}
+	return row.val, nil
+}
+
+// Insert в отличие от Put не перезаписывает существующую строку
+func (t TableMem[K, V]) Insert(key K, val V) error {
+	_, ok := t.data.rows[key]
+	if ok {
+		return fmt.Errorf("%w: %s %v", ErrDuplicateKey, t.name, key)
+	}
+	t.Put(key, val)
+	return nil
+}
+
+func (t TableMem[K, V]) Put(key K, val V) {
+	t.tx.mustWrite()
+	prev, ok := t.data.rows[key]
+	seq := prev.seq
+	if !ok {
+		t.data.seq++
+		seq = t.data.seq
+	}
+	t.data.rows[key] = rowMem[V]{val, seq}
+	t.tx.undos = append(t.tx.undos, func() {
+		if ok {
+			t.data.rows[key] = prev
+		} else {
+			delete(t.data.rows, key)
+		}
+	})
+}
+
+func (t TableMem[K, V]) Delete(key K) {
+	t.tx.mustWrite()
+	prev, ok := t.data.rows[key]
+	if !ok {
+		return
+	}
+	delete(t.data.rows, key)
+	t.tx.undos = append(t.tx.undos, func() {
+		t.data.rows[key] = prev
+	})
+}
+
+func (t TableMem[K, V]) Rows() []V {
+	rows := make([]rowMem[V], 0, len(t.data.rows))
+	for _, row := range t.data.rows {
+		rows = append(rows, row)
+	}
+	slices.SortFunc(rows, func(a, b rowMem[V]) int {
+		return cmp.Compare(a.seq, b.seq)
+	})
+	vals := make([]V, len(rows))
+	for i, row := range rows {
+		vals[i] = row.val
+	}
+	return vals
+}
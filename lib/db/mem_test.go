@@ -0,0 +1,109 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestOperatorMem(t *testing.T) {
+	errAbort := errors.New("abort")
+	tests := []struct {
+		name string
+		op   func(Source) error
+		want []string
+		err  error
+	}{
+		{
+			name: "commit",
+			op: func(source Source) error {
+				rows := TableOf[int, string](MustConform[SourceMem](source), "rows")
+				rows.Put(3, "c")
+				return nil
+			},
+			want: []string{"a", "b", "c"},
+		},
+		{
+			name: "rollback",
+			op: func(source Source) error {
+				rows := TableOf[int, string](MustConform[SourceMem](source), "rows")
+				rows.Put(1, "x")
+				rows.Delete(2)
+				rows.Put(3, "c")
+				return errAbort
+			},
+			want: []string{"a", "b"},
+			err:  errAbort,
+		},
+		{
+			name: "duplicate",
+			op: func(source Source) error {
+				rows := TableOf[int, string](MustConform[SourceMem](source), "rows")
+				rows.Put(3, "c")
+				return rows.Insert(1, "x")
+			},
+			want: []string{"a", "b"},
+			err:  ErrDuplicateKey,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			o := NewOperatorMem()
+			err := o.Explicit(ctx, func(source Source) error {
+				rows := TableOf[int, string](MustConform[SourceMem](source), "rows")
+				rows.Put(1, "a")
+				rows.Put(2, "b")
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			err = o.Explicit(ctx, tt.op)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("unexpected error: want %q, got %q", tt.err, err)
+			}
+			var got []string
+			err = o.Explicit(ctx, func(source Source) error {
+				got = TableOf[int, string](MustConform[SourceMem](source), "rows").Rows()
+				return nil
+			}, WithReadOnly())
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("unexpected rows (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAfterCommit(t *testing.T) {
+	errAbort := errors.New("abort")
+	tests := []struct {
+		name string
+		err  error
+		want []string
+	}{
+		{name: "commit", want: []string{"op", "commit"}},
+		{name: "rollback", err: errAbort, want: []string{"op"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			err := NewOperatorMem().Explicit(context.Background(), func(source Source) error {
+				AfterCommit(source, func() { got = append(got, "commit") })
+				got = append(got, "op")
+				return tt.err
+			})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("unexpected error: want %q, got %q", tt.err, err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("unexpected actions (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/fx"
+
+	"orglang/go-engine/db/postgres"
+	"orglang/go-engine/db/sqlite"
+)
+
+const (
+	versionsTable = "schema_versions"
+	// ключ pg_advisory_xact_lock, под которым узлы мигрируют по очереди
+	migrationLock = 7307012519
+)
+
+// ErrSchemaTooNew означает, что схему мигрировал более новый движок
+var ErrSchemaTooNew = errors.New("schema version is newer than engine")
+
+// ErrSchemaBehind означает, что схема отстает от движка,
+// а применять миграции конфигурация не разрешает
+var ErrSchemaBehind = errors.New("schema version is behind engine")
+
+type migration struct {
+	version int
+	name    string
+	sql     string
+}
+
+// миграции называются NNNN_name.sql, а версии идут подряд с единицы
+func loadMigrations(fsys fs.FS) ([]migration, error) {
+	paths, err := fs.Glob(fsys, "*/*.sql")
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(paths)
+	migrations := make([]migration, 0, len(paths))
+	for i, p := range paths {
+		prefix, name, ok := strings.Cut(strings.TrimSuffix(path.Base(p), ".sql"), "_")
+		if !ok {
+			return nil, fmt.Errorf("migration name malformed: %s", p)
+		}
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("migration version malformed: %s", p)
+		}
+		if version != i+1 {
+			return nil, fmt.Errorf("migration version out of order: want %d, got %s", i+1, p)
+		}
+		sql, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, migration{version, name, string(sql)})
+	}
+	return migrations, nil
+}
+
+// у каждого хранилища свои миграции, но версии и имена общие
+var dialectMigrations = map[Dialect]fs.FS{
+	Postgres: postgres.Migrations,
+	SQLite:   sqlite.Migrations,
+}
+
+// migrator приводит схему хранилища к версии движка; все миграции
+// применяются в одной транзакции, поэтому схема не остается
+// в промежуточном состоянии
+type migrator struct {
+	operator   Operator
+	dialect    Dialect
+	migrations []migration
+	log        *slog.Logger
+}
+
+func newMigrator(operator Operator, dialect Dialect, log *slog.Logger) (*migrator, error) {
+	migrations, err := loadMigrations(dialectMigrations[dialect])
+	if err != nil {
+		return nil, err
+	}
+	name := slog.String("name", reflect.TypeFor[migrator]().Name())
+	return &migrator{operator, dialect, migrations, log.With(name)}, nil
+}
+
+// миграции применяются при старте, после чего приложение останавливается
+func migrateOnly(dto storageCS, log *slog.Logger, lc fx.Lifecycle, sd fx.Shutdowner) error {
+	var operator Operator
+	switch dto.Protocol.Mode {
+	case sqliteProto:
+		sqlDB, err := newSQLiteDriver(dto, lc)
+		if err != nil {
+			return err
+		}
+		operator = &OperatorSQLite{sqlDB}
+	default:
+		pool, err := newPgxDriver(dto, lc)
+		if err != nil {
+			return err
+		}
+		operator = &OperatorPgx{pool}
+	}
+	m, err := newMigrator(operator, newDialect(dto), log)
+	if err != nil {
+		return err
+	}
+	lc.Append(
+		fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				err := m.Apply(ctx)
+				if err != nil {
+					return err
+				}
+				return sd.Shutdown()
+			},
+		},
+	)
+	return nil
+}
+
+func (m *migrator) Apply(ctx context.Context) error {
+	return m.operator.Explicit(ctx, func(source Source) error {
+		ds := MustConform[SourcePgx](source)
+		// пишущая транзакция SQLite и так исключает других писателей
+		if m.dialect == Postgres {
+			_, err := ds.Conn.Exec(ds.Ctx, "SELECT pg_advisory_xact_lock($1)", migrationLock)
+			if err != nil {
+				return err
+			}
+		}
+		applied, err := m.appliedVersions(ds.Ctx, ds.Conn)
+		if err != nil {
+			return err
+		}
+		// версии, принятые от Liquibase, записываются первыми
+		for _, mig := range m.migrations[:len(applied)] {
+			err = recordVersion(ds.Ctx, ds.Conn, mig)
+			if err != nil {
+				return err
+			}
+		}
+		for _, mig := range m.migrations[len(applied):] {
+			migAttr := slog.String("migration", mig.name)
+			_, err = ds.Conn.Exec(ds.Ctx, mig.sql)
+			if err != nil {
+				m.log.Error("migration failed", slog.Int("version", mig.version), migAttr)
+				return err
+			}
+			err = recordVersion(ds.Ctx, ds.Conn, mig)
+			if err != nil {
+				return err
+			}
+			m.log.Info("migration succeed", slog.Int("version", mig.version), migAttr)
+		}
+		return nil
+	})
+}
+
+// таблица версий появляется после первой миграции,
+// которая создает схему владельца
+func recordVersion(ctx context.Context, conn Conn, mig migration) error {
+	_, err := conn.Exec(ctx, "CREATE TABLE IF NOT EXISTS "+versionsTable+
+		" (version integer PRIMARY KEY, name varchar, applied_at timestamptz DEFAULT CURRENT_TIMESTAMP)")
+	if err != nil {
+		return err
+	}
+	_, err = conn.Exec(ctx, "INSERT INTO "+versionsTable+" (version, name) VALUES ($1, $2) "+
+		"ON CONFLICT (version) DO NOTHING", mig.version, mig.name)
+	return err
+}
+
+func (m *migrator) Check(ctx context.Context) error {
+	return m.operator.Implicit(ctx, func(source Source) error {
+		ds := MustConform[SourcePgx](source)
+		applied, err := m.appliedVersions(ds.Ctx, ds.Conn)
+		if err != nil {
+			return err
+		}
+		if len(applied) < len(m.migrations) {
+			return fmt.Errorf("%w: want %d, got %d", ErrSchemaBehind, len(m.migrations), len(applied))
+		}
+		return nil
+	})
+}
+
+// примененные версии сверяются с известными движку по именам
+func (m *migrator) appliedVersions(ctx context.Context, conn Conn) ([]string, error) {
+	applied, err := m.selectVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	if len(applied) > len(m.migrations) {
+		return nil, fmt.Errorf("%w: want %d, got %d", ErrSchemaTooNew, len(m.migrations), len(applied))
+	}
+	for i, name := range applied {
+		if m.migrations[i].name != name {
+			return nil, fmt.Errorf("migration %d mismatch: want %s, got %s", i+1, m.migrations[i].name, name)
+		}
+	}
+	m.log.Debug("versions selection succeed", slog.Int("applied", len(applied)), slog.Int("known", len(m.migrations)))
+	return applied, nil
+}
+
+// наличие таблицы каждое хранилище проверяет по своему каталогу
+var tableExists = map[Dialect]string{
+	Postgres: "SELECT to_regclass($1) IS NOT NULL",
+	SQLite:   "SELECT count(*) > 0 FROM sqlite_master WHERE type = 'table' AND name = $1",
+}
+
+func (m *migrator) selectVersions(ctx context.Context, conn Conn) ([]string, error) {
+	var exists bool
+	err := conn.QueryRow(ctx, tableExists[m.dialect], versionsTable).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists && m.dialect == Postgres {
+		return m.selectChangeSets(ctx, conn)
+	}
+	if !exists {
+		return nil, nil
+	}
+	rows, err := conn.Query(ctx, "SELECT name FROM "+versionsTable+" ORDER BY version")
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// схему, накатанную Liquibase, движок принимает как есть:
+// миграции названы так же, как changeSet
+func (m *migrator) selectChangeSets(ctx context.Context, conn Conn) ([]string, error) {
+	var exists bool
+	err := conn.QueryRow(ctx, "SELECT to_regclass('public.databasechangelog') IS NOT NULL").Scan(&exists)
+	if err != nil || !exists {
+		return nil, err
+	}
+	rows, err := conn.Query(ctx, "SELECT id FROM public.databasechangelog ORDER BY orderexecuted")
+	if err != nil {
+		return nil, err
+	}
+	changeSets, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, err
+	}
+	m.log.Info("liquibase changelog found", slog.Any("changesets", changeSets))
+	return changeSets, nil
+}
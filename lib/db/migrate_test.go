@@ -0,0 +1,227 @@
+package db
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"regexp"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/fx/fxtest"
+
+	"orglang/go-engine/adt/valkey"
+	"orglang/go-engine/db/postgres"
+	"orglang/go-engine/db/sqlite"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := loadMigrations(postgres.Migrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"sepulkarium", "exp-vk-rekeys", "pool-comp-steps", "pool-comp-leases", "proc-comp-journal", "pool-labor-market", "comm-locks", "comp-fuels", "comp-journals", "pool-comp-bodies"}
+	if len(migrations) != len(want) {
+		t.Fatalf("want %d migrations, got %d", len(want), len(migrations))
+	}
+	for i, mig := range migrations {
+		if mig.version != i+1 || mig.name != want[i] {
+			t.Errorf("want %d_%s, got %d_%s", i+1, want[i], mig.version, mig.name)
+		}
+	}
+}
+
+func TestLoadMigrationsMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{
+			name: "gap",
+			fsys: fstest.MapFS{
+				"m/0001_a.sql": {},
+				"m/0003_c.sql": {},
+			},
+		},
+		{
+			name: "no prefix",
+			fsys: fstest.MapFS{
+				"m/a.sql": {},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadMigrations(tt.fsys)
+			if err == nil {
+				t.Error("want error, got nil")
+			}
+		})
+	}
+}
+
+// схема sqlite повторяет миграции postgres версия в версию
+func TestSQLiteMigrationsMatch(t *testing.T) {
+	createTable := regexp.MustCompile(`CREATE TABLE (\w+)`)
+	pgMigrations, err := loadMigrations(postgres.Migrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqliteMigrations, err := loadMigrations(sqlite.Migrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sqliteMigrations) != len(pgMigrations) {
+		t.Fatalf("want %d migrations, got %d", len(pgMigrations), len(sqliteMigrations))
+	}
+	for i, mig := range sqliteMigrations {
+		if mig.name != pgMigrations[i].name {
+			t.Errorf("migration %d: want %s, got %s", mig.version, pgMigrations[i].name, mig.name)
+		}
+		tables := make(map[string]bool)
+		for _, m := range createTable.FindAllStringSubmatch(pgMigrations[i].sql, -1) {
+			tables[m[1]] = true
+		}
+		for _, m := range createTable.FindAllStringSubmatch(mig.sql, -1) {
+			if !tables[m[1]] {
+				t.Errorf("migration %d: table %s not covered by postgres", mig.version, m[1])
+			}
+		}
+	}
+}
+
+func newTestMigrator(t *testing.T) *migrator {
+	dto := storageCS{Protocol: protocolCS{Mode: sqliteProto, SQLite: sqliteCS{Path: memoryPath}}}
+	lc := fxtest.NewLifecycle(t)
+	sqlDB, err := newSQLiteDriver(dto, lc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lc.RequireStart()
+	t.Cleanup(lc.RequireStop)
+	m, err := newMigrator(&OperatorSQLite{sqlDB}, SQLite, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestSQLiteMigratorApply(t *testing.T) {
+	m := newTestMigrator(t)
+	err := m.Check(t.Context())
+	if !errors.Is(err, ErrSchemaBehind) {
+		t.Fatalf("want %q, got %v", ErrSchemaBehind, err)
+	}
+	// повторный запуск ничего не применяет
+	for range 2 {
+		err = m.Apply(t.Context())
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = m.Check(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	err = m.operator.Implicit(t.Context(), func(source Source) error {
+		ds := MustConform[SourcePgx](source)
+		rows, err := ds.Conn.Query(ds.Ctx, "SELECT name FROM "+versionsTable+" ORDER BY version")
+		if err != nil {
+			return err
+		}
+		got, err = pgx.CollectRows(rows, pgx.RowTo[string])
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []string
+	for _, mig := range m.migrations {
+		want = append(want, mig.name)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestSQLiteMigratorTooNew(t *testing.T) {
+	m := newTestMigrator(t)
+	err := m.Apply(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// движок, которому известны не все примененные версии
+	m.migrations = m.migrations[:len(m.migrations)-1]
+	err = m.Apply(t.Context())
+	if !errors.Is(err, ErrSchemaTooNew) {
+		t.Fatalf("want %q, got %v", ErrSchemaTooNew, err)
+	}
+}
+
+// перевод ключей проверяется на живой базе, которую задает DB_TEST_URL;
+// миграции накатываются в отдельной схеме и откатываются вместе с транзакцией
+func TestExpRekeyMigration(t *testing.T) {
+	url := os.Getenv("DB_TEST_URL")
+	if url == "" {
+		t.Skip("DB_TEST_URL not set")
+	}
+	migrations, err := loadMigrations(postgres.Migrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := pgx.Connect(t.Context(), url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close(t.Context())
+	tx, err := conn.Begin(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback(t.Context())
+	steps := []string{
+		"CREATE SCHEMA exp_rekey_test",
+		"SET LOCAL search_path TO exp_rekey_test, public",
+		migrations[0].sql,
+		// объявления со старыми 32-битными ключами
+		`INSERT INTO proc_type_exps (exp_vk, kind, spec) VALUES (101, 2, '{"link": "foo"}')`,
+		`INSERT INTO proc_term_decs (term_id, term_rn, liab_var, asset_vars) VALUES
+			('proc-term', 1, '{"id": "a", "ph": "x", "vk": 101}', '[{"id": "b", "ph": "y", "vk": 101}]')`,
+		`INSERT INTO pool_type_exps (exp_vk, kind, spec) VALUES (102, 2, '{"link": "bar"}')`,
+		`INSERT INTO pool_term_decs (term_id, term_rn, liab_var, asset_vars) VALUES
+			('pool-term', 1, '{"id": "c", "ph": "z", "vk": 102}', '[]')`,
+		migrations[1].sql,
+	}
+	for _, step := range steps {
+		_, err = tx.Exec(t.Context(), step)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		name  string
+		query string
+		want  valkey.ADT
+	}{
+		{"proc exp", "SELECT exp_vk FROM proc_type_exps", valkey.NewHasher(2).WriteString("foo").Sum()},
+		{"proc liab", "SELECT (liab_var->>'vk')::bigint FROM proc_term_decs", valkey.NewHasher(2).WriteString("foo").Sum()},
+		{"proc asset", "SELECT (asset_vars->0->>'vk')::bigint FROM proc_term_decs", valkey.NewHasher(2).WriteString("foo").Sum()},
+		{"pool liab", "SELECT (liab_var->>'vk')::bigint FROM pool_term_decs", valkey.NewHasher(2).WriteString("bar").Sum()},
+		{"pool assets", "SELECT jsonb_array_length(asset_vars) FROM pool_term_decs", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got int64
+			err := tx.QueryRow(t.Context(), tt.query).Scan(&got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if valkey.ADT(got) != tt.want {
+				t.Errorf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
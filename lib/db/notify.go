@@ -0,0 +1,128 @@
+package db
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	reconnectDelay = time.Second
+)
+
+// NotifierPgx опирается на LISTEN/NOTIFY: уведомление уходит
+// слушателям всех узлов только при фиксации транзакции
+type NotifierPgx struct {
+	pool *pgxpool.Pool
+	log  *slog.Logger
+}
+
+func newNotifier(pool *pgxpool.Pool, log *slog.Logger) *NotifierPgx {
+	name := slog.String("name", reflect.TypeFor[NotifierPgx]().Name())
+	return &NotifierPgx{pool, log.With(name)}
+}
+
+func (n *NotifierPgx) Notify(source Source, channel string, payload string) error {
+	ds := MustConform[SourcePgx](source)
+	_, err := ds.Conn.Exec(ds.Ctx, "SELECT pg_notify($1, $2)", channel, payload)
+	return err
+}
+
+// слушатель держит отдельное соединение, чтобы не занимать пул,
+// и переподключается при обрыве
+func (n *NotifierPgx) Listen(ctx context.Context, channel string) (<-chan string, error) {
+	payloads := make(chan string, 1)
+	go func() {
+		defer close(payloads)
+		for ctx.Err() == nil {
+			err := n.listen(ctx, channel, payloads)
+			if ctx.Err() != nil {
+				return
+			}
+			n.log.Warn("listening failed", slog.String("channel", channel), slog.Any("reason", err))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(reconnectDelay):
+			}
+		}
+	}()
+	return payloads, nil
+}
+
+func (n *NotifierPgx) listen(ctx context.Context, channel string, payloads chan<- string) error {
+	conn, err := pgx.ConnectConfig(ctx, n.pool.Config().ConnConfig)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+	_, err = conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize())
+	if err != nil {
+		return err
+	}
+	// пока соединения не было, уведомления могли потеряться
+	deliver(payloads, "")
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		deliver(payloads, notification.Payload)
+	}
+}
+
+// NotifierLocal заменяет LISTEN/NOTIFY в пределах одного процесса
+type NotifierLocal struct {
+	mu        sync.Mutex
+	listeners map[string][]chan string
+}
+
+func NewNotifierLocal() *NotifierLocal {
+	return &NotifierLocal{listeners: make(map[string][]chan string)}
+}
+
+// как и NOTIFY, уведомление уходит только после фиксации
+func (n *NotifierLocal) Notify(source Source, channel string, payload string) error {
+	AfterCommit(source, func() {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		for _, payloads := range n.listeners[channel] {
+			deliver(payloads, payload)
+		}
+	})
+	return nil
+}
+
+func (n *NotifierLocal) Listen(ctx context.Context, channel string) (<-chan string, error) {
+	payloads := make(chan string, 1)
+	n.mu.Lock()
+	n.listeners[channel] = append(n.listeners[channel], payloads)
+	n.mu.Unlock()
+	go func() {
+		<-ctx.Done()
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		listeners := n.listeners[channel]
+		for i, l := range listeners {
+			if l == payloads {
+				n.listeners[channel] = append(listeners[:i], listeners[i+1:]...)
+				break
+			}
+		}
+		close(payloads)
+	}()
+	return payloads, nil
+}
+
+// уведомления только будят получателя, поэтому при занятом буфере схлопываются
+func deliver(payloads chan<- string, payload string) {
+	select {
+	case payloads <- payload:
+	default:
+	}
+}
@@ -2,13 +2,43 @@ package db
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/fx"
 )
 
-func newOperator(pool *pgxpool.Pool) Operator {
-	return &OperatorPgx{pool}
+const (
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+)
+
+func newPgxStorage(dto storageCS, log *slog.Logger, lc fx.Lifecycle) (Operator, Notifier, error) {
+	pool, err := newPgxDriver(dto, lc)
+	if err != nil {
+		return nil, nil, err
+	}
+	operator := &OperatorPgx{pool}
+	m, err := newMigrator(operator, Postgres, log)
+	if err != nil {
+		return nil, nil, err
+	}
+	// хук добавлен после проверки соединения
+	lc.Append(
+		fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				if dto.Protocol.Postgres.Migration == checkMigration {
+					return m.Check(ctx)
+				}
+				return m.Apply(ctx)
+			},
+		},
+	)
+	return operator, newNotifier(pool, log), nil
 }
 
 func newPgxDriver(dto storageCS, lc fx.Lifecycle) (*pgxpool.Pool, error) {
@@ -32,3 +62,30 @@ func newPgxDriver(dto storageCS, lc fx.Lifecycle) (*pgxpool.Pool, error) {
 	)
 	return pool, nil
 }
+
+func convertTxOptions(opts TxOptions) pgx.TxOptions {
+	txOpts := pgx.TxOptions{IsoLevel: pgx.ReadCommitted, AccessMode: pgx.ReadWrite}
+	switch opts.Isolation {
+	case RepeatableRead:
+		txOpts.IsoLevel = pgx.RepeatableRead
+	case Serializable:
+		txOpts.IsoLevel = pgx.Serializable
+	}
+	if opts.ReadOnly {
+		txOpts.AccessMode = pgx.ReadOnly
+	}
+	return txOpts
+}
+
+// отказы сериализации означают конкурентное изменение,
+// и транзакцию можно повторить
+func convertTxErr(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+	if pgErr.Code == serializationFailure || pgErr.Code == deadlockDetected {
+		return fmt.Errorf("%w: %w", ErrConcurrentModification, err)
+	}
+	return err
+}
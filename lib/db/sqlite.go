@@ -0,0 +1,616 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/fx"
+	_ "modernc.org/sqlite"
+)
+
+const (
+	memoryPath = ":memory:"
+	// SQLITE_BUSY и SQLITE_LOCKED без расширенных кодов
+	sqliteBusy   = 5
+	sqliteLocked = 6
+	// так время хранится в схеме и понимается функциями дат SQLite
+	sqliteTimeLayout = "2006-01-02 15:04:05.999999999"
+)
+
+func newSQLiteStorage(dto storageCS, log *slog.Logger, lc fx.Lifecycle) (Operator, Notifier, error) {
+	sqlDB, err := newSQLiteDriver(dto, lc)
+	if err != nil {
+		return nil, nil, err
+	}
+	operator := &OperatorSQLite{sqlDB}
+	m, err := newMigrator(operator, SQLite, log)
+	if err != nil {
+		return nil, nil, err
+	}
+	// встроенное хранилище принадлежит одному процессу,
+	// поэтому его схему некому мигрировать заранее
+	lc.Append(fx.StartHook(m.Apply))
+	// и уведомления между узлами не нужны
+	return operator, NewNotifierLocal(), nil
+}
+
+func newSQLiteDriver(dto storageCS, lc fx.Lifecycle) (*sql.DB, error) {
+	path := dto.Protocol.SQLite.Path
+	sqlDB, err := sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, err
+	}
+	// каждое соединение с базой в памяти видело бы свою базу
+	if path == memoryPath {
+		sqlDB.SetMaxOpenConns(1)
+	}
+	lc.Append(fx.StopHook(sqlDB.Close))
+	return sqlDB, nil
+}
+
+// OperatorSQLite выдает DAO тот же SourcePgx, что и postgres,
+// но поверх соединения встроенного хранилища
+type OperatorSQLite struct {
+	db *sql.DB
+}
+
+// SQLite всегда сериализует транзакции, поэтому уровень изоляции
+// не задается; пишущая транзакция сразу берет блокировку записи,
+// чтобы не получить отказ при повышении читающей
+func (o *OperatorSQLite) Explicit(ctx context.Context, op func(Source) error, opts ...TxOption) error {
+	conn, err := o.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	begin := "BEGIN IMMEDIATE"
+	if NewTxOptions(opts...).ReadOnly {
+		begin = "BEGIN"
+	}
+	_, err = conn.ExecContext(ctx, begin)
+	if err != nil {
+		return convertSQLiteErr(err)
+	}
+	var commits []func()
+	err = op(SourcePgx{Ctx: ctx, Conn: &connSQLite{conn}, commits: &commits})
+	if err == nil {
+		_, err = conn.ExecContext(ctx, "COMMIT")
+	}
+	if err != nil {
+		_, rollbackErr := conn.ExecContext(context.WithoutCancel(ctx), "ROLLBACK")
+		return convertSQLiteErr(errors.Join(err, rollbackErr))
+	}
+	runCommits(commits)
+	return nil
+}
+
+func (o *OperatorSQLite) Implicit(ctx context.Context, op func(Source) error) error {
+	conn, err := o.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return convertSQLiteErr(op(SourcePgx{Ctx: ctx, Conn: &connSQLite{conn}}))
+}
+
+// занятая другим писателем база означает конкурентное изменение
+func convertSQLiteErr(err error) error {
+	var codeErr interface{ Code() int }
+	if !errors.As(err, &codeErr) {
+		return err
+	}
+	code := codeErr.Code() & 0xff
+	if code == sqliteBusy || code == sqliteLocked {
+		return fmt.Errorf("%w: %w", ErrConcurrentModification, err)
+	}
+	return err
+}
+
+// connSQLite исполняет запросы DAO, написанные для pgx:
+// переписывает параметры, кодирует документы в JSON и обратно
+// и исполняет пакеты последовательно
+type connSQLite struct {
+	conn *sql.Conn
+}
+
+func (c *connSQLite) Exec(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+	query, args, err := rewriteQuery(ctx, query, args)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	res, err := c.conn.ExecContext(ctx, query, args...)
+	if err != nil {
+		return pgconn.CommandTag{}, convertSQLiteErr(err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	verb, _, _ := strings.Cut(strings.TrimSpace(query), " ")
+	return pgconn.NewCommandTag(fmt.Sprintf("%s %d", strings.ToUpper(verb), affected)), nil
+}
+
+func (c *connSQLite) Query(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
+	query, args, err := rewriteQuery(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := c.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, convertSQLiteErr(err)
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, errors.Join(err, rows.Close())
+	}
+	fields := make([]pgconn.FieldDescription, len(columns))
+	for i, column := range columns {
+		fields[i] = pgconn.FieldDescription{Name: column}
+	}
+	return &rowsSQLite{rows: rows, fields: fields}, nil
+}
+
+func (c *connSQLite) QueryRow(ctx context.Context, query string, args ...any) pgx.Row {
+	rows, err := c.Query(ctx, query, args...)
+	return &rowSQLite{rows, err}
+}
+
+func (c *connSQLite) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	return &batchSQLite{conn: c, ctx: ctx, queries: b.QueuedQueries}
+}
+
+type rowsSQLite struct {
+	rows   *sql.Rows
+	fields []pgconn.FieldDescription
+	err    error
+}
+
+func (r *rowsSQLite) Close() {
+	err := r.rows.Close()
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+func (r *rowsSQLite) Err() error {
+	if r.err != nil {
+		return r.err
+	}
+	return convertSQLiteErr(r.rows.Err())
+}
+
+func (r *rowsSQLite) CommandTag() pgconn.CommandTag {
+	return pgconn.CommandTag{}
+}
+
+func (r *rowsSQLite) FieldDescriptions() []pgconn.FieldDescription {
+	return r.fields
+}
+
+func (r *rowsSQLite) Next() bool {
+	return r.rows.Next()
+}
+
+// документы и время SQLite отдает текстом, поэтому такие назначения
+// читаются как есть и декодируются после сканирования
+func (r *rowsSQLite) Scan(dest ...any) error {
+	targets := make([]any, len(dest))
+	for i, d := range dest {
+		if isDecodable(d) {
+			targets[i] = new(any)
+		} else {
+			targets[i] = d
+		}
+	}
+	err := r.rows.Scan(targets...)
+	if err != nil {
+		r.err = err
+		return err
+	}
+	for i, d := range dest {
+		if !isDecodable(d) {
+			continue
+		}
+		err = decodeValue(*targets[i].(*any), reflect.ValueOf(d).Elem())
+		if err != nil {
+			r.err = fmt.Errorf("column %s: %w", r.fields[i].Name, err)
+			return r.err
+		}
+	}
+	return nil
+}
+
+func (r *rowsSQLite) Values() ([]any, error) {
+	values := make([]any, len(r.fields))
+	targets := make([]any, len(r.fields))
+	for i := range values {
+		targets[i] = &values[i]
+	}
+	err := r.rows.Scan(targets...)
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// сырых значений в формате postgres нет
+func (r *rowsSQLite) RawValues() [][]byte {
+	return nil
+}
+
+func (r *rowsSQLite) Conn() *pgx.Conn {
+	return nil
+}
+
+type rowSQLite struct {
+	rows pgx.Rows
+	err  error
+}
+
+func (r *rowSQLite) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	defer r.rows.Close()
+	if !r.rows.Next() {
+		err := r.rows.Err()
+		if err != nil {
+			return err
+		}
+		return pgx.ErrNoRows
+	}
+	err := r.rows.Scan(dest...)
+	if err != nil {
+		return err
+	}
+	r.rows.Close()
+	return r.rows.Err()
+}
+
+// пакет исполняется по одному запросу на каждое чтение результата,
+// а непрочитанные запросы исполняются при закрытии, как и в pgx
+type batchSQLite struct {
+	conn    *connSQLite
+	ctx     context.Context
+	queries []*pgx.QueuedQuery
+	next    int
+	err     error
+}
+
+func (b *batchSQLite) Exec() (pgconn.CommandTag, error) {
+	query, err := b.nextQuery()
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	tag, err := b.conn.Exec(b.ctx, query.SQL, query.Arguments...)
+	b.err = err
+	return tag, err
+}
+
+func (b *batchSQLite) Query() (pgx.Rows, error) {
+	query, err := b.nextQuery()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := b.conn.Query(b.ctx, query.SQL, query.Arguments...)
+	b.err = err
+	return rows, err
+}
+
+func (b *batchSQLite) QueryRow() pgx.Row {
+	rows, err := b.Query()
+	return &rowSQLite{rows, err}
+}
+
+func (b *batchSQLite) Close() error {
+	for b.err == nil && b.next < len(b.queries) {
+		query := b.queries[b.next]
+		if query.Fn != nil {
+			b.err = query.Fn(b)
+			continue
+		}
+		_, b.err = b.Exec()
+	}
+	if errors.Is(b.err, errBatchClosed) {
+		return nil
+	}
+	err := b.err
+	b.err = errBatchClosed
+	return err
+}
+
+var errBatchClosed = errors.New("batch already closed")
+
+func (b *batchSQLite) nextQuery() (*pgx.QueuedQuery, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if b.next >= len(b.queries) {
+		return nil, errors.New("no more results in batch")
+	}
+	query := b.queries[b.next]
+	b.next++
+	return query, nil
+}
+
+// именованные параметры pgx раскрываются без соединения,
+// а позиционные $N переписываются в ?N
+func rewriteQuery(ctx context.Context, query string, args []any) (string, []any, error) {
+	if len(args) > 0 {
+		rewriter, ok := args[0].(pgx.QueryRewriter)
+		if ok {
+			var err error
+			query, args, err = rewriter.RewriteQuery(ctx, nil, query, args[1:])
+			if err != nil {
+				return "", nil, err
+			}
+		}
+	}
+	values := make([]any, len(args))
+	for i, arg := range args {
+		value, err := encodeArg(arg)
+		if err != nil {
+			return "", nil, fmt.Errorf("argument %d: %w", i+1, err)
+		}
+		values[i] = value
+	}
+	return rewritePlaceholders(query), values, nil
+}
+
+// плейсхолдеры внутри строковых литералов и идентификаторов в кавычках не трогаются
+func rewritePlaceholders(query string) string {
+	var sb strings.Builder
+	sb.Grow(len(query))
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		ch := query[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"':
+			quote = ch
+		case ch == '$' && i+1 < len(query) && query[i+1] >= '0' && query[i+1] <= '9':
+			ch = '?'
+		}
+		sb.WriteByte(ch)
+	}
+	return sb.String()
+}
+
+// документы кодируются в JSON, как это делает pgx для jsonb,
+// а именованные скалярные типы приводятся к базовым
+func encodeArg(arg any) (any, error) {
+	if arg == nil {
+		return nil, nil
+	}
+	switch v := arg.(type) {
+	case driver.Valuer, []byte:
+		return v, nil
+	case time.Time:
+		return v.UTC().Format(sqliteTimeLayout), nil
+	}
+	rv := reflect.ValueOf(arg)
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		if isDocument(rv.Type().Elem()) {
+			return encodeDocument(arg)
+		}
+		return encodeArg(rv.Elem().Interface())
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		return encodeDocument(arg)
+	case reflect.Bool:
+		return rv.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	case reflect.String:
+		return rv.String(), nil
+	default:
+		return nil, fmt.Errorf("unsupported type %T", arg)
+	}
+}
+
+func encodeDocument(arg any) (any, error) {
+	text, err := json.Marshal(arg)
+	if err != nil {
+		return nil, err
+	}
+	return string(text), nil
+}
+
+var (
+	scannerType = reflect.TypeFor[sql.Scanner]()
+	timeType    = reflect.TypeFor[time.Time]()
+	bytesType   = reflect.TypeFor[[]byte]()
+)
+
+func isDecodable(dest any) bool {
+	rt := reflect.TypeOf(dest)
+	if rt == nil || rt.Kind() != reflect.Pointer || rt.Implements(scannerType) {
+		return false
+	}
+	return rt.Elem() == timeType || isDocument(rt.Elem())
+}
+
+func isDocument(rt reflect.Type) bool {
+	if rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	if rt == timeType || rt == bytesType || reflect.PointerTo(rt).Implements(scannerType) {
+		return false
+	}
+	switch rt.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		return true
+	default:
+		return false
+	}
+}
+
+// составные значения приходят JSON-объектами, поля которых
+// сопоставляются по тегам db, как колонки строки
+func isRecord(rt reflect.Type) bool {
+	if rt.Kind() != reflect.Struct || reflect.PointerTo(rt).Implements(scannerType) {
+		return false
+	}
+	for i := range rt.NumField() {
+		if rt.Field(i).Tag.Get("db") != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeValue(src any, dv reflect.Value) error {
+	if src == nil {
+		dv.SetZero()
+		return nil
+	}
+	var text []byte
+	switch v := src.(type) {
+	case time.Time:
+		if dv.Type() != timeType {
+			return fmt.Errorf("cannot decode %T into %s", src, dv.Type())
+		}
+		dv.Set(reflect.ValueOf(v))
+		return nil
+	case string:
+		text = []byte(v)
+	case []byte:
+		text = v
+	default:
+		return fmt.Errorf("cannot decode %T into %s", src, dv.Type())
+	}
+	if dv.Type() == timeType {
+		t, err := time.ParseInLocation(sqliteTimeLayout, string(text), time.UTC)
+		if err != nil {
+			return err
+		}
+		dv.Set(reflect.ValueOf(t))
+		return nil
+	}
+	return decodeDocument(text, dv)
+}
+
+func decodeDocument(text []byte, dv reflect.Value) error {
+	text = bytes.TrimSpace(text)
+	if bytes.Equal(text, []byte("null")) {
+		dv.SetZero()
+		return nil
+	}
+	rt := dv.Type()
+	switch {
+	case rt.Kind() == reflect.Pointer && isRecord(rt.Elem()):
+		ptr := reflect.New(rt.Elem())
+		err := decodeDocument(text, ptr.Elem())
+		if err != nil {
+			return err
+		}
+		dv.Set(ptr)
+		return nil
+	case rt.Kind() == reflect.Slice && isRecord(rt.Elem()):
+		var items []json.RawMessage
+		err := json.Unmarshal(text, &items)
+		if err != nil {
+			return err
+		}
+		slice := reflect.MakeSlice(rt, len(items), len(items))
+		for i, item := range items {
+			err = decodeDocument(item, slice.Index(i))
+			if err != nil {
+				return err
+			}
+		}
+		dv.Set(slice)
+		return nil
+	case isRecord(rt):
+		return decodeRecord(text, dv)
+	default:
+		return json.Unmarshal(text, dv.Addr().Interface())
+	}
+}
+
+func decodeRecord(text []byte, dv reflect.Value) error {
+	var fields map[string]json.RawMessage
+	err := json.Unmarshal(text, &fields)
+	if err != nil {
+		return err
+	}
+	rt := dv.Type()
+	for i := range rt.NumField() {
+		raw, ok := fields[rt.Field(i).Tag.Get("db")]
+		if !ok {
+			continue
+		}
+		field := dv.Field(i)
+		if isDocument(field.Type()) {
+			err = decodeDocument(raw, field)
+		} else {
+			err = decodeScalar(raw, field)
+		}
+		if err != nil {
+			return fmt.Errorf("field %s: %w", rt.Field(i).Name, err)
+		}
+	}
+	return nil
+}
+
+func decodeScalar(raw json.RawMessage, dv reflect.Value) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var src any
+	err := dec.Decode(&src)
+	if err != nil {
+		return err
+	}
+	if number, ok := src.(json.Number); ok {
+		src, err = number.Int64()
+		if err != nil {
+			src, err = number.Float64()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if scanner, ok := dv.Addr().Interface().(sql.Scanner); ok {
+		return scanner.Scan(src)
+	}
+	if src == nil {
+		dv.SetZero()
+		return nil
+	}
+	if dv.Type() == timeType {
+		return decodeValue(src, dv)
+	}
+	sv := reflect.ValueOf(src)
+	switch {
+	case dv.Kind() == reflect.Bool && sv.Kind() == reflect.Int64:
+		dv.SetBool(sv.Int() != 0)
+	case (dv.Kind() == reflect.String) != (sv.Kind() == reflect.String):
+		return fmt.Errorf("cannot assign %T to %s", src, dv.Type())
+	case sv.CanConvert(dv.Type()):
+		dv.Set(sv.Convert(dv.Type()))
+	default:
+		return fmt.Errorf("cannot assign %T to %s", src, dv.Type())
+	}
+	return nil
+}
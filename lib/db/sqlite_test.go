@@ -0,0 +1,165 @@
+package db
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRewritePlaceholders(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "positional",
+			query: "SELECT * FROM t WHERE a = $1 AND b = $12",
+			want:  "SELECT * FROM t WHERE a = ?1 AND b = ?12",
+		},
+		{
+			name:  "string literal",
+			query: "SELECT '$1', a FROM t WHERE b = $1",
+			want:  "SELECT '$1', a FROM t WHERE b = ?1",
+		},
+		{
+			name:  "quoted identifier",
+			query: `SELECT "$2" FROM t WHERE a = $2`,
+			want:  `SELECT "$2" FROM t WHERE a = ?2`,
+		},
+		{
+			name:  "escaped quote",
+			query: "SELECT 'it''s $1' WHERE a = $1",
+			want:  "SELECT 'it''s $1' WHERE a = ?1",
+		},
+		{
+			name:  "dollar without digit",
+			query: "SELECT $a, $ FROM t WHERE a = $1",
+			want:  "SELECT $a, $ FROM t WHERE a = ?1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rewritePlaceholders(tt.query)
+			if got != tt.want {
+				t.Errorf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+type testKind uint8
+
+type testName string
+
+type testDoc struct {
+	On string `json:"on"`
+	To int64  `json:"to"`
+}
+
+func TestEncodeArg(t *testing.T) {
+	ts := time.Date(2024, 3, 1, 12, 30, 15, 500, time.FixedZone("MSK", 3*60*60))
+	num := 7
+	var nilNum *int
+	tests := []struct {
+		name string
+		arg  any
+		want any
+		err  bool
+	}{
+		{name: "nil", arg: nil, want: nil},
+		{name: "named uint", arg: testKind(3), want: int64(3)},
+		{name: "named string", arg: testName("foo"), want: "foo"},
+		{name: "bool", arg: true, want: true},
+		{name: "float", arg: float32(1.5), want: 1.5},
+		{name: "time", arg: ts, want: "2024-03-01 09:30:15.0000005"},
+		{name: "bytes", arg: []byte("raw"), want: []byte("raw")},
+		{name: "valuer", arg: sql.NullString{String: "x", Valid: true}, want: sql.NullString{String: "x", Valid: true}},
+		{name: "pointer", arg: &num, want: int64(7)},
+		{name: "nil pointer", arg: nilNum, want: nil},
+		{name: "struct", arg: testDoc{On: "a", To: 2}, want: `{"on":"a","to":2}`},
+		{name: "struct pointer", arg: &testDoc{On: "b"}, want: `{"on":"b","to":0}`},
+		{name: "slice", arg: []testDoc{{On: "a", To: 1}}, want: `[{"on":"a","to":1}]`},
+		{name: "map", arg: map[string]int{"a": 1}, want: `{"a":1}`},
+		{name: "unsupported", arg: make(chan int), err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := encodeArg(tt.arg)
+			if tt.err {
+				if err == nil {
+					t.Fatalf("want error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("unexpected value (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+type testRec struct {
+	ID     string        `db:"id"`
+	Kind   testKind      `db:"kind"`
+	Active bool          `db:"active"`
+	Doc    testDoc       `db:"doc"`
+	Seen   time.Time     `db:"seen"`
+	Null   sql.NullInt64 `db:"null"`
+}
+
+func TestDecodeDocument(t *testing.T) {
+	seen := time.Date(2024, 3, 1, 9, 30, 15, 0, time.UTC)
+	rec := testRec{
+		ID:     "a",
+		Kind:   2,
+		Active: true,
+		Doc:    testDoc{On: "x", To: 1},
+		Seen:   seen,
+		Null:   sql.NullInt64{Int64: 5, Valid: true},
+	}
+	recText := `{"id": "a", "kind": 2, "active": 1, "doc": {"on": "x", "to": 1},
+		"seen": "2024-03-01 09:30:15", "null": 5, "extra": true}`
+	tests := []struct {
+		name string
+		text string
+		dest any
+		want any
+		err  bool
+	}{
+		{name: "document", text: `{"on": "y", "to": 3}`, dest: new(testDoc), want: testDoc{On: "y", To: 3}},
+		{name: "map", text: `{"a": 1}`, dest: new(map[string]int), want: map[string]int{"a": 1}},
+		{name: "null", text: ` null `, dest: &testDoc{On: "z"}, want: testDoc{}},
+		{name: "record", text: recText, dest: new(testRec), want: rec},
+		{name: "record pointer", text: recText, dest: new(*testRec), want: &rec},
+		{name: "records", text: "[" + recText + "]", dest: new([]testRec), want: []testRec{rec}},
+		{name: "record null field", text: `{"id": "b", "null": null}`, dest: new(testRec), want: testRec{ID: "b"}},
+		{name: "string into number", text: `{"kind": "2"}`, dest: new(testRec), err: true},
+		{name: "number into string", text: `{"id": 2}`, dest: new(testRec), err: true},
+		{name: "malformed", text: `{"on":`, dest: new(testDoc), err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := decodeDocument([]byte(tt.text), reflect.ValueOf(tt.dest).Elem())
+			if tt.err {
+				if err == nil {
+					t.Fatal("want error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			got := reflect.ValueOf(tt.dest).Elem().Interface()
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("unexpected value (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
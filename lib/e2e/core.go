@@ -12,6 +12,7 @@ import (
 	compexec1 "github.com/orglang/go-sdk/proc/compexec"
 	compstep1 "github.com/orglang/go-sdk/proc/compstep"
 	"github.com/orglang/go-sdk/proc/termdec"
+	"github.com/orglang/go-sdk/proc/termdef"
 	"github.com/orglang/go-sdk/proc/typedef"
 )
 
@@ -50,6 +51,14 @@ func newProcDecAPI(client *resty.Client) ProcDecAPI {
 	return &termdec.RestySDK{Client: client}
 }
 
+type ProcDefAPI interface {
+	Create(termdef.DefSpec) (termdef.DefSnap, error)
+}
+
+func newProcDefAPI(client *resty.Client) ProcDefAPI {
+	return &termdef.RestySDK{Client: client}
+}
+
 type ProcExecAPI interface {
 	Take(compstep1.StepSpec) error
 }
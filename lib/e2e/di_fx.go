@@ -10,6 +10,7 @@ var Module = fx.Module("lib/e2e",
 		newPoolDecAPI,
 		newXactDefAPI,
 		newProcDecAPI,
+		newProcDefAPI,
 		newProcExecAPI,
 		newTypeDefAPI,
 	),
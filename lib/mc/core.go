@@ -0,0 +1,111 @@
+package mc
+
+import (
+	"container/list"
+	"expvar"
+	"sync"
+	"sync/atomic"
+)
+
+// метрики всех кэшей публикуются через /debug/vars
+var metrics = expvar.NewMap("caches")
+
+// Limits ограничивает число записей каждого кэша
+type Limits struct {
+	Entries int
+}
+
+// Cache ограниченный кэш, вытесняющий давно не читанные записи
+type Cache[K comparable, V any] struct {
+	mu      sync.Mutex
+	limit   int
+	entries map[K]*list.Element
+	order   *list.List
+	// растет при каждой инвалидации, чтобы не сохранять значения,
+	// прочитанные до нее
+	gen    uint64
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+type entry[K comparable, V any] struct {
+	key K
+	val V
+}
+
+func New[K comparable, V any](name string, limits Limits) *Cache[K, V] {
+	c := &Cache[K, V]{
+		limit:   limits.Entries,
+		entries: make(map[K]*list.Element, limits.Entries),
+		order:   list.New(),
+	}
+	metrics.Set(name, expvar.Func(c.stats))
+	return c
+}
+
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		c.misses.Add(1)
+		var zero V
+		return zero, false
+	}
+	c.hits.Add(1)
+	c.order.MoveToFront(elem)
+	return elem.Value.(*entry[K, V]).val, true
+}
+
+// Gen запоминается перед чтением источника и передается в Put
+func (c *Cache[K, V]) Gen() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.gen
+}
+
+// Put пропускает значение, если после чтения источника была инвалидация
+func (c *Cache[K, V]) Put(gen uint64, key K, val V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if gen != c.gen {
+		return
+	}
+	elem, ok := c.entries[key]
+	if ok {
+		elem.Value.(*entry[K, V]).val = val
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&entry[K, V]{key, val})
+	if c.order.Len() > c.limit {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*entry[K, V]).key)
+	}
+}
+
+func (c *Cache[K, V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	clear(c.entries)
+	c.order.Init()
+}
+
+func (c *Cache[K, V]) stats() any {
+	hits, misses := c.hits.Load(), c.misses.Load()
+	ratio := 0.0
+	if hits+misses > 0 {
+		ratio = float64(hits) / float64(hits+misses)
+	}
+	c.mu.Lock()
+	size := c.order.Len()
+	c.mu.Unlock()
+	return map[string]any{
+		"hits":   hits,
+		"misses": misses,
+		"ratio":  ratio,
+		"size":   size,
+	}
+}
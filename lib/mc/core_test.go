@@ -0,0 +1,82 @@
+package mc
+
+import (
+	"testing"
+)
+
+func TestCacheEviction(t *testing.T) {
+	c := New[string, int]("test_eviction", Limits{Entries: 2})
+	c.Put(c.Gen(), "a", 1)
+	c.Put(c.Gen(), "b", 2)
+	// чтение освежает запись, поэтому вытесняется b
+	_, ok := c.Get("a")
+	if !ok {
+		t.Fatal("a missing")
+	}
+	c.Put(c.Gen(), "c", 3)
+	_, ok = c.Get("b")
+	if ok {
+		t.Error("b not evicted")
+	}
+	for key, want := range map[string]int{"a": 1, "c": 3} {
+		got, ok := c.Get(key)
+		if !ok || got != want {
+			t.Errorf("key %v: got %v, %v, want %v", key, got, ok, want)
+		}
+	}
+}
+
+func TestCachePutRefreshes(t *testing.T) {
+	c := New[string, int]("test_refresh", Limits{Entries: 2})
+	c.Put(c.Gen(), "a", 1)
+	c.Put(c.Gen(), "b", 2)
+	// повторная запись обновляет значение и освежает запись
+	c.Put(c.Gen(), "a", 10)
+	c.Put(c.Gen(), "c", 3)
+	got, ok := c.Get("a")
+	if !ok || got != 10 {
+		t.Errorf("got %v, %v, want 10", got, ok)
+	}
+	_, ok = c.Get("b")
+	if ok {
+		t.Error("b not evicted")
+	}
+}
+
+func TestCacheStalePut(t *testing.T) {
+	c := New[string, int]("test_stale", Limits{Entries: 2})
+	c.Put(c.Gen(), "a", 1)
+	// значение прочитано до инвалидации
+	gen := c.Gen()
+	c.Purge()
+	_, ok := c.Get("a")
+	if ok {
+		t.Fatal("a survived purge")
+	}
+	c.Put(gen, "a", 2)
+	_, ok = c.Get("a")
+	if ok {
+		t.Error("stale value stored")
+	}
+	c.Put(c.Gen(), "a", 3)
+	got, ok := c.Get("a")
+	if !ok || got != 3 {
+		t.Errorf("got %v, %v, want 3", got, ok)
+	}
+}
+
+func TestCacheStats(t *testing.T) {
+	c := New[string, int]("test_stats", Limits{Entries: 2})
+	c.Put(c.Gen(), "a", 1)
+	c.Get("a")
+	c.Get("a")
+	c.Get("a")
+	c.Get("b")
+	stats := c.stats().(map[string]any)
+	if stats["hits"] != int64(3) || stats["misses"] != int64(1) || stats["size"] != 1 {
+		t.Errorf("got %v", stats)
+	}
+	if stats["ratio"] != 0.75 {
+		t.Errorf("got ratio %v, want 0.75", stats["ratio"])
+	}
+}
@@ -0,0 +1,27 @@
+package mc
+
+import (
+	"orglang/go-engine/lib/kv"
+)
+
+func newCachingCS(loader kv.Loader) (cachingCS, error) {
+	dto := new(cachingCS)
+	loadingErr := loader.Load("caching", dto)
+	if loadingErr != nil {
+		return cachingCS{}, loadingErr
+	}
+	validateErr := dto.Validate()
+	if validateErr != nil {
+		return cachingCS{}, validateErr
+	}
+	return *dto, nil
+}
+
+type cachingCS struct {
+	// сколько записей держит каждый кэш окружения
+	Entries int `mapstructure:"entries"`
+}
+
+func newLimits(dto cachingCS) Limits {
+	return Limits{Entries: dto.Entries}
+}
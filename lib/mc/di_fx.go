@@ -0,0 +1,15 @@
+package mc
+
+import (
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module("lib/mc",
+	fx.Provide(
+		newLimits,
+	),
+	fx.Provide(
+		fx.Private,
+		newCachingCS,
+	),
+)
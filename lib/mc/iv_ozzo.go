@@ -0,0 +1,16 @@
+package mc
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+const (
+	MinEntries = 1
+	MaxEntries = 1 << 20
+)
+
+func (dto cachingCS) Validate() error {
+	return validation.ValidateStruct(&dto,
+		validation.Field(&dto.Entries, validation.Required, validation.Min(MinEntries), validation.Max(MaxEntries)),
+	)
+}
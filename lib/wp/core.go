@@ -0,0 +1,24 @@
+package wp
+
+import (
+	"errors"
+)
+
+// Executor исполняет задачи с одинаковым ключом по порядку их отправки,
+// а задачи с разными ключами - параллельно
+type Executor interface {
+	// Submit блокируется, пока очередь исполнителя ключа заполнена
+	Submit(key string, task func()) error
+}
+
+// Policy сообщает политику паник тем, кто перехватывает их сам,
+// например шагу, который исполняется и вне пула
+type Policy interface {
+	// Recovers разрешает продолжить работу после паники
+	Recovers() bool
+}
+
+// RecoverPolicy перехватывает паники независимо от конфигурации
+var RecoverPolicy Policy = recoverPanics
+
+var ErrStopped = errors.New("executor stopped")
@@ -0,0 +1,47 @@
+package wp
+
+import (
+	"orglang/go-engine/lib/kv"
+)
+
+func newExecutionCS(loader kv.Loader) (executionCS, error) {
+	dto := new(executionCS)
+	loadingErr := loader.Load("execution", dto)
+	if loadingErr != nil {
+		return executionCS{}, loadingErr
+	}
+	validateErr := dto.Validate()
+	if validateErr != nil {
+		return executionCS{}, validateErr
+	}
+	return *dto, nil
+}
+
+type executionCS struct {
+	Pool   poolCS      `mapstructure:"pool"`
+	Panics panicModeCS `mapstructure:"panics"`
+}
+
+type poolCS struct {
+	// число параллельных исполнителей
+	Size int `mapstructure:"size"`
+	// сколько задач может ждать исполнителя, прежде чем отправитель заблокируется
+	Queue int `mapstructure:"queue"`
+}
+
+type panicModeCS string
+
+const (
+	// паника задачи журналируется, исполнитель продолжает работу
+	recoverPanics panicModeCS = "recover"
+	// паника задачи роняет процесс
+	crashPanics panicModeCS = "crash"
+)
+
+func (mode panicModeCS) Recovers() bool {
+	return mode == recoverPanics
+}
+
+func newPanicPolicy(dto executionCS) panicModeCS {
+	return dto.Panics
+}
@@ -8,5 +8,11 @@ var Module = fx.Module("lib/wp",
 	fx.Provide(
 		newPondPool,
 		newWorkerPool,
+		fx.Annotate(newShardedExecutor, fx.As(new(Executor))),
+		fx.Annotate(newPanicPolicy, fx.As(new(Policy))),
+	),
+	fx.Provide(
+		fx.Private,
+		newExecutionCS,
 	),
 )
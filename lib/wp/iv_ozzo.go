@@ -0,0 +1,26 @@
+package wp
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+const (
+	MinSize  = 1
+	MaxSize  = 1024
+	MinQueue = 1
+	MaxQueue = 1 << 20
+)
+
+func (dto executionCS) Validate() error {
+	return validation.ValidateStruct(&dto,
+		validation.Field(&dto.Pool, validation.Required),
+		validation.Field(&dto.Panics, validation.Required, validation.In(recoverPanics, crashPanics)),
+	)
+}
+
+func (dto poolCS) Validate() error {
+	return validation.ValidateStruct(&dto,
+		validation.Field(&dto.Size, validation.Required, validation.Min(MinSize), validation.Max(MaxSize)),
+		validation.Field(&dto.Queue, validation.Required, validation.Min(MinQueue), validation.Max(MaxQueue)),
+	)
+}
@@ -4,6 +4,10 @@ import (
 	"github.com/alitto/pond/v2"
 )
 
-func newPondPool() pond.Pool {
-	return pond.NewPool(1, pond.WithoutPanicRecovery())
+func newPondPool(dto executionCS) pond.Pool {
+	opts := []pond.Option{pond.WithQueueSize(dto.Pool.Queue)}
+	if dto.Panics == crashPanics {
+		opts = append(opts, pond.WithoutPanicRecovery())
+	}
+	return pond.NewPool(dto.Pool.Size, opts...)
 }
@@ -0,0 +1,94 @@
+package wp
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"reflect"
+	"runtime/debug"
+	"sync"
+
+	"go.uber.org/fx"
+)
+
+// shardedExecutor закрепляет ключ за одной из очередей,
+// каждую из которых разбирает свой исполнитель
+type shardedExecutor struct {
+	shards  []chan func()
+	panics  panicModeCS
+	mu      sync.RWMutex
+	stopped bool
+	wg      sync.WaitGroup
+	log     *slog.Logger
+}
+
+func newShardedExecutor(dto executionCS, log *slog.Logger, lc fx.Lifecycle) *shardedExecutor {
+	name := slog.String("name", reflect.TypeFor[shardedExecutor]().Name())
+	// общая граница очереди делится между исполнителями
+	shardQueue := max(dto.Pool.Queue/dto.Pool.Size, 1)
+	shards := make([]chan func(), dto.Pool.Size)
+	for i := range shards {
+		shards[i] = make(chan func(), shardQueue)
+	}
+	e := &shardedExecutor{shards: shards, panics: dto.Panics, log: log.With(name)}
+	lc.Append(
+		fx.Hook{
+			OnStart: func(context.Context) error {
+				e.start()
+				return nil
+			},
+			OnStop: func(context.Context) error {
+				e.stop()
+				return nil
+			},
+		},
+	)
+	return e
+}
+
+func (e *shardedExecutor) start() {
+	for _, shard := range e.shards {
+		e.wg.Go(func() {
+			for task := range shard {
+				e.run(task)
+			}
+		})
+	}
+}
+
+// дожидается исполнения уже принятых задач
+func (e *shardedExecutor) stop() {
+	e.mu.Lock()
+	e.stopped = true
+	for _, shard := range e.shards {
+		close(shard)
+	}
+	e.mu.Unlock()
+	e.wg.Wait()
+}
+
+func (e *shardedExecutor) Submit(key string, task func()) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.stopped {
+		return ErrStopped
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	e.shards[h.Sum32()%uint32(len(e.shards))] <- task
+	return nil
+}
+
+// при политике crash паника выходит из исполнителя и роняет процесс
+func (e *shardedExecutor) run(task func()) {
+	if e.panics.Recovers() {
+		defer func() {
+			r := recover()
+			if r != nil {
+				e.log.Error("task panicked", slog.Any("reason", fmt.Sprint(r)), slog.String("stack", string(debug.Stack())))
+			}
+		}()
+	}
+	task()
+}
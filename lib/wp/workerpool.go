@@ -4,6 +4,7 @@ import (
 	"github.com/gammazero/workerpool"
 )
 
-func newWorkerPool() *workerpool.WorkerPool {
-	return workerpool.New(1)
+// очередь workerpool не ограничена, а паники не перехватываются
+func newWorkerPool(dto executionCS) *workerpool.WorkerPool {
+	return workerpool.New(dto.Pool.Size)
 }
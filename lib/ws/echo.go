@@ -2,16 +2,24 @@ package ws
 
 import (
 	"context"
+	"errors"
+	"expvar"
 	"fmt"
 	"log/slog"
+	"net/http"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"go.uber.org/fx"
+
+	"orglang/go-engine/lib/db"
 )
 
 func newEchoServer(dto exchangeCS, l *slog.Logger, lc fx.Lifecycle) *echo.Echo {
 	e := echo.New()
+	e.HTTPErrorHandler = newErrorHandler(e)
+	// метрики кэшей и рантайма
+	e.GET("/debug/vars", echo.WrapHandler(expvar.Handler()))
 	log := l.With(slog.String("name", "echoServer"))
 	e.Use(middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
 		LogMethod:   true,
@@ -49,3 +57,13 @@ func newEchoServer(dto exchangeCS, l *slog.Logger, lc fx.Lifecycle) *echo.Echo {
 	)
 	return e
 }
+
+// конфликт ревизий отличим от прочих отказов, так как запрос можно повторить
+func newErrorHandler(e *echo.Echo) echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		if errors.Is(err, db.ErrConcurrentModification) {
+			err = echo.NewHTTPError(http.StatusConflict, err.Error()).SetInternal(err)
+		}
+		e.DefaultHTTPErrorHandler(err, c)
+	}
+}
@@ -10,6 +10,6 @@ var Module = fx.Module("pool/commexch",
 	),
 	fx.Provide(
 		fx.Private,
-		fx.Annotate(newSQLBuilder, fx.As(new(queryBuilder))),
+		newDialectBuilder,
 	),
 )
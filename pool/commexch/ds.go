@@ -24,6 +24,7 @@ type exchRecDS struct {
 
 type exchModDS struct {
 	CommID   string          `db:"comm_id"`
+	CommRN   int64           `db:"comm_rn"`
 	OffsetNr sql.Null[int64] `db:"offset_nr"`
 }
 
@@ -1,6 +1,8 @@
 package commexch
 
 import (
+	"errors"
+	"fmt"
 	"log/slog"
 	"reflect"
 
@@ -42,20 +44,26 @@ func (dao *pgxDAO) AddRec(source db.Source, rec ExchRec) error {
 	return nil
 }
 
+// ревизия обмена сверяется со снепшотом, по которому был сделан шаг
 func (dao *pgxDAO) ModifyRec(source db.Source, mod ExchMod) error {
-	if mod.OffsetNr == nil {
+	if mod.CommRef.CommID.IsEmpty() {
 		return nil
 	}
 	ds := db.MustConform[db.SourcePgx](source)
 	dto := DataFromMod(mod)
 	refAttr := slog.Any("ref", mod.CommRef)
 	sql, args := dao.qb.updateRec(dto)
-	_, execErr := ds.Conn.Exec(ds.Ctx, sql, args...)
-	if execErr != nil {
+	var commRN int64
+	scanErr := ds.Conn.QueryRow(ds.Ctx, sql, args...).Scan(&commRN)
+	if errors.Is(scanErr, pgx.ErrNoRows) {
+		dao.log.Error("update failed", refAttr)
+		return errConcurrentModification(mod.CommRef)
+	}
+	if scanErr != nil {
 		dao.log.Error("query execution failed", refAttr, slog.String("sql", sql))
-		return execErr
+		return scanErr
 	}
-	dao.log.Log(ds.Ctx, lf.LevelTrace, "modification succeed", slog.Any("dto", dto))
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "modification succeed", slog.Any("dto", dto), slog.Int64("rn", commRN))
 	return nil
 }
 
@@ -88,3 +96,7 @@ func (dao *pgxDAO) GetSnapByQry(source db.Source, qry ExchQry) (ExchSnap, error)
 	}
 	return snap, nil
 }
+
+func errConcurrentModification(got commsem.SemRef) error {
+	return fmt.Errorf("%w: %v", db.ErrConcurrentModification, got)
+}
@@ -1,5 +1,9 @@
 package commexch
 
+import (
+	"orglang/go-engine/lib/db"
+)
+
 const (
 	commExchs = "pool_comm_exchs "
 	commTurns = "pool_comm_turns "
@@ -10,3 +14,10 @@ type queryBuilder interface {
 	updateRec(exchModDS) (string, []any)
 	selectSnap(exchQryDS) (string, []any)
 }
+
+func newDialectBuilder(dialect db.Dialect) queryBuilder {
+	if dialect == db.SQLite {
+		return newSQLiteBuilder()
+	}
+	return newSQLBuilder()
+}
@@ -26,16 +26,24 @@ func (qb *sqlBuilder) insertRec(rec exchRecDS) (string, []any) {
 	return qb.exchBuilder.InsertInto(commExchs, rec).Build()
 }
 
+// продвигает ревизию с проверкой на конкурентное изменение
 func (qb *sqlBuilder) updateRec(mod exchModDS) (string, []any) {
-	conn := qb.exchBuilder.Update(commExchs, mod)
+	exch := sqlbuilder.PostgreSQL.NewUpdateBuilder()
+	exch.Update(commExchs)
+	exch.Set(exch.Incr("comm_rn"))
 	if mod.OffsetNr.Valid {
-		conn.Set(conn.Assign("offset_nr", mod.OffsetNr.V))
+		exch.SetMore(exch.Assign("offset_nr", mod.OffsetNr.V))
 	}
-	conn.Where(conn.Equal("comm_id", mod.CommID))
-	return conn.Build()
+	exch.Where(exch.Equal("comm_id", mod.CommID), exch.Equal("comm_rn", mod.CommRN))
+	exch.Returning("comm_rn")
+	return exch.Build()
 }
 
 func (qb *sqlBuilder) selectSnap(qry exchQryDS) (string, []any) {
+	return qb.buildSnap(qry, turnsAgg)
+}
+
+func (qb *sqlBuilder) buildSnap(qry exchQryDS, agg string) (string, []any) {
 	exch := qb.exchBuilder.SelectFrom(commExchs + "exch")
 	turn := qb.turnBuilder.SelectFrom(commTurns + "turn")
 	turn.Where(turn.Equal("comm_id", qry.CommID))
@@ -45,8 +53,12 @@ func (qb *sqlBuilder) selectSnap(qry exchQryDS) (string, []any) {
 	turns := sqlbuilder.PostgreSQL.NewCTEBuilder()
 	return exch.With(turns.With(sqlbuilder.CTEQuery("turns").As(turn))).
 		SelectMore(
-			exch.BuilderAs(sqlbuilder.Build("SELECT array_agg(row(t.*)) FROM turns t WHERE t.comm_rn > exch.offset_nr"), "turns"),
+			exch.BuilderAs(sqlbuilder.Build(agg), "turns"),
 		).
 		Where(exch.Equal("exch.comm_id", qry.CommID)).
 		Build()
 }
+
+const (
+	turnsAgg = "SELECT array_agg(row(t.*)) FROM turns t WHERE t.comm_rn > exch.offset_nr"
+)
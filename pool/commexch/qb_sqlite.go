@@ -0,0 +1,21 @@
+package commexch
+
+// sqliteBuilder заменяет запросы, у которых нет переносимой формы
+type sqliteBuilder struct {
+	*sqlBuilder
+}
+
+func newSQLiteBuilder() *sqliteBuilder {
+	return &sqliteBuilder{newSQLBuilder()}
+}
+
+func (qb *sqliteBuilder) selectSnap(qry exchQryDS) (string, []any) {
+	return qb.buildSnap(qry, sqliteTurnsAgg)
+}
+
+const (
+	sqliteTurnsAgg = `SELECT json_group_array(json_object(
+	'comm_id', t.comm_id, 'comm_rn', t.comm_rn, 'comp_id', t.comp_id,
+	'chnl_id', t.chnl_id, 'kind', t.kind, 'exp', json(t.exp)
+) ORDER BY t.comm_rn) FROM turns t WHERE t.comm_rn > exch.offset_nr`
+)
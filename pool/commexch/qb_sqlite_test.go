@@ -0,0 +1,13 @@
+package commexch
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+)
+
+func TestSelectSnapSQLite(t *testing.T) {
+	qb := newSQLiteBuilder()
+	sql, _ := qb.selectSnap(exchQryDS{ChnlID: sql.Null[string]{Valid: true}})
+	fmt.Println(sql)
+}
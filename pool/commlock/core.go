@@ -0,0 +1,251 @@
+package commlock
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strconv"
+	"time"
+
+	"orglang/go-engine/lib/db"
+
+	"orglang/go-engine/adt/identity"
+	"orglang/go-engine/adt/symbol"
+	"orglang/go-engine/adt/uniqsym"
+)
+
+type API interface {
+	// Detect строит граф ожиданий и помечает зависшие вычисления
+	Detect(Realm) ([]LockRec, error)
+	// RetrieveLocks отдает пометки последнего обнаружения
+	RetrieveLocks(Realm) ([]LockRec, error)
+}
+
+// вычисления и обмены proc и pool хранятся раздельно
+type Realm string
+
+const (
+	ProcRealm Realm = "proc"
+	PoolRealm Realm = "pool"
+)
+
+type Kind int16
+
+const (
+	unkKind Kind = iota
+	// вычисления ждут друг друга по кругу
+	CycleKind
+	// другого конца канала нет ни у одного вычисления
+	OrphanKind
+)
+
+// ожидание вычислением хода по каналу (aka ребро графа ожиданий)
+type WaitRec struct {
+	CompID identity.ADT
+	CompQN uniqsym.ADT
+	CommID identity.ADT
+	ChnlID identity.ADT
+	ChnlPH symbol.ADT
+	// владелец другого конца канала; пустой у сиротского ожидания
+	HolderID identity.ADT
+}
+
+// зависание: связная часть графа, в которой никто не продвинется
+type LockRec struct {
+	// наименьший идентификатор вычисления, поэтому стабилен между обнаружениями
+	LockID     identity.ADT
+	Kind       Kind
+	Waits      []WaitRec
+	DetectedAt time.Time
+}
+
+type service struct {
+	lockRepo Repo
+	operator db.Operator
+	log      *slog.Logger
+}
+
+// for compilation purposes
+func newAPI() API {
+	return new(service)
+}
+
+func newService(lockRepo Repo, operator db.Operator, log *slog.Logger) *service {
+	name := slog.String("name", reflect.TypeFor[service]().Name())
+	return &service{lockRepo, operator, log.With(name)}
+}
+
+func (s *service) Detect(realm Realm) (_ []LockRec, err error) {
+	ctx := context.Background()
+	realmAttr := slog.Any("realm", realm)
+	var locks []LockRec
+	// ожидания читаются из одного снепшота, иначе граф может оказаться
+	// склеенным из разных моментов
+	err = s.operator.Explicit(ctx, func(ds db.Source) error {
+		waits, err := s.lockRepo.SelectWaits(ds, realm)
+		if err != nil {
+			return err
+		}
+		locks = FindLocks(waits)
+		detectedAt := time.Now()
+		for i := range locks {
+			locks[i].DetectedAt = detectedAt
+		}
+		return s.lockRepo.SaveLocks(ds, realm, locks, detectedAt)
+	}, db.WithIsolation(db.RepeatableRead))
+	if err != nil {
+		s.log.Error("detection failed", realmAttr)
+		return nil, err
+	}
+	for _, lock := range locks {
+		s.log.Warn("deadlock detected", realmAttr, slog.Any("lock", lock))
+	}
+	s.log.Debug("detection succeed", realmAttr, slog.Int("locks", len(locks)))
+	return locks, nil
+}
+
+func (s *service) RetrieveLocks(realm Realm) (_ []LockRec, err error) {
+	ctx := context.Background()
+	var locks []LockRec
+	selectErr := s.operator.Implicit(ctx, func(ds db.Source) error {
+		locks, err = s.lockRepo.SelectLocks(ds, realm)
+		return err
+	})
+	if selectErr != nil {
+		s.log.Error("retrieval failed", slog.Any("realm", realm))
+		return nil, selectErr
+	}
+	return locks, nil
+}
+
+// FindLocks отбирает вычисления, которые не продвинутся без внешнего шага.
+//
+// Вычисление с ожиданием заблокировано. Оно остается зависшим, пока все
+// владельцы ждущих его каналов тоже зависли: так отсеиваются ожидания
+// тех, кто еще может сделать ход. Оставшиеся вычисления группируются
+// по связности, а группа без цикла держится на сиротском ожидании.
+func FindLocks(waits []WaitRec) []LockRec {
+	waitsBy := make(map[identity.ADT][]WaitRec)
+	for _, wait := range waits {
+		waitsBy[wait.CompID] = append(waitsBy[wait.CompID], wait)
+	}
+	stuck := make(map[identity.ADT]bool, len(waitsBy))
+	for compID := range waitsBy {
+		stuck[compID] = true
+	}
+	for changed := true; changed; {
+		changed = false
+		for compID := range stuck {
+			for _, wait := range waitsBy[compID] {
+				if !wait.HolderID.IsEmpty() && !stuck[wait.HolderID] {
+					delete(stuck, compID)
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	// группируем зависшие вычисления по ребрам ожиданий
+	roots := make(map[identity.ADT]identity.ADT, len(stuck))
+	var find func(identity.ADT) identity.ADT
+	find = func(id identity.ADT) identity.ADT {
+		root, ok := roots[id]
+		if !ok || root == id {
+			return id
+		}
+		root = find(root)
+		roots[id] = root
+		return root
+	}
+	for compID := range stuck {
+		for _, wait := range waitsBy[compID] {
+			if wait.HolderID.IsEmpty() {
+				continue
+			}
+			a, b := find(compID), find(wait.HolderID)
+			if a != b {
+				roots[a] = b
+			}
+		}
+	}
+	groups := make(map[identity.ADT][]identity.ADT)
+	for compID := range stuck {
+		root := find(compID)
+		groups[root] = append(groups[root], compID)
+	}
+	locks := make([]LockRec, 0, len(groups))
+	for _, compIDs := range groups {
+		slices.SortFunc(compIDs, compareIDs)
+		lock := LockRec{LockID: compIDs[0], Kind: OrphanKind}
+		for _, compID := range compIDs {
+			lock.Waits = append(lock.Waits, waitsBy[compID]...)
+		}
+		if hasCycle(compIDs, waitsBy) {
+			lock.Kind = CycleKind
+		}
+		locks = append(locks, lock)
+	}
+	slices.SortFunc(locks, func(a, b LockRec) int { return compareIDs(a.LockID, b.LockID) })
+	return locks
+}
+
+// вершины без исходящих ребер снимаются, пока это возможно;
+// если что-то осталось, в группе есть цикл
+func hasCycle(compIDs []identity.ADT, waitsBy map[identity.ADT][]WaitRec) bool {
+	left := make(map[identity.ADT]bool, len(compIDs))
+	for _, compID := range compIDs {
+		left[compID] = true
+	}
+	for changed := true; changed; {
+		changed = false
+		for compID := range left {
+			waiting := slices.ContainsFunc(waitsBy[compID], func(wait WaitRec) bool {
+				return left[wait.HolderID]
+			})
+			if !waiting {
+				delete(left, compID)
+				changed = true
+			}
+		}
+	}
+	return len(left) > 0
+}
+
+func compareIDs(a, b identity.ADT) int {
+	return cmp.Compare(a.String(), b.String())
+}
+
+func (r WaitRec) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Any("comp", r.CompID),
+		slog.String("qn", uniqsym.ConvertToNullString(r.CompQN).String),
+		slog.Any("ph", r.ChnlPH),
+		slog.Any("holder", r.HolderID),
+	)
+}
+
+func (r LockRec) LogValue() slog.Value {
+	attrs := []slog.Attr{slog.Any("id", r.LockID), slog.Any("kind", r.Kind)}
+	for i, wait := range r.Waits {
+		attrs = append(attrs, slog.Any(strconv.Itoa(i), wait))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+func (k Kind) String() string {
+	switch k {
+	case CycleKind:
+		return "cycle"
+	case OrphanKind:
+		return "orphan"
+	default:
+		return fmt.Sprintf("unknown(%d)", k)
+	}
+}
+
+func ErrRealmUnexpected(got Realm) error {
+	return fmt.Errorf("realm unexpected: %v", got)
+}
@@ -0,0 +1,57 @@
+package commlock
+
+import (
+	"slices"
+	"testing"
+
+	"orglang/go-engine/adt/identity"
+)
+
+func TestFindLocks(t *testing.T) {
+	a, b, c, d := identity.New(), identity.New(), identity.New(), identity.New()
+	wait := func(comp, holder identity.ADT) WaitRec {
+		return WaitRec{CompID: comp, ChnlID: identity.New(), HolderID: holder}
+	}
+	orphan := identity.Empty()
+	var tests = []struct {
+		name  string
+		waits []WaitRec
+		want  map[Kind][]identity.ADT
+	}{
+		{"no waits", nil, map[Kind][]identity.ADT{}},
+		{"holder can move", []WaitRec{wait(a, b)}, map[Kind][]identity.ADT{}},
+		{"cycle", []WaitRec{wait(a, b), wait(b, a)}, map[Kind][]identity.ADT{CycleKind: {a, b}}},
+		{"self cycle", []WaitRec{wait(a, a)}, map[Kind][]identity.ADT{CycleKind: {a}}},
+		{"orphan", []WaitRec{wait(a, orphan)}, map[Kind][]identity.ADT{OrphanKind: {a}}},
+		{"chain to orphan", []WaitRec{wait(a, b), wait(b, orphan)}, map[Kind][]identity.ADT{OrphanKind: {a, b}}},
+		{"tail to cycle", []WaitRec{wait(c, a), wait(a, b), wait(b, a)}, map[Kind][]identity.ADT{CycleKind: {a, b, c}}},
+		{"one way out", []WaitRec{wait(a, b), wait(b, a), wait(b, d)}, map[Kind][]identity.ADT{}},
+		{
+			"separate",
+			[]WaitRec{wait(a, b), wait(b, a), wait(c, orphan)},
+			map[Kind][]identity.ADT{CycleKind: {a, b}, OrphanKind: {c}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := make(map[Kind][]identity.ADT)
+			for _, lock := range FindLocks(test.waits) {
+				for _, w := range lock.Waits {
+					if !slices.Contains(got[lock.Kind], w.CompID) {
+						got[lock.Kind] = append(got[lock.Kind], w.CompID)
+					}
+				}
+			}
+			if len(got) != len(test.want) {
+				t.Fatalf("got %v, want %v", got, test.want)
+			}
+			for kind, want := range test.want {
+				slices.SortFunc(want, compareIDs)
+				slices.SortFunc(got[kind], compareIDs)
+				if !slices.Equal(got[kind], want) {
+					t.Errorf("%v: got %v, want %v", kind, got[kind], want)
+				}
+			}
+		})
+	}
+}
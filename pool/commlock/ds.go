@@ -0,0 +1,40 @@
+package commlock
+
+import (
+	"database/sql"
+	"time"
+
+	"orglang/go-engine/lib/db"
+)
+
+type Repo interface {
+	// SelectWaits выдает ожидающие подписки вместе с владельцами
+	// другого конца канала
+	SelectWaits(db.Source, Realm) ([]WaitRec, error)
+	// SaveLocks заменяет пометки прошлого обнаружения, сохраняя время,
+	// когда ожидание было замечено впервые
+	SaveLocks(db.Source, Realm, []LockRec, time.Time) error
+	SelectLocks(db.Source, Realm) ([]LockRec, error)
+}
+
+type waitRecDS struct {
+	CompID   string         `db:"comp_id"`
+	CompQN   sql.NullString `db:"comp_qn"`
+	CommID   string         `db:"comm_id"`
+	ChnlID   string         `db:"chnl_id"`
+	ChnlPH   sql.NullString `db:"chnl_ph"`
+	HolderID sql.NullString `db:"holder_id"`
+}
+
+type lockRecDS struct {
+	LockID     string         `db:"lock_id"`
+	CompID     string         `db:"comp_id"`
+	CompQN     sql.NullString `db:"comp_qn"`
+	CommID     string         `db:"comm_id"`
+	ChnlID     string         `db:"chnl_id"`
+	ChnlPH     sql.NullString `db:"chnl_ph"`
+	HolderID   sql.NullString `db:"holder_id"`
+	Kind       int16          `db:"kind"`
+	DetectedAt time.Time      `db:"detected_at"`
+	SeenAt     time.Time      `db:"seen_at"`
+}
@@ -0,0 +1,91 @@
+package commlock
+
+import (
+	"errors"
+	"log/slog"
+	"reflect"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"orglang/go-engine/lib/db"
+	"orglang/go-engine/lib/lf"
+)
+
+type pgxDAO struct {
+	qb  queryBuilder
+	log *slog.Logger
+}
+
+func newPgxDAO(qb queryBuilder, log *slog.Logger) *pgxDAO {
+	name := slog.String("name", reflect.TypeFor[pgxDAO]().Name())
+	return &pgxDAO{qb, log.With(name)}
+}
+
+// for compilation purposes
+func newRepo() Repo {
+	return new(pgxDAO)
+}
+
+func (dao *pgxDAO) SelectWaits(source db.Source, realm Realm) ([]WaitRec, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	sql, args := dao.qb.selectWaits(realm)
+	rows, execErr := ds.Conn.Query(ds.Ctx, sql, args...)
+	if execErr != nil {
+		dao.log.Error("query execution failed", slog.Any("realm", realm), slog.String("sql", sql))
+		return nil, execErr
+	}
+	defer rows.Close()
+	dtos, scanErr := pgx.CollectRows(rows, pgx.RowToStructByName[waitRecDS])
+	if scanErr != nil {
+		dao.log.Error("rows scanning failed", slog.Any("realm", realm))
+		return nil, scanErr
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "selection succeed", slog.Any("dtos", dtos))
+	return dataToWaitRecs(dtos)
+}
+
+func (dao *pgxDAO) SaveLocks(source db.Source, realm Realm, recs []LockRec, seenAt time.Time) (err error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	realmAttr := slog.Any("realm", realm)
+	batch := pgx.Batch{}
+	for _, rec := range recs {
+		for _, dto := range dataFromLockRec(rec) {
+			sql, args := dao.qb.upsertLock(realm, dto)
+			batch.Queue(sql, args...)
+		}
+	}
+	// пометки, не подтвержденные этим обнаружением, снимаются
+	sql, args := dao.qb.deleteLocks(realm, seenAt)
+	batch.Queue(sql, args...)
+	br := ds.Conn.SendBatch(ds.Ctx, &batch)
+	defer func() {
+		err = errors.Join(err, br.Close())
+	}()
+	for range batch.Len() {
+		_, readErr := br.Exec()
+		if readErr != nil {
+			dao.log.Error("query execution failed", realmAttr)
+			return readErr
+		}
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "saving succeed", realmAttr, slog.Int("count", len(recs)))
+	return nil
+}
+
+func (dao *pgxDAO) SelectLocks(source db.Source, realm Realm) ([]LockRec, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	sql, args := dao.qb.selectLocks(realm)
+	rows, execErr := ds.Conn.Query(ds.Ctx, sql, args...)
+	if execErr != nil {
+		dao.log.Error("query execution failed", slog.Any("realm", realm), slog.String("sql", sql))
+		return nil, execErr
+	}
+	defer rows.Close()
+	dtos, scanErr := pgx.CollectRows(rows, pgx.RowToStructByName[lockRecDS])
+	if scanErr != nil {
+		dao.log.Error("rows scanning failed", slog.Any("realm", realm))
+		return nil, scanErr
+	}
+	return dataToLockRecs(dtos)
+}
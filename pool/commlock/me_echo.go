@@ -0,0 +1,49 @@
+package commlock
+
+import (
+	"log/slog"
+	"net/http"
+	"reflect"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Server-side primary adapter
+type echoController struct {
+	api API
+	log *slog.Logger
+}
+
+func newEchoController(api API, log *slog.Logger) *echoController {
+	name := slog.String("name", reflect.TypeFor[echoController]().Name())
+	return &echoController{api, log.With(name)}
+}
+
+func cfgEchoController(server *echo.Echo, controller *echoController) error {
+	server.GET("/api/v1/procs/locks", controller.GetLocks(ProcRealm))
+	server.POST("/api/v1/procs/locks", controller.PostLocks(ProcRealm))
+	server.GET("/api/v1/pools/execs/locks", controller.GetLocks(PoolRealm))
+	server.POST("/api/v1/pools/execs/locks", controller.PostLocks(PoolRealm))
+	return nil
+}
+
+func (c *echoController) GetLocks(realm Realm) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		recs, apiErr := c.api.RetrieveLocks(realm)
+		if apiErr != nil {
+			return apiErr
+		}
+		return ctx.JSON(http.StatusOK, ViewFromLocks(recs))
+	}
+}
+
+// обнаружение по запросу, не дожидаясь очередного запуска
+func (c *echoController) PostLocks(realm Realm) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		recs, apiErr := c.api.Detect(realm)
+		if apiErr != nil {
+			return apiErr
+		}
+		return ctx.JSON(http.StatusOK, ViewFromLocks(recs))
+	}
+}
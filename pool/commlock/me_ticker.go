@@ -0,0 +1,61 @@
+package commlock
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.uber.org/fx"
+
+	"orglang/go-engine/lib/db"
+)
+
+const (
+	// как часто ищутся зависания; зависание не проходит само,
+	// поэтому частый поиск не нужен
+	detectInterval = time.Minute
+)
+
+// хранилище в памяти не поддерживает запросов, поэтому зависания
+// ищутся только в SQL
+func cfgTicker(api API, dialect db.Dialect, log *slog.Logger, lc fx.Lifecycle) error {
+	if dialect == db.Memory {
+		log.Warn("deadlock detection disabled", slog.Any("dialect", dialect))
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	lc.Append(
+		fx.Hook{
+			OnStart: func(context.Context) error {
+				wg.Go(func() { detect(ctx, api, log) })
+				return nil
+			},
+			OnStop: func(context.Context) error {
+				cancel()
+				wg.Wait()
+				return nil
+			},
+		},
+	)
+	return nil
+}
+
+func detect(ctx context.Context, api API, log *slog.Logger) {
+	ticker := time.NewTicker(detectInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for _, realm := range []Realm{ProcRealm, PoolRealm} {
+			_, err := api.Detect(realm)
+			if err != nil {
+				log.Error("detection failed", slog.Any("realm", realm), slog.Any("reason", err))
+			}
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package commlock
+
+import (
+	"time"
+)
+
+const (
+	// таблицы разных миров называются одинаково с точностью до префикса
+	commTurns = "_comm_turns"
+	commExchs = "_comm_exchs"
+	commLocks = "_comm_locks"
+	compVars  = "_comp_vars"
+	implBinds = "_impl_binds"
+	// шаги ждут исполнения только у pool
+	poolSteps = "pool_comp_steps"
+)
+
+const (
+	// совпадает с commturn
+	subKind int16 = 2
+	// совпадает с compstep
+	pendingStatus int16 = 1
+)
+
+type queryBuilder interface {
+	selectWaits(Realm) (string, []any)
+	upsertLock(Realm, lockRecDS) (string, []any)
+	deleteLocks(Realm, time.Time) (string, []any)
+	selectLocks(Realm) (string, []any)
+}
@@ -0,0 +1,72 @@
+package commlock
+
+import (
+	"time"
+
+	"github.com/huandu/go-sqlbuilder"
+)
+
+// запросы переносимы, поэтому отдельного построителя для SQLite нет
+type sqlBuilder struct {
+	lockBuilder *sqlbuilder.Struct
+}
+
+// for compilation purposes
+func newQueryBuilder() queryBuilder {
+	return new(sqlBuilder)
+}
+
+func newSQLBuilder() *sqlBuilder {
+	lockBuilder := sqlbuilder.NewStruct(new(lockRecDS)).For(sqlbuilder.PostgreSQL)
+	return &sqlBuilder{lockBuilder}
+}
+
+// Подписка ждет, пока офсет обмена не дошел до ее ревизии. Владелец
+// другого конца канала ищется среди последних состояний переменных,
+// исключая исчерпанные и отданные. Вычисления pool с готовыми шагами
+// в очереди еще продвинутся, поэтому их подписки не считаются.
+func (qb *sqlBuilder) selectWaits(realm Realm) (string, []any) {
+	prefix := string(realm)
+	args := []any{subKind}
+	ready := ""
+	if realm == PoolRealm {
+		ready = " AND NOT EXISTS (SELECT 1 FROM " + poolSteps + " step " +
+			"WHERE step.comp_id = turn.comp_id AND step.status = $?)"
+		args = append(args, pendingStatus)
+	}
+	return sqlbuilder.Build(`WITH vars AS (
+	SELECT comp_id, chnl_id, chnl_ph, exp_vk,
+		ROW_NUMBER() OVER (PARTITION BY comp_id, chnl_ph ORDER BY comp_rn DESC) AS nr
+	FROM `+prefix+compVars+`
+), live AS (
+	SELECT comp_id, chnl_id, chnl_ph FROM vars WHERE nr = 1 AND exp_vk > 0
+)
+SELECT turn.comp_id, CAST(bind.impl_qn AS text) AS comp_qn, turn.comm_id, turn.chnl_id,
+	own.chnl_ph, holder.comp_id AS holder_id
+FROM `+prefix+commTurns+` turn
+JOIN `+prefix+commExchs+` exch ON exch.comm_id = turn.comm_id
+LEFT JOIN live own ON own.comp_id = turn.comp_id AND own.chnl_id = turn.chnl_id
+LEFT JOIN live holder ON holder.chnl_id = turn.chnl_id AND holder.comp_id <> turn.comp_id
+LEFT JOIN `+prefix+implBinds+` bind ON bind.impl_id = turn.comp_id
+WHERE turn.kind = $? AND turn.comm_rn > exch.offset_nr`+ready,
+		args...,
+	).BuildWithFlavor(sqlbuilder.PostgreSQL)
+}
+
+func (qb *sqlBuilder) upsertLock(realm Realm, rec lockRecDS) (string, []any) {
+	return qb.lockBuilder.InsertInto(string(realm)+commLocks, rec).
+		SQL("ON CONFLICT (comp_id, chnl_id) DO UPDATE SET " +
+			"lock_id = excluded.lock_id, kind = excluded.kind, holder_id = excluded.holder_id, " +
+			"comp_qn = excluded.comp_qn, chnl_ph = excluded.chnl_ph, seen_at = excluded.seen_at").
+		Build()
+}
+
+func (qb *sqlBuilder) deleteLocks(realm Realm, seenAt time.Time) (string, []any) {
+	del := sqlbuilder.PostgreSQL.NewDeleteBuilder()
+	return del.DeleteFrom(string(realm) + commLocks).Where(del.LessThan("seen_at", seenAt)).Build()
+}
+
+func (qb *sqlBuilder) selectLocks(realm Realm) (string, []any) {
+	sb := qb.lockBuilder.SelectFrom(string(realm) + commLocks)
+	return sb.OrderBy("lock_id", "comp_id", "chnl_ph").Build()
+}
@@ -0,0 +1,30 @@
+package commlock
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSelectWaits(t *testing.T) {
+	qb := newSQLBuilder()
+	for _, realm := range []Realm{ProcRealm, PoolRealm} {
+		sql, args := qb.selectWaits(realm)
+		fmt.Println(sql)
+		fmt.Println(args)
+	}
+}
+
+func TestUpsertLock(t *testing.T) {
+	qb := newSQLBuilder()
+	sql, args := qb.upsertLock(ProcRealm, lockRecDS{})
+	fmt.Println(sql)
+	fmt.Println(args)
+}
+
+func TestDeleteLocks(t *testing.T) {
+	qb := newSQLBuilder()
+	sql, args := qb.deleteLocks(PoolRealm, time.Now())
+	fmt.Println(sql)
+	fmt.Println(args)
+}
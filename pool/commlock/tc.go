@@ -0,0 +1,113 @@
+package commlock
+
+import (
+	"time"
+
+	"orglang/go-engine/adt/identity"
+	"orglang/go-engine/adt/symbol"
+	"orglang/go-engine/adt/uniqsym"
+)
+
+func dataToWaitRec(dto waitRecDS) (WaitRec, error) {
+	compID, err := identity.ConvertFromString(dto.CompID)
+	if err != nil {
+		return WaitRec{}, err
+	}
+	compQN, err := uniqsym.ConvertFromNullString(dto.CompQN)
+	if err != nil {
+		return WaitRec{}, err
+	}
+	commID, err := identity.ConvertFromString(dto.CommID)
+	if err != nil {
+		return WaitRec{}, err
+	}
+	chnlID, err := identity.ConvertFromString(dto.ChnlID)
+	if err != nil {
+		return WaitRec{}, err
+	}
+	chnlPH, err := symbol.ConvertFromNullString(dto.ChnlPH)
+	if err != nil {
+		return WaitRec{}, err
+	}
+	holderID, err := identity.ConvertFromNullString(dto.HolderID)
+	if err != nil {
+		return WaitRec{}, err
+	}
+	return WaitRec{
+		CompID:   compID,
+		CompQN:   compQN,
+		CommID:   commID,
+		ChnlID:   chnlID,
+		ChnlPH:   chnlPH,
+		HolderID: holderID,
+	}, nil
+}
+
+func dataToWaitRecs(dtos []waitRecDS) ([]WaitRec, error) {
+	recs := make([]WaitRec, 0, len(dtos))
+	for _, dto := range dtos {
+		rec, err := dataToWaitRec(dto)
+		if err != nil {
+			return nil, err
+		}
+		recs = append(recs, rec)
+	}
+	return recs, nil
+}
+
+// по строке на ожидание; время обнаружения у всех строк зависания общее
+func dataFromLockRec(rec LockRec) []lockRecDS {
+	dtos := make([]lockRecDS, 0, len(rec.Waits))
+	for _, wait := range rec.Waits {
+		dtos = append(dtos, lockRecDS{
+			LockID:     identity.ConvertToString(rec.LockID),
+			CompID:     identity.ConvertToString(wait.CompID),
+			CompQN:     uniqsym.ConvertToNullString(wait.CompQN),
+			CommID:     identity.ConvertToString(wait.CommID),
+			ChnlID:     identity.ConvertToString(wait.ChnlID),
+			ChnlPH:     symbol.ConvertToNullString(wait.ChnlPH),
+			HolderID:   identity.ConvertToNullString(wait.HolderID),
+			Kind:       int16(rec.Kind),
+			DetectedAt: rec.DetectedAt,
+			SeenAt:     rec.DetectedAt,
+		})
+	}
+	return dtos
+}
+
+// строки приходят упорядоченными по зависанию; зависание замечено тогда,
+// когда замечено самое раннее из его ожиданий
+func dataToLockRecs(dtos []lockRecDS) ([]LockRec, error) {
+	var recs []LockRec
+	for _, dto := range dtos {
+		lockID, err := identity.ConvertFromString(dto.LockID)
+		if err != nil {
+			return nil, err
+		}
+		wait, err := dataToWaitRec(waitRecDS{
+			CompID:   dto.CompID,
+			CompQN:   dto.CompQN,
+			CommID:   dto.CommID,
+			ChnlID:   dto.ChnlID,
+			ChnlPH:   dto.ChnlPH,
+			HolderID: dto.HolderID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(recs) == 0 || recs[len(recs)-1].LockID != lockID {
+			recs = append(recs, LockRec{LockID: lockID, Kind: Kind(dto.Kind), DetectedAt: dto.DetectedAt})
+		}
+		last := &recs[len(recs)-1]
+		last.Waits = append(last.Waits, wait)
+		last.DetectedAt = earliest(last.DetectedAt, dto.DetectedAt)
+	}
+	return recs, nil
+}
+
+func earliest(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
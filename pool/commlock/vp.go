@@ -0,0 +1,47 @@
+package commlock
+
+import (
+	"time"
+
+	"orglang/go-engine/adt/identity"
+	"orglang/go-engine/adt/symbol"
+	"orglang/go-engine/adt/uniqsym"
+)
+
+type LockVP struct {
+	LockID     string    `json:"id"`
+	Kind       string    `json:"kind"`
+	Waits      []WaitVP  `json:"waits"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+type WaitVP struct {
+	CompID   string `json:"comp_id"`
+	CompQN   string `json:"qn,omitempty"`
+	CommID   string `json:"comm_id"`
+	ChnlPH   string `json:"ph"`
+	HolderID string `json:"holder_id,omitempty"`
+}
+
+func ViewFromLocks(recs []LockRec) []LockVP {
+	views := make([]LockVP, 0, len(recs))
+	for _, rec := range recs {
+		waits := make([]WaitVP, 0, len(rec.Waits))
+		for _, wait := range rec.Waits {
+			waits = append(waits, WaitVP{
+				CompID:   identity.ConvertToString(wait.CompID),
+				CompQN:   uniqsym.ConvertToNullString(wait.CompQN).String,
+				CommID:   identity.ConvertToString(wait.CommID),
+				ChnlPH:   symbol.ConvertToString(wait.ChnlPH),
+				HolderID: identity.ConvertToNullString(wait.HolderID).String,
+			})
+		}
+		views = append(views, LockVP{
+			LockID:     identity.ConvertToString(rec.LockID),
+			Kind:       rec.Kind.String(),
+			Waits:      waits,
+			DetectedAt: rec.DetectedAt,
+		})
+	}
+	return views
+}
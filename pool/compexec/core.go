@@ -1,17 +1,27 @@
 package compexec
 
 import (
+	"cmp"
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"maps"
+	"math/rand/v2"
 	"reflect"
+	"runtime/debug"
+	"slices"
+	"time"
 
 	"orglang/go-engine/lib/db"
+	"orglang/go-engine/lib/wp"
 
 	"orglang/go-engine/adt/commsem"
 	"orglang/go-engine/adt/compsem"
 	"orglang/go-engine/adt/identity"
 	"orglang/go-engine/adt/implsem"
 	"orglang/go-engine/adt/option"
+	"orglang/go-engine/adt/polarity"
 	"orglang/go-engine/adt/seqnum"
 	"orglang/go-engine/adt/symbol"
 	"orglang/go-engine/adt/uniqsym"
@@ -21,11 +31,16 @@ import (
 	"orglang/go-engine/pool/commturn"
 	"orglang/go-engine/pool/compstep"
 	"orglang/go-engine/pool/compvar"
+	"orglang/go-engine/pool/labormkt"
 	"orglang/go-engine/pool/termdef"
 	"orglang/go-engine/pool/termexp"
 	"orglang/go-engine/pool/typeexp"
 
+	proccommexch "orglang/go-engine/proc/commexch"
 	proccompexec "orglang/go-engine/proc/compexec"
+	proccompfuel "orglang/go-engine/proc/compfuel"
+	proccompstep "orglang/go-engine/proc/compstep"
+	proctermdec "orglang/go-engine/proc/termdec"
 	proctermdef "orglang/go-engine/proc/termdef"
 )
 
@@ -33,6 +48,15 @@ type API interface {
 	Run(ExecSpec) (compsem.SemRef, error) // aka Create
 	Take(compstep.StepSpec) error
 	Spawn(compstep.StepSpec) (compsem.SemRef, error)
+	// шаги, исчерпавшие попытки исполнения
+	RetrieveDeadSteps() ([]compstep.StepRec, error)
+	// шаги, остановленные без запаса
+	RetrieveHaltedSteps() ([]compstep.StepRec, error)
+	// Refuel пополняет запас пула и возобновляет его шаги
+	Refuel(proccompfuel.FuelSpec) (proccompfuel.FuelRec, error)
+	// Replay повторяет журнал пула над хранилищем в памяти
+	// и сверяет результаты с тем, что записали шаги
+	Replay(compsem.SemRef) (proccompexec.ReplayRep, error)
 }
 
 type ExecSpec struct {
@@ -58,6 +82,17 @@ func (mod ExecMod) isEmpty() bool { return len(mod.Vars) == 0 }
 
 type ExecEff struct {
 	Steps []compstep.StepSpec
+	// процессы, порождаемые пулом
+	Spawns []SpawnEff
+	// вычисления, чьи переменные шаг меняет без их участия;
+	// их ревизии сверяются при записи
+	Peers []compsem.SemRef
+}
+
+// порождение процесса по декларации с каналами пула
+type SpawnEff struct {
+	ProcTermQN uniqsym.ADT
+	AssetVars  []compvar.LinearRec
 }
 
 type ExecSnap1 struct {
@@ -80,17 +115,44 @@ type ExecSnap3 struct {
 	LinearExps map[symbol.ADT]typeexp.ExpRec
 }
 
+// Запись журнала: шаг и все, что он прочитал вне таблиц типов,
+// в том виде, в каком прочитал, и переменные, которые он записал.
+//
+// Переменные пулов не хранят ревизию записавшего шага,
+// поэтому конфигурация и записанное журналируются вместе с шагом.
+type StepRec struct {
+	// ревизия, на которой взят шаг
+	CompRef    compsem.SemRef
+	PoolExp    termexp.ExpSpec
+	StructVars []compvar.StructRec
+	LinearVars []compvar.LinearRec
+	ExchSnaps  []commexch.ExchSnap
+	// снепшоты ожидавших на обменах отправителей
+	ExecSnaps []ExecSnap2
+	// исходы подбора на рынке труда
+	Counters []option.ADT[labormkt.PostRec]
+	Vars     []compvar.VarRec
+}
+
 type service struct {
 	compExecRepo   Repo
 	compExecBroker Broker
+	compStepRepo   compstep.Repo
 	compVarRepo    compvar.Repo
 	commExchRepo   commexch.Repo
 	commTurnRepo   commturn.Repo
 	typeExpRepo    typeexp.Repo
 	procExecRepo   proccompexec.Repo
+	procExecAPI    proccompexec.API
+	procExchRepo   proccommexch.Repo
+	procDecRepo    proctermdec.Repo
+	procDefRepo    proctermdef.Repo
 	termDefRepo    termdef.Repo
 	implSemRepo    implsem.Repo
 	compSemRepo    compsem.Repo
+	laborMatcher   labormkt.Matcher
+	fuelMeter      proccompfuel.Meter
+	panics         wp.Policy
 	operator       db.Operator
 	log            *slog.Logger
 }
@@ -103,23 +165,32 @@ func newAPI() API {
 func newService(
 	compExecRepo Repo,
 	compExecExch Broker,
+	compStepRepo compstep.Repo,
 	compVarRepo compvar.Repo,
 	commExchRepo commexch.Repo,
 	commTurnRepo commturn.Repo,
 	typeExpRepo typeexp.Repo,
 	procExecRepo proccompexec.Repo,
+	procExecAPI proccompexec.API,
+	procExchRepo proccommexch.Repo,
+	procDecRepo proctermdec.Repo,
+	procDefRepo proctermdef.Repo,
 	termDefRepo termdef.Repo,
 	implSemRepo implsem.Repo,
 	compSemRepo compsem.Repo,
+	laborMatcher labormkt.Matcher,
+	fuelMeter proccompfuel.Meter,
+	panics wp.Policy,
 	operator db.Operator,
 	log *slog.Logger,
 ) *service {
 	name := slog.String("name", reflect.TypeFor[service]().Name())
 	return &service{
-		compExecRepo, compExecExch, compVarRepo,
-		commExchRepo, commTurnRepo, typeExpRepo, procExecRepo, termDefRepo,
-		implSemRepo, compSemRepo,
-		operator, log.With(name),
+		compExecRepo, compExecExch, compStepRepo, compVarRepo,
+		commExchRepo, commTurnRepo, typeExpRepo,
+		procExecRepo, procExecAPI, procExchRepo, procDecRepo, procDefRepo, termDefRepo,
+		implSemRepo, compSemRepo, laborMatcher, fuelMeter,
+		panics, operator, log.With(name),
 	}
 }
 
@@ -210,66 +281,662 @@ func (s *service) Run(spec ExecSpec) (_ compsem.SemRef, err error) {
 	return newExec.CompRef, nil
 }
 
+// Порождает линейный процесс по декларации (aka Spawn).
+//
+// Пул передает порожденному каналы, по которым владеет потребляемыми
+// процессами, и лишается их, а тело порожденного ставится в очередь
+// в той же транзакции.
 func (s *service) Spawn(spec compstep.StepSpec) (_ compsem.SemRef, err error) {
 	ctx := context.Background()
 	refAttr := slog.Any("ref", spec.CompRef)
 	s.log.Debug("proc spawning started", refAttr, slog.Any("exp", spec.PoolExp))
-	newExec := proccompexec.ExecRec{CompRef: compsem.New(), LiabMode: compvar.LinearMode}
+	termExp, ok := spec.PoolExp.(termexp.SpawnSpec2)
+	if !ok {
+		s.log.Error("proc spawning failed", refAttr)
+		return compsem.SemRef{}, termexp.ErrSpecTypeUnexpected(spec.PoolExp)
+	}
+	var procRef compsem.SemRef
+	var bodySteps []compstep.StepSpec
 	transactErr := s.operator.Explicit(ctx, func(ds db.Source) error {
-		return s.procExecRepo.AddRec(ds, newExec)
-	})
+		// порождение - шаг пула
+		_, err = s.fuelMeter.Burn(ds, proccompfuel.PoolRealm, spec.CompRef.CompID)
+		if err != nil {
+			return err
+		}
+		execSnap, err := s.retrieveSnap(ds, spec.CompRef)
+		if err != nil {
+			return err
+		}
+		termDecs, err := s.procDecRepo.GetRecs(ds, []identity.ADT{termExp.ProcTermRef.TermID})
+		if err != nil {
+			return err
+		}
+		if len(termDecs) == 0 {
+			return proctermdec.ErrRootMissingInEnv(termExp.ProcTermRef.TermID)
+		}
+		assetVars, err := s.selectAssetVars(ds, execSnap, termExp.ProcCompRefs)
+		if err != nil {
+			return err
+		}
+		err = s.compVarRepo.AddRecs(ds, consumeVars(assetVars))
+		if err != nil {
+			return err
+		}
+		err = s.compSemRepo.TouchRef(ds, execSnap.CompRef)
+		if err != nil {
+			return err
+		}
+		procRef, bodySteps, err = s.spawnWith(ds, execSnap.CompRef, termDecs[0], assetVars)
+		if err != nil {
+			return err
+		}
+		return s.compExecBroker.StoreSpecs(ds, bodySteps)
+	}, db.WithIsolation(db.RepeatableRead))
 	if transactErr != nil {
 		s.log.Error("proc spawning failed", refAttr)
 		return compsem.SemRef{}, transactErr
 	}
-	s.log.Debug("proc spawning succeed", refAttr, slog.Any("proc", newExec.CompRef))
-	return newExec.CompRef, nil
+	for _, step := range bodySteps {
+		sendErr := s.compExecBroker.SendSpec(step)
+		if sendErr != nil {
+			s.log.Error("proc spawning failed", refAttr, slog.Any("proc", procRef))
+			return compsem.SemRef{}, sendErr
+		}
+	}
+	s.log.Debug("proc spawning succeed", refAttr, slog.Any("proc", procRef))
+	return procRef, nil
 }
 
-func (s *service) Take(spec compstep.StepSpec) (err error) {
-	ctx := context.Background()
+// Создает порожденный процесс и вяжет в него каналы пула;
+// пул лишается этих каналов отдельно, в своих переменных.
+func (s *service) spawnWith(
+	ds db.Source,
+	poolRef compsem.SemRef,
+	termDec proctermdec.DecRec,
+	assetVars []compvar.LinearRec,
+) (
+	procRef compsem.SemRef,
+	bodySteps []compstep.StepSpec,
+	err error,
+) {
+	termAttr := slog.Any("termQN", termDec.TermQN)
+	if len(assetVars) != len(termDec.AssetVars) {
+		s.log.Error("proc spawning failed", termAttr)
+		return procRef, nil, errAssetCountMismatch(len(termDec.AssetVars), len(assetVars))
+	}
+	newExec := proccompexec.ExecRec{CompRef: compsem.New(), LiabMode: compvar.LinearMode}
+	newExch := proccommexch.ExchRec{CommRef: commsem.New(), OffsetNr: seqnum.Zero}
+	execMod := proccompexec.ExecMod{
+		CompRefs: []compsem.SemRef{newExec.CompRef},
+		NewExecs: []proccompexec.ExecRec{newExec},
+		NewExchs: []proccommexch.ExchRec{newExch},
+	}
+	// вяжем обязательство порожденного
+	execMod.LinearVars = append(execMod.LinearVars, compvar.LinearRec{
+		CompRef: newExec.CompRef,
+		CommRef: newExch.CommRef,
+		ChnlID:  newExch.CommRef.CommID,
+		ChnlPH:  termDec.LiabVar.ChnlPH,
+		ChnlBS:  compvar.LiabSide,
+		ExpVK:   termDec.LiabVar.ExpVK,
+	})
+	for i, assetVar := range assetVars {
+		// вяжем канал пула как значение порожденного
+		execMod.LinearVars = append(execMod.LinearVars, compvar.LinearRec{
+			CompRef: newExec.CompRef,
+			CommRef: assetVar.CommRef,
+			ChnlID:  assetVar.ChnlID,
+			ChnlPH:  termDec.AssetVars[i].ChnlPH,
+			ChnlBS:  compvar.AssetSide,
+			ExpVK:   assetVar.ExpVK,
+		})
+	}
+	err = s.procExchRepo.AddRec(ds, newExch)
+	if err != nil {
+		return procRef, nil, err
+	}
+	err = s.procExecRepo.ModifyRec(ds, execMod)
+	if err != nil {
+		return procRef, nil, err
+	}
+	// порожденный процесс тратит и общий запас пула
+	err = s.fuelMeter.Grant(ds, newExec.CompRef.CompID, poolRef.CompID)
+	if err != nil {
+		return procRef, nil, err
+	}
+	termDef, err := s.procDefRepo.GetRecByQN(ds, termDec.TermQN)
+	if errors.Is(err, db.ErrNotFound) {
+		// процесс без определения ждет шагов извне
+		return newExec.CompRef, nil, nil
+	}
+	if err != nil {
+		return procRef, nil, err
+	}
+	// шедулим тело порожденного
+	bodySteps = append(bodySteps, compstep.StepSpec{
+		CompRef: newExec.CompRef,
+		ProcExp: termDef.ProcES,
+	})
+	s.log.Debug("proc spawned", termAttr, slog.Any("proc", newExec.CompRef))
+	return newExec.CompRef, bodySteps, nil
+}
+
+// Пул владеет процессом, если держит клиентскую сторону
+// канала его обязательства.
+func (s *service) selectAssetVars(
+	ds db.Source,
+	execSnap ExecSnap3,
+	compRefs []compsem.SemRef,
+) ([]compvar.LinearRec, error) {
+	assetVars := make([]compvar.LinearRec, 0, len(compRefs))
+	for _, compRef := range compRefs {
+		liabVar, err := s.selectLiabVar(ds, compRef)
+		if err != nil {
+			return nil, err
+		}
+		assetVar, ok := findChnl(execSnap.LinearVars, liabVar.ChnlID)
+		if !ok || assetVar.ChnlBS != compvar.AssetSide {
+			s.log.Error("proc spawning failed", slog.Any("compRef", compRef))
+			return nil, errProcNotOwned(execSnap.CompRef, compRef)
+		}
+		assetVars = append(assetVars, assetVar)
+	}
+	return assetVars, nil
+}
+
+func (s *service) selectLiabVar(ds db.Source, ref compsem.SemRef) (compvar.LinearRec, error) {
+	execSnap, err := s.procExecRepo.GetSnapByRef(ds, ref)
+	if err != nil {
+		return compvar.LinearRec{}, err
+	}
+	for _, linearVar := range execSnap.LinearVars {
+		if linearVar.ChnlBS == compvar.LiabSide {
+			return linearVar, nil
+		}
+	}
+	return compvar.LinearRec{}, errMissingLiab(ref)
+}
+
+func findChnl(vars map[symbol.ADT]compvar.LinearRec, chnlID identity.ADT) (compvar.LinearRec, bool) {
+	for _, linearVar := range vars {
+		if linearVar.ChnlID == chnlID {
+			return linearVar, true
+		}
+	}
+	return compvar.LinearRec{}, false
+}
+
+// лишаем пул переданных каналов
+func consumeVars(linearVars []compvar.LinearRec) []compvar.VarRec {
+	consumed := make([]compvar.VarRec, 0, len(linearVars))
+	for _, linearVar := range linearVars {
+		linearVar.ExpVK = linearVar.ExpVK.Invert()
+		consumed = append(consumed, linearVar)
+	}
+	return consumed
+}
+
+const (
+	// сколько раз шаг берется заново при конкурентном изменении
+	stepTakingAttempts = 5
+	stepTakingBackoff  = 10 * time.Millisecond
+)
+
+func (s *service) Take(spec compstep.StepSpec) error {
+	if spec.ProcExp != nil {
+		return s.takeBody(spec)
+	}
 	refAttr := slog.Any("ref", spec.CompRef)
 	s.log.Debug("step taking started", refAttr, slog.Any("exp", spec.PoolExp))
-	execSnap, retErr := s.retrieveSnap(spec.CompRef)
-	if retErr != nil {
-		s.log.Error("step taking failed", refAttr)
-		return retErr
+	steps, takeErr := s.takeRetrying(spec)
+	if takeErr != nil {
+		return takeErr
+	}
+	for _, step := range steps {
+		sendErr := s.compExecBroker.SendSpec(step)
+		if sendErr != nil {
+			s.log.Error("step taking failed", refAttr)
+			return sendErr
+		}
 	}
-	execMod, execEff, exchMod, takeErr := s.take(execSnap, spec.PoolExp)
+	return nil
+}
+
+// Тело порожденного процесса берет исполнитель процессов в своих
+// транзакциях, поэтому запись очереди удаляется после передачи:
+// отказ оставит ее для повторной доставки.
+func (s *service) takeBody(spec compstep.StepSpec) error {
+	refAttr := slog.Any("proc", spec.CompRef)
+	s.log.Debug("body taking started", refAttr)
+	takeErr := s.procExecAPI.Take(proccompstep.StepSpec{CompRef: spec.CompRef, ProcExp: spec.ProcExp})
 	if takeErr != nil {
-		s.log.Error("step taking failed", refAttr)
+		s.log.Error("body taking failed", refAttr)
 		return takeErr
 	}
-	transactErr := s.operator.Explicit(ctx, func(ds db.Source) error {
-		err = s.commTurnRepo.AddRecs(ds, exchMod.Turns)
+	if spec.StepID.IsEmpty() {
+		return nil
+	}
+	return s.operator.Explicit(context.Background(), func(ds db.Source) error {
+		return s.compStepRepo.RemoveRec(ds, spec.StepID)
+	})
+}
+
+// при конкурентном изменении чтение, проверка и взятие шага
+// повторяются на свежем снепшоте
+func (s *service) takeRetrying(spec compstep.StepSpec) (steps []compstep.StepSpec, err error) {
+	for attempt := 1; ; attempt++ {
+		steps, err = s.takeStep(spec)
+		if !errors.Is(err, db.ErrConcurrentModification) || attempt == stepTakingAttempts {
+			return steps, err
+		}
+		s.log.Warn("step taking conflicted", slog.Any("ref", spec.CompRef), slog.Int("attempt", attempt))
+		time.Sleep(rand.N(time.Duration(attempt) * stepTakingBackoff))
+	}
+}
+
+// чтения, проверка и запись шага видят одно состояние базы,
+// поэтому конкурентные шаги не дают фантомных чтений
+func (s *service) takeStep(spec compstep.StepSpec) (steps []compstep.StepSpec, err error) {
+	ctx := context.Background()
+	err = s.operator.Explicit(ctx, func(ds db.Source) error {
+		steps, err = s.takeStepWith(ds, spec)
+		return err
+	}, db.WithIsolation(db.RepeatableRead))
+	if err != nil {
+		s.log.Error("step taking failed", slog.Any("ref", spec.CompRef))
+		return nil, err
+	}
+	return steps, nil
+}
+
+func (s *service) takeStepWith(ds db.Source, spec compstep.StepSpec) ([]compstep.StepSpec, error) {
+	// шаг из очереди удаляется в той же транзакции, поэтому повторная
+	// доставка после истечения аренды откатится, не повторив ходов
+	if !spec.StepID.IsEmpty() {
+		err := s.compStepRepo.RemoveRec(ds, spec.StepID)
 		if err != nil {
-			return err
+			return nil, err
+		}
+	}
+	execSnap, err := s.retrieveSnap(ds, spec.CompRef)
+	if err != nil {
+		return nil, err
+	}
+	// при отказе транзакция откатит и списание
+	_, err = s.fuelMeter.Burn(ds, proccompfuel.PoolRealm, execSnap.CompRef.CompID)
+	if err != nil {
+		return nil, err
+	}
+	exchs := &exchRecorder{Repo: s.commExchRepo}
+	execs := &execRecorder{Repo: s.compExecRepo}
+	matches := &matchRecorder{Matcher: s.laborMatcher}
+	taker := *s
+	taker.commExchRepo = exchs
+	taker.compExecRepo = execs
+	taker.laborMatcher = matches
+	execMod, execEff, exchMod, err := taker.takeSafely(ds, execSnap, spec.PoolExp)
+	if err != nil {
+		return nil, err
+	}
+	// ревизия обмена сверяется со снепшотом, по которому сделан шаг
+	err = s.commExchRepo.ModifyRec(ds, exchMod)
+	if err != nil {
+		return nil, err
+	}
+	err = s.commTurnRepo.AddRecs(ds, exchMod.Turns)
+	if err != nil {
+		return nil, err
+	}
+	err = s.compVarRepo.AddRecs(ds, execMod.Vars)
+	if err != nil {
+		return nil, err
+	}
+	err = s.compSemRepo.TouchRef(ds, execSnap.CompRef)
+	if err != nil {
+		return nil, err
+	}
+	for _, peerRef := range execEff.Peers {
+		err = s.compSemRepo.TouchRef(ds, peerRef)
+		if err != nil {
+			return nil, err
 		}
-		err = s.commExchRepo.ModifyRec(ds, exchMod)
+	}
+	steps := execEff.Steps
+	for _, spawn := range execEff.Spawns {
+		termDecs, err := s.procDecRepo.SelectEnv(ds, []uniqsym.ADT{spawn.ProcTermQN})
 		if err != nil {
-			return err
+			return nil, err
+		}
+		termDec, ok := termDecs[spawn.ProcTermQN]
+		if !ok {
+			return nil, proctermdec.ErrSymMissingInEnv(spawn.ProcTermQN)
 		}
-		err = s.compVarRepo.AddRecs(ds, execMod.Vars)
+		_, bodySteps, err := s.spawnWith(ds, execSnap.CompRef, termDec, spawn.AssetVars)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, bodySteps...)
+	}
+	// продолжения фиксируются вместе с ходами, чтобы не потеряться при падении
+	err = s.compExecBroker.StoreSpecs(ds, steps)
+	if err != nil {
+		return nil, err
+	}
+	err = s.compExecRepo.AddStep(ds, StepRec{
+		CompRef:    execSnap.CompRef,
+		PoolExp:    spec.PoolExp,
+		StructVars: slices.Collect(maps.Values(execSnap.StructVars)),
+		LinearVars: slices.Collect(maps.Values(execSnap.LinearVars)),
+		ExchSnaps:  exchs.snaps,
+		ExecSnaps:  execs.snaps,
+		Counters:   matches.counters,
+		Vars:       execMod.Vars,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return steps, nil
+}
+
+// exchRecorder запоминает обмены, какими их увидел шаг
+type exchRecorder struct {
+	commexch.Repo
+	snaps []commexch.ExchSnap
+}
+
+func (r *exchRecorder) GetSnapByQry(ds db.Source, qry commexch.ExchQry) (commexch.ExchSnap, error) {
+	snap, err := r.Repo.GetSnapByQry(ds, qry)
+	if err != nil {
+		return commexch.ExchSnap{}, err
+	}
+	r.snaps = append(r.snaps, snap)
+	return snap, nil
+}
+
+// execRecorder запоминает снепшоты отправителей
+type execRecorder struct {
+	Repo
+	snaps []ExecSnap2
+}
+
+func (r *execRecorder) GetSnapByRef(ds db.Source, ref compsem.SemRef) (ExecSnap2, error) {
+	snap, err := r.Repo.GetSnapByRef(ds, ref)
+	if err != nil {
+		return ExecSnap2{}, err
+	}
+	r.snaps = append(r.snaps, snap)
+	return snap, nil
+}
+
+func (r *execRecorder) GetSnapByChnl(ds db.Source, chnlID identity.ADT, self compsem.SemRef) (ExecSnap2, error) {
+	snap, err := r.Repo.GetSnapByChnl(ds, chnlID, self)
+	if err != nil {
+		return ExecSnap2{}, err
+	}
+	r.snaps = append(r.snaps, snap)
+	return snap, nil
+}
+
+// matchRecorder запоминает исходы подбора
+type matchRecorder struct {
+	labormkt.Matcher
+	counters []option.ADT[labormkt.PostRec]
+}
+
+func (r *matchRecorder) Match(ds db.Source, post labormkt.PostRec) (option.ADT[labormkt.PostRec], error) {
+	counter, err := r.Matcher.Match(ds, post)
+	if err != nil {
+		return nil, err
+	}
+	r.counters = append(r.counters, counter)
+	return counter, nil
+}
+
+// exchReplayer отдает обмены из журнала в том порядке,
+// в каком их запрашивал исходный шаг
+type exchReplayer struct {
+	commexch.Repo
+	snaps []commexch.ExchSnap
+}
+
+func (r *exchReplayer) GetSnapByQry(_ db.Source, qry commexch.ExchQry) (commexch.ExchSnap, error) {
+	if len(r.snaps) == 0 || r.snaps[0].CommRef.CommID != qry.CommRef.CommID {
+		return commexch.ExchSnap{}, proccompexec.ErrJournalMismatch(qry.CommRef)
+	}
+	snap := r.snaps[0]
+	r.snaps = r.snaps[1:]
+	return snap, nil
+}
+
+type execReplayer struct {
+	Repo
+	snaps []ExecSnap2
+}
+
+func (r *execReplayer) GetSnapByRef(_ db.Source, ref compsem.SemRef) (ExecSnap2, error) {
+	if len(r.snaps) == 0 || r.snaps[0].CompRef.CompID != ref.CompID {
+		return ExecSnap2{}, errExecMissingInJournal(ref)
+	}
+	snap := r.snaps[0]
+	r.snaps = r.snaps[1:]
+	return snap, nil
+}
+
+func (r *execReplayer) GetSnapByChnl(_ db.Source, chnlID identity.ADT, self compsem.SemRef) (ExecSnap2, error) {
+	if len(r.snaps) == 0 || r.snaps[0].CompRef.CompID == self.CompID {
+		return ExecSnap2{}, errPeerMissingInJournal(chnlID)
+	}
+	_, ok := findChnl(compvar.ConvertRecsToRecMap(r.snaps[0].LinearVars), chnlID)
+	if !ok {
+		return ExecSnap2{}, errPeerMissingInJournal(chnlID)
+	}
+	snap := r.snaps[0]
+	r.snaps = r.snaps[1:]
+	return snap, nil
+}
+
+// matchReplayer отдает исходы подбора из журнала, а рынок не меняет
+type matchReplayer struct {
+	labormkt.Matcher
+	counters []option.ADT[labormkt.PostRec]
+}
+
+func (r *matchReplayer) Match(_ db.Source, post labormkt.PostRec) (option.ADT[labormkt.PostRec], error) {
+	if len(r.counters) == 0 {
+		return nil, errMatchMissingInJournal(post.CommChnl.CommRef)
+	}
+	counter := r.counters[0]
+	r.counters = r.counters[1:]
+	return counter, nil
+}
+
+func (r *matchReplayer) Dismiss(db.Source, commsem.SemRef) error {
+	return nil
+}
+
+func (s *service) Replay(ref compsem.SemRef) (_ proccompexec.ReplayRep, err error) {
+	ctx := context.Background()
+	refAttr := slog.Any("ref", ref)
+	s.log.Debug("replay started", refAttr)
+	rep := proccompexec.ReplayRep{CompRef: ref}
+	err = s.operator.Explicit(ctx, func(ds db.Source) error {
+		steps, err := s.compExecRepo.GetSteps(ds, ref)
 		if err != nil {
 			return err
 		}
-		return s.compSemRepo.TouchRef(ds, execSnap.CompRef)
+		for _, step := range steps {
+			div, err := s.replayStep(ds, step)
+			if err != nil {
+				return err
+			}
+			if div != nil {
+				rep.Divergence = div
+				return nil
+			}
+			rep.Replayed++
+		}
+		return nil
+	}, db.WithIsolation(db.RepeatableRead), db.WithReadOnly())
+	if err != nil {
+		s.log.Error("replay failed", refAttr)
+		return proccompexec.ReplayRep{}, err
+	}
+	if rep.Divergence != nil {
+		s.log.Warn("replay diverged", refAttr, slog.Any("div", rep.Divergence))
+		return rep, nil
+	}
+	s.log.Debug("replay succeed", refAttr, slog.Int("steps", rep.Replayed))
+	return rep, nil
+}
+
+// Шаг повторяется на конфигурации из журнала с обменами, снепшотами
+// отправителей и исходами подбора, какими их увидел исходный шаг,
+// и сверяется с переменными, которые он записал.
+//
+// Из рабочего хранилища читаются только неизменные типы, а сам шаг
+// идет над пустым хранилищем в памяти, поэтому не дойдет до рабочего.
+func (s *service) replayStep(ds db.Source, step StepRec) (*proccompexec.StepDiv, error) {
+	structExps, err := s.typeExpRepo.GetRecMap(ds, ExtractExpVKs(step.StructVars))
+	if err != nil {
+		return nil, err
+	}
+	linearExps, err := s.typeExpRepo.GetRecMap(ds, ExtractExpVKs(step.LinearVars))
+	if err != nil {
+		return nil, err
+	}
+	execSnap := ExecSnap3{
+		CompRef:    step.CompRef,
+		StructVars: compvar.ConvertRecsToRecMap(step.StructVars),
+		StructExps: structExps,
+		LinearVars: compvar.ConvertRecsToRecMap(step.LinearVars),
+		LinearExps: linearExps,
+	}
+	replayer := *s
+	replayer.commExchRepo = &exchReplayer{Repo: s.commExchRepo, snaps: step.ExchSnaps}
+	replayer.compExecRepo = &execReplayer{Repo: s.compExecRepo, snaps: step.ExecSnaps}
+	replayer.laborMatcher = &matchReplayer{Matcher: s.laborMatcher, counters: step.Counters}
+	mem := db.NewOperatorMem()
+	replayer.operator = mem
+	var div *proccompexec.StepDiv
+	err = mem.Explicit(context.Background(), func(ms db.Source) error {
+		execMod, _, _, err := replayer.takeSafely(ms, execSnap, step.PoolExp)
+		if err != nil {
+			div = &proccompexec.StepDiv{StepRef: step.CompRef, Reason: err.Error()}
+			return nil
+		}
+		div = diffVars(step.CompRef, step.Vars, execMod.Vars)
+		return nil
 	})
-	if transactErr != nil {
-		s.log.Error("step taking failed", refAttr)
-		return transactErr
+	if err != nil {
+		return nil, err
 	}
-	for _, step := range execEff.Steps {
-		sendErr := s.compExecBroker.SendSpec(step)
-		if sendErr != nil {
-			s.log.Error("step taking failed", refAttr)
-			return sendErr
+	return div, nil
+}
+
+// Новые каналы получают новые идентификаторы, поэтому сверяются
+// заглушки, стороны и типы переменных.
+func diffVars(stepRef compsem.SemRef, want, got []compvar.VarRec) *proccompexec.StepDiv {
+	wantVars := indexVars(want)
+	gotVars := indexVars(got)
+	for key, gotVar := range gotVars {
+		_, ok := wantVars[key]
+		if !ok {
+			return &proccompexec.StepDiv{StepRef: stepRef, ChnlPH: gotVar.GetChnlPH(), GotVK: gotVar.GetExpVK(), Reason: "var missing in journal"}
+		}
+	}
+	keys := slices.SortedFunc(maps.Keys(wantVars), func(a, b varKey) int {
+		return cmp.Or(cmp.Compare(a.compID, b.compID), cmp.Compare(a.chnlPH, b.chnlPH))
+	})
+	for _, key := range keys {
+		wantVar := wantVars[key]
+		gotVar, ok := gotVars[key]
+		if !ok {
+			return &proccompexec.StepDiv{StepRef: stepRef, ChnlPH: wantVar.GetChnlPH(), WantVK: wantVar.GetExpVK(), Reason: "var missing in replay"}
+		}
+		if !isSameVar(wantVar, gotVar) {
+			return &proccompexec.StepDiv{StepRef: stepRef, ChnlPH: wantVar.GetChnlPH(), WantVK: wantVar.GetExpVK(), GotVK: gotVar.GetExpVK(), Reason: "var mismatch"}
 		}
 	}
 	return nil
 }
 
+func (s *service) RetrieveDeadSteps() ([]compstep.StepRec, error) {
+	return s.retrieveSteps(compstep.DeadStatus)
+}
+
+func (s *service) RetrieveHaltedSteps() ([]compstep.StepRec, error) {
+	return s.retrieveSteps(compstep.HaltedStatus)
+}
+
+func (s *service) retrieveSteps(status compstep.Status) (_ []compstep.StepRec, err error) {
+	ctx := context.Background()
+	var recs []compstep.StepRec
+	selectErr := s.operator.Implicit(ctx, func(ds db.Source) error {
+		recs, err = s.compStepRepo.SelectRecsByStatus(ds, status)
+		return err
+	})
+	if selectErr != nil {
+		s.log.Error("retrieval failed", slog.Any("status", status))
+		return nil, selectErr
+	}
+	return recs, nil
+}
+
+// Пополняет запас пула (aka Refuel).
+//
+// Шаги процессов пула берутся сразу, а остановленные шаги самого пула
+// возвращаются в очередь.
+func (s *service) Refuel(spec proccompfuel.FuelSpec) (_ proccompfuel.FuelRec, err error) {
+	ctx := context.Background()
+	idAttr := slog.Any("id", spec.CompID)
+	rec, err := s.procExecAPI.Refuel(spec)
+	if err != nil {
+		return proccompfuel.FuelRec{}, err
+	}
+	err = s.operator.Explicit(ctx, func(ds db.Source) error {
+		return s.compStepRepo.ResumeRecs(ds, spec.CompID)
+	})
+	if err != nil {
+		s.log.Error("refueling failed", idAttr)
+		return proccompfuel.FuelRec{}, err
+	}
+	// шаги уже в очереди, достаточно разбудить диспетчер
+	err = s.compExecBroker.SendSpec(compstep.StepSpec{CompRef: compsem.SemRef{CompID: spec.CompID}})
+	if err != nil {
+		s.log.Error("refueling failed", idAttr)
+		return proccompfuel.FuelRec{}, err
+	}
+	s.log.Debug("refueling succeed", idAttr, slog.Any("rec", rec))
+	return rec, nil
+}
+
+// паника при вычислении шага становится его отказом, а не падением сервера
+func (s *service) takeSafely(
+	ds db.Source,
+	execSnap ExecSnap3,
+	exp termexp.ExpSpec,
+) (
+	execMod ExecMod,
+	execEff ExecEff,
+	exchMod commexch.ExchMod,
+	err error,
+) {
+	// при политике crash паника доходит до исполнителя и роняет процесс
+	if !s.panics.Recovers() {
+		return s.take(ds, execSnap, exp)
+	}
+	defer func() {
+		r := recover()
+		if r != nil {
+			s.log.Error("step taking panicked", slog.Any("reason", fmt.Sprint(r)), slog.String("stack", string(debug.Stack())))
+			err = proccompexec.ErrStepPanicked(r)
+		}
+	}()
+	return s.take(ds, execSnap, exp)
+}
+
 func (s *service) take(
+	ds db.Source,
 	execSnap ExecSnap3,
 	exp termexp.ExpSpec,
 ) (
@@ -278,7 +945,6 @@ func (s *service) take(
 	exchMod commexch.ExchMod,
 	err error,
 ) {
-	ctx := context.Background()
 	compAttr := slog.Any("compRef", execSnap.CompRef)
 	switch termExp := exp.(type) {
 	case termexp.AcceptSpec:
@@ -295,13 +961,9 @@ func (s *service) take(
 		}
 		nextExpVK := typeExp.(typeexp.ProdRec).Next()
 		// получаем снепшот коммуникации
-		var commSnap commexch.ExchSnap
-		getErr := s.operator.Implicit(ctx, func(ds db.Source) error {
-			commSnap, err = s.commExchRepo.GetSnapByQry(ds, commexch.ExchQry{
-				CommRef: commChnl.CommRef,
-				ChnlID:  option.Some(commChnl.ChnlID),
-			})
-			return err
+		commSnap, getErr := s.commExchRepo.GetSnapByQry(ds, commexch.ExchQry{
+			CommRef: commChnl.CommRef,
+			ChnlID:  option.Some(commChnl.ChnlID),
 		})
 		if getErr != nil {
 			s.log.Error("step taking failed", compAttr)
@@ -384,13 +1046,9 @@ func (s *service) take(
 		}
 		nextExpVK := typeExp.(typeexp.ProdRec).Next()
 		// получаем снепшот коммуникации
-		var commSnap commexch.ExchSnap
-		getErr := s.operator.Implicit(ctx, func(ds db.Source) error {
-			commSnap, err = s.commExchRepo.GetSnapByQry(ds, commexch.ExchQry{
-				CommRef: commChnl.CommRef,
-				ChnlID:  option.Some(commChnl.ChnlID),
-			})
-			return err
+		commSnap, getErr := s.commExchRepo.GetSnapByQry(ds, commexch.ExchQry{
+			CommRef: commChnl.CommRef,
+			ChnlID:  option.Some(commChnl.ChnlID),
 		})
 		if getErr != nil {
 			s.log.Error("step taking failed", compAttr)
@@ -473,13 +1131,9 @@ func (s *service) take(
 		}
 		nextExpVK := typeExp.(typeexp.ProdRec).Next()
 		// получаем снепшот коммуникации
-		var commSnap commexch.ExchSnap
-		getErr := s.operator.Implicit(ctx, func(ds db.Source) error {
-			commSnap, err = s.commExchRepo.GetSnapByQry(ds, commexch.ExchQry{
-				CommRef: commChnl.CommRef,
-				ChnlID:  option.Some(commChnl.ChnlID),
-			})
-			return err
+		commSnap, getErr := s.commExchRepo.GetSnapByQry(ds, commexch.ExchQry{
+			CommRef: commChnl.CommRef,
+			ChnlID:  option.Some(commChnl.ChnlID),
 		})
 		if getErr != nil {
 			s.log.Error("step taking failed", compAttr)
@@ -489,18 +1143,25 @@ func (s *service) take(
 		commAttr := slog.Any("commRef", commSnap.CommRef)
 		subscription := commSnap.NextTurn()
 		if subscription == nil {
-			// регистрируем подписку соискателя
-			exchMod.Turns = append(exchMod.Turns, commturn.SubRec{
-				CommRef: commSnap.CommRef,
-				CompRef: execSnap.CompRef,
-				ChnlID:  commChnl.ChnlID,
-				ContExp: termexp.ApplyRec{
-					ContChnlPH: commChnl.ChnlPH,
-					ProcTermQN: termExp.ProcTermQN,
-					ContExp:    termExp.ContExp,
-				},
-			})
-			s.log.Debug("taking half done", compAttr, commAttr)
+			// ищем встречное размещение на рынке труда
+			post := labormkt.PostRec{
+				Side:       labormkt.ApplicationSide,
+				CommChnl:   commChnl,
+				NextExpVK:  nextExpVK,
+				ProcTermQN: termExp.ProcTermQN,
+				ContExp:    termExp.ContExp,
+			}
+			counter, matchErr := s.laborMatcher.Match(ds, post)
+			if matchErr != nil {
+				s.log.Error("step taking failed", compAttr, commAttr)
+				return execMod, execEff, exchMod, matchErr
+			}
+			if counter.IsEmpty() {
+				s.log.Debug("taking half done", compAttr, commAttr)
+				return execMod, execEff, exchMod, nil
+			}
+			s.hire(&execMod, &execEff, counter.Get(), post)
+			s.log.Debug("step taking succeed", compAttr, commAttr, slog.Any("qn", termExp.ProcTermQN))
 			return execMod, execEff, exchMod, nil
 		}
 		hiring, ok := subscription.(commturn.SubRec)
@@ -561,13 +1222,9 @@ func (s *service) take(
 		}
 		nextExpVK := typeExp.(typeexp.ProdRec).Next()
 		// получаем снепшот коммуникации
-		var commSnap commexch.ExchSnap
-		getErr := s.operator.Implicit(ctx, func(ds db.Source) error {
-			commSnap, err = s.commExchRepo.GetSnapByQry(ds, commexch.ExchQry{
-				CommRef: commChnl.CommRef,
-				ChnlID:  option.Some(commChnl.ChnlID),
-			})
-			return err
+		commSnap, getErr := s.commExchRepo.GetSnapByQry(ds, commexch.ExchQry{
+			CommRef: commChnl.CommRef,
+			ChnlID:  option.Some(commChnl.ChnlID),
 		})
 		if getErr != nil {
 			s.log.Error("step taking failed", compAttr)
@@ -577,18 +1234,25 @@ func (s *service) take(
 		commAttr := slog.Any("commRef", commSnap.CommRef)
 		subscription := commSnap.NextTurn()
 		if subscription == nil {
-			// регистрируем подписку нанимателя
-			exchMod.Turns = append(exchMod.Turns, commturn.SubRec{
-				CommRef: commSnap.CommRef,
-				CompRef: execSnap.CompRef,
-				ChnlID:  commChnl.ChnlID,
-				ContExp: termexp.HireRec{
-					ContChnlPH: commChnl.ChnlPH,
-					ProcTermQN: termExp.ProcTermQN,
-					ContExp:    termExp.ContExp,
-				},
-			})
-			s.log.Debug("taking half done", compAttr, commAttr)
+			// ищем встречное размещение на рынке труда
+			post := labormkt.PostRec{
+				Side:       labormkt.VacancySide,
+				CommChnl:   commChnl,
+				NextExpVK:  nextExpVK,
+				ProcTermQN: termExp.ProcTermQN,
+				ContExp:    termExp.ContExp,
+			}
+			counter, matchErr := s.laborMatcher.Match(ds, post)
+			if matchErr != nil {
+				s.log.Error("step taking failed", compAttr, commAttr)
+				return execMod, execEff, exchMod, matchErr
+			}
+			if counter.IsEmpty() {
+				s.log.Debug("taking half done", compAttr, commAttr)
+				return execMod, execEff, exchMod, nil
+			}
+			s.hire(&execMod, &execEff, post, counter.Get())
+			s.log.Debug("step taking succeed", compAttr, commAttr, slog.Any("qn", termExp.ProcTermQN))
 			return execMod, execEff, exchMod, nil
 		}
 		application, ok := subscription.(commturn.SubRec)
@@ -649,13 +1313,9 @@ func (s *service) take(
 		}
 		nextExpVK := typeExp.(typeexp.ProdRec).Next()
 		// получаем снепшот коммуникации
-		var commSnap commexch.ExchSnap
-		getErr := s.operator.Implicit(ctx, func(ds db.Source) error {
-			commSnap, err = s.commExchRepo.GetSnapByQry(ds, commexch.ExchQry{
-				CommRef: commChnl.CommRef,
-				ChnlID:  option.Some(commChnl.ChnlID),
-			})
-			return err
+		commSnap, getErr := s.commExchRepo.GetSnapByQry(ds, commexch.ExchQry{
+			CommRef: commChnl.CommRef,
+			ChnlID:  option.Some(commChnl.ChnlID),
 		})
 		if getErr != nil {
 			s.log.Error("step taking failed", compAttr)
@@ -721,13 +1381,9 @@ func (s *service) take(
 		}
 		nextExpVK := typeExp.(typeexp.ProdRec).Next()
 		// получаем снепшот коммуникации
-		var commSnap commexch.ExchSnap
-		getErr := s.operator.Implicit(ctx, func(ds db.Source) error {
-			commSnap, err = s.commExchRepo.GetSnapByQry(ds, commexch.ExchQry{
-				CommRef: commChnl.CommRef,
-				ChnlID:  option.Some(commChnl.ChnlID),
-			})
-			return err
+		commSnap, getErr := s.commExchRepo.GetSnapByQry(ds, commexch.ExchQry{
+			CommRef: commChnl.CommRef,
+			ChnlID:  option.Some(commChnl.ChnlID),
 		})
 		if getErr != nil {
 			s.log.Error("step taking failed", compAttr)
@@ -779,33 +1435,375 @@ func (s *service) take(
 		}
 		s.log.Debug("step taking succeed", compAttr, commAttr)
 		return execMod, execEff, exchMod, nil
+	case termexp.SendSpec:
+		commChnl, ok := execSnap.LinearVars[termExp.CommChnlPH]
+		if !ok {
+			s.log.Error("step taking failed", compAttr)
+			return execMod, execEff, exchMod, proctermdef.ErrMissingInCfg(termExp.CommChnlPH)
+		}
+		// вычисляем следующее состояние
+		typeExp, ok := execSnap.LinearExps[termExp.CommChnlPH]
+		if !ok {
+			s.log.Error("step taking failed", compAttr)
+			return execMod, execEff, exchMod, proctermdef.ErrMissingInCtx(termExp.CommChnlPH)
+		}
+		nextExpVK, valExpVK, err := splitTransfer(typeExp, commChnl)
+		if err != nil {
+			s.log.Error("step taking failed", compAttr)
+			return execMod, execEff, exchMod, err
+		}
+		valChnl, ok := execSnap.LinearVars[termExp.ValChnlPH]
+		if !ok {
+			s.log.Error("step taking failed", compAttr)
+			return execMod, execEff, exchMod, proctermdef.ErrMissingInCfg(termExp.ValChnlPH)
+		}
+		err = typeexp.CheckRef(valChnl.ExpVK, valExpVK)
+		if err != nil {
+			s.log.Error("step taking failed", compAttr)
+			return execMod, execEff, exchMod, err
+		}
+		// получаем снепшот коммуникации
+		commSnap, getErr := s.commExchRepo.GetSnapByQry(ds, commexch.ExchQry{
+			CommRef: commChnl.CommRef,
+			ChnlID:  option.Some(commChnl.ChnlID),
+		})
+		if getErr != nil {
+			s.log.Error("step taking failed", compAttr)
+			return execMod, execEff, exchMod, getErr
+		}
+		exchMod.CommRef = commSnap.CommRef
+		commAttr := slog.Any("commRef", commSnap.CommRef)
+		subscription := commSnap.NextTurn()
+		if subscription == nil {
+			// регистрируем подписку отправителя
+			exchMod.Turns = append(exchMod.Turns, commturn.SubRec{
+				CommRef: commSnap.CommRef,
+				CompRef: execSnap.CompRef,
+				ChnlID:  commChnl.ChnlID,
+				ContExp: termexp.SendRec{
+					ContChnlPH: commChnl.ChnlPH,
+					ValChnlPH:  valChnl.ChnlPH,
+					ContExp:    termExp.ContExp,
+				},
+			})
+			s.log.Debug("taking half done", compAttr, commAttr)
+			return execMod, execEff, exchMod, nil
+		}
+		receival, ok := subscription.(commturn.SubRec)
+		if !ok {
+			panic(commturn.ErrRecTypeUnexpected(subscription))
+		}
+		expRec, ok := receival.ContExp.(termexp.RecvRec)
+		if !ok {
+			panic(termexp.ErrRecTypeUnexpected(receival.ContExp))
+		}
+		// получатель ждет, поэтому его канал не меняется до сопоставления
+		receiverSnap, getErr := s.compExecRepo.GetSnapByRef(ds, receival.CompRef)
+		if getErr != nil {
+			s.log.Error("step taking failed", compAttr, commAttr)
+			return execMod, execEff, exchMod, getErr
+		}
+		receiverChnl, ok := compvar.ConvertRecsToRecMap(receiverSnap.LinearVars)[expRec.ContChnlPH]
+		if !ok {
+			s.log.Error("step taking failed", compAttr, commAttr)
+			return execMod, execEff, exchMod, proctermdef.ErrMissingInCfg(expRec.ContChnlPH)
+		}
+		// сдвигаем офсет коммуникации
+		exchMod.OffsetNr = option.Some(receival.CommRef.CommRN)
+		s.transfer(&execMod, &execEff, commChnl, receiverChnl, nextExpVK, valChnl, termExp.ContExp, expRec)
+		s.log.Debug("step taking succeed", compAttr, commAttr)
+		return execMod, execEff, exchMod, nil
+	case termexp.RecvSpec:
+		commChnl, ok := execSnap.LinearVars[termExp.CommChnlPH]
+		if !ok {
+			s.log.Error("step taking failed", compAttr)
+			return execMod, execEff, exchMod, proctermdef.ErrMissingInCfg(termExp.CommChnlPH)
+		}
+		// вычисляем следующее состояние
+		typeExp, ok := execSnap.LinearExps[termExp.CommChnlPH]
+		if !ok {
+			s.log.Error("step taking failed", compAttr)
+			return execMod, execEff, exchMod, proctermdef.ErrMissingInCtx(termExp.CommChnlPH)
+		}
+		// получаем снепшот коммуникации
+		commSnap, getErr := s.commExchRepo.GetSnapByQry(ds, commexch.ExchQry{
+			CommRef: commChnl.CommRef,
+			ChnlID:  option.Some(commChnl.ChnlID),
+		})
+		if getErr != nil {
+			s.log.Error("step taking failed", compAttr)
+			return execMod, execEff, exchMod, getErr
+		}
+		exchMod.CommRef = commSnap.CommRef
+		commAttr := slog.Any("commRef", commSnap.CommRef)
+		subscription := commSnap.NextTurn()
+		if subscription == nil {
+			// регистрируем подписку получателя
+			exchMod.Turns = append(exchMod.Turns, commturn.SubRec{
+				CommRef: commSnap.CommRef,
+				CompRef: execSnap.CompRef,
+				ChnlID:  commChnl.ChnlID,
+				ContExp: termexp.RecvRec{
+					ContChnlPH: commChnl.ChnlPH,
+					ValChnlPH:  termExp.ValChnlPH,
+					ContExp:    termExp.ContExp,
+				},
+			})
+			s.log.Debug("taking half done", compAttr, commAttr)
+			return execMod, execEff, exchMod, nil
+		}
+		sending, ok := subscription.(commturn.SubRec)
+		if !ok {
+			panic(commturn.ErrRecTypeUnexpected(subscription))
+		}
+		expRec, ok := sending.ContExp.(termexp.SendRec)
+		if !ok {
+			panic(termexp.ErrRecTypeUnexpected(sending.ContExp))
+		}
+		// отправитель ждет, поэтому его канал не меняется до сопоставления
+		senderSnap, getErr := s.compExecRepo.GetSnapByRef(ds, sending.CompRef)
+		if getErr != nil {
+			s.log.Error("step taking failed", compAttr, commAttr)
+			return execMod, execEff, exchMod, getErr
+		}
+		senderVars := compvar.ConvertRecsToRecMap(senderSnap.LinearVars)
+		senderChnl, ok := senderVars[expRec.ContChnlPH]
+		if !ok {
+			s.log.Error("step taking failed", compAttr, commAttr)
+			return execMod, execEff, exchMod, proctermdef.ErrMissingInCfg(expRec.ContChnlPH)
+		}
+		nextExpVK, valExpVK, err := splitTransfer(typeExp, senderChnl)
+		if err != nil {
+			s.log.Error("step taking failed", compAttr, commAttr)
+			return execMod, execEff, exchMod, err
+		}
+		valChnl, ok := senderVars[expRec.ValChnlPH]
+		if !ok {
+			s.log.Error("step taking failed", compAttr, commAttr)
+			return execMod, execEff, exchMod, proctermdef.ErrMissingInCfg(expRec.ValChnlPH)
+		}
+		err = typeexp.CheckRef(valChnl.ExpVK, valExpVK)
+		if err != nil {
+			s.log.Error("step taking failed", compAttr, commAttr)
+			return execMod, execEff, exchMod, err
+		}
+		// сдвигаем офсет коммуникации
+		exchMod.OffsetNr = option.Some(sending.CommRef.CommRN)
+		s.transfer(&execMod, &execEff, senderChnl, commChnl, nextExpVK, valChnl, expRec.ContExp, termexp.RecvRec{
+			ContChnlPH: commChnl.ChnlPH,
+			ValChnlPH:  termExp.ValChnlPH,
+			ContExp:    termExp.ContExp,
+		})
+		s.log.Debug("step taking succeed", compAttr, commAttr)
+		return execMod, execEff, exchMod, nil
+	case termexp.FireSpec:
+		return s.dismiss(ds, execSnap, termExp.CommChnlPH, termExp.ProcTermQN)
+	case termexp.QuitSpec:
+		return s.dismiss(ds, execSnap, termExp.CommChnlPH, termExp.ProcTermQN)
+	case termexp.SpawnSpec:
+		// потребляемые процессы пул именует заглушками каналов,
+		// по которым ими владеет
+		assetVars := make([]compvar.LinearRec, 0, len(termExp.ProcCompQNs))
+		for _, compQN := range termExp.ProcCompQNs {
+			assetVar, ok := execSnap.LinearVars[compQN.Sym()]
+			if !ok {
+				s.log.Error("step taking failed", compAttr)
+				return execMod, execEff, exchMod, proctermdef.ErrMissingInCfg(compQN.Sym())
+			}
+			if assetVar.ChnlBS != compvar.AssetSide {
+				s.log.Error("step taking failed", compAttr, slog.Any("ph", assetVar.ChnlPH))
+				return execMod, execEff, exchMod, errChnlNotOwned(assetVar.ChnlPH)
+			}
+			assetVars = append(assetVars, assetVar)
+		}
+		execMod.Vars = append(execMod.Vars, consumeVars(assetVars)...)
+		// сам процесс создается при записи шага
+		execEff.Spawns = append(execEff.Spawns, SpawnEff{
+			ProcTermQN: termExp.ProcTermQN,
+			AssetVars:  assetVars,
+		})
+		s.log.Debug("step taking succeed", compAttr, slog.Any("qn", termExp.ProcTermQN))
+		return execMod, execEff, exchMod, nil
 	default:
 		panic(termexp.ErrSpecTypeUnexpected(exp))
 	}
 }
 
-func (s *service) retrieveSnap(ref compsem.SemRef) (_ ExecSnap3, err error) {
-	ctx := context.Background()
-	var execSnap ExecSnap2
-	getErr1 := s.operator.Implicit(ctx, func(ds db.Source) error {
-		execSnap, err = s.compExecRepo.GetSnapByRef(ds, ref)
-		return err
-	})
-	if getErr1 != nil {
-		return ExecSnap3{}, getErr1
+// Упраздняет должность в одностороннем порядке (aka Fire/Quit).
+//
+// Другая сторона найма находится по общему каналу, а ее ревизия
+// сверяется при записи, поэтому встречного шага не требуется.
+func (s *service) dismiss(
+	ds db.Source,
+	execSnap ExecSnap3,
+	commChnlPH symbol.ADT,
+	procTermQN uniqsym.ADT,
+) (
+	execMod ExecMod,
+	execEff ExecEff,
+	exchMod commexch.ExchMod,
+	err error,
+) {
+	compAttr := slog.Any("compRef", execSnap.CompRef)
+	commChnl, ok := execSnap.LinearVars[commChnlPH]
+	if !ok {
+		s.log.Error("step taking failed", compAttr)
+		return execMod, execEff, exchMod, proctermdef.ErrMissingInCfg(commChnlPH)
 	}
-	var structExps map[symbol.ADT]typeexp.ExpRec
-	var linearExps map[symbol.ADT]typeexp.ExpRec
-	getErr2 := s.operator.Implicit(ctx, func(ds db.Source) error {
-		structExps, err = s.typeExpRepo.GetRecMap(ds, ExtractExpVKs(execSnap.StructVars))
-		if err != nil {
-			return err
+	commAttr := slog.Any("commRef", commChnl.CommRef)
+	peerSnap, err := s.compExecRepo.GetSnapByChnl(ds, commChnl.ChnlID, execSnap.CompRef)
+	if err != nil {
+		s.log.Error("step taking failed", compAttr, commAttr)
+		return execMod, execEff, exchMod, err
+	}
+	peerChnl, ok := findChnl(compvar.ConvertRecsToRecMap(peerSnap.LinearVars), commChnl.ChnlID)
+	if !ok {
+		s.log.Error("step taking failed", compAttr, commAttr)
+		return execMod, execEff, exchMod, errMissingPeer(commChnl.ChnlID)
+	}
+	// закрываем найм на рынке труда
+	err = s.laborMatcher.Dismiss(ds, commChnl.CommRef)
+	if err != nil {
+		s.log.Error("step taking failed", compAttr, commAttr)
+		return execMod, execEff, exchMod, err
+	}
+	// должность упраздняется, поэтому обе стороны лишаются канала
+	// и продолжений нет
+	execMod.Vars = append(execMod.Vars, consumeVars([]compvar.LinearRec{commChnl, peerChnl})...)
+	execEff.Peers = append(execEff.Peers, peerSnap.CompRef)
+	s.log.Debug("step taking succeed", compAttr, commAttr, slog.Any("qn", procTermQN))
+	return execMod, execEff, exchMod, nil
+}
+
+// Передает канал отправителя получателю (aka Tensor/Lolli).
+//
+// Отправитель лишается передаваемого канала, а продолжения обоих
+// вяжутся на новый канал коммуникации.
+// Сведенные на рынке вакансия и заявка могут прийти с разных обменов,
+// поэтому обе стороны вяжутся на обмене нанимателя.
+func (s *service) hire(
+	execMod *ExecMod,
+	execEff *ExecEff,
+	vacancy labormkt.PostRec,
+	application labormkt.PostRec,
+) {
+	newChnlID := identity.New()
+	for _, post := range []labormkt.PostRec{vacancy, application} {
+		// вяжем продолжение стороны
+		execMod.Vars = append(execMod.Vars, compvar.LinearRec{
+			CompRef: post.CommChnl.CompRef,
+			CommRef: vacancy.CommChnl.CommRef,
+			ChnlID:  newChnlID,
+			ChnlPH:  post.CommChnl.ChnlPH,
+			ChnlBS:  post.CommChnl.ChnlBS,
+			ExpVK:   post.NextExpVK,
+		})
+		if post.ContExp != nil {
+			// шедулим продолжение стороны
+			execEff.Steps = append(execEff.Steps, compstep.StepSpec{
+				CompRef: post.CommChnl.CompRef,
+				PoolExp: post.ContExp,
+			})
 		}
-		linearExps, err = s.typeExpRepo.GetRecMap(ds, ExtractExpVKs(execSnap.LinearVars))
-		return err
+	}
+}
+
+// разбирает тип канала передачи: провайдер отправляет по тензору,
+// а клиент по лолли
+func splitTransfer(typeExp typeexp.ExpRec, senderChnl compvar.LinearRec) (valkey.ADT, valkey.ADT, error) {
+	prodExp, ok := typeExp.(typeexp.ProdRec)
+	if !ok {
+		return valkey.Zero, valkey.Zero, typeexp.ErrRecTypeUnexpected(typeExp)
+	}
+	valExp, ok := typeExp.(typeexp.ValRec)
+	if !ok {
+		return valkey.Zero, valkey.Zero, typeexp.ErrRecTypeUnexpected(typeExp)
+	}
+	wantPol := polarity.Neg
+	if senderChnl.ChnlBS == compvar.LiabSide {
+		wantPol = polarity.Pos
+	}
+	if typeExp.Pol() != wantPol {
+		return valkey.Zero, valkey.Zero, typeexp.ErrPolarityMismatch(wantPol, typeExp.Pol())
+	}
+	return prodExp.Next(), valExp.Val(), nil
+}
+
+func (s *service) transfer(
+	execMod *ExecMod,
+	execEff *ExecEff,
+	senderChnl compvar.LinearRec,
+	receiverChnl compvar.LinearRec,
+	nextExpVK valkey.ADT,
+	valChnl compvar.LinearRec,
+	contExp termexp.ExpSpec,
+	expRec termexp.RecvRec,
+) {
+	newChnlID := identity.New()
+	// вяжем продолжение отправителя
+	execMod.Vars = append(execMod.Vars, compvar.LinearRec{
+		CompRef: senderChnl.CompRef,
+		CommRef: senderChnl.CommRef,
+		ChnlID:  newChnlID,
+		ChnlPH:  senderChnl.ChnlPH,
+		ChnlBS:  senderChnl.ChnlBS,
+		ExpVK:   nextExpVK,
 	})
-	if getErr2 != nil {
-		return ExecSnap3{}, getErr2
+	// лишаем значения отправителя
+	execMod.Vars = append(execMod.Vars, compvar.LinearRec{
+		CompRef: senderChnl.CompRef,
+		CommRef: valChnl.CommRef,
+		ChnlID:  valChnl.ChnlID,
+		ChnlPH:  valChnl.ChnlPH,
+		ChnlBS:  valChnl.ChnlBS,
+		ExpVK:   valChnl.ExpVK.Invert(),
+	})
+	if contExp != nil {
+		// шедулим продолжение отправителя
+		execEff.Steps = append(execEff.Steps, compstep.StepSpec{
+			CompRef: senderChnl.CompRef,
+			PoolExp: contExp,
+		})
+	}
+	// вяжем продолжение получателя
+	execMod.Vars = append(execMod.Vars, compvar.LinearRec{
+		CompRef: receiverChnl.CompRef,
+		CommRef: receiverChnl.CommRef,
+		ChnlID:  newChnlID,
+		ChnlPH:  expRec.ContChnlPH,
+		ChnlBS:  receiverChnl.ChnlBS,
+		ExpVK:   nextExpVK,
+	})
+	// вяжем значение получателя
+	execMod.Vars = append(execMod.Vars, compvar.LinearRec{
+		CompRef: receiverChnl.CompRef,
+		CommRef: valChnl.CommRef,
+		ChnlID:  valChnl.ChnlID,
+		ChnlPH:  expRec.ValChnlPH,
+		ChnlBS:  valChnl.ChnlBS,
+		ExpVK:   valChnl.ExpVK,
+	})
+	if expRec.ContExp != nil {
+		// шедулим продолжение получателя
+		execEff.Steps = append(execEff.Steps, compstep.StepSpec{
+			CompRef: receiverChnl.CompRef,
+			PoolExp: expRec.ContExp,
+		})
+	}
+}
+
+func (s *service) retrieveSnap(ds db.Source, ref compsem.SemRef) (ExecSnap3, error) {
+	execSnap, err := s.compExecRepo.GetSnapByRef(ds, ref)
+	if err != nil {
+		return ExecSnap3{}, err
+	}
+	structExps, err := s.typeExpRepo.GetRecMap(ds, ExtractExpVKs(execSnap.StructVars))
+	if err != nil {
+		return ExecSnap3{}, err
+	}
+	linearExps, err := s.typeExpRepo.GetRecMap(ds, ExtractExpVKs(execSnap.LinearVars))
+	if err != nil {
+		return ExecSnap3{}, err
 	}
 	return ExecSnap3{
 		CompRef:    execSnap.CompRef,
@@ -815,3 +1813,35 @@ func (s *service) retrieveSnap(ref compsem.SemRef) (_ ExecSnap3, err error) {
 		LinearExps: linearExps,
 	}, nil
 }
+
+func errAssetCountMismatch(want, got int) error {
+	return fmt.Errorf("asset count mismatch: want %v, got %v", want, got)
+}
+
+func errExecMissingInJournal(got compsem.SemRef) error {
+	return fmt.Errorf("exec missing in journal: %v", got)
+}
+
+func errPeerMissingInJournal(got identity.ADT) error {
+	return fmt.Errorf("peer missing in journal: %v", got)
+}
+
+func errMatchMissingInJournal(got commsem.SemRef) error {
+	return fmt.Errorf("match missing in journal: %v", got)
+}
+
+func errProcNotOwned(pool, proc compsem.SemRef) error {
+	return fmt.Errorf("proc not owned: pool %v, proc %v", pool, proc)
+}
+
+func errChnlNotOwned(ph symbol.ADT) error {
+	return fmt.Errorf("chnl not owned: %v", ph)
+}
+
+func errMissingPeer(chnlID identity.ADT) error {
+	return fmt.Errorf("peer missing: %v", chnlID)
+}
+
+func errMissingLiab(ref compsem.SemRef) error {
+	return fmt.Errorf("liab var missing: %v", ref)
+}
@@ -0,0 +1,494 @@
+package compexec
+
+import (
+	"log/slog"
+	"testing"
+
+	"orglang/go-engine/lib/db"
+
+	"orglang/go-engine/adt/commsem"
+	"orglang/go-engine/adt/compsem"
+	"orglang/go-engine/adt/identity"
+	"orglang/go-engine/adt/option"
+	"orglang/go-engine/adt/symbol"
+	"orglang/go-engine/adt/uniqsym"
+	"orglang/go-engine/adt/valkey"
+
+	"orglang/go-engine/pool/commexch"
+	"orglang/go-engine/pool/commturn"
+	"orglang/go-engine/pool/compvar"
+	"orglang/go-engine/pool/labormkt"
+	"orglang/go-engine/pool/termexp"
+	"orglang/go-engine/pool/typeexp"
+
+	proccompexec "orglang/go-engine/proc/compexec"
+)
+
+func liabVar(ph string, comp compsem.SemRef, expVK valkey.ADT) compvar.VarRec {
+	comm := commsem.New()
+	return compvar.LinearRec{
+		CompRef: comp,
+		CommRef: comm,
+		ChnlID:  comm.CommID,
+		ChnlPH:  symbol.New(ph),
+		ChnlBS:  compvar.LiabSide,
+		ExpVK:   expVK,
+	}
+}
+
+func assetVar(ph string, comp compsem.SemRef, expVK valkey.ADT) compvar.VarRec {
+	comm := commsem.New()
+	return compvar.LinearRec{
+		CompRef: comp,
+		CommRef: comm,
+		ChnlID:  comm.CommID,
+		ChnlPH:  symbol.New(ph),
+		ChnlBS:  compvar.AssetSide,
+		ExpVK:   expVK,
+	}
+}
+
+func TestDiffVars(t *testing.T) {
+	stepRef := compsem.New()
+	pool := compsem.New()
+	other := compsem.New()
+	tests := []struct {
+		name   string
+		want   []compvar.VarRec
+		got    []compvar.VarRec
+		reason string
+	}{
+		{"equal", []compvar.VarRec{liabVar("x", pool, valkey.One)}, []compvar.VarRec{liabVar("x", pool, valkey.One)}, ""},
+		{"none", nil, nil, ""},
+		// новые каналы получают новые идентификаторы
+		{"new channel", []compvar.VarRec{liabVar("x", pool, valkey.One)}, []compvar.VarRec{liabVar("x", pool, valkey.One)}, ""},
+		// шаг пишет переменные нескольких пулов
+		{"other pool", []compvar.VarRec{liabVar("x", pool, valkey.One), liabVar("x", other, valkey.One)}, []compvar.VarRec{liabVar("x", pool, valkey.One)}, "var missing in replay"},
+		{"missing in replay", []compvar.VarRec{liabVar("x", pool, valkey.One)}, nil, "var missing in replay"},
+		{"missing in journal", nil, []compvar.VarRec{liabVar("x", pool, valkey.One)}, "var missing in journal"},
+		{"type mismatch", []compvar.VarRec{liabVar("x", pool, valkey.One)}, []compvar.VarRec{liabVar("x", pool, valkey.One.Invert())}, "var mismatch"},
+		{"side mismatch", []compvar.VarRec{liabVar("x", pool, valkey.One)}, []compvar.VarRec{assetVar("x", pool, valkey.One)}, "var mismatch"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			div := diffVars(stepRef, tt.want, tt.got)
+			if tt.reason == "" {
+				if div != nil {
+					t.Fatalf("unexpected divergence: %+v", div)
+				}
+				return
+			}
+			if div == nil {
+				t.Fatalf("want divergence %q, got nil", tt.reason)
+			}
+			if div.Reason != tt.reason || div.StepRef != stepRef || div.ChnlPH != symbol.New("x") {
+				t.Errorf("unexpected divergence: %+v", div)
+			}
+		})
+	}
+}
+
+func TestExchReplayer(t *testing.T) {
+	first := commsem.New()
+	second := commsem.New()
+	r := &exchReplayer{snaps: []commexch.ExchSnap{{CommRef: first}, {CommRef: second}}}
+	// обмены отдаются в порядке исходного шага
+	_, err := r.GetSnapByQry(nil, commexch.ExchQry{CommRef: second})
+	if err == nil {
+		t.Fatal("want error, got nil")
+	}
+	for _, ref := range []commsem.SemRef{first, second} {
+		snap, err := r.GetSnapByQry(nil, commexch.ExchQry{CommRef: ref})
+		if err != nil {
+			t.Fatalf("unexpected error %q", err)
+		}
+		if snap.CommRef != ref {
+			t.Errorf("want %v, got %v", ref, snap.CommRef)
+		}
+	}
+	_, err = r.GetSnapByQry(nil, commexch.ExchQry{CommRef: first})
+	if err == nil {
+		t.Error("want error on exhausted journal, got nil")
+	}
+}
+
+func TestExecReplayer(t *testing.T) {
+	sender := compsem.New()
+	r := &execReplayer{snaps: []ExecSnap2{{CompRef: sender}}}
+	_, err := r.GetSnapByRef(nil, compsem.New())
+	if err == nil {
+		t.Fatal("want error, got nil")
+	}
+	snap, err := r.GetSnapByRef(nil, sender)
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	if snap.CompRef != sender {
+		t.Errorf("want %v, got %v", sender, snap.CompRef)
+	}
+}
+
+func TestMatchReplayer(t *testing.T) {
+	counter := labormkt.PostRec{ProcTermQN: uniqsym.New(symbol.New("foo"))}
+	r := &matchReplayer{counters: []option.ADT[labormkt.PostRec]{option.None[labormkt.PostRec](), option.Some(counter)}}
+	got, err := r.Match(nil, labormkt.PostRec{})
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	if !got.IsEmpty() {
+		t.Errorf("want no counter, got %v", got.Get())
+	}
+	got, err = r.Match(nil, labormkt.PostRec{})
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	if got.IsEmpty() || got.Get().ProcTermQN != counter.ProcTermQN {
+		t.Errorf("want %v, got %v", counter, got)
+	}
+	// в повторе рынок не меняется
+	err = r.Dismiss(nil, commsem.New())
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	_, err = r.Match(nil, labormkt.PostRec{})
+	if err == nil {
+		t.Error("want error on exhausted journal, got nil")
+	}
+}
+
+func newTestService() *service {
+	return &service{log: slog.New(slog.DiscardHandler)}
+}
+
+func linearSnap(ref compsem.SemRef, vars ...compvar.VarRec) ExecSnap3 {
+	linearVars := make([]compvar.LinearRec, 0, len(vars))
+	for _, rec := range vars {
+		linearVars = append(linearVars, rec.(compvar.LinearRec))
+	}
+	return ExecSnap3{CompRef: ref, LinearVars: compvar.ConvertRecsToRecMap(linearVars)}
+}
+
+func TestConsumeVars(t *testing.T) {
+	pool := compsem.New()
+	asset := assetVar("x", pool, valkey.One).(compvar.LinearRec)
+	got := consumeVars([]compvar.LinearRec{asset})
+	if len(got) != 1 {
+		t.Fatalf("want 1 var, got %v", len(got))
+	}
+	consumed := got[0].(compvar.LinearRec)
+	if consumed.ChnlID != asset.ChnlID || consumed.ExpVK != asset.ExpVK.Invert() || consumed.CompRef != pool {
+		t.Errorf("unexpected var: %+v", consumed)
+	}
+}
+
+func TestTakeSpawn(t *testing.T) {
+	pool := compsem.New()
+	procQN := uniqsym.New(symbol.New("proc"))
+	tests := []struct {
+		name string
+		vars []compvar.VarRec
+		err  bool
+	}{
+		{"owned", []compvar.VarRec{assetVar("x", pool, valkey.One)}, false},
+		{"missing", nil, true},
+		// пул предоставляет канал, а не владеет им
+		{"not owned", []compvar.VarRec{liabVar("x", pool, valkey.One)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := termexp.SpawnSpec{
+				ProcTermQN:  procQN,
+				ProcCompQNs: []uniqsym.ADT{uniqsym.New(symbol.New("x"))},
+			}
+			execMod, execEff, _, err := newTestService().take(nil, linearSnap(pool, tt.vars...), spec)
+			if tt.err {
+				if err == nil {
+					t.Fatal("want error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			if len(execEff.Spawns) != 1 || execEff.Spawns[0].ProcTermQN != procQN {
+				t.Fatalf("unexpected spawns: %+v", execEff.Spawns)
+			}
+			owned := tt.vars[0].(compvar.LinearRec)
+			if len(execEff.Spawns[0].AssetVars) != 1 || execEff.Spawns[0].AssetVars[0] != owned {
+				t.Errorf("unexpected assets: %+v", execEff.Spawns[0].AssetVars)
+			}
+			// пул лишается переданного канала
+			if len(execMod.Vars) != 1 || execMod.Vars[0].GetExpVK() != owned.ExpVK.Invert() {
+				t.Errorf("unexpected vars: %+v", execMod.Vars)
+			}
+		})
+	}
+}
+
+func TestDismiss(t *testing.T) {
+	employer := compsem.New()
+	employee := compsem.New()
+	hired := liabVar("e", employer, valkey.One).(compvar.LinearRec)
+	held := hired
+	held.CompRef = employee
+	held.ChnlPH = symbol.New("h")
+	held.ChnlBS = compvar.AssetSide
+	stale := held
+	stale.ChnlID = identity.New()
+	tests := []struct {
+		name  string
+		peers []ExecSnap2
+		err   bool
+	}{
+		{"fire", []ExecSnap2{{CompRef: employee, LinearVars: []compvar.LinearRec{held}}}, false},
+		// сотрудник уже держит другой канал
+		{"peer moved on", []ExecSnap2{{CompRef: employee, LinearVars: []compvar.LinearRec{stale}}}, true},
+		{"no peer", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService()
+			s.compExecRepo = &execReplayer{snaps: tt.peers}
+			s.laborMatcher = &matchReplayer{}
+			spec := termexp.FireSpec{CommChnlPH: hired.ChnlPH}
+			execMod, execEff, exchMod, err := s.take(nil, linearSnap(employer, hired), spec)
+			if tt.err {
+				if err == nil {
+					t.Fatal("want error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			// встречного шага нет, поэтому ходов по обмену тоже
+			if !exchMod.CommRef.CommID.IsEmpty() || len(exchMod.Turns) != 0 {
+				t.Errorf("unexpected exchange: %+v", exchMod)
+			}
+			if len(execEff.Peers) != 1 || execEff.Peers[0] != employee {
+				t.Errorf("unexpected peers: %+v", execEff.Peers)
+			}
+			div := diffVars(employer, consumeVars([]compvar.LinearRec{hired, held}), execMod.Vars)
+			if div != nil {
+				t.Errorf("unexpected vars: %+v", div)
+			}
+		})
+	}
+}
+
+func TestSplitTransfer(t *testing.T) {
+	comp := compsem.New()
+	provider := liabVar("c", comp, valkey.One).(compvar.LinearRec)
+	client := assetVar("d", comp, valkey.One).(compvar.LinearRec)
+	val := typeexp.OneRec{ExpVK: valkey.Two}
+	cont := typeexp.OneRec{ExpVK: valkey.Three}
+	tensor := typeexp.TensorRec{ExpVK: valkey.One, ValExp: val, ContExp: cont}
+	lolli := typeexp.LolliRec{ExpVK: valkey.One, ValExp: val, ContExp: cont}
+	tests := []struct {
+		name    string
+		typeExp typeexp.ExpRec
+		sender  compvar.LinearRec
+		err     bool
+	}{
+		{"provider sends tensor", tensor, provider, false},
+		{"client sends lolli", lolli, client, false},
+		// направление передачи не совпадает с полярностью
+		{"client sends tensor", tensor, client, true},
+		{"provider sends lolli", lolli, provider, true},
+		{"not a product", cont, provider, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nextExpVK, valExpVK, err := splitTransfer(tt.typeExp, tt.sender)
+			if tt.err {
+				if err == nil {
+					t.Fatal("want error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			if nextExpVK != cont.ExpVK || valExpVK != val.ExpVK {
+				t.Errorf("want %v and %v, got %v and %v", cont.ExpVK, val.ExpVK, nextExpVK, valExpVK)
+			}
+		})
+	}
+}
+
+// transferFixture описывает передачу значения провайдером клиенту по тензору
+type transferFixture struct {
+	provider  compsem.SemRef
+	client    compsem.SemRef
+	tensor    typeexp.TensorRec
+	liab      compvar.LinearRec
+	asset     compvar.LinearRec
+	val       compvar.LinearRec
+	recvValPH symbol.ADT
+}
+
+func newTransferFixture() transferFixture {
+	f := transferFixture{
+		provider:  compsem.New(),
+		client:    compsem.New(),
+		recvValPH: symbol.New("w"),
+	}
+	f.tensor = typeexp.TensorRec{
+		ExpVK:   valkey.One,
+		ValExp:  typeexp.OneRec{ExpVK: valkey.Two},
+		ContExp: typeexp.OneRec{ExpVK: valkey.Three},
+	}
+	f.liab = liabVar("c", f.provider, f.tensor.ExpVK).(compvar.LinearRec)
+	f.asset = f.liab
+	f.asset.CompRef = f.client
+	f.asset.ChnlPH = symbol.New("d")
+	f.asset.ChnlBS = compvar.AssetSide
+	f.val = assetVar("v", f.provider, f.tensor.ValExp.Key()).(compvar.LinearRec)
+	return f
+}
+
+// checkTransfer сверяет стороны и типы каналов после передачи
+func (f transferFixture) checkTransfer(t *testing.T, execMod ExecMod) {
+	t.Helper()
+	want := map[compsem.SemRef]map[symbol.ADT]compvar.LinearRec{
+		f.provider: {
+			f.liab.ChnlPH: {ChnlBS: compvar.LiabSide, ExpVK: f.tensor.Next()},
+			f.val.ChnlPH:  {ChnlBS: f.val.ChnlBS, ExpVK: f.val.ExpVK.Invert()},
+		},
+		f.client: {
+			f.asset.ChnlPH: {ChnlBS: compvar.AssetSide, ExpVK: f.tensor.Next()},
+			f.recvValPH:    {ChnlBS: f.val.ChnlBS, ExpVK: f.val.ExpVK},
+		},
+	}
+	if len(execMod.Vars) != 4 {
+		t.Fatalf("want 4 vars, got %+v", execMod.Vars)
+	}
+	for _, rec := range execMod.Vars {
+		got := rec.(compvar.LinearRec)
+		wantVar, ok := want[got.CompRef][got.ChnlPH]
+		if !ok {
+			t.Errorf("unexpected var: %+v", got)
+			continue
+		}
+		if got.ChnlBS != wantVar.ChnlBS || got.ExpVK != wantVar.ExpVK {
+			t.Errorf("want %+v, got %+v", wantVar, got)
+		}
+	}
+}
+
+func TestTakeSend(t *testing.T) {
+	f := newTransferFixture()
+	execSnap := linearSnap(f.provider, f.liab, f.val)
+	execSnap.LinearExps = map[symbol.ADT]typeexp.ExpRec{f.liab.ChnlPH: f.tensor}
+	s := newTestService()
+	s.commExchRepo = &exchReplayer{snaps: []commexch.ExchSnap{{
+		CommRef: f.liab.CommRef,
+		Turns: []commturn.TurnRec{commturn.SubRec{
+			CommRef: f.liab.CommRef,
+			CompRef: f.client,
+			ChnlID:  f.liab.ChnlID,
+			ContExp: termexp.RecvRec{ContChnlPH: f.asset.ChnlPH, ValChnlPH: f.recvValPH},
+		}},
+	}}}
+	s.compExecRepo = &execReplayer{snaps: []ExecSnap2{{CompRef: f.client, LinearVars: []compvar.LinearRec{f.asset}}}}
+	spec := termexp.SendSpec{CommChnlPH: f.liab.ChnlPH, ValChnlPH: f.val.ChnlPH}
+	execMod, _, exchMod, err := s.take(nil, execSnap, spec)
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	if exchMod.OffsetNr.IsEmpty() {
+		t.Error("want offset shifted, got none")
+	}
+	f.checkTransfer(t, execMod)
+}
+
+func TestTakeRecv(t *testing.T) {
+	f := newTransferFixture()
+	execSnap := linearSnap(f.client, f.asset)
+	execSnap.LinearExps = map[symbol.ADT]typeexp.ExpRec{f.asset.ChnlPH: f.tensor}
+	s := newTestService()
+	s.commExchRepo = &exchReplayer{snaps: []commexch.ExchSnap{{
+		CommRef: f.asset.CommRef,
+		Turns: []commturn.TurnRec{commturn.SubRec{
+			CommRef: f.asset.CommRef,
+			CompRef: f.provider,
+			ChnlID:  f.asset.ChnlID,
+			ContExp: termexp.SendRec{ContChnlPH: f.liab.ChnlPH, ValChnlPH: f.val.ChnlPH},
+		}},
+	}}}
+	s.compExecRepo = &execReplayer{snaps: []ExecSnap2{{CompRef: f.provider, LinearVars: []compvar.LinearRec{f.liab, f.val}}}}
+	spec := termexp.RecvSpec{CommChnlPH: f.asset.ChnlPH, ValChnlPH: f.recvValPH}
+	execMod, _, _, err := s.take(nil, execSnap, spec)
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	f.checkTransfer(t, execMod)
+}
+
+func TestTakeSendPolarity(t *testing.T) {
+	f := newTransferFixture()
+	// клиент не может отправлять по тензору
+	execSnap := linearSnap(f.client, f.asset, f.val)
+	execSnap.LinearExps = map[symbol.ADT]typeexp.ExpRec{f.asset.ChnlPH: f.tensor}
+	spec := termexp.SendSpec{CommChnlPH: f.asset.ChnlPH, ValChnlPH: f.val.ChnlPH}
+	_, _, _, err := newTestService().take(nil, execSnap, spec)
+	if err == nil {
+		t.Fatal("want error, got nil")
+	}
+}
+
+// procExecStub отдает снепшоты процессов из памяти
+type procExecStub struct {
+	proccompexec.Repo
+	snaps map[identity.ADT]proccompexec.ExecSnap
+}
+
+func (r procExecStub) GetSnapByRef(_ db.Source, ref compsem.SemRef) (proccompexec.ExecSnap, error) {
+	snap, ok := r.snaps[ref.CompID]
+	if !ok {
+		return proccompexec.ExecSnap{}, db.ErrNotFound
+	}
+	return snap, nil
+}
+
+func TestSelectAssetVars(t *testing.T) {
+	pool := compsem.New()
+	proc := compsem.New()
+	liab := liabVar("p", proc, valkey.One).(compvar.LinearRec)
+	owned := liab
+	owned.CompRef = pool
+	owned.ChnlPH = symbol.New("x")
+	owned.ChnlBS = compvar.AssetSide
+	s := newTestService()
+	s.procExecRepo = procExecStub{snaps: map[identity.ADT]proccompexec.ExecSnap{
+		proc.CompID: {CompRef: proc, LinearVars: compvar.ConvertRecsToRecMap([]compvar.LinearRec{liab})},
+	}}
+	tests := []struct {
+		name string
+		vars []compvar.VarRec
+		refs []compsem.SemRef
+		err  bool
+	}{
+		{"owned", []compvar.VarRec{owned}, []compsem.SemRef{proc}, false},
+		// канал пула ведет не к обязательству процесса
+		{"not owned", []compvar.VarRec{assetVar("x", pool, valkey.One)}, []compsem.SemRef{proc}, true},
+		{"missing proc", []compvar.VarRec{owned}, []compsem.SemRef{compsem.New()}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.selectAssetVars(nil, linearSnap(pool, tt.vars...), tt.refs)
+			if tt.err {
+				if err == nil {
+					t.Fatal("want error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			if len(got) != 1 || got[0] != owned {
+				t.Errorf("unexpected assets: %+v", got)
+			}
+		})
+	}
+}
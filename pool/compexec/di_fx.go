@@ -15,16 +15,17 @@ var Module = fx.Module("pool/compexec",
 	fx.Provide(
 		fx.Private,
 		newEchoController,
-		newPondBroker,
-		fx.Annotate(newPondBroker, fx.As(new(Broker))),
+		fx.Annotate(newOutboxBroker, fx.As(fx.Self()), fx.As(new(Broker))),
+		// fx.Annotate(newPondBroker, fx.As(new(Broker))),
 		// fx.Annotate(newWorkerPoolBroker, fx.As(new(Exch))),
-		fx.Annotate(newSQLBuilder, fx.As(new(queryBuilder))),
-		fx.Annotate(implsem.NewPgxDAO(implBinds), fx.As(new(implsem.Repo))),
+		newDialectBuilder,
+		implsem.NewDialectDAO(implBinds),
 		fx.Annotate(compsem.NewPgxDAO(compExecs), fx.As(new(compsem.Repo))),
 	),
 	fx.Invoke(
 		cfgEchoController,
-		cfgPondBroker,
+		cfgOutboxBroker,
+		// cfgPondBroker,
 		// fx.Annotate(cfgPondBroker, fx.From(new(Exch))),
 		// cfgWorkerPoolBroker,
 	),
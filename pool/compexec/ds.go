@@ -5,14 +5,22 @@ import (
 
 	"orglang/go-engine/adt/compsem"
 	"orglang/go-engine/adt/compvar"
+	"orglang/go-engine/adt/identity"
 	"orglang/go-engine/adt/uniqsym"
+	"orglang/go-engine/pool/commturn"
+	"orglang/go-engine/pool/termexp"
 )
 
 type Repo interface {
 	AddRec(db.Source, ExecRec) error
 	ModifyRec(db.Source, ExecMod) error
 	GetSnapByRef(db.Source, compsem.SemRef) (ExecSnap2, error)
+	// GetSnapByChnl возвращает снепшот другого текущего держателя канала
+	GetSnapByChnl(db.Source, identity.ADT, compsem.SemRef) (ExecSnap2, error)
 	GetSnapMapByQNs(db.Source, []uniqsym.ADT) (map[uniqsym.ADT]ExecSnap1, error)
+	AddStep(db.Source, StepRec) error
+	// GetSteps возвращает журнал пула в порядке ревизий
+	GetSteps(db.Source, compsem.SemRef) ([]StepRec, error)
 }
 
 type execRec struct {
@@ -35,3 +43,38 @@ type execSnap2 struct {
 	StructVars []compvar.VarRecDS `db:"struct_vars"`
 	LinearVars []compvar.VarRecDS `db:"linear_vars"`
 }
+
+type stepRecDS struct {
+	CompID string            `db:"comp_id"`
+	CompRN int64             `db:"comp_rn"`
+	Exp    termexp.ExpSpecDS `db:"exp" fieldopt:"noexpand"`
+	Snap   stepSnapDS        `db:"snap" fieldopt:"noexpand"`
+}
+
+// то, что шаг увидел и записал, хранится одним документом
+type stepSnapDS struct {
+	StructVars []compvar.VarRecDS `json:"struct_vars"`
+	LinearVars []compvar.VarRecDS `json:"linear_vars"`
+	Exchs      []exchSnapDS       `json:"exchs"`
+	Execs      []execSnap2        `json:"execs"`
+	// nil, если встречного размещения не нашлось
+	Posts []*postSnapDS `json:"posts"`
+	// шаги пишут только линейные переменные
+	Vars []compvar.VarRecDS `json:"vars"`
+}
+
+// обмен в том виде, в каком его увидел шаг
+type exchSnapDS struct {
+	CommID string               `json:"comm_id"`
+	CommRN int64                `json:"comm_rn"`
+	Turns  []commturn.TurnRecDS `json:"turns"`
+}
+
+// встречное размещение в том виде, в каком его отдал рынок труда
+type postSnapDS struct {
+	Side     int16              `json:"side"`
+	CommChnl compvar.VarRecDS   `json:"comm_chnl"`
+	NextVK   int64              `json:"next_vk"`
+	TermQN   string             `json:"term_qn"`
+	ContExp  *termexp.ExpSpecDS `json:"cont_exp"`
+}
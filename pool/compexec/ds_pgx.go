@@ -12,6 +12,7 @@ import (
 	"orglang/go-engine/lib/lf"
 
 	"orglang/go-engine/adt/compsem"
+	"orglang/go-engine/adt/identity"
 	"orglang/go-engine/adt/uniqsym"
 )
 
@@ -72,6 +73,29 @@ func (dao *pgxDAO) GetSnapByRef(source db.Source, ref compsem.SemRef) (ExecSnap2
 	return rec, nil
 }
 
+func (dao *pgxDAO) GetSnapByChnl(source db.Source, chnlID identity.ADT, self compsem.SemRef) (ExecSnap2, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	chnlAttr := slog.Any("chnlID", chnlID)
+	sql, args := dao.qb.selectPeerByChnl(identity.ConvertToString(chnlID), identity.ConvertToString(self.CompID))
+	rows, execErr := ds.Conn.Query(ds.Ctx, sql, args...)
+	if execErr != nil {
+		dao.log.Error("query execution failed", chnlAttr, slog.String("sql", sql))
+		return ExecSnap2{}, execErr
+	}
+	defer rows.Close()
+	peerID, scanErr := pgx.CollectExactlyOneRow(rows, pgx.RowTo[string])
+	if scanErr != nil {
+		dao.log.Error("rows scanning failed", chnlAttr)
+		return ExecSnap2{}, db.ConvertNoRows(scanErr)
+	}
+	compID, convErr := identity.ConvertFromString(peerID)
+	if convErr != nil {
+		dao.log.Error("model conversion failed", chnlAttr)
+		return ExecSnap2{}, convErr
+	}
+	return dao.GetSnapByRef(source, compsem.SemRef{CompID: compID})
+}
+
 func (dao *pgxDAO) GetSnapMapByQNs(source db.Source, termQNs []uniqsym.ADT) (_ map[uniqsym.ADT]ExecSnap1, err error) {
 	ds := db.MustConform[db.SourcePgx](source)
 	dao.log.Log(ds.Ctx, lf.LevelTrace, "getting started", slog.Any("qns", termQNs))
@@ -106,3 +130,40 @@ func (dao *pgxDAO) GetSnapMapByQNs(source db.Source, termQNs []uniqsym.ADT) (_ m
 	dao.log.Log(ds.Ctx, lf.LevelTrace, "getting succeed", slog.Any("dtos", dtos))
 	return DataToSnapMap(dtos)
 }
+
+func (dao *pgxDAO) AddStep(source db.Source, rec StepRec) error {
+	ds := db.MustConform[db.SourcePgx](source)
+	refAttr := slog.Any("ref", rec.CompRef)
+	dto, err := DataFromStepRec(rec)
+	if err != nil {
+		dao.log.Error("model conversion failed", refAttr)
+		return err
+	}
+	sql, args := dao.qb.insertStep(dto)
+	_, err = ds.Conn.Exec(ds.Ctx, sql, args...)
+	if err != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("sql", sql))
+		return err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "insertion succeed", slog.Any("dto", dto))
+	return nil
+}
+
+func (dao *pgxDAO) GetSteps(source db.Source, ref compsem.SemRef) ([]StepRec, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	refAttr := slog.Any("ref", ref)
+	sql, args := dao.qb.selectStepsByID(ref.CompID.String())
+	rows, err := ds.Conn.Query(ds.Ctx, sql, args...)
+	if err != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("sql", sql))
+		return nil, err
+	}
+	defer rows.Close()
+	dtos, err := pgx.CollectRows(rows, pgx.RowToStructByName[stepRecDS])
+	if err != nil {
+		dao.log.Error("rows scanning failed", refAttr)
+		return nil, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "getting succeed", refAttr, slog.Int("steps", len(dtos)))
+	return DataToStepRecs(dtos)
+}
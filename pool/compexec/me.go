@@ -1,10 +1,15 @@
 package compexec
 
 import (
+	"orglang/go-engine/lib/db"
+
 	"orglang/go-engine/pool/compstep"
 )
 
 type Broker interface {
 	Subscribe(api API)
+	// StoreSpecs вызывается в транзакции шага, до ее фиксации
+	StoreSpecs(db.Source, []compstep.StepSpec) error
+	// SendSpec вызывается после фиксации транзакции шага
 	SendSpec(compstep.StepSpec) error
 }
@@ -10,8 +10,13 @@ import (
 	"github.com/orglang/go-sdk/pool/compexec"
 	sdk "github.com/orglang/go-sdk/pool/compstep"
 
+	sdk1 "github.com/orglang/go-sdk/adt/compsem"
+
 	"orglang/go-engine/adt/compsem"
 	"orglang/go-engine/pool/compstep"
+
+	proccompexec "orglang/go-engine/proc/compexec"
+	proccompfuel "orglang/go-engine/proc/compfuel"
 )
 
 // Server-side primary adapter
@@ -29,6 +34,10 @@ func cfgEchoController(server *echo.Echo, controller *echoController) error {
 	server.POST("/api/v1/pools/execs", controller.PostSpec)
 	server.POST("/api/v1/pools/execs/steps", controller.PostSpec2)
 	server.POST("/api/v1/pools/execs/spawns", controller.PostSpec3)
+	server.GET("/api/v1/pools/execs/steps/dead", controller.GetDeadSteps)
+	server.GET("/api/v1/pools/execs/steps/halted", controller.GetHaltedSteps)
+	server.POST("/api/v1/pools/execs/:id/fuel", controller.PostFuel)
+	server.GET("/api/v1/pools/execs/:id/replay", controller.GetReplay)
 	return nil
 }
 
@@ -103,3 +112,62 @@ func (c *echoController) PostSpec3(ctx echo.Context) error {
 	}
 	return ctx.JSON(http.StatusCreated, compsem.MsgFromRef(ref))
 }
+
+func (c *echoController) GetDeadSteps(ctx echo.Context) error {
+	recs, apiErr := c.api.RetrieveDeadSteps()
+	if apiErr != nil {
+		return apiErr
+	}
+	return ctx.JSON(http.StatusOK, ViewFromStepRecs(recs))
+}
+
+func (c *echoController) GetHaltedSteps(ctx echo.Context) error {
+	recs, apiErr := c.api.RetrieveHaltedSteps()
+	if apiErr != nil {
+		return apiErr
+	}
+	return ctx.JSON(http.StatusOK, ViewFromStepRecs(recs))
+}
+
+func (c *echoController) PostFuel(ctx echo.Context) error {
+	var dto proccompfuel.FuelSpecVP
+	bindErr := ctx.Bind(&dto)
+	if bindErr != nil {
+		c.log.Error("binding failed", slog.Any("dto", reflect.TypeFor[proccompfuel.FuelSpecVP]()))
+		return bindErr
+	}
+	validErr := dto.Validate()
+	if validErr != nil {
+		c.log.Error("validation failed", slog.Any("dto", dto))
+		return validErr
+	}
+	spec, convErr := proccompfuel.MsgToFuelSpec(proccompfuel.PoolRealm, dto)
+	if convErr != nil {
+		c.log.Error("conversion failed", slog.Any("dto", dto))
+		return convErr
+	}
+	rec, apiErr := c.api.Refuel(spec)
+	if apiErr != nil {
+		return apiErr
+	}
+	return ctx.JSON(http.StatusOK, proccompfuel.ViewFromFuelRec(rec))
+}
+
+func (c *echoController) GetReplay(ctx echo.Context) error {
+	var dto sdk1.SemRef
+	bindErr := ctx.Bind(&dto)
+	if bindErr != nil {
+		c.log.Error("binding failed", slog.Any("dto", dto))
+		return bindErr
+	}
+	ref, convErr := compsem.MsgToRef(dto)
+	if convErr != nil {
+		c.log.Error("conversion failed", slog.Any("dto", dto))
+		return convErr
+	}
+	rep, apiErr := c.api.Replay(ref)
+	if apiErr != nil {
+		return apiErr
+	}
+	return ctx.JSON(http.StatusOK, proccompexec.ViewFromReplayRep(rep))
+}
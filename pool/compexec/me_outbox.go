@@ -0,0 +1,307 @@
+package compexec
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/fx"
+
+	"orglang/go-engine/lib/db"
+	"orglang/go-engine/lib/wp"
+
+	"orglang/go-engine/adt/identity"
+
+	"orglang/go-engine/pool/compstep"
+
+	proccompfuel "orglang/go-engine/proc/compfuel"
+)
+
+const (
+	// как часто диспетчер проверяет очередь без уведомлений
+	outboxPollInterval = time.Second
+	// сколько шагов забирается за один заход
+	outboxClaimLimit = 16
+	// на сколько шаг скрывается от других диспетчеров на время исполнения
+	outboxLeaseTimeout = time.Minute
+	// на сколько вычисление закрепляется за узлом без продления;
+	// по истечении вычисление умершего узла забирает другой
+	outboxCompLease = 30 * time.Second
+	// канал уведомлений о новых шагах для всех узлов
+	outboxChannel = "pool_comp_steps"
+	// после скольких неудач шаг становится мертвым
+	outboxMaxAttempts = 8
+	outboxMinBackoff  = time.Second
+	outboxMaxBackoff  = 5 * time.Minute
+)
+
+// outboxBroker сохраняет шаги в той же транзакции, что и ходы с переменными,
+// поэтому запланированные продолжения переживают падение процесса;
+// несколько узлов делят очередь, закрепляя за собой вычисления
+type outboxBroker struct {
+	api      API
+	nodeID   identity.ADT
+	stepRepo compstep.Repo
+	operator db.Operator
+	notifier db.Notifier
+	executor wp.Executor
+	wakeup   chan struct{}
+	// взятые шаги, ждущие исполнителя или исполняемые
+	mu   sync.Mutex
+	held map[identity.ADT]struct{}
+	log  *slog.Logger
+}
+
+func newOutboxBroker(
+	stepRepo compstep.Repo,
+	operator db.Operator,
+	notifier db.Notifier,
+	executor wp.Executor,
+	log *slog.Logger,
+) *outboxBroker {
+	name := slog.String("name", reflect.TypeFor[outboxBroker]().Name())
+	nodeID := identity.New()
+	return &outboxBroker{
+		api:      nil,
+		nodeID:   nodeID,
+		stepRepo: stepRepo,
+		operator: operator,
+		notifier: notifier,
+		executor: executor,
+		wakeup:   make(chan struct{}, 1),
+		held:     make(map[identity.ADT]struct{}),
+		log:      log.With(name, slog.Any("node", nodeID)),
+	}
+}
+
+func cfgOutboxBroker(broker *outboxBroker, api API, lc fx.Lifecycle) error {
+	broker.Subscribe(api)
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	lc.Append(
+		fx.Hook{
+			OnStart: func(context.Context) error {
+				payloads, err := broker.notifier.Listen(ctx, outboxChannel)
+				if err != nil {
+					cancel()
+					return err
+				}
+				wg.Go(func() { broker.listen(payloads) })
+				wg.Go(func() { broker.renew(ctx) })
+				wg.Go(func() { broker.dispatch(ctx) })
+				return nil
+			},
+			OnStop: func(stopCtx context.Context) error {
+				cancel()
+				wg.Wait()
+				// вычисления сразу достаются другим узлам, не дожидаясь истечения аренды
+				return broker.operator.Explicit(stopCtx, func(ds db.Source) error {
+					return broker.stepRepo.ReleaseLeases(ds, broker.nodeID)
+				})
+			},
+		},
+	)
+	return nil
+}
+
+// for compilation purposes
+func newOutboxExch() Broker {
+	return new(outboxBroker)
+}
+
+func (b *outboxBroker) Subscribe(api API) {
+	b.api = api
+}
+
+func (b *outboxBroker) StoreSpecs(ds db.Source, specs []compstep.StepSpec) error {
+	recs := make([]compstep.StepRec, 0, len(specs))
+	for _, spec := range specs {
+		recs = append(recs, compstep.StepRec{
+			StepID:   identity.New(),
+			StepSpec: spec,
+			Status:   compstep.PendingStatus,
+		})
+	}
+	if len(recs) == 0 {
+		return nil
+	}
+	err := b.stepRepo.AddRecs(ds, recs)
+	if err != nil {
+		return err
+	}
+	// уведомление разбудит диспетчеры всех узлов после фиксации
+	return b.notifier.Notify(ds, outboxChannel, "")
+}
+
+// шаг уже сохранен, поэтому достаточно разбудить свой диспетчер
+func (b *outboxBroker) SendSpec(compstep.StepSpec) error {
+	b.wake()
+	return nil
+}
+
+func (b *outboxBroker) wake() {
+	select {
+	case b.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+func (b *outboxBroker) listen(payloads <-chan string) {
+	for range payloads {
+		b.wake()
+	}
+}
+
+// продлевает аренду вычислений и взятых шагов, пока узел жив;
+// шаг может ждать в очереди исполнителя дольше своей аренды
+func (b *outboxBroker) renew(ctx context.Context) {
+	ticker := time.NewTicker(outboxCompLease / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		stepIDs := b.heldIDs()
+		renewErr := b.operator.Explicit(ctx, func(ds db.Source) error {
+			err := b.stepRepo.RenewLeases(ds, b.nodeID, outboxCompLease)
+			if err != nil {
+				return err
+			}
+			return b.stepRepo.RenewRecs(ds, stepIDs, outboxLeaseTimeout)
+		})
+		if renewErr != nil {
+			b.log.Error("renewal failed", slog.Any("reason", renewErr))
+		}
+	}
+}
+
+func (b *outboxBroker) dispatch(ctx context.Context) {
+	ticker := time.NewTicker(outboxPollInterval)
+	defer ticker.Stop()
+	for {
+		b.dispatchDue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-b.wakeup:
+		}
+	}
+}
+
+func (b *outboxBroker) dispatchDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		var recs []compstep.StepRec
+		claimErr := b.operator.Explicit(ctx, func(ds db.Source) error {
+			var err error
+			recs, err = b.stepRepo.ClaimRecs(ds, compstep.ClaimQry{
+				NodeID:    b.nodeID,
+				Limit:     outboxClaimLimit,
+				StepLease: outboxLeaseTimeout,
+				CompLease: outboxCompLease,
+			})
+			return err
+		})
+		if claimErr != nil {
+			b.log.Error("claiming failed", slog.Any("reason", claimErr))
+			return
+		}
+		if len(recs) == 0 {
+			return
+		}
+		for _, rec := range recs {
+			b.hold(rec.StepID)
+			// шаги одного вычисления исполняются по порядку, разных - параллельно
+			submitErr := b.executor.Submit(identity.ConvertToString(rec.StepSpec.CompRef.CompID), func() {
+				defer b.unhold(rec.StepID)
+				b.consume(rec)
+			})
+			if submitErr != nil {
+				// шаг останется в очереди и будет взят по истечении аренды
+				b.unhold(rec.StepID)
+				b.log.Error("submission failed", slog.Any("id", rec.StepID), slog.Any("reason", submitErr))
+				return
+			}
+		}
+	}
+}
+
+func (b *outboxBroker) hold(stepID identity.ADT) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.held[stepID] = struct{}{}
+}
+
+func (b *outboxBroker) unhold(stepID identity.ADT) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.held, stepID)
+}
+
+func (b *outboxBroker) heldIDs() []identity.ADT {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return slices.Collect(maps.Keys(b.held))
+}
+
+// запись удаляется в транзакции шага, поэтому ходы фиксируются
+// ровно однажды, даже если шаг успели взять два узла
+func (b *outboxBroker) consume(rec compstep.StepRec) {
+	// учет доводится до конца и после остановки диспетчера
+	ctx := context.Background()
+	refAttr := slog.Any("ref", rec.StepSpec.CompRef)
+	idAttr := slog.Any("id", rec.StepID)
+	apiErr := b.api.Take(rec.StepSpec)
+	if apiErr == nil {
+		b.log.Debug("consumption succeed", idAttr, refAttr)
+		return
+	}
+	if errors.Is(apiErr, compstep.ErrStepTaken) {
+		b.log.Warn("consumption skipped", idAttr, refAttr)
+		return
+	}
+	rec.Reason = apiErr.Error()
+	if errors.Is(apiErr, proccompfuel.ErrOutOfFuel) {
+		// шаг ждет пополнения запаса и не тратит попытки
+		haltErr := b.operator.Explicit(ctx, func(ds db.Source) error {
+			return b.stepRepo.HaltRec(ds, rec)
+		})
+		if haltErr != nil {
+			b.log.Error("halting failed", idAttr, slog.Any("reason", haltErr))
+			return
+		}
+		b.log.Warn("consumption halted", idAttr, refAttr)
+		return
+	}
+	if rec.Attempts >= outboxMaxAttempts {
+		rec.Status = compstep.DeadStatus
+	}
+	deferErr := b.operator.Explicit(ctx, func(ds db.Source) error {
+		return b.stepRepo.DeferRec(ds, rec, outboxBackoff(rec.Attempts))
+	})
+	if deferErr != nil {
+		b.log.Error("deferring failed", idAttr, slog.Any("reason", deferErr))
+		return
+	}
+	b.log.Error("consumption failed", idAttr, refAttr,
+		slog.Int("attempts", rec.Attempts),
+		slog.Any("status", rec.Status),
+		slog.Any("reason", apiErr),
+	)
+}
+
+// экспоненциальная задержка перед следующей попыткой
+func outboxBackoff(attempts int) time.Duration {
+	delay := outboxMinBackoff
+	for i := 1; i < attempts && delay < outboxMaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, outboxMaxBackoff)
+}
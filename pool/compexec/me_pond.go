@@ -2,10 +2,13 @@ package compexec
 
 import (
 	"log/slog"
-	"orglang/go-engine/pool/compstep"
 	"reflect"
 
 	"github.com/alitto/pond/v2"
+
+	"orglang/go-engine/lib/db"
+
+	"orglang/go-engine/pool/compstep"
 )
 
 type pondBroker struct {
@@ -33,6 +36,11 @@ func (b *pondBroker) Subscribe(api API) {
 	b.api = api
 }
 
+// шаги живут только в памяти процесса
+func (b *pondBroker) StoreSpecs(db.Source, []compstep.StepSpec) error {
+	return nil
+}
+
 func (b *pondBroker) SendSpec(spec compstep.StepSpec) error {
 	b.pool.Go(func() {
 		apiErr := b.api.Take(spec)
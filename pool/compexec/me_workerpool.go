@@ -2,10 +2,13 @@ package compexec
 
 import (
 	"log/slog"
-	"orglang/go-engine/pool/compstep"
 	"reflect"
 
 	"github.com/gammazero/workerpool"
+
+	"orglang/go-engine/lib/db"
+
+	"orglang/go-engine/pool/compstep"
 )
 
 type workerPoolBroker struct {
@@ -33,6 +36,11 @@ func (b *workerPoolBroker) Subscribe(api API) {
 	b.api = api
 }
 
+// шаги живут только в памяти процесса
+func (b *workerPoolBroker) StoreSpecs(db.Source, []compstep.StepSpec) error {
+	return nil
+}
+
 func (b *workerPoolBroker) SendSpec(spec compstep.StepSpec) error {
 	b.pool.Submit(func() {
 		apiErr := b.api.Take(spec)
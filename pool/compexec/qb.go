@@ -1,6 +1,8 @@
 package compexec
 
 import (
+	"orglang/go-engine/lib/db"
+
 	"orglang/go-engine/adt/compsem"
 )
 
@@ -9,10 +11,21 @@ const (
 	compExecs      string = "pool_comp_execs "
 	poolStructVars string = "pool_struct_vars "
 	poolLinearVars string = "pool_linear_vars "
+	compJournal    string = "pool_comp_journal "
 )
 
 type queryBuilder interface {
 	insertRec(execRec) (string, []any)
 	selectRecByRef(compsem.SemRefDS) (string, []any)
+	selectPeerByChnl(string, string) (string, []any)
 	selectSnapByQN(string) (string, []any)
+	insertStep(stepRecDS) (string, []any)
+	selectStepsByID(string) (string, []any)
+}
+
+func newDialectBuilder(dialect db.Dialect) queryBuilder {
+	if dialect == db.SQLite {
+		return newSQLiteBuilder()
+	}
+	return newSQLBuilder()
 }
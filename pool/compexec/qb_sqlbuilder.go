@@ -11,6 +11,7 @@ type sqlBuilder struct {
 	recBuilder     *sqlbuilder.Struct
 	snapBuilder    *sqlbuilder.Struct
 	compVarBuilder *sqlbuilder.Struct
+	stepBuilder    *sqlbuilder.Struct
 }
 
 // for compilation purposes
@@ -22,7 +23,8 @@ func newSQLBuilder() *sqlBuilder {
 	recBuilder := sqlbuilder.NewStruct(new(execRec)).For(sqlbuilder.PostgreSQL)
 	snapBuilder := sqlbuilder.NewStruct(new(execSnap1)).For(sqlbuilder.PostgreSQL)
 	compVarBuilder := sqlbuilder.NewStruct(new(compvar.VarRecDS)).For(sqlbuilder.PostgreSQL)
-	return &sqlBuilder{recBuilder, snapBuilder, compVarBuilder}
+	stepBuilder := sqlbuilder.NewStruct(new(stepRecDS)).For(sqlbuilder.PostgreSQL)
+	return &sqlBuilder{recBuilder, snapBuilder, compVarBuilder, stepBuilder}
 }
 
 func (qb *sqlBuilder) insertRec(rec execRec) (string, []any) {
@@ -30,6 +32,10 @@ func (qb *sqlBuilder) insertRec(rec execRec) (string, []any) {
 }
 
 func (qb *sqlBuilder) selectRecByRef(ref compsem.SemRefDS) (string, []any) {
+	return qb.buildRecByRef(ref, arrayAgg)
+}
+
+func (qb *sqlBuilder) buildRecByRef(ref compsem.SemRefDS, agg string) (string, []any) {
 	exec := qb.recBuilder.SelectFrom(compExecs + "exec")
 	structVar := qb.compVarBuilder.SelectFrom(poolStructVars + "var")
 	linearVar := qb.compVarBuilder.SelectFrom(poolLinearVars + "var")
@@ -40,13 +46,32 @@ func (qb *sqlBuilder) selectRecByRef(ref compsem.SemRefDS) (string, []any) {
 	)
 	return exec.With(vars).
 		SelectMore(
-			exec.BuilderAs(sqlbuilder.Buildf(arrayAgg, sqlbuilder.Raw("struct_vars")), "struct_vars"),
-			exec.BuilderAs(sqlbuilder.Buildf(arrayAgg, sqlbuilder.Raw("linear_vars")), "linear_vars"),
+			exec.BuilderAs(sqlbuilder.Buildf(agg, sqlbuilder.Raw("struct_vars")), "struct_vars"),
+			exec.BuilderAs(sqlbuilder.Buildf(agg, sqlbuilder.Raw("linear_vars")), "linear_vars"),
 		).
 		Where(exec.Equal("exec.comp_id", ref.CompID)).
 		Build()
 }
 
+// держатель канала - вычисление, у которого строка с каналом
+// последняя по заглушке; лишенные канала держат уже другую строку
+func (qb *sqlBuilder) selectPeerByChnl(chnlID string, compID string) (string, []any) {
+	later := sqlbuilder.PostgreSQL.NewSelectBuilder()
+	later.Select("1").From(poolLinearVars+"later").Where(
+		"later.comp_id = var.comp_id",
+		"later.chnl_ph = var.chnl_ph",
+		"later.comp_rn > var.comp_rn",
+	)
+	sb := sqlbuilder.PostgreSQL.NewSelectBuilder()
+	return sb.Distinct().Select("var.comp_id").From(poolLinearVars+"var").
+		Where(
+			sb.Equal("var.chnl_id", chnlID),
+			sb.NotEqual("var.comp_id", compID),
+			sb.NotExists(later),
+		).
+		Build()
+}
+
 func (qb *sqlBuilder) selectSnapByQN(qn string) (string, []any) {
 	sb := qb.snapBuilder.SelectFrom(compExecs)
 	return sb.Join(compExecs, "exec.comp_id = sem.comp_id").
@@ -59,6 +84,15 @@ func (qb *sqlBuilder) selectSnapByQN(qn string) (string, []any) {
 		Build()
 }
 
+func (qb *sqlBuilder) insertStep(rec stepRecDS) (string, []any) {
+	return qb.stepBuilder.InsertInto(compJournal, rec).Build()
+}
+
+func (qb *sqlBuilder) selectStepsByID(id string) (string, []any) {
+	sb := qb.stepBuilder.SelectFrom(compJournal)
+	return sb.Where(sb.Equal("comp_id", id)).OrderBy("comp_rn").Build()
+}
+
 const (
 	arrayAgg = "SELECT array_agg(row(r.*)) FROM %s r"
 )
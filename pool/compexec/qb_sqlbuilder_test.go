@@ -17,3 +17,22 @@ func TestSelectSnap(t *testing.T) {
 	sql, _ := qb.selectRecByRef(compsem.SemRefDS{})
 	fmt.Println(sql)
 }
+
+func TestInsertStep(t *testing.T) {
+	qb := newSQLBuilder()
+	sql, _ := qb.insertStep(stepRecDS{})
+	fmt.Println(sql)
+}
+
+func TestSelectStepsByID(t *testing.T) {
+	qb := newSQLBuilder()
+	sql, _ := qb.selectStepsByID("foo")
+	fmt.Println(sql)
+}
+
+func TestSelectPeerByChnl(t *testing.T) {
+	qb := newSQLBuilder()
+	sql, args := qb.selectPeerByChnl("foo", "bar")
+	fmt.Println(sql)
+	fmt.Println(args)
+}
@@ -0,0 +1,25 @@
+package compexec
+
+import (
+	"orglang/go-engine/adt/compsem"
+)
+
+// sqliteBuilder заменяет запросы, у которых нет переносимой формы
+type sqliteBuilder struct {
+	*sqlBuilder
+}
+
+func newSQLiteBuilder() *sqliteBuilder {
+	return &sqliteBuilder{newSQLBuilder()}
+}
+
+func (qb *sqliteBuilder) selectRecByRef(ref compsem.SemRefDS) (string, []any) {
+	return qb.buildRecByRef(ref, sqliteVarsAgg)
+}
+
+const (
+	sqliteVarsAgg = `SELECT json_group_array(json_object(
+	'comp_id', r.comp_id, 'comp_rn', r.comp_rn, 'comm_id', r.comm_id, 'chnl_id', r.chnl_id,
+	'chnl_ph', r.chnl_ph, 'exp_vk', r.exp_vk, 'side', r.side
+)) FROM %s r`
+)
@@ -0,0 +1,13 @@
+package compexec
+
+import (
+	"fmt"
+	"orglang/go-engine/adt/compsem"
+	"testing"
+)
+
+func TestSelectSnapSQLite(t *testing.T) {
+	qb := newSQLiteBuilder()
+	sql, _ := qb.selectRecByRef(compsem.SemRefDS{})
+	fmt.Println(sql)
+}
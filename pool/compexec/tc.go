@@ -1,10 +1,19 @@
 package compexec
 
 import (
+	"orglang/go-engine/adt/commsem"
 	"orglang/go-engine/adt/compsem"
 	"orglang/go-engine/adt/compvar"
+	"orglang/go-engine/adt/identity"
+	"orglang/go-engine/adt/option"
+	"orglang/go-engine/adt/seqnum"
 	"orglang/go-engine/adt/symbol"
+	"orglang/go-engine/adt/uniqsym"
 	"orglang/go-engine/adt/valkey"
+	"orglang/go-engine/pool/commexch"
+	"orglang/go-engine/pool/commturn"
+	"orglang/go-engine/pool/labormkt"
+	"orglang/go-engine/pool/termexp"
 )
 
 func ConvertRecToRef(rec ExecSnap2) compsem.SemRef {
@@ -45,3 +54,194 @@ func DataToExecSnap1(dto execSnap1) (ExecSnap1, error) {
 		panic(compvar.ErrUnexpectedMode(mode))
 	}
 }
+
+func DataFromStepRec(rec StepRec) (stepRecDS, error) {
+	exchs := make([]exchSnapDS, 0, len(rec.ExchSnaps))
+	for _, snap := range rec.ExchSnaps {
+		turns := make([]commturn.TurnRecDS, 0, len(snap.Turns))
+		for _, turn := range snap.Turns {
+			turns = append(turns, commturn.DataFromStepRec(turn))
+		}
+		commRef := commsem.DataFromRef(snap.CommRef)
+		exchs = append(exchs, exchSnapDS{CommID: commRef.CommID, CommRN: commRef.CommRN, Turns: turns})
+	}
+	execs := make([]execSnap2, 0, len(rec.ExecSnaps))
+	for _, snap := range rec.ExecSnaps {
+		execs = append(execs, DataFromExecSnap2(snap))
+	}
+	posts := make([]*postSnapDS, 0, len(rec.Counters))
+	for _, counter := range rec.Counters {
+		if counter.IsEmpty() {
+			posts = append(posts, nil)
+			continue
+		}
+		post := counter.Get()
+		dto := postSnapDS{
+			Side:     int16(post.Side),
+			CommChnl: compvar.DataFromLinearRec(post.CommChnl),
+			NextVK:   valkey.ConvertToInt(post.NextExpVK),
+			TermQN:   uniqsym.ConvertToString(post.ProcTermQN),
+		}
+		if post.ContExp != nil {
+			contExp := termexp.DataFromExpSpec(post.ContExp)
+			dto.ContExp = &contExp
+		}
+		posts = append(posts, &dto)
+	}
+	vars := make([]compvar.VarRecDS, 0, len(rec.Vars))
+	for _, rec := range rec.Vars {
+		vars = append(vars, compvar.DataFromVarRec(rec))
+	}
+	compRef := compsem.DataFromRef(rec.CompRef)
+	return stepRecDS{
+		CompID: compRef.CompID,
+		CompRN: compRef.CompRN,
+		Exp:    termexp.DataFromExpSpec(rec.PoolExp),
+		Snap: stepSnapDS{
+			StructVars: compvar.DataFromStructRecs(rec.StructVars),
+			LinearVars: compvar.DataFromLinearRecs(rec.LinearVars),
+			Exchs:      exchs,
+			Execs:      execs,
+			Posts:      posts,
+			Vars:       vars,
+		},
+	}, nil
+}
+
+func DataToStepRec(dto stepRecDS) (StepRec, error) {
+	compRef, err := compsem.DataToRef(compsem.SemRefDS{CompID: dto.CompID, CompRN: dto.CompRN})
+	if err != nil {
+		return StepRec{}, err
+	}
+	exp, err := termexp.DataToExpSpec(dto.Exp)
+	if err != nil {
+		return StepRec{}, err
+	}
+	structVars, err := compvar.DataToStructRecs(dto.Snap.StructVars)
+	if err != nil {
+		return StepRec{}, err
+	}
+	linearVars, err := compvar.DataToLinearRecs(dto.Snap.LinearVars)
+	if err != nil {
+		return StepRec{}, err
+	}
+	exchs := make([]commexch.ExchSnap, 0, len(dto.Snap.Exchs))
+	for _, exchDTO := range dto.Snap.Exchs {
+		commID, err := identity.ConvertFromString(exchDTO.CommID)
+		if err != nil {
+			return StepRec{}, err
+		}
+		turns := make([]commturn.TurnRec, 0, len(exchDTO.Turns))
+		for _, turnDTO := range exchDTO.Turns {
+			turn, err := commturn.DataToStepRec(turnDTO)
+			if err != nil {
+				return StepRec{}, err
+			}
+			turns = append(turns, turn)
+		}
+		exchs = append(exchs, commexch.ExchSnap{
+			CommRef: commsem.SemRef{CommID: commID, CommRN: seqnum.ConvertFromInt(exchDTO.CommRN)},
+			Turns:   turns,
+		})
+	}
+	execs := make([]ExecSnap2, 0, len(dto.Snap.Execs))
+	for _, execDTO := range dto.Snap.Execs {
+		snap, err := DataToExecSnap2(execDTO)
+		if err != nil {
+			return StepRec{}, err
+		}
+		execs = append(execs, snap)
+	}
+	counters := make([]option.ADT[labormkt.PostRec], 0, len(dto.Snap.Posts))
+	for _, postDTO := range dto.Snap.Posts {
+		if postDTO == nil {
+			counters = append(counters, option.None[labormkt.PostRec]())
+			continue
+		}
+		post, err := dataToPost(*postDTO)
+		if err != nil {
+			return StepRec{}, err
+		}
+		counters = append(counters, option.Some(post))
+	}
+	vars := make([]compvar.VarRec, 0, len(dto.Snap.Vars))
+	for _, varDTO := range dto.Snap.Vars {
+		linearVar, err := compvar.DataToLinearRec(varDTO)
+		if err != nil {
+			return StepRec{}, err
+		}
+		vars = append(vars, linearVar)
+	}
+	return StepRec{
+		CompRef:    compRef,
+		PoolExp:    exp,
+		StructVars: structVars,
+		LinearVars: linearVars,
+		ExchSnaps:  exchs,
+		ExecSnaps:  execs,
+		Counters:   counters,
+		Vars:       vars,
+	}, nil
+}
+
+func DataToStepRecs(dtos []stepRecDS) ([]StepRec, error) {
+	recs := make([]StepRec, 0, len(dtos))
+	for _, dto := range dtos {
+		rec, err := DataToStepRec(dto)
+		if err != nil {
+			return nil, err
+		}
+		recs = append(recs, rec)
+	}
+	return recs, nil
+}
+
+func dataToPost(dto postSnapDS) (labormkt.PostRec, error) {
+	commChnl, err := compvar.DataToLinearRec(dto.CommChnl)
+	if err != nil {
+		return labormkt.PostRec{}, err
+	}
+	termQN, err := uniqsym.ConvertFromString(dto.TermQN)
+	if err != nil {
+		return labormkt.PostRec{}, err
+	}
+	nextExpVK, err := valkey.ConvertFromInt(dto.NextVK)
+	if err != nil {
+		return labormkt.PostRec{}, err
+	}
+	var contExp termexp.ExpSpec
+	if dto.ContExp != nil {
+		contExp, err = termexp.DataToExpSpec(*dto.ContExp)
+		if err != nil {
+			return labormkt.PostRec{}, err
+		}
+	}
+	return labormkt.PostRec{
+		Side:       labormkt.Side(dto.Side),
+		CommChnl:   commChnl,
+		NextExpVK:  nextExpVK,
+		ProcTermQN: termQN,
+		ContExp:    contExp,
+	}, nil
+}
+
+// шаг пишет переменные нескольких пулов
+type varKey struct {
+	compID string
+	chnlPH string
+}
+
+func indexVars(recs []compvar.VarRec) map[varKey]compvar.VarRec {
+	indexed := make(map[varKey]compvar.VarRec, len(recs))
+	for _, rec := range recs {
+		dto := compvar.DataFromVarRec(rec)
+		indexed[varKey{dto.CompID.String, dto.ChnlPH.String}] = rec
+	}
+	return indexed
+}
+
+func isSameVar(want, got compvar.VarRec) bool {
+	wantDTO := compvar.DataFromVarRec(want)
+	gotDTO := compvar.DataFromVarRec(got)
+	return gotDTO.ExpVK == wantDTO.ExpVK && gotDTO.ChnlBS == wantDTO.ChnlBS
+}
@@ -0,0 +1,33 @@
+package compexec
+
+import (
+	"time"
+
+	sdk "github.com/orglang/go-sdk/pool/compstep"
+
+	"orglang/go-engine/adt/identity"
+	"orglang/go-engine/pool/compstep"
+)
+
+// шаг очереди, исчерпавший попытки или остановленный без запаса
+type StepRecVP struct {
+	StepID   string       `json:"id"`
+	StepSpec sdk.StepSpec `json:"step"`
+	Attempts int          `json:"attempts"`
+	DueAt    time.Time    `json:"due_at"`
+	Reason   string       `json:"reason"`
+}
+
+func ViewFromStepRecs(recs []compstep.StepRec) []StepRecVP {
+	views := make([]StepRecVP, 0, len(recs))
+	for _, rec := range recs {
+		views = append(views, StepRecVP{
+			StepID:   identity.ConvertToString(rec.StepID),
+			StepSpec: compstep.MsgFromStepSpec(rec.StepSpec),
+			Attempts: rec.Attempts,
+			DueAt:    rec.DueAt,
+			Reason:   rec.Reason,
+		})
+	}
+	return views
+}
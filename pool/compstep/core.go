@@ -1,11 +1,67 @@
 package compstep
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	"orglang/go-engine/adt/compsem"
+	"orglang/go-engine/adt/identity"
 	"orglang/go-engine/pool/termexp"
+
+	proctermexp "orglang/go-engine/proc/termexp"
 )
 
 type StepSpec struct {
 	CompRef compsem.SemRef
 	PoolExp termexp.ExpSpec
+	// тело процесса, порожденного пулом; такой шаг берет
+	// исполнитель процессов, а пул только хранит его в очереди
+	ProcExp proctermexp.ExpSpec
+	// запись очереди, из которой взят шаг; пуст при вызове по API
+	StepID identity.ADT
+}
+
+// запланированный шаг в исходящей очереди (outbox)
+type StepRec struct {
+	StepID   identity.ADT
+	StepSpec StepSpec
+	Status   Status
+	// число уже предпринятых попыток
+	Attempts int
+	// не раньше какого момента шаг можно забрать
+	DueAt time.Time
+	// причина последней неудачи
+	Reason string
+}
+
+// запрос на аренду готовых шагов узлом
+type ClaimQry struct {
+	// узел, который будет исполнять шаги
+	NodeID identity.ADT
+	Limit  int
+	// на сколько шаг скрывается от других диспетчеров
+	StepLease time.Duration
+	// на сколько вычисление закрепляется за узлом; шаги вычисления,
+	// закрепленного за другим живым узлом, не выдаются
+	CompLease time.Duration
+}
+
+type Status int16
+
+const (
+	nonStatus Status = iota
+	// ожидает исполнения
+	PendingStatus
+	// исчерпал попытки и ждет разбора
+	DeadStatus
+	// вычисление исчерпало запас шагов и ждет пополнения
+	HaltedStatus
+)
+
+// запись уже удалена: шаг исполнил другой узел после истечения аренды
+var ErrStepTaken = errors.New("step already taken")
+
+func ErrStatusUnexpected(got Status) error {
+	return fmt.Errorf("status unexpected: %v", got)
 }
@@ -0,0 +1,15 @@
+package compstep
+
+import (
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module("pool/compstep",
+	fx.Provide(
+		fx.Annotate(newPgxDAO, fx.As(new(Repo))),
+	),
+	fx.Provide(
+		fx.Private,
+		newDialectBuilder,
+	),
+)
@@ -0,0 +1,57 @@
+package compstep
+
+import (
+	"time"
+
+	"orglang/go-engine/lib/db"
+
+	"orglang/go-engine/adt/identity"
+
+	proctermexp "orglang/go-engine/proc/termexp"
+)
+
+type Repo interface {
+	AddRecs(db.Source, []StepRec) error
+	// ClaimRecs забирает готовые шаги в аренду: пока аренда не истекла,
+	// другие диспетчеры их не увидят
+	ClaimRecs(db.Source, ClaimQry) ([]StepRec, error)
+	// DeferRec откладывает шаг после неудачи или переводит в мертвые
+	DeferRec(db.Source, StepRec, time.Duration) error
+	// RemoveRec удаляет исполненный шаг; отсутствие записи дает ErrStepTaken
+	RemoveRec(db.Source, identity.ADT) error
+	// HaltRec останавливает шаг до пополнения запаса; попытка не засчитывается
+	HaltRec(db.Source, StepRec) error
+	// ResumeRecs возвращает в очередь остановленные шаги вычисления
+	ResumeRecs(db.Source, identity.ADT) error
+	SelectRecsByStatus(db.Source, Status) ([]StepRec, error)
+	// RenewLeases продлевает аренду вычислений, удерживаемых узлом
+	RenewLeases(db.Source, identity.ADT, time.Duration) error
+	// RenewRecs продлевает аренду взятых, но еще не исполненных шагов
+	RenewRecs(db.Source, []identity.ADT, time.Duration) error
+	// ReleaseLeases отпускает вычисления узла для других узлов
+	ReleaseLeases(db.Source, identity.ADT) error
+}
+
+type stepRecDS struct {
+	StepID   string    `db:"step_id"`
+	CompID   string    `db:"comp_id"`
+	Spec     []byte    `db:"spec"`
+	Status   int16     `db:"status"`
+	Attempts int       `db:"attempts"`
+	DueAt    time.Time `db:"due_at"`
+	Reason   string    `db:"reason"`
+	// у тел порожденных процессов спецификации пула нет
+	Body *bodySpecDS `db:"body"`
+}
+
+type bodySpecDS struct {
+	CompRN int64                 `json:"rn"`
+	Exp    proctermexp.ExpSpecDS `json:"exp"`
+}
+
+type claimQryDS struct {
+	NodeID    string
+	Limit     int
+	StepLease time.Duration
+	CompLease time.Duration
+}
@@ -0,0 +1,207 @@
+package compstep
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"orglang/go-engine/lib/db"
+	"orglang/go-engine/lib/lf"
+
+	"orglang/go-engine/adt/identity"
+)
+
+type pgxDAO struct {
+	qb  queryBuilder
+	log *slog.Logger
+}
+
+func newPgxDAO(qb queryBuilder, log *slog.Logger) *pgxDAO {
+	name := slog.String("name", reflect.TypeFor[pgxDAO]().Name())
+	return &pgxDAO{qb, log.With(name)}
+}
+
+// for compilation purposes
+func newRepo() Repo {
+	return new(pgxDAO)
+}
+
+func (dao *pgxDAO) AddRecs(source db.Source, recs []StepRec) (err error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	if len(recs) == 0 {
+		return nil
+	}
+	batch := pgx.Batch{}
+	for _, rec := range recs {
+		dto, convErr := dataFromStepRec(rec)
+		if convErr != nil {
+			dao.log.Error("model conversion failed", slog.Any("rec", rec))
+			return convErr
+		}
+		sql, args := dao.qb.insertRec(dto)
+		batch.Queue(sql, args...)
+	}
+	br := ds.Conn.SendBatch(ds.Ctx, &batch)
+	defer func() {
+		err = errors.Join(err, br.Close())
+	}()
+	for _, rec := range recs {
+		_, readErr := br.Exec()
+		if readErr != nil {
+			dao.log.Error("query execution failed", slog.Any("rec", rec))
+			return readErr
+		}
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "insertion succeed", slog.Int("count", len(recs)))
+	return nil
+}
+
+func (dao *pgxDAO) ClaimRecs(source db.Source, qry ClaimQry) ([]StepRec, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	sql, args := dao.qb.claimRecs(claimQryDS{
+		NodeID:    identity.ConvertToString(qry.NodeID),
+		Limit:     qry.Limit,
+		StepLease: qry.StepLease,
+		CompLease: qry.CompLease,
+	})
+	rows, execErr := ds.Conn.Query(ds.Ctx, sql, args...)
+	if execErr != nil {
+		dao.log.Error("query execution failed", slog.String("sql", sql))
+		return nil, execErr
+	}
+	defer rows.Close()
+	dtos, scanErr := pgx.CollectRows(rows, pgx.RowToStructByName[stepRecDS])
+	if scanErr != nil {
+		dao.log.Error("rows scanning failed")
+		return nil, scanErr
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "claiming succeed", slog.Int("count", len(dtos)))
+	return dataToStepRecs(dtos)
+}
+
+func (dao *pgxDAO) DeferRec(source db.Source, rec StepRec, delay time.Duration) error {
+	ds := db.MustConform[db.SourcePgx](source)
+	dto, convErr := dataFromStepRec(rec)
+	if convErr != nil {
+		dao.log.Error("model conversion failed", slog.Any("rec", rec))
+		return convErr
+	}
+	idAttr := slog.String("id", dto.StepID)
+	sql, args := dao.qb.deferRec(dto, delay)
+	_, execErr := ds.Conn.Exec(ds.Ctx, sql, args...)
+	if execErr != nil {
+		dao.log.Error("query execution failed", idAttr, slog.String("sql", sql))
+		return execErr
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "deferring succeed", idAttr)
+	return nil
+}
+
+func (dao *pgxDAO) RemoveRec(source db.Source, stepID identity.ADT) error {
+	ds := db.MustConform[db.SourcePgx](source)
+	idAttr := slog.Any("id", stepID)
+	sql, args := dao.qb.deleteRec(identity.ConvertToString(stepID))
+	tag, execErr := ds.Conn.Exec(ds.Ctx, sql, args...)
+	if execErr != nil {
+		dao.log.Error("query execution failed", idAttr, slog.String("sql", sql))
+		return execErr
+	}
+	if tag.RowsAffected() == 0 {
+		dao.log.Warn("entity removal failed", idAttr)
+		return fmt.Errorf("%w: %v", ErrStepTaken, stepID)
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "removal succeed", idAttr)
+	return nil
+}
+
+func (dao *pgxDAO) HaltRec(source db.Source, rec StepRec) error {
+	ds := db.MustConform[db.SourcePgx](source)
+	idAttr := slog.Any("id", rec.StepID)
+	sql, args := dao.qb.haltRec(stepRecDS{StepID: identity.ConvertToString(rec.StepID), Reason: rec.Reason})
+	_, execErr := ds.Conn.Exec(ds.Ctx, sql, args...)
+	if execErr != nil {
+		dao.log.Error("query execution failed", idAttr, slog.String("sql", sql))
+		return execErr
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "halting succeed", idAttr)
+	return nil
+}
+
+func (dao *pgxDAO) ResumeRecs(source db.Source, compID identity.ADT) error {
+	ds := db.MustConform[db.SourcePgx](source)
+	idAttr := slog.Any("comp", compID)
+	sql, args := dao.qb.resumeRecs(identity.ConvertToString(compID))
+	tag, execErr := ds.Conn.Exec(ds.Ctx, sql, args...)
+	if execErr != nil {
+		dao.log.Error("query execution failed", idAttr, slog.String("sql", sql))
+		return execErr
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "resumption succeed", idAttr, slog.Int64("count", tag.RowsAffected()))
+	return nil
+}
+
+func (dao *pgxDAO) SelectRecsByStatus(source db.Source, status Status) ([]StepRec, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	sql, args := dao.qb.selectRecsByStatus(int16(status))
+	rows, execErr := ds.Conn.Query(ds.Ctx, sql, args...)
+	if execErr != nil {
+		dao.log.Error("query execution failed", slog.String("sql", sql))
+		return nil, execErr
+	}
+	defer rows.Close()
+	dtos, scanErr := pgx.CollectRows(rows, pgx.RowToStructByName[stepRecDS])
+	if scanErr != nil {
+		dao.log.Error("rows scanning failed")
+		return nil, scanErr
+	}
+	return dataToStepRecs(dtos)
+}
+
+func (dao *pgxDAO) RenewLeases(source db.Source, nodeID identity.ADT, ttl time.Duration) error {
+	ds := db.MustConform[db.SourcePgx](source)
+	idAttr := slog.Any("node", nodeID)
+	sql, args := dao.qb.updateLeases(identity.ConvertToString(nodeID), ttl)
+	_, execErr := ds.Conn.Exec(ds.Ctx, sql, args...)
+	if execErr != nil {
+		dao.log.Error("query execution failed", idAttr, slog.String("sql", sql))
+		return execErr
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "renewal succeed", idAttr)
+	return nil
+}
+
+func (dao *pgxDAO) RenewRecs(source db.Source, stepIDs []identity.ADT, ttl time.Duration) error {
+	if len(stepIDs) == 0 {
+		return nil
+	}
+	ds := db.MustConform[db.SourcePgx](source)
+	ids := make([]string, 0, len(stepIDs))
+	for _, stepID := range stepIDs {
+		ids = append(ids, identity.ConvertToString(stepID))
+	}
+	sql, args := dao.qb.updateRecs(ids, ttl)
+	_, execErr := ds.Conn.Exec(ds.Ctx, sql, args...)
+	if execErr != nil {
+		dao.log.Error("query execution failed", slog.String("sql", sql))
+		return execErr
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "renewal succeed", slog.Int("count", len(ids)))
+	return nil
+}
+
+func (dao *pgxDAO) ReleaseLeases(source db.Source, nodeID identity.ADT) error {
+	ds := db.MustConform[db.SourcePgx](source)
+	idAttr := slog.Any("node", nodeID)
+	sql, args := dao.qb.deleteLeases(identity.ConvertToString(nodeID))
+	_, execErr := ds.Conn.Exec(ds.Ctx, sql, args...)
+	if execErr != nil {
+		dao.log.Error("query execution failed", idAttr, slog.String("sql", sql))
+		return execErr
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "release succeed", idAttr)
+	return nil
+}
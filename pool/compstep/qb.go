@@ -0,0 +1,32 @@
+package compstep
+
+import (
+	"time"
+
+	"orglang/go-engine/lib/db"
+)
+
+const (
+	compSteps  string = "pool_comp_steps"
+	compLeases string = "pool_comp_leases"
+)
+
+type queryBuilder interface {
+	insertRec(stepRecDS) (string, []any)
+	claimRecs(claimQryDS) (string, []any)
+	deferRec(stepRecDS, time.Duration) (string, []any)
+	deleteRec(string) (string, []any)
+	haltRec(stepRecDS) (string, []any)
+	resumeRecs(string) (string, []any)
+	selectRecsByStatus(int16) (string, []any)
+	updateLeases(string, time.Duration) (string, []any)
+	updateRecs([]string, time.Duration) (string, []any)
+	deleteLeases(string) (string, []any)
+}
+
+func newDialectBuilder(dialect db.Dialect) queryBuilder {
+	if dialect == db.SQLite {
+		return newSQLiteBuilder()
+	}
+	return newSQLBuilder()
+}
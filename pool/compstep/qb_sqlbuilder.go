@@ -0,0 +1,133 @@
+package compstep
+
+import (
+	"strings"
+	"time"
+
+	"github.com/huandu/go-sqlbuilder"
+)
+
+type sqlBuilder struct {
+	recBuilder *sqlbuilder.Struct
+}
+
+// for compilation purposes
+func newQueryBuilder() queryBuilder {
+	return new(sqlBuilder)
+}
+
+func newSQLBuilder() *sqlBuilder {
+	recBuilder := sqlbuilder.NewStruct(new(stepRecDS)).For(sqlbuilder.PostgreSQL)
+	return &sqlBuilder{recBuilder}
+}
+
+func (qb *sqlBuilder) insertRec(rec stepRecDS) (string, []any) {
+	ib := qb.recBuilder.InsertInto(compSteps, rec)
+	return ib.Build()
+}
+
+// параллельные диспетчеры пропускают чужие блокировки, а сдвиг due_at
+// работает как аренда шага на время исполнения; вычисление при этом
+// закрепляется за узлом, и шаги чужих живых вычислений не выдаются
+func (qb *sqlBuilder) claimRecs(qry claimQryDS) (string, []any) {
+	return sqlbuilder.WithFlavor(sqlbuilder.Buildf(claimTmpl,
+		int16(PendingStatus),
+		qry.NodeID,
+		qry.Limit,
+		qry.NodeID,
+		qry.CompLease,
+		qry.StepLease,
+		sqlbuilder.Raw(strings.Join(qb.recBuilder.Columns(), ", ")),
+	), sqlbuilder.PostgreSQL).Build()
+}
+
+func (qb *sqlBuilder) deferRec(rec stepRecDS, delay time.Duration) (string, []any) {
+	ub := sqlbuilder.PostgreSQL.NewUpdateBuilder()
+	return ub.Update(compSteps).
+		Set(
+			ub.Assign("status", rec.Status),
+			ub.Assign("reason", rec.Reason),
+			"due_at = now() + "+ub.Var(delay)+"::interval",
+		).
+		Where(ub.Equal("step_id", rec.StepID)).
+		Build()
+}
+
+func (qb *sqlBuilder) deleteRec(stepID string) (string, []any) {
+	del := sqlbuilder.PostgreSQL.NewDeleteBuilder()
+	return del.DeleteFrom(compSteps).Where(del.Equal("step_id", stepID)).Build()
+}
+
+func (qb *sqlBuilder) haltRec(rec stepRecDS) (string, []any) {
+	ub := sqlbuilder.PostgreSQL.NewUpdateBuilder()
+	return ub.Update(compSteps).
+		Set(
+			ub.Assign("status", int16(HaltedStatus)),
+			ub.Assign("reason", rec.Reason),
+			ub.Decr("attempts"),
+		).
+		Where(ub.Equal("step_id", rec.StepID)).
+		Build()
+}
+
+func (qb *sqlBuilder) resumeRecs(compID string) (string, []any) {
+	ub := sqlbuilder.PostgreSQL.NewUpdateBuilder()
+	return ub.Update(compSteps).
+		Set(
+			ub.Assign("status", int16(PendingStatus)),
+			"due_at = now()",
+		).
+		Where(ub.Equal("comp_id", compID), ub.Equal("status", int16(HaltedStatus))).
+		Build()
+}
+
+func (qb *sqlBuilder) selectRecsByStatus(status int16) (string, []any) {
+	sb := qb.recBuilder.SelectFrom(compSteps)
+	return sb.Where(sb.Equal("status", status)).OrderBy("due_at").Build()
+}
+
+func (qb *sqlBuilder) updateLeases(nodeID string, ttl time.Duration) (string, []any) {
+	ub := sqlbuilder.PostgreSQL.NewUpdateBuilder()
+	return ub.Update(compLeases).
+		Set("expires_at = now() + " + ub.Var(ttl) + "::interval").
+		Where(ub.Equal("node_id", nodeID)).
+		Build()
+}
+
+// сдвиг due_at продлевает аренду шага, ждущего исполнителя
+func (qb *sqlBuilder) updateRecs(stepIDs []string, ttl time.Duration) (string, []any) {
+	ub := sqlbuilder.PostgreSQL.NewUpdateBuilder()
+	return ub.Update(compSteps).
+		Set("due_at = now() + "+ub.Var(ttl)+"::interval").
+		Where(ub.In("step_id", sqlbuilder.Flatten(stepIDs)...), ub.Equal("status", int16(PendingStatus))).
+		Build()
+}
+
+func (qb *sqlBuilder) deleteLeases(nodeID string) (string, []any) {
+	del := sqlbuilder.PostgreSQL.NewDeleteBuilder()
+	return del.DeleteFrom(compLeases).Where(del.Equal("node_id", nodeID)).Build()
+}
+
+const (
+	claimTmpl = `WITH due AS (
+	SELECT step.step_id, step.comp_id
+	FROM pool_comp_steps step
+	LEFT JOIN pool_comp_leases lease ON lease.comp_id = step.comp_id
+	WHERE step.status = %v AND step.due_at <= now()
+		AND (lease.comp_id IS NULL OR lease.node_id = %v::varchar OR lease.expires_at < now())
+	ORDER BY step.due_at
+	LIMIT %v
+	FOR UPDATE OF step SKIP LOCKED
+), leased AS (
+	INSERT INTO pool_comp_leases (comp_id, node_id, expires_at)
+	SELECT DISTINCT comp_id, %v::varchar, now() + %v::interval FROM due
+	ON CONFLICT (comp_id) DO UPDATE
+	SET node_id = excluded.node_id, expires_at = excluded.expires_at
+	WHERE pool_comp_leases.node_id = excluded.node_id OR pool_comp_leases.expires_at < now()
+	RETURNING comp_id
+)
+UPDATE pool_comp_steps
+SET attempts = attempts + 1, due_at = now() + %v::interval
+WHERE step_id IN (SELECT step_id FROM due WHERE comp_id IN (SELECT comp_id FROM leased))
+RETURNING %v`
+)
@@ -0,0 +1,42 @@
+package compstep
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestClaimRecs(t *testing.T) {
+	qb := newSQLBuilder()
+	sql, args := qb.claimRecs(claimQryDS{NodeID: "node", Limit: 10, StepLease: time.Minute, CompLease: time.Minute})
+	fmt.Println(sql)
+	fmt.Println(args)
+}
+
+func TestDeferRec(t *testing.T) {
+	qb := newSQLBuilder()
+	sql, args := qb.deferRec(stepRecDS{}, time.Second)
+	fmt.Println(sql)
+	fmt.Println(args)
+}
+
+func TestHaltRec(t *testing.T) {
+	qb := newSQLBuilder()
+	sql, args := qb.haltRec(stepRecDS{})
+	fmt.Println(sql)
+	fmt.Println(args)
+}
+
+func TestResumeRecs(t *testing.T) {
+	qb := newSQLBuilder()
+	sql, args := qb.resumeRecs("foo")
+	fmt.Println(sql)
+	fmt.Println(args)
+}
+
+func TestUpdateRecs(t *testing.T) {
+	qb := newSQLBuilder()
+	sql, args := qb.updateRecs([]string{"a", "b"}, time.Minute)
+	fmt.Println(sql)
+	fmt.Println(args)
+}
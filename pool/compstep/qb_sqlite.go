@@ -0,0 +1,86 @@
+package compstep
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/huandu/go-sqlbuilder"
+)
+
+// sqliteBuilder заменяет запросы, у которых нет переносимой формы
+type sqliteBuilder struct {
+	*sqlBuilder
+}
+
+func newSQLiteBuilder() *sqliteBuilder {
+	return &sqliteBuilder{newSQLBuilder()}
+}
+
+// встроенное хранилище обслуживает один узел, а писатели SQLite
+// сериализуются, поэтому ни пропуска блокировок, ни аренды вычислений
+// не требуется: остается только аренда шагов
+func (qb *sqliteBuilder) claimRecs(qry claimQryDS) (string, []any) {
+	return sqlbuilder.Build(sqliteClaimTmpl,
+		shiftModifier(qry.StepLease),
+		int16(PendingStatus),
+		qry.Limit,
+		sqlbuilder.Raw(strings.Join(qb.recBuilder.Columns(), ", ")),
+	).BuildWithFlavor(sqlbuilder.PostgreSQL)
+}
+
+func (qb *sqliteBuilder) deferRec(rec stepRecDS, delay time.Duration) (string, []any) {
+	ub := sqlbuilder.PostgreSQL.NewUpdateBuilder()
+	return ub.Update(compSteps).
+		Set(
+			ub.Assign("status", rec.Status),
+			ub.Assign("reason", rec.Reason),
+			"due_at = strftime('%Y-%m-%d %H:%M:%f', 'now', "+ub.Var(shiftModifier(delay))+")",
+		).
+		Where(ub.Equal("step_id", rec.StepID)).
+		Build()
+}
+
+func (qb *sqliteBuilder) resumeRecs(compID string) (string, []any) {
+	ub := sqlbuilder.PostgreSQL.NewUpdateBuilder()
+	return ub.Update(compSteps).
+		Set(
+			ub.Assign("status", int16(PendingStatus)),
+			"due_at = strftime('%Y-%m-%d %H:%M:%f', 'now')",
+		).
+		Where(ub.Equal("comp_id", compID), ub.Equal("status", int16(HaltedStatus))).
+		Build()
+}
+
+func (qb *sqliteBuilder) updateLeases(nodeID string, ttl time.Duration) (string, []any) {
+	ub := sqlbuilder.PostgreSQL.NewUpdateBuilder()
+	return ub.Update(compLeases).
+		Set("expires_at = strftime('%Y-%m-%d %H:%M:%f', 'now', " + ub.Var(shiftModifier(ttl)) + ")").
+		Where(ub.Equal("node_id", nodeID)).
+		Build()
+}
+
+func (qb *sqliteBuilder) updateRecs(stepIDs []string, ttl time.Duration) (string, []any) {
+	ub := sqlbuilder.PostgreSQL.NewUpdateBuilder()
+	return ub.Update(compSteps).
+		Set("due_at = strftime('%Y-%m-%d %H:%M:%f', 'now', "+ub.Var(shiftModifier(ttl))+")").
+		Where(ub.In("step_id", sqlbuilder.Flatten(stepIDs)...), ub.Equal("status", int16(PendingStatus))).
+		Build()
+}
+
+// модификатор функций дат SQLite вместо интервала postgres
+func shiftModifier(d time.Duration) string {
+	return fmt.Sprintf("%+.3f seconds", d.Seconds())
+}
+
+const (
+	sqliteClaimTmpl = `UPDATE pool_comp_steps
+SET attempts = attempts + 1, due_at = strftime('%Y-%m-%d %H:%M:%f', 'now', $?)
+WHERE step_id IN (
+	SELECT step_id FROM pool_comp_steps
+	WHERE status = $? AND due_at <= strftime('%Y-%m-%d %H:%M:%f', 'now')
+	ORDER BY due_at
+	LIMIT $?
+)
+RETURNING $?`
+)
@@ -0,0 +1,35 @@
+package compstep
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestClaimRecsSQLite(t *testing.T) {
+	qb := newSQLiteBuilder()
+	sql, args := qb.claimRecs(claimQryDS{NodeID: "node", Limit: 10, StepLease: time.Minute, CompLease: time.Minute})
+	fmt.Println(sql)
+	fmt.Println(args)
+}
+
+func TestDeferRecSQLite(t *testing.T) {
+	qb := newSQLiteBuilder()
+	sql, args := qb.deferRec(stepRecDS{}, time.Second)
+	fmt.Println(sql)
+	fmt.Println(args)
+}
+
+func TestResumeRecsSQLite(t *testing.T) {
+	qb := newSQLiteBuilder()
+	sql, args := qb.resumeRecs("foo")
+	fmt.Println(sql)
+	fmt.Println(args)
+}
+
+func TestUpdateRecsSQLite(t *testing.T) {
+	qb := newSQLiteBuilder()
+	sql, args := qb.updateRecs([]string{"a", "b"}, time.Minute)
+	fmt.Println(sql)
+	fmt.Println(args)
+}
@@ -0,0 +1,113 @@
+package compstep
+
+import (
+	"encoding/json"
+
+	"github.com/orglang/go-sdk/pool/compstep"
+
+	"orglang/go-engine/adt/compsem"
+	"orglang/go-engine/adt/identity"
+	"orglang/go-engine/adt/seqnum"
+
+	proctermexp "orglang/go-engine/proc/termexp"
+)
+
+// спецификация хранится в том же виде, в каком приходит по API
+func dataFromStepRec(rec StepRec) (stepRecDS, error) {
+	if rec.StepSpec.ProcExp != nil {
+		return dataFromBodyRec(rec)
+	}
+	spec, err := json.Marshal(MsgFromStepSpec(rec.StepSpec))
+	if err != nil {
+		return stepRecDS{}, err
+	}
+	return stepRecDS{
+		StepID:   identity.ConvertToString(rec.StepID),
+		CompID:   identity.ConvertToString(rec.StepSpec.CompRef.CompID),
+		Spec:     spec,
+		Status:   int16(rec.Status),
+		Attempts: rec.Attempts,
+		DueAt:    rec.DueAt,
+		Reason:   rec.Reason,
+	}, nil
+}
+
+func dataToStepRec(dto stepRecDS) (StepRec, error) {
+	stepID, err := identity.ConvertFromString(dto.StepID)
+	if err != nil {
+		return StepRec{}, err
+	}
+	if dto.Body != nil {
+		return dataToBodyRec(stepID, dto)
+	}
+	var msg compstep.StepSpec
+	err = json.Unmarshal(dto.Spec, &msg)
+	if err != nil {
+		return StepRec{}, err
+	}
+	spec, err := MsgToStepSpec(msg)
+	if err != nil {
+		return StepRec{}, err
+	}
+	spec.StepID = stepID
+	return StepRec{
+		StepID:   stepID,
+		StepSpec: spec,
+		Status:   Status(dto.Status),
+		Attempts: dto.Attempts,
+		DueAt:    dto.DueAt,
+		Reason:   dto.Reason,
+	}, nil
+}
+
+// в API тел процессов нет, поэтому они хранятся в своем виде
+func dataFromBodyRec(rec StepRec) (stepRecDS, error) {
+	exp, err := proctermexp.DataFromExpSpec(rec.StepSpec.ProcExp)
+	if err != nil {
+		return stepRecDS{}, err
+	}
+	return stepRecDS{
+		StepID:   identity.ConvertToString(rec.StepID),
+		CompID:   identity.ConvertToString(rec.StepSpec.CompRef.CompID),
+		Body:     &bodySpecDS{CompRN: seqnum.ConvertToInt(rec.StepSpec.CompRef.CompRN), Exp: exp},
+		Status:   int16(rec.Status),
+		Attempts: rec.Attempts,
+		DueAt:    rec.DueAt,
+		Reason:   rec.Reason,
+	}, nil
+}
+
+func dataToBodyRec(stepID identity.ADT, dto stepRecDS) (StepRec, error) {
+	compID, err := identity.ConvertFromString(dto.CompID)
+	if err != nil {
+		return StepRec{}, err
+	}
+	exp, err := proctermexp.DataToExpSpec(dto.Body.Exp)
+	if err != nil {
+		return StepRec{}, err
+	}
+	return StepRec{
+		StepID: stepID,
+		StepSpec: StepSpec{
+			CompRef: compsem.SemRef{CompID: compID, CompRN: seqnum.ConvertFromInt(dto.Body.CompRN)},
+			ProcExp: exp,
+			StepID:  stepID,
+		},
+		Status:   Status(dto.Status),
+		Attempts: dto.Attempts,
+		DueAt:    dto.DueAt,
+		Reason:   dto.Reason,
+	}, nil
+}
+
+func dataToStepRecs(dtos []stepRecDS) ([]StepRec, error) {
+	recs := make([]StepRec, 0, len(dtos))
+	for _, dto := range dtos {
+		rec, err := dataToStepRec(dto)
+		if err != nil {
+			return nil, err
+		}
+		recs = append(recs, rec)
+	}
+	return recs, nil
+}
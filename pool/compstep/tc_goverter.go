@@ -9,6 +9,7 @@ import (
 // goverter:extend orglang/go-engine/adt/compsem:Msg.*
 // goverter:extend orglang/go-engine/pool/termexp:Msg.*
 var (
+	// goverter:ignore StepID ProcExp
 	MsgToStepSpec   func(compstep.StepSpec) (StepSpec, error)
 	MsgFromStepSpec func(StepSpec) compstep.StepSpec
 )
@@ -6,7 +6,7 @@ import (
 
 var Module = fx.Module("pool/compvar",
 	fx.Provide(
-		fx.Annotate(newPgxDAO, fx.As(new(Repo))),
+		newDialectDAO,
 	),
 	fx.Provide(
 		fx.Private,
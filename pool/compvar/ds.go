@@ -1,6 +1,8 @@
 package compvar
 
 import (
+	"log/slog"
+
 	"orglang/go-engine/lib/db"
 
 	"orglang/go-engine/adt/compvar"
@@ -9,3 +11,11 @@ import (
 type Repo interface {
 	AddRecs(db.Source, []compvar.VarRec) error
 }
+
+// хранилищу в памяти запросы не нужны, поэтому DAO выбирается по диалекту
+func newDialectDAO(dialect db.Dialect, qb queryBuilder, log *slog.Logger) Repo {
+	if dialect == db.Memory {
+		return newMemDAO(log)
+	}
+	return newPgxDAO(qb, log)
+}
@@ -0,0 +1,40 @@
+package compvar
+
+import (
+	"log/slog"
+	"reflect"
+
+	"orglang/go-engine/lib/db"
+	"orglang/go-engine/lib/lf"
+
+	"orglang/go-engine/adt/compvar"
+)
+
+// Adapter
+type memDAO struct {
+	log *slog.Logger
+}
+
+// у таблиц переменных нет первичного ключа, а в памяти
+// строки различаются ревизией вычисления и заглушкой канала
+type varKeyDS struct {
+	CompID string
+	CompRN int64
+	ChnlPH string
+}
+
+func newMemDAO(log *slog.Logger) *memDAO {
+	name := slog.String("name", reflect.TypeFor[memDAO]().Name())
+	return &memDAO{log.With(name)}
+}
+
+func (dao *memDAO) AddRecs(source db.Source, recs []compvar.VarRec) error {
+	ds := db.MustConform[db.SourceMem](source)
+	for _, rec := range recs {
+		dto := compvar.DataFromVarRec(rec)
+		key := varKeyDS{dto.CompID.String, dto.CompRN.Int64, dto.ChnlPH.String}
+		db.TableOf[varKeyDS, compvar.VarRecDS](ds, getTableName(rec)).Put(key, dto)
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "insertion succeed")
+	return nil
+}
@@ -0,0 +1,330 @@
+package labormkt
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"reflect"
+	"slices"
+	"time"
+
+	"orglang/go-engine/lib/db"
+
+	"orglang/go-engine/adt/commsem"
+	"orglang/go-engine/adt/identity"
+	"orglang/go-engine/adt/option"
+	"orglang/go-engine/adt/uniqsym"
+	"orglang/go-engine/adt/valkey"
+	"orglang/go-engine/pool/compvar"
+	"orglang/go-engine/pool/termexp"
+	"orglang/go-engine/proc/termdec"
+	"orglang/go-engine/proc/typedef"
+	"orglang/go-engine/proc/typeexp"
+)
+
+type API interface {
+	// открытые вакансии или заявки в порядке размещения
+	RetrievePosts(Side) ([]PostRec, error)
+}
+
+// Matcher сводит вакансии с заявками в транзакции шага пула
+type Matcher interface {
+	// Match сводит размещение с подходящим встречным, а если такого нет,
+	// оставляет размещение открытым
+	Match(db.Source, PostRec) (option.ADT[PostRec], error)
+	// Dismiss закрывает наймы на упраздненном обмене
+	Dismiss(db.Source, commsem.SemRef) error
+}
+
+// сторона рынка
+type Side int16
+
+const (
+	unkSide Side = iota
+	// спрос нанимателя (Hire)
+	VacancySide
+	// предложение соискателя (Apply)
+	ApplicationSide
+)
+
+func (s Side) Opposite() Side {
+	switch s {
+	case VacancySide:
+		return ApplicationSide
+	case ApplicationSide:
+		return VacancySide
+	default:
+		panic(ErrSideUnexpected(s))
+	}
+}
+
+// размещение на рынке труда
+type PostRec struct {
+	PostID identity.ADT
+	Side   Side
+	// канал, по которому пул нанимает или предлагает компетенцию
+	CommChnl compvar.LinearRec
+	// состояние канала после сведения
+	NextExpVK  valkey.ADT
+	ProcTermQN uniqsym.ADT
+	ContExp    termexp.ExpSpec
+	PostedAt   time.Time
+}
+
+// найм: сведенные вакансия и заявка делят обмен нанимателя
+type HireRec struct {
+	HireID identity.ADT
+	CommID identity.ADT
+	// пул соискателя
+	CompID  identity.ADT
+	HiredAt time.Time
+}
+
+// загрузка пула соискателя
+type LoadRec struct {
+	CompID identity.ADT
+	// действующие наймы
+	Active int
+	// последний найм
+	HiredAt time.Time
+}
+
+type service struct {
+	policy      policyCS
+	laborRepo   Repo
+	termDecRepo termdec.Repo
+	typeDefRepo typedef.Repo
+	typeExpRepo typeexp.Repo
+	operator    db.Operator
+	log         *slog.Logger
+}
+
+// for compilation purposes
+func newAPI() API {
+	return new(service)
+}
+
+func newService(
+	dto marketCS,
+	laborRepo Repo,
+	termDecRepo termdec.Repo,
+	typeDefRepo typedef.Repo,
+	typeExpRepo typeexp.Repo,
+	operator db.Operator,
+	log *slog.Logger,
+) *service {
+	name := slog.String("name", reflect.TypeFor[service]().Name())
+	return &service{
+		dto.Policy, laborRepo, termDecRepo, typeDefRepo, typeExpRepo,
+		operator, log.With(name, slog.Any("policy", dto.Policy)),
+	}
+}
+
+func (s *service) RetrievePosts(side Side) (_ []PostRec, err error) {
+	ctx := context.Background()
+	var recs []PostRec
+	selectErr := s.operator.Implicit(ctx, func(ds db.Source) error {
+		recs, err = s.laborRepo.SelectPosts(ds, side)
+		return err
+	})
+	if selectErr != nil {
+		s.log.Error("retrieval failed", slog.Any("side", side))
+		return nil, selectErr
+	}
+	return recs, nil
+}
+
+func (s *service) Match(ds db.Source, post PostRec) (option.ADT[PostRec], error) {
+	postAttr := slog.Any("qn", post.ProcTermQN)
+	counters, err := s.laborRepo.SelectPosts(ds, post.Side.Opposite())
+	if err != nil {
+		return nil, err
+	}
+	var direct, others, candidates []PostRec
+	for _, counter := range counters {
+		// встречное размещение на том же обмене сводится как раньше,
+		// без проверки компетенций
+		if counter.CommChnl.CommRef.CommID == post.CommChnl.CommRef.CommID {
+			direct = append(direct, counter)
+			continue
+		}
+		others = append(others, counter)
+	}
+	if len(direct) == 0 && len(others) > 0 {
+		termQNs := []uniqsym.ADT{post.ProcTermQN}
+		for _, counter := range others {
+			termQNs = append(termQNs, counter.ProcTermQN)
+		}
+		env, err := s.loadCompatEnv(ds, termQNs)
+		if err != nil {
+			return nil, err
+		}
+		for _, counter := range others {
+			vacancy, application := post, counter
+			if post.Side == ApplicationSide {
+				vacancy, application = counter, post
+			}
+			if env.isCompatible(application.ProcTermQN, vacancy.ProcTermQN) {
+				candidates = append(candidates, counter)
+			}
+		}
+	}
+	var picked PostRec
+	switch {
+	case len(direct) > 0:
+		picked = direct[0]
+	case len(candidates) == 0:
+		post.PostID = identity.New()
+		post.PostedAt = time.Now()
+		err = s.laborRepo.AddPost(ds, post)
+		if err != nil {
+			return nil, err
+		}
+		s.log.Debug("posting succeed", postAttr, slog.Any("side", post.Side))
+		return option.None[PostRec](), nil
+	case post.Side == ApplicationSide:
+		// соискатель занимает самую давнюю из подходящих вакансий
+		picked = candidates[0]
+	default:
+		picked, err = s.pickApplication(ds, candidates)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = s.laborRepo.RemovePost(ds, picked.PostID)
+	if err != nil {
+		return nil, err
+	}
+	vacancy, application := post, picked
+	if post.Side == ApplicationSide {
+		vacancy, application = picked, post
+	}
+	err = s.laborRepo.AddHire(ds, HireRec{
+		HireID:  identity.New(),
+		CommID:  vacancy.CommChnl.CommRef.CommID,
+		CompID:  application.CommChnl.CompRef.CompID,
+		HiredAt: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.log.Debug("matching succeed", postAttr, slog.Any("counterQN", picked.ProcTermQN))
+	return option.Some(picked), nil
+}
+
+func (s *service) Dismiss(ds db.Source, commRef commsem.SemRef) error {
+	return s.laborRepo.DismissHires(ds, commRef, time.Now())
+}
+
+// заявки приходят в порядке размещения, поэтому при равенстве
+// загрузки выигрывает самая давняя
+func (s *service) pickApplication(ds db.Source, candidates []PostRec) (PostRec, error) {
+	if s.policy == fifoPolicy {
+		return candidates[0], nil
+	}
+	compIDs := make([]identity.ADT, 0, len(candidates))
+	for _, candidate := range candidates {
+		compIDs = append(compIDs, candidate.CommChnl.CompRef.CompID)
+	}
+	loads, err := s.laborRepo.SelectLoads(ds, compIDs)
+	if err != nil {
+		return PostRec{}, err
+	}
+	byPolicy := func(a, b PostRec) int {
+		loadA := loads[a.CommChnl.CompRef.CompID]
+		loadB := loads[b.CommChnl.CompRef.CompID]
+		if s.policy == leastLoadedPolicy {
+			return cmp.Compare(loadA.Active, loadB.Active)
+		}
+		// по кругу: первым идет пул, дольше всех ждавший найма
+		return loadA.HiredAt.Compare(loadB.HiredAt)
+	}
+	return slices.MinFunc(candidates, byPolicy), nil
+}
+
+// окружение для сравнения компетенций
+type compatEnv struct {
+	termDecs map[uniqsym.ADT]termdec.DecRec
+	typeExps map[valkey.ADT]typeexp.ExpRec
+	typeDefs typeexp.Defs
+}
+
+// Загружает декларации, обязательства и определения типов
+// всех сравниваемых компетенций разом.
+func (s *service) loadCompatEnv(ds db.Source, termQNs []uniqsym.ADT) (compatEnv, error) {
+	env := compatEnv{typeDefs: make(typeexp.Defs)}
+	termQNs = slices.Collect(maps.Keys(setOf(termQNs)))
+	termDecs, err := s.termDecRepo.SelectEnv(ds, termQNs)
+	if errors.Is(err, db.ErrNotFound) {
+		// выборка не говорит, какой декларации нет,
+		// поэтому недостающие выясняются поштучно
+		termDecs, err = s.selectDeclared(ds, termQNs)
+	}
+	if err != nil {
+		return compatEnv{}, err
+	}
+	env.termDecs = termDecs
+	expVKs := make([]valkey.ADT, 0, len(termDecs))
+	for _, termDec := range termDecs {
+		expVKs = append(expVKs, termDec.LiabVar.ExpVK)
+	}
+	env.typeExps, err = s.typeExpRepo.SelectEnv(ds, slices.Collect(maps.Keys(setOf(expVKs))))
+	if err != nil {
+		return compatEnv{}, err
+	}
+	err = typedef.SelectDefs(ds, s.typeDefRepo, s.typeExpRepo, env.typeDefs, maps.Values(env.typeExps))
+	if err != nil {
+		return compatEnv{}, err
+	}
+	return env, nil
+}
+
+func (s *service) selectDeclared(ds db.Source, termQNs []uniqsym.ADT) (map[uniqsym.ADT]termdec.DecRec, error) {
+	termDecs := make(map[uniqsym.ADT]termdec.DecRec, len(termQNs))
+	for _, termQN := range termQNs {
+		env, err := s.termDecRepo.SelectEnv(ds, []uniqsym.ADT{termQN})
+		if errors.Is(err, db.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		termDecs[termQN] = env[termQN]
+	}
+	return termDecs, nil
+}
+
+// Компетенция соискателя подходит, если ее обязательство
+// есть подтип обязательства, запрошенного нанимателем.
+func (e compatEnv) isCompatible(gotQN, wantQN uniqsym.ADT) bool {
+	if gotQN.Equal(wantQN) {
+		return true
+	}
+	gotDec, ok := e.termDecs[gotQN]
+	if !ok {
+		// компетенции без деклараций сравнимы только по имени
+		return false
+	}
+	wantDec, ok := e.termDecs[wantQN]
+	if !ok {
+		return false
+	}
+	gotExp := e.typeExps[gotDec.LiabVar.ExpVK]
+	wantExp := e.typeExps[wantDec.LiabVar.ExpVK]
+	return typeexp.CheckSub(e.typeDefs, gotExp, wantExp) == nil
+}
+
+func setOf[T comparable](vals []T) map[T]struct{} {
+	set := make(map[T]struct{}, len(vals))
+	for _, val := range vals {
+		set[val] = struct{}{}
+	}
+	return set
+}
+
+func ErrSideUnexpected(got Side) error {
+	return fmt.Errorf("side unexpected: %v", got)
+}
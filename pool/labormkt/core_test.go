@@ -0,0 +1,212 @@
+package labormkt
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"orglang/go-engine/lib/db"
+
+	"orglang/go-engine/adt/commsem"
+	"orglang/go-engine/adt/compsem"
+	"orglang/go-engine/adt/identity"
+	"orglang/go-engine/adt/symbol"
+	"orglang/go-engine/adt/termvar"
+	"orglang/go-engine/adt/uniqsym"
+	"orglang/go-engine/adt/valkey"
+	"orglang/go-engine/pool/compvar"
+	"orglang/go-engine/proc/termdec"
+	"orglang/go-engine/proc/typeexp"
+)
+
+// laborStub держит рынок в памяти
+type laborStub struct {
+	Repo
+	posts []PostRec
+	loads map[identity.ADT]LoadRec
+	added []PostRec
+	hires []HireRec
+}
+
+func (r *laborStub) AddPost(_ db.Source, post PostRec) error {
+	r.added = append(r.added, post)
+	return nil
+}
+
+func (r *laborStub) RemovePost(_ db.Source, id identity.ADT) error {
+	return nil
+}
+
+func (r *laborStub) SelectPosts(_ db.Source, side Side) ([]PostRec, error) {
+	var posts []PostRec
+	for _, post := range r.posts {
+		if post.Side == side {
+			posts = append(posts, post)
+		}
+	}
+	return posts, nil
+}
+
+func (r *laborStub) AddHire(_ db.Source, hire HireRec) error {
+	r.hires = append(r.hires, hire)
+	return nil
+}
+
+func (r *laborStub) SelectLoads(_ db.Source, _ []identity.ADT) (map[identity.ADT]LoadRec, error) {
+	return r.loads, nil
+}
+
+// termDecStub считает выборки деклараций
+type termDecStub struct {
+	termdec.Repo
+	decs  map[uniqsym.ADT]termdec.DecRec
+	calls int
+}
+
+func (r *termDecStub) SelectEnv(_ db.Source, termQNs []uniqsym.ADT) (map[uniqsym.ADT]termdec.DecRec, error) {
+	r.calls++
+	env := make(map[uniqsym.ADT]termdec.DecRec, len(termQNs))
+	for _, termQN := range termQNs {
+		dec, ok := r.decs[termQN]
+		if !ok {
+			return nil, db.ErrNotFound
+		}
+		env[termQN] = dec
+	}
+	return env, nil
+}
+
+// typeExpStub считает выборки типов
+type typeExpStub struct {
+	typeexp.Repo
+	exps  map[valkey.ADT]typeexp.ExpRec
+	calls int
+}
+
+func (r *typeExpStub) SelectEnv(_ db.Source, expVKs []valkey.ADT) (map[valkey.ADT]typeexp.ExpRec, error) {
+	r.calls++
+	env := make(map[valkey.ADT]typeexp.ExpRec, len(expVKs))
+	for _, expVK := range expVKs {
+		exp, ok := r.exps[expVK]
+		if !ok {
+			return nil, db.ErrNotFound
+		}
+		env[expVK] = exp
+	}
+	return env, nil
+}
+
+func newPost(side Side, qn string) PostRec {
+	comm := commsem.New()
+	return PostRec{
+		PostID: identity.New(),
+		Side:   side,
+		CommChnl: compvar.LinearRec{
+			CompRef: compsem.New(),
+			CommRef: comm,
+			ChnlID:  comm.CommID,
+		},
+		ProcTermQN: uniqsym.New(symbol.New(qn)),
+	}
+}
+
+func TestMatchCheckSub(t *testing.T) {
+	one := typeexp.OneRec{ExpVK: valkey.One}
+	label := uniqsym.New(symbol.New("a"))
+	// внешний выбор с большим числом меток есть подтип
+	wantExp := typeexp.WithRec{ExpVK: valkey.Two, Choices: map[uniqsym.ADT]typeexp.ExpRec{label: one}}
+	gotExp := typeexp.WithRec{ExpVK: valkey.Three, Choices: map[uniqsym.ADT]typeexp.ExpRec{
+		label:                        one,
+		uniqsym.New(symbol.New("b")): one,
+	}}
+	vacancy := newPost(VacancySide, "want")
+	subtype := newPost(ApplicationSide, "sub")
+	mismatch := newPost(ApplicationSide, "one")
+	undeclared := newPost(ApplicationSide, "undeclared")
+	decs := map[uniqsym.ADT]termdec.DecRec{
+		vacancy.ProcTermQN:  {LiabVar: termvar.VarRec{ExpVK: wantExp.ExpVK}},
+		subtype.ProcTermQN:  {LiabVar: termvar.VarRec{ExpVK: gotExp.ExpVK}},
+		mismatch.ProcTermQN: {LiabVar: termvar.VarRec{ExpVK: one.ExpVK}},
+	}
+	exps := map[valkey.ADT]typeexp.ExpRec{
+		one.ExpVK:     one,
+		wantExp.ExpVK: wantExp,
+		gotExp.ExpVK:  gotExp,
+	}
+	tests := []struct {
+		name     string
+		posts    []PostRec
+		want     *PostRec
+		decCalls int
+	}{
+		{"subtype", []PostRec{mismatch, subtype}, &subtype, 1},
+		{"no subtype", []PostRec{mismatch}, nil, 1},
+		// недостающая декларация выясняется поштучно
+		{"undeclared", []PostRec{undeclared, subtype}, &subtype, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			laborRepo := &laborStub{posts: tt.posts}
+			termDecRepo := &termDecStub{decs: decs}
+			typeExpRepo := &typeExpStub{exps: exps}
+			s := &service{
+				policy:      fifoPolicy,
+				laborRepo:   laborRepo,
+				termDecRepo: termDecRepo,
+				typeExpRepo: typeExpRepo,
+				log:         slog.New(slog.DiscardHandler),
+			}
+			got, err := s.Match(nil, vacancy)
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			if got.IsEmpty() != (tt.want == nil) {
+				t.Fatalf("want %+v, got %+v", tt.want, got)
+			}
+			if tt.want == nil {
+				if len(laborRepo.added) != 1 {
+					t.Errorf("want vacancy posted, got %+v", laborRepo.added)
+				}
+			} else if got.Get().PostID != tt.want.PostID {
+				t.Errorf("want %v, got %v", tt.want.ProcTermQN, got.Get().ProcTermQN)
+			}
+			// компетенции всех встречных грузятся разом
+			if termDecRepo.calls != tt.decCalls || typeExpRepo.calls != 1 {
+				t.Errorf("want %v and 1 selections, got %v and %v", tt.decCalls, termDecRepo.calls, typeExpRepo.calls)
+			}
+		})
+	}
+}
+
+func TestPickApplication(t *testing.T) {
+	now := time.Now()
+	first := newPost(ApplicationSide, "first")
+	busy := newPost(ApplicationSide, "busy")
+	idle := newPost(ApplicationSide, "idle")
+	loads := map[identity.ADT]LoadRec{
+		first.CommChnl.CompRef.CompID: {Active: 2, HiredAt: now.Add(-time.Hour)},
+		busy.CommChnl.CompRef.CompID:  {Active: 3, HiredAt: now.Add(-2 * time.Hour)},
+		idle.CommChnl.CompRef.CompID:  {Active: 1, HiredAt: now},
+	}
+	tests := []struct {
+		policy policyCS
+		want   PostRec
+	}{
+		{fifoPolicy, first},
+		// дольше всех ждал найма
+		{roundRobinPolicy, busy},
+		{leastLoadedPolicy, idle},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			s := &service{policy: tt.policy, laborRepo: &laborStub{loads: loads}}
+			got, err := s.pickApplication(nil, []PostRec{first, busy, idle})
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			if got.PostID != tt.want.PostID {
+				t.Errorf("want %v, got %v", tt.want.ProcTermQN, got.ProcTermQN)
+			}
+		})
+	}
+}
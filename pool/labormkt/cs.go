@@ -0,0 +1,34 @@
+package labormkt
+
+import (
+	"orglang/go-engine/lib/kv"
+)
+
+func newMarketCS(loader kv.Loader) (marketCS, error) {
+	dto := new(marketCS)
+	loadingErr := loader.Load("market", dto)
+	if loadingErr != nil {
+		return marketCS{}, loadingErr
+	}
+	validateErr := dto.Validate()
+	if validateErr != nil {
+		return marketCS{}, validateErr
+	}
+	return *dto, nil
+}
+
+type marketCS struct {
+	Policy policyCS `mapstructure:"policy"`
+}
+
+// политика выбора среди подходящих заявок
+type policyCS string
+
+const (
+	// в порядке размещения
+	fifoPolicy policyCS = "fifo"
+	// первым нанимается пул, дольше всех ждавший найма
+	roundRobinPolicy policyCS = "round-robin"
+	// первым нанимается пул с наименьшим числом действующих наймов
+	leastLoadedPolicy policyCS = "least-loaded"
+)
@@ -0,0 +1,21 @@
+package labormkt
+
+import (
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module("pool/labormkt",
+	fx.Provide(
+		fx.Annotate(newService, fx.As(new(API)), fx.As(new(Matcher))),
+		fx.Annotate(newPgxDAO, fx.As(new(Repo))),
+	),
+	fx.Provide(
+		fx.Private,
+		newMarketCS,
+		newEchoController,
+		fx.Annotate(newSQLBuilder, fx.As(new(queryBuilder))),
+	),
+	fx.Invoke(
+		cfgEchoController,
+	),
+)
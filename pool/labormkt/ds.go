@@ -0,0 +1,49 @@
+package labormkt
+
+import (
+	"database/sql"
+	"time"
+
+	"orglang/go-engine/lib/db"
+
+	"orglang/go-engine/adt/commsem"
+	"orglang/go-engine/adt/identity"
+)
+
+type Repo interface {
+	AddPost(db.Source, PostRec) error
+	// RemovePost снимает размещение, если его не снял кто-то другой
+	RemovePost(db.Source, identity.ADT) error
+	// SelectPosts выдает открытые размещения в порядке размещения
+	SelectPosts(db.Source, Side) ([]PostRec, error)
+	AddHire(db.Source, HireRec) error
+	// DismissHires закрывает действующие наймы на обмене
+	DismissHires(db.Source, commsem.SemRef, time.Time) error
+	SelectLoads(db.Source, []identity.ADT) (map[identity.ADT]LoadRec, error)
+}
+
+type postRecDS struct {
+	PostID   string    `db:"post_id"`
+	Side     int16     `db:"side"`
+	CompID   string    `db:"comp_id"`
+	CommID   string    `db:"comm_id"`
+	TermQN   string    `db:"term_qn"`
+	CommVar  []byte    `db:"comm_var"`
+	NextVK   int64     `db:"next_vk"`
+	ContExp  []byte    `db:"cont_exp"`
+	PostedAt time.Time `db:"posted_at"`
+}
+
+type hireRecDS struct {
+	HireID  string       `db:"hire_id"`
+	CommID  string       `db:"comm_id"`
+	CompID  string       `db:"comp_id"`
+	HiredAt time.Time    `db:"hired_at"`
+	FiredAt sql.NullTime `db:"fired_at"`
+}
+
+type loadRecDS struct {
+	CompID  string       `db:"comp_id"`
+	Active  int          `db:"active"`
+	HiredAt sql.NullTime `db:"hired_at"`
+}
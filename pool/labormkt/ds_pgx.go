@@ -0,0 +1,136 @@
+package labormkt
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"orglang/go-engine/lib/db"
+	"orglang/go-engine/lib/lf"
+
+	"orglang/go-engine/adt/commsem"
+	"orglang/go-engine/adt/identity"
+)
+
+type pgxDAO struct {
+	qb  queryBuilder
+	log *slog.Logger
+}
+
+func newPgxDAO(qb queryBuilder, log *slog.Logger) *pgxDAO {
+	name := slog.String("name", reflect.TypeFor[pgxDAO]().Name())
+	return &pgxDAO{qb, log.With(name)}
+}
+
+// for compilation purposes
+func newRepo() Repo {
+	return new(pgxDAO)
+}
+
+func (dao *pgxDAO) AddPost(source db.Source, rec PostRec) error {
+	ds := db.MustConform[db.SourcePgx](source)
+	idAttr := slog.Any("id", rec.PostID)
+	dto, convErr := dataFromPostRec(rec)
+	if convErr != nil {
+		dao.log.Error("model conversion failed", idAttr)
+		return convErr
+	}
+	sql, args := dao.qb.insertPost(dto)
+	_, execErr := ds.Conn.Exec(ds.Ctx, sql, args...)
+	if execErr != nil {
+		dao.log.Error("query execution failed", idAttr, slog.String("sql", sql))
+		return execErr
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "addition succeed", slog.Any("dto", dto))
+	return nil
+}
+
+func (dao *pgxDAO) RemovePost(source db.Source, postID identity.ADT) error {
+	ds := db.MustConform[db.SourcePgx](source)
+	idAttr := slog.Any("id", postID)
+	sql, args := dao.qb.deletePost(identity.ConvertToString(postID))
+	ct, execErr := ds.Conn.Exec(ds.Ctx, sql, args...)
+	if execErr != nil {
+		dao.log.Error("query execution failed", idAttr, slog.String("sql", sql))
+		return execErr
+	}
+	if ct.RowsAffected() == 0 {
+		dao.log.Error("removal failed", idAttr)
+		return errConcurrentModification(postID)
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "removal succeed", idAttr)
+	return nil
+}
+
+func (dao *pgxDAO) SelectPosts(source db.Source, side Side) ([]PostRec, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	sql, args := dao.qb.selectPosts(int16(side))
+	rows, execErr := ds.Conn.Query(ds.Ctx, sql, args...)
+	if execErr != nil {
+		dao.log.Error("query execution failed", slog.String("sql", sql))
+		return nil, execErr
+	}
+	defer rows.Close()
+	dtos, scanErr := pgx.CollectRows(rows, pgx.RowToStructByName[postRecDS])
+	if scanErr != nil {
+		dao.log.Error("rows scanning failed")
+		return nil, scanErr
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "selection succeed", slog.Int("count", len(dtos)))
+	return dataToPostRecs(dtos)
+}
+
+func (dao *pgxDAO) AddHire(source db.Source, rec HireRec) error {
+	ds := db.MustConform[db.SourcePgx](source)
+	dto := dataFromHireRec(rec)
+	sql, args := dao.qb.insertHire(dto)
+	_, execErr := ds.Conn.Exec(ds.Ctx, sql, args...)
+	if execErr != nil {
+		dao.log.Error("query execution failed", slog.Any("id", rec.HireID), slog.String("sql", sql))
+		return execErr
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "addition succeed", slog.Any("dto", dto))
+	return nil
+}
+
+func (dao *pgxDAO) DismissHires(source db.Source, ref commsem.SemRef, firedAt time.Time) error {
+	ds := db.MustConform[db.SourcePgx](source)
+	refAttr := slog.Any("ref", ref)
+	sql, args := dao.qb.updateHires(identity.ConvertToString(ref.CommID), firedAt)
+	_, execErr := ds.Conn.Exec(ds.Ctx, sql, args...)
+	if execErr != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("sql", sql))
+		return execErr
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "dismissal succeed", refAttr)
+	return nil
+}
+
+func (dao *pgxDAO) SelectLoads(source db.Source, compIDs []identity.ADT) (map[identity.ADT]LoadRec, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	ids := make([]string, 0, len(compIDs))
+	for _, compID := range compIDs {
+		ids = append(ids, identity.ConvertToString(compID))
+	}
+	sql, args := dao.qb.selectLoads(ids)
+	rows, execErr := ds.Conn.Query(ds.Ctx, sql, args...)
+	if execErr != nil {
+		dao.log.Error("query execution failed", slog.String("sql", sql))
+		return nil, execErr
+	}
+	defer rows.Close()
+	dtos, scanErr := pgx.CollectRows(rows, pgx.RowToStructByName[loadRecDS])
+	if scanErr != nil {
+		dao.log.Error("rows scanning failed")
+		return nil, scanErr
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "selection succeed", slog.Any("dtos", dtos))
+	return dataToLoadRecs(dtos)
+}
+
+func errConcurrentModification(got identity.ADT) error {
+	return fmt.Errorf("%w: %v", db.ErrConcurrentModification, got)
+}
@@ -0,0 +1,11 @@
+package labormkt
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+func (dto marketCS) Validate() error {
+	return validation.ValidateStruct(&dto,
+		validation.Field(&dto.Policy, validation.Required, validation.In(fifoPolicy, roundRobinPolicy, leastLoadedPolicy)),
+	)
+}
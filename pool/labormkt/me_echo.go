@@ -0,0 +1,42 @@
+package labormkt
+
+import (
+	"log/slog"
+	"net/http"
+	"reflect"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Server-side primary adapter
+type echoController struct {
+	api API
+	log *slog.Logger
+}
+
+func newEchoController(api API, log *slog.Logger) *echoController {
+	name := slog.String("name", reflect.TypeFor[echoController]().Name())
+	return &echoController{api, log.With(name)}
+}
+
+func cfgEchoController(server *echo.Echo, controller *echoController) error {
+	server.GET("/api/v1/pools/market/vacancies", controller.GetVacancies)
+	server.GET("/api/v1/pools/market/applications", controller.GetApplications)
+	return nil
+}
+
+func (c *echoController) GetVacancies(ctx echo.Context) error {
+	recs, apiErr := c.api.RetrievePosts(VacancySide)
+	if apiErr != nil {
+		return apiErr
+	}
+	return ctx.JSON(http.StatusOK, ViewFromPosts(recs))
+}
+
+func (c *echoController) GetApplications(ctx echo.Context) error {
+	recs, apiErr := c.api.RetrievePosts(ApplicationSide)
+	if apiErr != nil {
+		return apiErr
+	}
+	return ctx.JSON(http.StatusOK, ViewFromPosts(recs))
+}
@@ -0,0 +1,19 @@
+package labormkt
+
+import (
+	"time"
+)
+
+const (
+	laborPosts string = "pool_labor_posts"
+	laborHires string = "pool_labor_hires"
+)
+
+type queryBuilder interface {
+	insertPost(postRecDS) (string, []any)
+	deletePost(string) (string, []any)
+	selectPosts(int16) (string, []any)
+	insertHire(hireRecDS) (string, []any)
+	updateHires(string, time.Time) (string, []any)
+	selectLoads([]string) (string, []any)
+}
@@ -0,0 +1,64 @@
+package labormkt
+
+import (
+	"time"
+
+	"github.com/huandu/go-sqlbuilder"
+)
+
+// запросы переносимы, поэтому отдельного построителя для SQLite нет
+type sqlBuilder struct {
+	postBuilder *sqlbuilder.Struct
+	hireBuilder *sqlbuilder.Struct
+}
+
+// for compilation purposes
+func newQueryBuilder() queryBuilder {
+	return new(sqlBuilder)
+}
+
+func newSQLBuilder() *sqlBuilder {
+	postBuilder := sqlbuilder.NewStruct(new(postRecDS)).For(sqlbuilder.PostgreSQL)
+	hireBuilder := sqlbuilder.NewStruct(new(hireRecDS)).For(sqlbuilder.PostgreSQL)
+	return &sqlBuilder{postBuilder, hireBuilder}
+}
+
+func (qb *sqlBuilder) insertPost(rec postRecDS) (string, []any) {
+	return qb.postBuilder.InsertInto(laborPosts, rec).Build()
+}
+
+func (qb *sqlBuilder) deletePost(postID string) (string, []any) {
+	del := sqlbuilder.PostgreSQL.NewDeleteBuilder()
+	return del.DeleteFrom(laborPosts).Where(del.Equal("post_id", postID)).Build()
+}
+
+func (qb *sqlBuilder) selectPosts(side int16) (string, []any) {
+	sb := qb.postBuilder.SelectFrom(laborPosts)
+	return sb.Where(sb.Equal("side", side)).OrderBy("posted_at").Build()
+}
+
+func (qb *sqlBuilder) insertHire(rec hireRecDS) (string, []any) {
+	return qb.hireBuilder.InsertInto(laborHires, rec).Build()
+}
+
+func (qb *sqlBuilder) updateHires(commID string, firedAt time.Time) (string, []any) {
+	ub := sqlbuilder.PostgreSQL.NewUpdateBuilder()
+	return ub.Update(laborHires).
+		Set(ub.Assign("fired_at", firedAt)).
+		Where(ub.Equal("comm_id", commID), ub.IsNull("fired_at")).
+		Build()
+}
+
+// пулы без наймов в выборку не попадают
+func (qb *sqlBuilder) selectLoads(compIDs []string) (string, []any) {
+	sb := sqlbuilder.PostgreSQL.NewSelectBuilder()
+	return sb.Select(
+		"comp_id",
+		sb.As("sum(CASE WHEN fired_at IS NULL THEN 1 ELSE 0 END)", "active"),
+		sb.As("max(hired_at)", "hired_at"),
+	).
+		From(laborHires).
+		Where(sb.In("comp_id", sqlbuilder.List(compIDs))).
+		GroupBy("comp_id").
+		Build()
+}
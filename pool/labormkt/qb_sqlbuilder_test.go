@@ -0,0 +1,28 @@
+package labormkt
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSelectPosts(t *testing.T) {
+	qb := newSQLBuilder()
+	sql, args := qb.selectPosts(int16(VacancySide))
+	fmt.Println(sql)
+	fmt.Println(args)
+}
+
+func TestUpdateHires(t *testing.T) {
+	qb := newSQLBuilder()
+	sql, args := qb.updateHires("comm", time.Now())
+	fmt.Println(sql)
+	fmt.Println(args)
+}
+
+func TestSelectLoads(t *testing.T) {
+	qb := newSQLBuilder()
+	sql, args := qb.selectLoads([]string{"comp1", "comp2"})
+	fmt.Println(sql)
+	fmt.Println(args)
+}
@@ -0,0 +1,119 @@
+package labormkt
+
+import (
+	"encoding/json"
+
+	"orglang/go-engine/adt/compvar"
+	"orglang/go-engine/adt/identity"
+	"orglang/go-engine/adt/uniqsym"
+	"orglang/go-engine/adt/valkey"
+	"orglang/go-engine/pool/termexp"
+)
+
+// канал и продолжение хранятся так же, как в остальных таблицах пулов
+func dataFromPostRec(rec PostRec) (postRecDS, error) {
+	commVar, err := json.Marshal(compvar.DataFromLinearRec(rec.CommChnl))
+	if err != nil {
+		return postRecDS{}, err
+	}
+	var contExp []byte
+	if rec.ContExp != nil {
+		contExp, err = json.Marshal(termexp.DataFromExpSpec(rec.ContExp))
+		if err != nil {
+			return postRecDS{}, err
+		}
+	}
+	return postRecDS{
+		PostID:   identity.ConvertToString(rec.PostID),
+		Side:     int16(rec.Side),
+		CompID:   identity.ConvertToString(rec.CommChnl.CompRef.CompID),
+		CommID:   identity.ConvertToString(rec.CommChnl.CommRef.CommID),
+		TermQN:   uniqsym.ConvertToString(rec.ProcTermQN),
+		CommVar:  commVar,
+		NextVK:   valkey.ConvertToInt(rec.NextExpVK),
+		ContExp:  contExp,
+		PostedAt: rec.PostedAt,
+	}, nil
+}
+
+func dataToPostRec(dto postRecDS) (PostRec, error) {
+	postID, err := identity.ConvertFromString(dto.PostID)
+	if err != nil {
+		return PostRec{}, err
+	}
+	var varDTO compvar.VarRecDS
+	err = json.Unmarshal(dto.CommVar, &varDTO)
+	if err != nil {
+		return PostRec{}, err
+	}
+	commChnl, err := compvar.DataToLinearRec(varDTO)
+	if err != nil {
+		return PostRec{}, err
+	}
+	termQN, err := uniqsym.ConvertFromString(dto.TermQN)
+	if err != nil {
+		return PostRec{}, err
+	}
+	nextExpVK, err := valkey.ConvertFromInt(dto.NextVK)
+	if err != nil {
+		return PostRec{}, err
+	}
+	var contExp termexp.ExpSpec
+	if dto.ContExp != nil {
+		var expDTO termexp.ExpSpecDS
+		err = json.Unmarshal(dto.ContExp, &expDTO)
+		if err != nil {
+			return PostRec{}, err
+		}
+		contExp, err = termexp.DataToExpSpec(expDTO)
+		if err != nil {
+			return PostRec{}, err
+		}
+	}
+	return PostRec{
+		PostID:     postID,
+		Side:       Side(dto.Side),
+		CommChnl:   commChnl,
+		NextExpVK:  nextExpVK,
+		ProcTermQN: termQN,
+		ContExp:    contExp,
+		PostedAt:   dto.PostedAt,
+	}, nil
+}
+
+func dataToPostRecs(dtos []postRecDS) ([]PostRec, error) {
+	recs := make([]PostRec, 0, len(dtos))
+	for _, dto := range dtos {
+		rec, err := dataToPostRec(dto)
+		if err != nil {
+			return nil, err
+		}
+		recs = append(recs, rec)
+	}
+	return recs, nil
+}
+
+func dataFromHireRec(rec HireRec) hireRecDS {
+	return hireRecDS{
+		HireID:  identity.ConvertToString(rec.HireID),
+		CommID:  identity.ConvertToString(rec.CommID),
+		CompID:  identity.ConvertToString(rec.CompID),
+		HiredAt: rec.HiredAt,
+	}
+}
+
+func dataToLoadRecs(dtos []loadRecDS) (map[identity.ADT]LoadRec, error) {
+	recs := make(map[identity.ADT]LoadRec, len(dtos))
+	for _, dto := range dtos {
+		compID, err := identity.ConvertFromString(dto.CompID)
+		if err != nil {
+			return nil, err
+		}
+		recs[compID] = LoadRec{
+			CompID:  compID,
+			Active:  dto.Active,
+			HiredAt: dto.HiredAt.Time,
+		}
+	}
+	return recs, nil
+}
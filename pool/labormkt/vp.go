@@ -0,0 +1,34 @@
+package labormkt
+
+import (
+	"time"
+
+	"orglang/go-engine/adt/identity"
+	"orglang/go-engine/adt/symbol"
+	"orglang/go-engine/adt/uniqsym"
+)
+
+// открытая вакансия или заявка
+type PostVP struct {
+	PostID     string    `json:"id"`
+	CompID     string    `json:"comp_id"`
+	CommID     string    `json:"comm_id"`
+	ChnlPH     string    `json:"ph"`
+	ProcTermQN string    `json:"qn"`
+	PostedAt   time.Time `json:"posted_at"`
+}
+
+func ViewFromPosts(recs []PostRec) []PostVP {
+	views := make([]PostVP, 0, len(recs))
+	for _, rec := range recs {
+		views = append(views, PostVP{
+			PostID:     identity.ConvertToString(rec.PostID),
+			CompID:     identity.ConvertToString(rec.CommChnl.CompRef.CompID),
+			CommID:     identity.ConvertToString(rec.CommChnl.CommRef.CommID),
+			ChnlPH:     symbol.ConvertToString(rec.CommChnl.ChnlPH),
+			ProcTermQN: uniqsym.ConvertToString(rec.ProcTermQN),
+			PostedAt:   rec.PostedAt,
+		})
+	}
+	return views
+}
@@ -112,6 +112,34 @@ func (s QuitSpec) LogValue() slog.Value {
 	return slog.StringValue(fmt.Sprintf("%T%+v", s, s))
 }
 
+// передача канала (подписка) со стороны отправителя
+type SendSpec struct {
+	CommChnlPH symbol.ADT
+	// передаваемый канал
+	ValChnlPH symbol.ADT
+	ContExp   ExpSpec
+}
+
+func (s SendSpec) spec() {}
+
+func (s SendSpec) LogValue() slog.Value {
+	return slog.StringValue(fmt.Sprintf("%T%+v", s, s))
+}
+
+// прием канала (подписка) со стороны получателя
+type RecvSpec struct {
+	CommChnlPH symbol.ADT
+	// имя, под которым принятый канал попадает в конфигурацию
+	ValChnlPH symbol.ADT
+	ContExp   ExpSpec
+}
+
+func (s RecvSpec) spec() {}
+
+func (s RecvSpec) LogValue() slog.Value {
+	return slog.StringValue(fmt.Sprintf("%T%+v", s, s))
+}
+
 type SpawnSpec struct {
 	// ссылка на описание порождаемого процесса
 	ProcTermQN uniqsym.ADT
@@ -196,6 +224,22 @@ type QuitRec struct {
 
 func (r QuitRec) rec() {}
 
+type SendRec struct {
+	ContChnlPH symbol.ADT
+	ValChnlPH  symbol.ADT
+	ContExp    ExpSpec
+}
+
+func (r SendRec) rec() {}
+
+type RecvRec struct {
+	ContChnlPH symbol.ADT
+	ValChnlPH  symbol.ADT
+	ContExp    ExpSpec
+}
+
+func (r RecvRec) rec() {}
+
 type SpawnRec struct {
 	CommChnlPH symbol.ADT
 }
@@ -4,13 +4,17 @@ type Repo interface {
 }
 
 type ExpSpecDS struct {
-	K       expKind       `json:"k"`
-	Acquire *grantSpecDS  `json:"acquire,omitempty"`
-	Accept  *grantSpecDS  `json:"accept,omitempty"`
-	Hire    *coopSpecDS   `json:"hire,omitempty"`
-	Apply   *coopSpecDS   `json:"apply,omitempty"`
-	Release *revokeSpecDS `json:"release,omitempty"`
-	Detach  *revokeSpecDS `json:"detach,omitempty"`
+	K       expKind         `json:"k"`
+	Acquire *grantSpecDS    `json:"acquire,omitempty"`
+	Accept  *grantSpecDS    `json:"accept,omitempty"`
+	Hire    *coopSpecDS     `json:"hire,omitempty"`
+	Apply   *coopSpecDS     `json:"apply,omitempty"`
+	Release *revokeSpecDS   `json:"release,omitempty"`
+	Detach  *revokeSpecDS   `json:"detach,omitempty"`
+	Fire    *dismissSpecDS  `json:"fire,omitempty"`
+	Quit    *dismissSpecDS  `json:"quit,omitempty"`
+	Send    *transferSpecDS `json:"send,omitempty"`
+	Recv    *transferSpecDS `json:"recv,omitempty"`
 }
 
 type grantSpecDS struct {
@@ -28,14 +32,29 @@ type revokeSpecDS struct {
 	CommChnlPH string `json:"ph"`
 }
 
+type transferSpecDS struct {
+	CommChnlPH string    `json:"ph"`
+	ValChnlPH  string    `json:"val"`
+	ContExp    ExpSpecDS `json:"exp"`
+}
+
+type dismissSpecDS struct {
+	CommChnlPH string `json:"ph"`
+	ProcTermQN string `json:"qn"`
+}
+
 type ExpRecDS struct {
-	K       expKind      `json:"k"`
-	Acquire *grantRecDS  `json:"acquire,omitempty"`
-	Accept  *grantRecDS  `json:"accept,omitempty"`
-	Hire    *coopRecDS   `json:"hire,omitempty"`
-	Apply   *coopRecDS   `json:"apply,omitempty"`
-	Release *revokeRecDS `json:"release,omitempty"`
-	Detach  *revokeRecDS `json:"detach,omitempty"`
+	K       expKind        `json:"k"`
+	Acquire *grantRecDS    `json:"acquire,omitempty"`
+	Accept  *grantRecDS    `json:"accept,omitempty"`
+	Hire    *coopRecDS     `json:"hire,omitempty"`
+	Apply   *coopRecDS     `json:"apply,omitempty"`
+	Release *revokeRecDS   `json:"release,omitempty"`
+	Detach  *revokeRecDS   `json:"detach,omitempty"`
+	Fire    *dismissRecDS  `json:"fire,omitempty"`
+	Quit    *dismissRecDS  `json:"quit,omitempty"`
+	Send    *transferRecDS `json:"send,omitempty"`
+	Recv    *transferRecDS `json:"recv,omitempty"`
 }
 
 type expKind int
@@ -48,6 +67,10 @@ const (
 	applyKind
 	releaseKind
 	detachKind
+	fireKind
+	quitKind
+	sendKind
+	recvKind
 )
 
 type grantRecDS struct {
@@ -64,3 +87,13 @@ type coopRecDS struct {
 type revokeRecDS struct {
 	ContChnlPH string `json:"ph"`
 }
+
+type transferRecDS struct {
+	ContChnlPH string    `json:"ph"`
+	ValChnlPH  string    `json:"val"`
+	ContExp    ExpSpecDS `json:"exp"`
+}
+
+type dismissRecDS struct {
+	CommChnlPH string `json:"ph"`
+}
@@ -92,6 +92,14 @@ func DataFromExpSpec(s ExpSpec) ExpSpecDS {
 		return ExpSpecDS{K: releaseKind, Release: DataFromReleaseSpec(spec)}
 	case DetachSpec:
 		return ExpSpecDS{K: detachKind, Detach: DataFromDetachSpec(spec)}
+	case FireSpec:
+		return ExpSpecDS{K: fireKind, Fire: DataFromFireSpec(spec)}
+	case QuitSpec:
+		return ExpSpecDS{K: quitKind, Quit: DataFromQuitSpec(spec)}
+	case SendSpec:
+		return ExpSpecDS{K: sendKind, Send: DataFromSendSpec(spec)}
+	case RecvSpec:
+		return ExpSpecDS{K: recvKind, Recv: DataFromRecvSpec(spec)}
 	default:
 		panic(ErrSpecTypeUnexpected(s))
 	}
@@ -111,6 +119,14 @@ func DataToExpSpec(dto ExpSpecDS) (ExpSpec, error) {
 		return DataToReleaseSpec(dto.Release)
 	case detachKind:
 		return DataToDetachSpec(dto.Detach)
+	case fireKind:
+		return DataToFireSpec(dto.Fire)
+	case quitKind:
+		return DataToQuitSpec(dto.Quit)
+	case sendKind:
+		return DataToSendSpec(dto.Send)
+	case recvKind:
+		return DataToRecvSpec(dto.Recv)
 	default:
 		panic(ErrExpKindUnexpected(dto.K))
 	}
@@ -130,6 +146,14 @@ func DataFromExpRec(r ExpRec) ExpRecDS {
 		return ExpRecDS{K: releaseKind, Release: DataFromReleaseRec(rec)}
 	case DetachRec:
 		return ExpRecDS{K: detachKind, Detach: DataFromDetachRec(rec)}
+	case FireRec:
+		return ExpRecDS{K: fireKind, Fire: DataFromFireRec(rec)}
+	case QuitRec:
+		return ExpRecDS{K: quitKind, Quit: DataFromQuitRec(rec)}
+	case SendRec:
+		return ExpRecDS{K: sendKind, Send: DataFromSendRec(rec)}
+	case RecvRec:
+		return ExpRecDS{K: recvKind, Recv: DataFromRecvRec(rec)}
 	default:
 		panic(ErrRecTypeUnexpected(r))
 	}
@@ -149,6 +173,14 @@ func DataToExpRec(dto ExpRecDS) (ExpRec, error) {
 		return DataToReleaseRec(dto.Release)
 	case detachKind:
 		return DataToDetachRec(dto.Detach)
+	case fireKind:
+		return DataToFireRec(dto.Fire)
+	case quitKind:
+		return DataToQuitRec(dto.Quit)
+	case sendKind:
+		return DataToSendRec(dto.Send)
+	case recvKind:
+		return DataToRecvRec(dto.Recv)
 	default:
 		panic(ErrExpKindUnexpected(dto.K))
 	}
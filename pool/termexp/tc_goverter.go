@@ -41,6 +41,10 @@ var (
 	DataFromApplySpec   func(ApplySpec) *coopSpecDS
 	DataFromReleaseSpec func(ReleaseSpec) *revokeSpecDS
 	DataFromDetachSpec  func(DetachSpec) *revokeSpecDS
+	DataFromFireSpec    func(FireSpec) *dismissSpecDS
+	DataFromQuitSpec    func(QuitSpec) *dismissSpecDS
+	DataFromSendSpec    func(SendSpec) *transferSpecDS
+	DataFromRecvSpec    func(RecvSpec) *transferSpecDS
 
 	DataToAcquireSpec func(*grantSpecDS) (AcquireSpec, error)
 	DataToAcceptSpec  func(*grantSpecDS) (AcceptSpec, error)
@@ -48,6 +52,10 @@ var (
 	DataToApplySpec   func(*coopSpecDS) (ApplySpec, error)
 	DataToReleaseSpec func(*revokeSpecDS) (ReleaseSpec, error)
 	DataToDetachSpec  func(*revokeSpecDS) (DetachSpec, error)
+	DataToFireSpec    func(*dismissSpecDS) (FireSpec, error)
+	DataToQuitSpec    func(*dismissSpecDS) (QuitSpec, error)
+	DataToSendSpec    func(*transferSpecDS) (SendSpec, error)
+	DataToRecvSpec    func(*transferSpecDS) (RecvSpec, error)
 
 	DataFromAcquireRec func(AcquireRec) *grantRecDS
 	DataFromAcceptRec  func(AcceptRec) *grantRecDS
@@ -55,6 +63,10 @@ var (
 	DataFromApplyRec   func(ApplyRec) *coopRecDS
 	DataFromReleaseRec func(ReleaseRec) *revokeRecDS
 	DataFromDetachRec  func(DetachRec) *revokeRecDS
+	DataFromFireRec    func(FireRec) *dismissRecDS
+	DataFromQuitRec    func(QuitRec) *dismissRecDS
+	DataFromSendRec    func(SendRec) *transferRecDS
+	DataFromRecvRec    func(RecvRec) *transferRecDS
 
 	DataToAcquireRec func(*grantRecDS) (AcquireRec, error)
 	DataToAcceptRec  func(*grantRecDS) (AcceptRec, error)
@@ -62,4 +74,8 @@ var (
 	DataToApplyRec   func(*coopRecDS) (ApplyRec, error)
 	DataToReleaseRec func(*revokeRecDS) (ReleaseRec, error)
 	DataToDetachRec  func(*revokeRecDS) (DetachRec, error)
+	DataToFireRec    func(*dismissRecDS) (FireRec, error)
+	DataToQuitRec    func(*dismissRecDS) (QuitRec, error)
+	DataToSendRec    func(*transferRecDS) (SendRec, error)
+	DataToRecvRec    func(*transferRecDS) (RecvRec, error)
 )
@@ -88,7 +88,7 @@ func (s *service) Create(spec DefSpec) (_ typesem.SemRef, err error) {
 }
 
 func errOptimisticUpdate(got seqnum.ADT) error {
-	return fmt.Errorf("entity concurrent modification: got revision %v", got)
+	return fmt.Errorf("%w: got revision %v", db.ErrConcurrentModification, got)
 }
 
 func ErrMissingInEnv(want valkey.ADT) error {
@@ -15,7 +15,7 @@ var Module = fx.Module("pool/typedef",
 		fx.Private,
 		newEchoController,
 		fx.Annotate(newSQLBuilder, fx.As(new(queryBuilder))),
-		fx.Annotate(descsem.NewPgxDAO(descBinds), fx.As(new(descsem.Repo))),
+		descsem.NewDialectDAO(descBinds),
 	),
 	fx.Invoke(
 		cfgEchoController,
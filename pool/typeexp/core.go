@@ -22,6 +22,22 @@ type LinkSpec struct {
 
 func (LinkSpec) spec() {}
 
+// отправка канала вместе с продолжением
+type TensorSpec struct {
+	ValExp  ExpSpec
+	ContExp ExpSpec
+}
+
+func (TensorSpec) spec() {}
+
+// прием канала вместе с продолжением
+type LolliSpec struct {
+	ValExp  ExpSpec
+	ContExp ExpSpec
+}
+
+func (LolliSpec) spec() {}
+
 // aka Internal Choice
 type PlusSpec struct {
 	ProcQNs []uniqsym.ADT
@@ -66,6 +82,18 @@ type LinkRef struct {
 
 func (r LinkRef) Key() valkey.ADT { return r.ExpVK }
 
+type TensorRef struct {
+	ExpVK valkey.ADT
+}
+
+func (r TensorRef) Key() valkey.ADT { return r.ExpVK }
+
+type LolliRef struct {
+	ExpVK valkey.ADT
+}
+
+func (r LolliRef) Key() valkey.ADT { return r.ExpVK }
+
 type PlusRef struct {
 	ExpVK valkey.ADT
 }
@@ -88,6 +116,11 @@ type ProdRec interface {
 	Next() valkey.ADT
 }
 
+// тип передаваемого по каналу значения
+type ValRec interface {
+	Val() valkey.ADT
+}
+
 type SumRec interface {
 	Next(uniqsym.ADT) valkey.ADT
 }
@@ -114,6 +147,38 @@ func (r LinkRec) Key() valkey.ADT { return r.ExpVK }
 
 func (LinkRec) Pol() polarity.ADT { return polarity.Zero }
 
+type TensorRec struct {
+	ExpVK   valkey.ADT
+	ValExp  ExpRec
+	ContExp ExpRec
+}
+
+func (TensorRec) spec() {}
+
+func (r TensorRec) Key() valkey.ADT { return r.ExpVK }
+
+func (r TensorRec) Val() valkey.ADT { return r.ValExp.Key() }
+
+func (r TensorRec) Next() valkey.ADT { return r.ContExp.Key() }
+
+func (TensorRec) Pol() polarity.ADT { return polarity.Pos }
+
+type LolliRec struct {
+	ExpVK   valkey.ADT
+	ValExp  ExpRec
+	ContExp ExpRec
+}
+
+func (LolliRec) spec() {}
+
+func (r LolliRec) Key() valkey.ADT { return r.ExpVK }
+
+func (r LolliRec) Val() valkey.ADT { return r.ValExp.Key() }
+
+func (r LolliRec) Next() valkey.ADT { return r.ContExp.Key() }
+
+func (LolliRec) Pol() polarity.ADT { return polarity.Neg }
+
 // aka Internal Choice
 type PlusRec struct {
 	ExpVK   valkey.ADT
@@ -170,6 +235,14 @@ func (r DownRec) Next() valkey.ADT { return r.ContExp.Key() }
 
 func (DownRec) Pol() polarity.ADT { return polarity.Zero }
 
+// ключи типов структурные, поэтому их равенство есть равенство типов
+func CheckRef(got, want valkey.ADT) error {
+	if got != want {
+		return fmt.Errorf("type mismatch: want %+v, got %+v", want, got)
+	}
+	return nil
+}
+
 func ErrSpecTypeUnexpected(got ExpSpec) error {
 	return fmt.Errorf("spec type unexpected: %T", got)
 }
@@ -182,6 +255,14 @@ func ErrRecTypeUnexpected(got ExpRec) error {
 	return fmt.Errorf("rec type unexpected: %T", got)
 }
 
+func ErrPolarityMismatch(want, got polarity.ADT) error {
+	return fmt.Errorf("polarity mismatch: want %v, got %v", want, got)
+}
+
+func ErrKeyCollision(got valkey.ADT) error {
+	return fmt.Errorf("key taken by another spec: %v", got)
+}
+
 func ErrDoesNotExist(want valkey.ADT) error {
 	return fmt.Errorf("root doesn't exist: %v", want)
 }
@@ -20,7 +20,9 @@ func newSQLBuilder() *sqlBuilder {
 
 func (qb *sqlBuilder) insertRec(rec stateDS) (string, []any) {
 	return qb.stateBuilder.InsertInto(xactExps, rec).
-		SQL("ON CONFLICT (exp_vk) DO NOTHING").
+		// повтор совпадает со строкой, коллизия ключа не обновляет ничего
+		SQL("ON CONFLICT (exp_vk) DO UPDATE SET exp_vk = EXCLUDED.exp_vk").
+		SQL("WHERE " + xactExps + ".kind = EXCLUDED.kind AND " + xactExps + ".spec = EXCLUDED.spec").
 		Build()
 }
 
@@ -17,63 +17,85 @@ func ConvertSpecToRec(s ExpSpec) (ExpRec, error) {
 	case OneSpec:
 		return OneRec{ExpVK: valkey.One}, nil
 	case LinkSpec:
-		expVK, err := spec.TypeQN.Key()
+		expVK := valkey.NewHasher(int16(linkKind)).
+			WriteString(uniqsym.ConvertToString(spec.TypeQN)).
+			Sum()
+		return LinkRec{ExpVK: expVK, TypeQN: spec.TypeQN}, nil
+	case TensorSpec:
+		valExp, contExp, expVK, err := convertProdToRec(tensorKind, spec.ValExp, spec.ContExp)
 		if err != nil {
 			return nil, err
 		}
-		return LinkRec{ExpVK: expVK, TypeQN: spec.TypeQN}, nil
-	case WithSpec:
-		contExp, err := ConvertSpecToRec(spec.ContExp)
+		return TensorRec{ExpVK: expVK, ValExp: valExp, ContExp: contExp}, nil
+	case LolliSpec:
+		valExp, contExp, expVK, err := convertProdToRec(lolliKind, spec.ValExp, spec.ContExp)
 		if err != nil {
 			return nil, err
 		}
-		vks := make([]valkey.ADT, 0, len(spec.ProcQNs)+1)
-		for _, qn := range spec.ProcQNs {
-			vk, err := qn.Key()
-			if err != nil {
-				return nil, err
-			}
-			vks = append(vks, vk)
-		}
-		expVK, err := valkey.Compose(append(vks, contExp.Key())...)
+		return LolliRec{ExpVK: expVK, ValExp: valExp, ContExp: contExp}, nil
+	case WithSpec:
+		contExp, err := ConvertSpecToRec(spec.ContExp)
 		if err != nil {
 			return nil, err
 		}
+		expVK := composeSumKey(withKind, spec.ProcQNs, contExp)
 		return WithRec{ExpVK: expVK, ProcQNs: spec.ProcQNs, ContExp: contExp}, nil
 	case PlusSpec:
 		contExp, err := ConvertSpecToRec(spec.ContExp)
 		if err != nil {
 			return nil, err
 		}
-		vks := make([]valkey.ADT, 0, len(spec.ProcQNs)+1)
-		for _, qn := range spec.ProcQNs {
-			vk, err := qn.Key()
-			if err != nil {
-				return nil, err
-			}
-			vks = append(vks, vk)
-		}
-		expVK, err := valkey.Compose(append(vks, contExp.Key())...)
+		expVK := composeSumKey(plusKind, spec.ProcQNs, contExp)
 		return PlusRec{ExpVK: expVK, ProcQNs: spec.ProcQNs, ContExp: contExp}, nil
 	case UpSpec:
 		contExp, err := ConvertSpecToRec(spec.ContExp)
 		if err != nil {
 			return nil, err
 		}
-		expVK, err := valkey.Compose([]valkey.ADT{valkey.Two, contExp.Key()}...)
+		expVK := valkey.NewHasher(int16(upKind)).
+			WriteKey(contExp.Key()).
+			Sum()
 		return UpRec{ExpVK: expVK, ContExp: contExp}, nil
 	case DownSpec:
 		contExp, err := ConvertSpecToRec(spec.ContExp)
 		if err != nil {
 			return nil, err
 		}
-		expVK, err := valkey.Compose([]valkey.ADT{valkey.Three, contExp.Key()}...)
-		return UpRec{ExpVK: expVK, ContExp: contExp}, nil
+		expVK := valkey.NewHasher(int16(downKind)).
+			WriteKey(contExp.Key()).
+			Sum()
+		return DownRec{ExpVK: expVK, ContExp: contExp}, nil
 	default:
 		panic(ErrSpecTypeUnexpected(spec))
 	}
 }
 
+func convertProdToRec(k expKind, valSpec, contSpec ExpSpec) (ExpRec, ExpRec, valkey.ADT, error) {
+	valExp, err := ConvertSpecToRec(valSpec)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	contExp, err := ConvertSpecToRec(contSpec)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	expVK := valkey.NewHasher(int16(k)).
+		WriteKey(valExp.Key()).
+		WriteKey(contExp.Key()).
+		Sum()
+	return valExp, contExp, expVK, nil
+}
+
+// ключ продолжения идет первым, чтобы список имен
+// однозначно дочитывался до конца
+func composeSumKey(k expKind, procQNs []uniqsym.ADT, contExp ExpRec) valkey.ADT {
+	h := valkey.NewHasher(int16(k)).WriteKey(contExp.Key())
+	for _, procQN := range procQNs {
+		h.WriteString(uniqsym.ConvertToString(procQN))
+	}
+	return h.Sum()
+}
+
 func ConvertRecToSpec(r ExpRec) ExpSpec {
 	if r == nil {
 		return nil
@@ -83,6 +105,10 @@ func ConvertRecToSpec(r ExpRec) ExpSpec {
 		return OneSpec{}
 	case LinkRec:
 		return LinkSpec{TypeQN: rec.TypeQN}
+	case TensorRec:
+		return TensorSpec{ValExp: ConvertRecToSpec(rec.ValExp), ContExp: ConvertRecToSpec(rec.ContExp)}
+	case LolliRec:
+		return LolliSpec{ValExp: ConvertRecToSpec(rec.ValExp), ContExp: ConvertRecToSpec(rec.ContExp)}
 	case WithRec:
 		return WithSpec{ProcQNs: rec.ProcQNs, ContExp: ConvertRecToSpec(rec.ContExp)}
 	case PlusRec:
@@ -104,6 +130,20 @@ func MsgFromExpSpec(s ExpSpec) typeexp.ExpSpec {
 		return typeexp.ExpSpec{
 			K:    typeexp.Link,
 			Link: &typeexp.LinkSpec{TypeQN: uniqsym.ConvertToString(spec.TypeQN)}}
+	case TensorSpec:
+		return typeexp.ExpSpec{
+			K: typeexp.Tensor,
+			Tensor: &typeexp.ProdSpec{
+				ValExp:  MsgFromExpSpec(spec.ValExp),
+				ContExp: MsgFromExpSpec(spec.ContExp)},
+		}
+	case LolliSpec:
+		return typeexp.ExpSpec{
+			K: typeexp.Lolli,
+			Lolli: &typeexp.ProdSpec{
+				ValExp:  MsgFromExpSpec(spec.ValExp),
+				ContExp: MsgFromExpSpec(spec.ContExp)},
+		}
 	case WithSpec:
 		return typeexp.ExpSpec{
 			K: typeexp.With,
@@ -133,6 +173,26 @@ func MsgToExpSpec(dto typeexp.ExpSpec) (ExpSpec, error) {
 			return nil, err
 		}
 		return LinkSpec{TypeQN: xactQN}, nil
+	case typeexp.Tensor:
+		valExp, err := MsgToExpSpec(dto.Tensor.ValExp)
+		if err != nil {
+			return nil, err
+		}
+		contExp, err := MsgToExpSpec(dto.Tensor.ContExp)
+		if err != nil {
+			return nil, err
+		}
+		return TensorSpec{ValExp: valExp, ContExp: contExp}, nil
+	case typeexp.Lolli:
+		valExp, err := MsgToExpSpec(dto.Lolli.ValExp)
+		if err != nil {
+			return nil, err
+		}
+		contExp, err := MsgToExpSpec(dto.Lolli.ContExp)
+		if err != nil {
+			return nil, err
+		}
+		return LolliSpec{ValExp: valExp, ContExp: contExp}, nil
 	case typeexp.Plus:
 		procQNs, err := uniqsym.ConvertFromStrings(dto.Plus.ProcQNs)
 		if err != nil {
@@ -177,6 +237,10 @@ func msgFromExpRef(ref ExpRef) typeexp.ExpRef {
 		return typeexp.ExpRef{K: typeexp.One, ExpVK: expVK}
 	case LinkRef, LinkRec:
 		return typeexp.ExpRef{K: typeexp.Link, ExpVK: expVK}
+	case TensorRef, TensorRec:
+		return typeexp.ExpRef{K: typeexp.Tensor, ExpVK: expVK}
+	case LolliRef, LolliRec:
+		return typeexp.ExpRef{K: typeexp.Lolli, ExpVK: expVK}
 	case PlusRef, PlusRec:
 		return typeexp.ExpRef{K: typeexp.Plus, ExpVK: expVK}
 	case WithRef, WithRec:
@@ -196,6 +260,10 @@ func msgToExpRef(dto typeexp.ExpRef) (ExpRef, error) {
 		return OneRef{expVK}, nil
 	case typeexp.Link:
 		return LinkRef{expVK}, nil
+	case typeexp.Tensor:
+		return TensorRef{expVK}, nil
+	case typeexp.Lolli:
+		return LolliRef{expVK}, nil
 	case typeexp.Plus:
 		return PlusRef{expVK}, nil
 	case typeexp.With:
@@ -215,6 +283,10 @@ func dataFromExpRef(ref ExpRef) expRefDS {
 		return expRefDS{K: oneKind, ExpVK: expVK}
 	case LinkRef, LinkRec:
 		return expRefDS{K: linkKind, ExpVK: expVK}
+	case TensorRef, TensorRec:
+		return expRefDS{K: tensorKind, ExpVK: expVK}
+	case LolliRef, LolliRec:
+		return expRefDS{K: lolliKind, ExpVK: expVK}
 	case PlusRef, PlusRec:
 		return expRefDS{K: plusKind, ExpVK: expVK}
 	case WithRef, WithRec:
@@ -234,6 +306,10 @@ func dataToExpRef(dto expRefDS) (ExpRef, error) {
 		return OneRef{expVK}, nil
 	case linkKind:
 		return LinkRef{expVK}, nil
+	case tensorKind:
+		return TensorRef{expVK}, nil
+	case lolliKind:
+		return LolliRef{expVK}, nil
 	case plusKind:
 		return PlusRef{expVK}, nil
 	case withKind:
@@ -277,6 +353,26 @@ func statesToExpRec(states map[int64]stateDS, st stateDS) (ExpRec, error) {
 			return nil, err
 		}
 		return LinkRec{ExpVK: expVK, TypeQN: xactQN}, nil
+	case tensorKind:
+		valExp, err := statesToExpRec(states, states[st.Spec.Tensor.ValExpVK])
+		if err != nil {
+			return nil, err
+		}
+		contExp, err := statesToExpRec(states, states[st.Spec.Tensor.ContExpVK])
+		if err != nil {
+			return nil, err
+		}
+		return TensorRec{ExpVK: expVK, ValExp: valExp, ContExp: contExp}, nil
+	case lolliKind:
+		valExp, err := statesToExpRec(states, states[st.Spec.Lolli.ValExpVK])
+		if err != nil {
+			return nil, err
+		}
+		contExp, err := statesToExpRec(states, states[st.Spec.Lolli.ContExpVK])
+		if err != nil {
+			return nil, err
+		}
+		return LolliRec{ExpVK: expVK, ValExp: valExp, ContExp: contExp}, nil
 	case plusKind:
 		procQNs, err := uniqsym.ConvertFromStrings(st.Spec.Plus.ProcQNs)
 		if err != nil {
@@ -332,6 +428,30 @@ func statesFromExpRec(supExpVK int64, r ExpRec, dto *expRecDS) int64 {
 		}
 		dto.States = append(dto.States, st)
 		return expVK
+	case TensorRec:
+		st := stateDS{
+			ExpVK:    expVK,
+			K:        tensorKind,
+			SupExpVK: supExpVK,
+			Spec: expSpecDS{Tensor: &prodDS{
+				ValExpVK:  statesFromExpRec(expVK, rec.ValExp, dto),
+				ContExpVK: statesFromExpRec(expVK, rec.ContExp, dto),
+			}},
+		}
+		dto.States = append(dto.States, st)
+		return expVK
+	case LolliRec:
+		st := stateDS{
+			ExpVK:    expVK,
+			K:        lolliKind,
+			SupExpVK: supExpVK,
+			Spec: expSpecDS{Lolli: &prodDS{
+				ValExpVK:  statesFromExpRec(expVK, rec.ValExp, dto),
+				ContExpVK: statesFromExpRec(expVK, rec.ContExp, dto),
+			}},
+		}
+		dto.States = append(dto.States, st)
+		return expVK
 	case PlusRec:
 		st := stateDS{
 			ExpVK:    expVK,
@@ -0,0 +1,41 @@
+package typeexp
+
+import (
+	"testing"
+
+	"orglang/go-engine/adt/symbol"
+	"orglang/go-engine/adt/uniqsym"
+)
+
+func TestConvertSpecToRecDistinct(t *testing.T) {
+	a := uniqsym.New(symbol.New("a"))
+	b := uniqsym.New(symbol.New("b"))
+	one := OneSpec{}
+	var tests = []struct {
+		name string
+		x, y ExpSpec
+	}{
+		{"plus vs with", PlusSpec{[]uniqsym.ADT{a}, one}, WithSpec{[]uniqsym.ADT{a}, one}},
+		{"proc order", WithSpec{[]uniqsym.ADT{a, b}, one}, WithSpec{[]uniqsym.ADT{b, a}, one}},
+		{"up vs down", UpSpec{one}, DownSpec{one}},
+		{"tensor vs lolli", TensorSpec{one, one}, LolliSpec{one, one}},
+		{"val vs cont", TensorSpec{one, LinkSpec{a}}, TensorSpec{LinkSpec{a}, one}},
+		{"link vs link", LinkSpec{a}, LinkSpec{b}},
+		{"cont", WithSpec{[]uniqsym.ADT{a}, one}, WithSpec{[]uniqsym.ADT{a}, LinkSpec{a}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			x, err := ConvertSpecToRec(test.x)
+			if err != nil {
+				t.Fatal(err)
+			}
+			y, err := ConvertSpecToRec(test.y)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if x.Key() == y.Key() {
+				t.Errorf("got collision %v", x.Key())
+			}
+		})
+	}
+}
@@ -1,15 +1,51 @@
 package commexch
 
 import (
+	"database/sql"
+	"log/slog"
+
 	"orglang/go-engine/lib/db"
 
 	"orglang/go-engine/adt/commsem"
 	"orglang/go-engine/adt/uniqsym"
+	"orglang/go-engine/proc/commturn"
 )
 
 type Repo interface {
 	AddRec(db.Source, ExchRec) error
-	Modifyec(db.Source, ExchMod) error
+	ModifyRec(db.Source, ExchMod) error
 	GetRefsByQNs(db.Source, []uniqsym.ADT) (map[uniqsym.ADT]commsem.SemRef, error)
 	GetSnapByQry(db.Source, ExchQry) (ExchSnap, error)
 }
+
+// хранилищу в памяти запросы не нужны, поэтому DAO выбирается по диалекту
+func newDialectDAO(dialect db.Dialect, qb queryBuilder, log *slog.Logger) Repo {
+	if dialect == db.Memory {
+		return newMemDAO(log)
+	}
+	return newPgxDAO(qb, log)
+}
+
+type exchRecDS struct {
+	CommID   string `db:"comm_id"`
+	CommRN   int64  `db:"comm_rn"`
+	OffsetNr int64  `db:"offset_nr"`
+}
+
+type exchModDS struct {
+	CommID   string          `db:"comm_id"`
+	CommRN   int64           `db:"comm_rn"`
+	OffsetNr sql.Null[int64] `db:"offset_nr"`
+}
+
+type exchQryDS struct {
+	CommID string           `db:"comm_id"`
+	ChnlID sql.Null[string] `db:"chnl_id"`
+}
+
+type exchSnapDS struct {
+	CommID   string               `db:"comm_id"`
+	CommRN   int64                `db:"comm_rn"`
+	OffsetNr int64                `db:"offset_nr"`
+	Turns    []commturn.TurnRecDS `db:"turns"`
+}
@@ -0,0 +1,112 @@
+package commexch
+
+import (
+	"log/slog"
+	"reflect"
+
+	"orglang/go-engine/lib/db"
+	"orglang/go-engine/lib/lf"
+
+	"orglang/go-engine/adt/commsem"
+	"orglang/go-engine/adt/uniqsym"
+	"orglang/go-engine/proc/commturn"
+)
+
+const (
+	// ревизию обмена продвигают и ходы, а их пакет не видит строк обмена,
+	// поэтому в памяти она хранится отдельно от офсета
+	exchRevs = "proc_comm_exchs.comm_rn"
+)
+
+// Adapter
+type memDAO struct {
+	log *slog.Logger
+}
+
+func newMemDAO(log *slog.Logger) *memDAO {
+	name := slog.String("name", reflect.TypeFor[memDAO]().Name())
+	return &memDAO{log.With(name)}
+}
+
+// NewMemRepo отдает хранилище в памяти тестам соседних модулей,
+// которые собирают сервисы без fx
+func NewMemRepo(log *slog.Logger) Repo {
+	return newMemDAO(log)
+}
+
+func (dao *memDAO) AddRec(source db.Source, rec ExchRec) error {
+	ds := db.MustConform[db.SourceMem](source)
+	dto := DataFromRec(rec)
+	refAttr := slog.Any("ref", rec.CommRef)
+	err := db.TableOf[string, exchRecDS](ds, commExchs).Insert(dto.CommID, dto)
+	if err != nil {
+		dao.log.Error("insertion failed", refAttr)
+		return err
+	}
+	db.TableOf[string, int64](ds, exchRevs).Put(dto.CommID, dto.CommRN)
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "addition succeed", slog.Any("dto", dto))
+	return nil
+}
+
+func (dao *memDAO) ModifyRec(source db.Source, mod ExchMod) error {
+	if mod.CommRef.CommID.IsEmpty() {
+		return nil
+	}
+	ds := db.MustConform[db.SourceMem](source)
+	dto := DataFromMod(mod)
+	refAttr := slog.Any("ref", mod.CommRef)
+	revs := db.TableOf[string, int64](ds, exchRevs)
+	commRN, ok := revs.Get(dto.CommID)
+	if !ok || commRN != dto.CommRN {
+		dao.log.Error("update failed", refAttr)
+		return errConcurrentModification(mod.CommRef)
+	}
+	revs.Put(dto.CommID, commRN+1)
+	if dto.OffsetNr.Valid {
+		exchs := db.TableOf[string, exchRecDS](ds, commExchs)
+		exchDTO, _ := exchs.Get(dto.CommID)
+		exchDTO.OffsetNr = dto.OffsetNr.V
+		exchs.Put(dto.CommID, exchDTO)
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "modification succeed", slog.Any("dto", dto), slog.Int64("rn", commRN+1))
+	return nil
+}
+
+func (dao *memDAO) GetRefsByQNs(db.Source, []uniqsym.ADT) (map[uniqsym.ADT]commsem.SemRef, error) {
+	panic("unimplemented")
+}
+
+func (dao *memDAO) GetSnapByQry(source db.Source, qry ExchQry) (ExchSnap, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	refAttr := slog.Any("ref", qry.CommRef)
+	qryDTO := DataFromQry(qry)
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "getting started", slog.Any("qry", qryDTO))
+	exchDTO, err := db.TableOf[string, exchRecDS](ds, commExchs).Find(qryDTO.CommID)
+	if err != nil {
+		dao.log.Error("row selection failed", refAttr)
+		return ExchSnap{}, err
+	}
+	commRN, _ := db.TableOf[string, int64](ds, exchRevs).Get(qryDTO.CommID)
+	snapDTO := exchSnapDS{
+		CommID:   exchDTO.CommID,
+		CommRN:   commRN,
+		OffsetNr: exchDTO.OffsetNr,
+	}
+	// ходы вставляются в порядке ревизий обмена
+	for _, turnDTO := range db.TableOf[commsem.SemRefDS, commturn.TurnRecDS](ds, commTurns).Rows() {
+		if turnDTO.CommID != qryDTO.CommID || turnDTO.CommRN <= exchDTO.OffsetNr {
+			continue
+		}
+		if qryDTO.ChnlID.Valid && turnDTO.ChnlID != qryDTO.ChnlID.V {
+			continue
+		}
+		snapDTO.Turns = append(snapDTO.Turns, turnDTO)
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "getting succeed", slog.Any("dto", snapDTO))
+	snap, convErr := DataToSnap(snapDTO)
+	if convErr != nil {
+		dao.log.Error("model conversion failed", refAttr)
+		return ExchSnap{}, convErr
+	}
+	return snap, nil
+}
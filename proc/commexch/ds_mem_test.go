@@ -0,0 +1,63 @@
+package commexch
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"orglang/go-engine/lib/db"
+
+	"orglang/go-engine/adt/commsem"
+	"orglang/go-engine/adt/option"
+	"orglang/go-engine/adt/seqnum"
+)
+
+func TestModifyRecMem(t *testing.T) {
+	tests := []struct {
+		name   string
+		commRN seqnum.ADT
+		err    error
+	}{
+		{"actual", seqnum.New(), nil},
+		{"stale", seqnum.New().Next(), db.ErrConcurrentModification},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			operator := db.NewOperatorMem()
+			dao := newMemDAO(slog.New(slog.DiscardHandler))
+			ref := commsem.New()
+			err := operator.Explicit(ctx, func(ds db.Source) error {
+				return dao.AddRec(ds, ExchRec{CommRef: ref})
+			})
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			mod := ExchMod{
+				CommRef: commsem.SemRef{CommID: ref.CommID, CommRN: tt.commRN},
+				CommON:  option.Some(seqnum.New()),
+			}
+			err = operator.Explicit(ctx, func(ds db.Source) error {
+				return dao.ModifyRec(ds, mod)
+			})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("unexpected error: want %q, got %q", tt.err, err)
+			}
+			var snap ExchSnap
+			err = operator.Explicit(ctx, func(ds db.Source) error {
+				snap, err = dao.GetSnapByQry(ds, ExchQry{CommRef: ref})
+				return err
+			}, db.WithReadOnly())
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			if tt.err == nil && snap.CommRef.CommRN != ref.CommRN.Next() {
+				t.Errorf("unexpected revision: want %v, got %v", ref.CommRN.Next(), snap.CommRef.CommRN)
+			}
+			if tt.err != nil && snap.CommRef.CommRN != ref.CommRN {
+				t.Errorf("unexpected revision: want %v, got %v", ref.CommRN, snap.CommRef.CommRN)
+			}
+		})
+	}
+}
@@ -1,20 +1,28 @@
 package commexch
 
 import (
+	"errors"
+	"fmt"
 	"log/slog"
+	"reflect"
+
+	"orglang/go-engine/lib/db"
+	"orglang/go-engine/lib/lf"
+
 	"orglang/go-engine/adt/commsem"
 	"orglang/go-engine/adt/uniqsym"
-	"orglang/go-engine/lib/db"
-	"reflect"
+
+	"github.com/jackc/pgx/v5"
 )
 
 type pgxDAO struct {
+	qb  queryBuilder
 	log *slog.Logger
 }
 
-func newPgxDAO(log *slog.Logger) *pgxDAO {
+func newPgxDAO(qb queryBuilder, log *slog.Logger) *pgxDAO {
 	name := slog.String("name", reflect.TypeFor[pgxDAO]().Name())
-	return &pgxDAO{log.With(name)}
+	return &pgxDAO{qb, log.With(name)}
 }
 
 // for compilation purposes
@@ -22,18 +30,73 @@ func newRepo() Repo {
 	return new(pgxDAO)
 }
 
-func (dao *pgxDAO) AddRec(db.Source, ExchRec) error {
-	panic("unimplemented")
+func (dao *pgxDAO) AddRec(source db.Source, rec ExchRec) error {
+	ds := db.MustConform[db.SourcePgx](source)
+	dto := DataFromRec(rec)
+	refAttr := slog.Any("ref", rec.CommRef)
+	sql, args := dao.qb.insertRec(dto)
+	_, execErr := ds.Conn.Exec(ds.Ctx, sql, args...)
+	if execErr != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("sql", sql))
+		return execErr
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "addition succeed", slog.Any("dto", dto))
+	return nil
+}
+
+// ревизия обмена сверяется со снепшотом, по которому был сделан шаг
+func (dao *pgxDAO) ModifyRec(source db.Source, mod ExchMod) error {
+	if mod.CommRef.CommID.IsEmpty() {
+		return nil
+	}
+	ds := db.MustConform[db.SourcePgx](source)
+	dto := DataFromMod(mod)
+	refAttr := slog.Any("ref", mod.CommRef)
+	sql, args := dao.qb.updateRec(dto)
+	var commRN int64
+	scanErr := ds.Conn.QueryRow(ds.Ctx, sql, args...).Scan(&commRN)
+	if errors.Is(scanErr, pgx.ErrNoRows) {
+		dao.log.Error("update failed", refAttr)
+		return errConcurrentModification(mod.CommRef)
+	}
+	if scanErr != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("sql", sql))
+		return scanErr
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "modification succeed", slog.Any("dto", dto), slog.Int64("rn", commRN))
+	return nil
 }
 
 func (dao *pgxDAO) GetRefsByQNs(db.Source, []uniqsym.ADT) (map[uniqsym.ADT]commsem.SemRef, error) {
 	panic("unimplemented")
 }
 
-func (dao *pgxDAO) GetSnapByQry(db.Source, ExchQry) (ExchSnap, error) {
-	panic("unimplemented")
+func (dao *pgxDAO) GetSnapByQry(source db.Source, qry ExchQry) (ExchSnap, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	refAttr := slog.Any("ref", qry.CommRef)
+	qryDTO := DataFromQry(qry)
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "getting started", slog.Any("qry", qryDTO))
+	sql, args := dao.qb.selectSnap(qryDTO)
+	rows, execErr := ds.Conn.Query(ds.Ctx, sql, args...)
+	if execErr != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("sql", sql))
+		return ExchSnap{}, execErr
+	}
+	defer rows.Close()
+	snapDTO, scanErr := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[exchSnapDS])
+	if scanErr != nil {
+		dao.log.Error("row scanning failed", refAttr)
+		return ExchSnap{}, scanErr
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "getting succeed", slog.Any("dto", snapDTO))
+	snap, convErr := DataToSnap(snapDTO)
+	if convErr != nil {
+		dao.log.Error("model conversion failed", refAttr)
+		return ExchSnap{}, convErr
+	}
+	return snap, nil
 }
 
-func (dao *pgxDAO) Modifyec(db.Source, ExchMod) error {
-	panic("unimplemented")
+func errConcurrentModification(got commsem.SemRef) error {
+	return fmt.Errorf("%w: %v", db.ErrConcurrentModification, got)
 }
@@ -0,0 +1,23 @@
+package commexch
+
+import (
+	"orglang/go-engine/lib/db"
+)
+
+const (
+	commExchs = "proc_comm_exchs "
+	commTurns = "proc_comm_turns "
+)
+
+type queryBuilder interface {
+	insertRec(exchRecDS) (string, []any)
+	updateRec(exchModDS) (string, []any)
+	selectSnap(exchQryDS) (string, []any)
+}
+
+func newDialectBuilder(dialect db.Dialect) queryBuilder {
+	if dialect == db.SQLite {
+		return newSQLiteBuilder()
+	}
+	return newSQLBuilder()
+}
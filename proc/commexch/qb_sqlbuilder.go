@@ -0,0 +1,64 @@
+package commexch
+
+import (
+	"github.com/huandu/go-sqlbuilder"
+
+	"orglang/go-engine/proc/commturn"
+)
+
+type sqlBuilder struct {
+	exchBuilder *sqlbuilder.Struct
+	turnBuilder *sqlbuilder.Struct
+}
+
+// for compilation purposes
+func newQueryBuilder() queryBuilder {
+	return new(sqlBuilder)
+}
+
+func newSQLBuilder() *sqlBuilder {
+	exchBuilder := sqlbuilder.NewStruct(new(exchRecDS)).For(sqlbuilder.PostgreSQL)
+	turnBuilder := sqlbuilder.NewStruct(new(commturn.TurnRecDS)).For(sqlbuilder.PostgreSQL)
+	return &sqlBuilder{exchBuilder, turnBuilder}
+}
+
+func (qb *sqlBuilder) insertRec(rec exchRecDS) (string, []any) {
+	return qb.exchBuilder.InsertInto(commExchs, rec).Build()
+}
+
+// продвигает ревизию с проверкой на конкурентное изменение
+func (qb *sqlBuilder) updateRec(mod exchModDS) (string, []any) {
+	exch := sqlbuilder.PostgreSQL.NewUpdateBuilder()
+	exch.Update(commExchs)
+	exch.Set(exch.Incr("comm_rn"))
+	if mod.OffsetNr.Valid {
+		exch.SetMore(exch.Assign("offset_nr", mod.OffsetNr.V))
+	}
+	exch.Where(exch.Equal("comm_id", mod.CommID), exch.Equal("comm_rn", mod.CommRN))
+	exch.Returning("comm_rn")
+	return exch.Build()
+}
+
+func (qb *sqlBuilder) selectSnap(qry exchQryDS) (string, []any) {
+	return qb.buildSnap(qry, turnsAgg)
+}
+
+func (qb *sqlBuilder) buildSnap(qry exchQryDS, agg string) (string, []any) {
+	exch := qb.exchBuilder.SelectFrom(commExchs + "exch")
+	turn := qb.turnBuilder.SelectFrom(commTurns + "turn")
+	turn.Where(turn.Equal("comm_id", qry.CommID))
+	if qry.ChnlID.Valid {
+		turn.Where(turn.Equal("chnl_id", qry.ChnlID.V))
+	}
+	turns := sqlbuilder.PostgreSQL.NewCTEBuilder()
+	return exch.With(turns.With(sqlbuilder.CTEQuery("turns").As(turn))).
+		SelectMore(
+			exch.BuilderAs(sqlbuilder.Build(agg), "turns"),
+		).
+		Where(exch.Equal("exch.comm_id", qry.CommID)).
+		Build()
+}
+
+const (
+	turnsAgg = "SELECT json_agg(t ORDER BY t.comm_rn) FROM turns t WHERE t.comm_rn > exch.offset_nr"
+)
@@ -6,6 +6,10 @@ import (
 
 var Module = fx.Module("proc/commturn",
 	fx.Provide(
-		fx.Annotate(newPgxDAO, fx.As(new(Repo))),
+		newDialectDAO,
+	),
+	fx.Provide(
+		fx.Private,
+		newDialectBuilder,
 	),
 )
@@ -1,7 +1,7 @@
 package commturn
 
 import (
-	"database/sql"
+	"log/slog"
 
 	"orglang/go-engine/lib/db"
 
@@ -9,20 +9,30 @@ import (
 )
 
 type Repo interface {
-	InsertRecs(db.Source, ...TurnRec) error
+	AddRecs(db.Source, []TurnRec) error
 }
 
-type StepRecDS struct {
-	K      stepKindDS       `db:"kind"`
-	ExecID sql.NullString   `db:"impl_id"`
-	ChnlID sql.NullString   `db:"chnl_id"`
-	ProcER termexp.ExpRecDS `db:"proc_er"`
+// хранилищу в памяти запросы не нужны, поэтому DAO выбирается по диалекту
+func newDialectDAO(dialect db.Dialect, qb queryBuilder, log *slog.Logger) Repo {
+	if dialect == db.Memory {
+		return newMemDAO(log)
+	}
+	return newPgxDAO(qb, log)
 }
 
-type stepKindDS int
+type TurnRecDS struct {
+	CommID string           `db:"comm_id" json:"comm_id"`
+	CommRN int64            `db:"comm_rn" json:"comm_rn"`
+	CompID string           `db:"comp_id" json:"comp_id"`
+	ChnlID string           `db:"chnl_id" json:"chnl_id"`
+	K      turnKind         `db:"kind" json:"kind"`
+	Exp    termexp.ExpRecDS `db:"exp" json:"exp" fieldopt:"noexpand"`
+}
+
+type turnKind int16
 
 const (
-	nonStep = stepKindDS(iota)
-	msgStep
-	svcStep
+	unkKind turnKind = iota
+	pubKind
+	subKind
 )
@@ -0,0 +1,61 @@
+package commturn
+
+import (
+	"log/slog"
+	"reflect"
+
+	"orglang/go-engine/lib/db"
+	"orglang/go-engine/lib/lf"
+
+	"orglang/go-engine/adt/commsem"
+)
+
+const (
+	// ревизии обменов, которые в памяти хранятся отдельно от их строк
+	exchRevs = "proc_comm_exchs.comm_rn"
+)
+
+// Adapter
+type memDAO struct {
+	log *slog.Logger
+}
+
+func newMemDAO(log *slog.Logger) *memDAO {
+	name := slog.String("name", reflect.TypeFor[memDAO]().Name())
+	return &memDAO{log.With(name)}
+}
+
+// NewMemRepo отдает хранилище в памяти тестам соседних модулей,
+// которые собирают сервисы без fx
+func NewMemRepo(log *slog.Logger) Repo {
+	return newMemDAO(log)
+}
+
+// как и в postgres, ревизию хода назначает обмен
+func (dao *memDAO) AddRecs(source db.Source, recs []TurnRec) error {
+	if len(recs) == 0 {
+		return nil
+	}
+	ds := db.MustConform[db.SourceMem](source)
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "insertion started", slog.Any("recs", recs))
+	revs := db.TableOf[string, int64](ds, exchRevs)
+	turns := db.TableOf[commsem.SemRefDS, TurnRecDS](ds, commTurns)
+	for _, rec := range recs {
+		dto, convErr := DataFromTurnRec(rec)
+		if convErr != nil {
+			dao.log.Error("model conversion failed", slog.Any("rec", rec))
+			return convErr
+		}
+		commRN, ok := revs.Get(dto.CommID)
+		if !ok {
+			// в postgres ход без обмена не вставляется вовсе
+			dao.log.Warn("exchange not found", slog.Any("rec", rec))
+			continue
+		}
+		dto.CommRN = commRN + 1
+		revs.Put(dto.CommID, dto.CommRN)
+		turns.Put(commsem.SemRefDS{CommID: dto.CommID, CommRN: dto.CommRN}, dto)
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "insertion succeed")
+	return nil
+}
@@ -9,96 +9,50 @@ import (
 
 	"orglang/go-engine/lib/db"
 	"orglang/go-engine/lib/lf"
-
-	"orglang/go-engine/adt/identity"
 )
 
 type pgxDAO struct {
+	qb  queryBuilder
 	log *slog.Logger
 }
 
+func newPgxDAO(qb queryBuilder, log *slog.Logger) *pgxDAO {
+	name := slog.String("name", reflect.TypeFor[pgxDAO]().Name())
+	return &pgxDAO{qb, log.With(name)}
+}
+
 // for compilation purposes
 func newRepo() Repo {
 	return new(pgxDAO)
 }
 
-func newPgxDAO(l *slog.Logger) *pgxDAO {
-	name := slog.String("name", reflect.TypeFor[pgxDAO]().Name())
-	return &pgxDAO{l.With(name)}
-}
-
-func (dao *pgxDAO) InsertRecs(source db.Source, recs ...TurnRec) error {
-	ds := db.MustConform[db.SourcePgx](source)
-	dtos, err := DataFromStepRecs(recs)
-	if err != nil {
-		dao.log.Error("conversion failed")
-		return err
+func (dao *pgxDAO) AddRecs(source db.Source, recs []TurnRec) (err error) {
+	if len(recs) == 0 {
+		return nil
 	}
+	ds := db.MustConform[db.SourcePgx](source)
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "insertion started", slog.Any("recs", recs))
 	batch := pgx.Batch{}
-	for _, dto := range dtos {
-		args := pgx.NamedArgs{
-			"kind":    dto.K,
-			"comp_id": dto.ExecID,
-			"chnl_id": dto.ChnlID,
-			"proc_er": dto.ProcER,
+	for _, rec := range recs {
+		dto, convErr := DataFromTurnRec(rec)
+		if convErr != nil {
+			dao.log.Error("model conversion failed", slog.Any("rec", rec))
+			return convErr
 		}
-		batch.Queue(insertStep, args)
+		sql, args := dao.qb.insertRec(dto)
+		batch.Queue(sql, args...)
 	}
 	br := ds.Conn.SendBatch(ds.Ctx, &batch)
 	defer func() {
 		err = errors.Join(err, br.Close())
 	}()
-	for _, dto := range dtos {
-		_, err = br.Exec()
-		if err != nil {
-			dao.log.Error("execution failed", slog.String("q", insertStep), slog.Any("dto", dto))
+	for _, rec := range recs {
+		_, execErr := br.Exec()
+		if execErr != nil {
+			dao.log.Error("query execution failed", slog.Any("rec", rec))
+			return execErr
 		}
 	}
-	if err != nil {
-		return err
-	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "insertion succeed")
 	return nil
 }
-
-func (dao *pgxDAO) SelectRecs(source db.Source, rid identity.ADT) (TurnRec, error) {
-	query := `
-		select
-			id, kind, pid, vid, spec
-		from steps
-		WHERE id = $1`
-	return dao.execute(source, query, rid.String())
-}
-
-func (dao *pgxDAO) execute(source db.Source, query string, arg string) (TurnRec, error) {
-	ds := db.MustConform[db.SourcePgx](source)
-	rows, err := ds.Conn.Query(ds.Ctx, query, arg)
-	if err != nil {
-		dao.log.Error("query execution failed", slog.String("q", query))
-		return nil, err
-	}
-	defer rows.Close()
-	dto, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[StepRecDS])
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, nil
-	}
-	if err != nil {
-		dao.log.Error("row scanning failed")
-		return nil, err
-	}
-	root, err := dataToStepRec(dto)
-	if err != nil {
-		dao.log.Error("model conversion failed")
-		return nil, err
-	}
-	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity selection succeed", slog.Any("root", root))
-	return root, nil
-}
-
-const (
-	insertStep = `
-		insert into pool_comm_turns (
-			id, kind, pid, vid, spec
-		) values (
-			@id, @kind, @pid, @vid, @spec
-		)`
-)
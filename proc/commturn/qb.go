@@ -0,0 +1,21 @@
+package commturn
+
+import (
+	"orglang/go-engine/lib/db"
+)
+
+const (
+	commExchs = "proc_comm_exchs "
+	commTurns = "proc_comm_turns "
+)
+
+type queryBuilder interface {
+	insertRec(TurnRecDS) (string, []any)
+}
+
+func newDialectBuilder(dialect db.Dialect) queryBuilder {
+	if dialect == db.SQLite {
+		return newSQLiteBuilder()
+	}
+	return newSQLBuilder()
+}
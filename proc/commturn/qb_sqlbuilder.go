@@ -0,0 +1,27 @@
+package commturn
+
+import (
+	"github.com/huandu/go-sqlbuilder"
+)
+
+type sqlBuilder struct{}
+
+// for compilation purposes
+func newQueryBuilder() queryBuilder {
+	return new(sqlBuilder)
+}
+
+func newSQLBuilder() *sqlBuilder {
+	return &sqlBuilder{}
+}
+
+// ревизия хода назначается обменом, что упорядочивает ходы
+// в пределах обмена и позволяет сдвигать его офсет
+func (qb *sqlBuilder) insertRec(rec TurnRecDS) (string, []any) {
+	return sqlbuilder.Build(
+		"WITH exch AS (UPDATE "+commExchs+"SET comm_rn = comm_rn + 1 WHERE comm_id = $? RETURNING comm_id, comm_rn) "+
+			"INSERT INTO "+commTurns+"(comm_id, comm_rn, comp_id, chnl_id, kind, exp) "+
+			"SELECT exch.comm_id, exch.comm_rn, $?, $?, $?, $? FROM exch",
+		rec.CommID, rec.CompID, rec.ChnlID, rec.K, rec.Exp,
+	).BuildWithFlavor(sqlbuilder.PostgreSQL)
+}
@@ -0,0 +1,24 @@
+package commturn
+
+import (
+	"github.com/huandu/go-sqlbuilder"
+)
+
+// sqliteBuilder заменяет запросы, у которых нет переносимой формы
+type sqliteBuilder struct {
+	*sqlBuilder
+}
+
+func newSQLiteBuilder() *sqliteBuilder {
+	return &sqliteBuilder{newSQLBuilder()}
+}
+
+// SQLite не допускает изменений в WITH, поэтому ревизию обмена
+// продвигает триггер proc_comm_turns_rn
+func (qb *sqliteBuilder) insertRec(rec TurnRecDS) (string, []any) {
+	return sqlbuilder.Build(
+		"INSERT INTO "+commTurns+"(comm_id, comm_rn, comp_id, chnl_id, kind, exp) "+
+			"SELECT exch.comm_id, exch.comm_rn + 1, $?, $?, $?, $? FROM "+commExchs+"exch WHERE exch.comm_id = $?",
+		rec.CompID, rec.ChnlID, rec.K, rec.Exp, rec.CommID,
+	).BuildWithFlavor(sqlbuilder.PostgreSQL)
+}
@@ -0,0 +1,12 @@
+package commturn
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestInsertRecSQLite(t *testing.T) {
+	qb := newSQLiteBuilder()
+	sql, _ := qb.insertRec(TurnRecDS{})
+	fmt.Println(sql)
+}
@@ -3,60 +3,60 @@ package commturn
 import (
 	"fmt"
 
+	"orglang/go-engine/adt/commsem"
+	"orglang/go-engine/adt/compsem"
+	"orglang/go-engine/adt/identity"
 	"orglang/go-engine/proc/termexp"
 )
 
-func dataFromStepRec(r TurnRec) (StepRecDS, error) {
-	if r == nil {
-		return StepRecDS{}, nil
-	}
+func DataFromTurnRec(r TurnRec) (TurnRecDS, error) {
 	switch rec := r.(type) {
 	case PubRec:
-		msgVal, err := termexp.DataFromExpRec(rec.ValExp)
+		exp, err := termexp.DataFromExpRec(rec.ValExp)
 		if err != nil {
-			return StepRecDS{}, err
+			return TurnRecDS{}, err
 		}
-		return StepRecDS{
-			K:      msgStep,
-			ProcER: msgVal,
+		commRef := commsem.DataFromRef(rec.CommRef)
+		compRef := compsem.DataFromRef(rec.CompRef)
+		return TurnRecDS{
+			CommID: commRef.CommID,
+			CommRN: commRef.CommRN,
+			CompID: compRef.CompID,
+			ChnlID: identity.ConvertToString(rec.ChnlID),
+			K:      pubKind,
+			Exp:    exp,
 		}, nil
 	case SubRec:
-		svcCont, err := termexp.DataFromExpRec(rec.ContExp)
+		exp, err := termexp.DataFromExpRec(rec.ContExp)
 		if err != nil {
-			return StepRecDS{}, err
+			return TurnRecDS{}, err
 		}
-		return StepRecDS{
-			K:      svcStep,
-			ProcER: svcCont,
+		commRef := commsem.DataFromRef(rec.CommRef)
+		compRef := compsem.DataFromRef(rec.CompRef)
+		return TurnRecDS{
+			CommID: commRef.CommID,
+			CommRN: commRef.CommRN,
+			CompID: compRef.CompID,
+			ChnlID: identity.ConvertToString(rec.ChnlID),
+			K:      subKind,
+			Exp:    exp,
 		}, nil
 	default:
-		panic(ErrRecTypeUnexpected(rec))
+		panic(ErrRecTypeUnexpected(r))
 	}
 }
 
-func dataToStepRec(dto StepRecDS) (TurnRec, error) {
-	var nilData StepRecDS
-	if dto == nilData {
-		return nil, nil
-	}
+func DataToTurnRec(dto TurnRecDS) (TurnRec, error) {
 	switch dto.K {
-	case msgStep:
-		val, err := termexp.DataToExpRec(dto.ProcER)
-		if err != nil {
-			return nil, err
-		}
-		return PubRec{ValExp: val}, nil
-	case svcStep:
-		cont, err := termexp.DataToExpRec(dto.ProcER)
-		if err != nil {
-			return nil, err
-		}
-		return SubRec{ContExp: cont}, nil
+	case pubKind:
+		return DataToPubRec(dto)
+	case subKind:
+		return DataToSubRec(dto)
 	default:
-		panic(errUnexpectedStepKind(dto.K))
+		panic(errTurnKindUnexpected(dto.K))
 	}
 }
 
-func errUnexpectedStepKind(k stepKindDS) error {
-	return fmt.Errorf("unexpected step kind: %v", k)
+func errTurnKindUnexpected(k turnKind) error {
+	return fmt.Errorf("turn kind unexpected: %v", k)
 }
@@ -2,14 +2,21 @@ package compexec
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"iter"
 	"log/slog"
 	"maps"
+	"math/rand/v2"
 	"reflect"
+	"runtime/debug"
+	"slices"
+	"time"
 
 	"orglang/go-engine/lib/db"
+	"orglang/go-engine/lib/wp"
 
+	"orglang/go-engine/adt/commsem"
 	"orglang/go-engine/adt/compsem"
 	"orglang/go-engine/adt/compvar"
 	"orglang/go-engine/adt/identity"
@@ -17,10 +24,12 @@ import (
 	"orglang/go-engine/adt/polarity"
 	"orglang/go-engine/adt/seqnum"
 	"orglang/go-engine/adt/symbol"
+	"orglang/go-engine/adt/termsem"
 	"orglang/go-engine/adt/uniqsym"
 	"orglang/go-engine/adt/valkey"
 	"orglang/go-engine/proc/commexch"
 	"orglang/go-engine/proc/commturn"
+	"orglang/go-engine/proc/compfuel"
 	"orglang/go-engine/proc/compstep"
 	"orglang/go-engine/proc/termdec"
 	"orglang/go-engine/proc/termdef"
@@ -32,6 +41,13 @@ import (
 type API interface {
 	Take(compstep.StepSpec) error
 	RetrieveSnap(compsem.SemRef) (ExecSnap, error)
+	// Replay повторяет журнал вычисления над хранилищем в памяти
+	// и сверяет результаты с историей; шаги, взятые до появления
+	// журнала (миграция 0005), не повторяются
+	Replay(compsem.SemRef) (ReplayRep, error)
+	// Refuel пополняет запас вычисления или пула и возобновляет
+	// остановленные без запаса шаги процессов
+	Refuel(compfuel.FuelSpec) (compfuel.FuelRec, error)
 }
 
 type ExecRec struct {
@@ -42,6 +58,10 @@ type ExecRec struct {
 type ExecMod struct {
 	CompRefs   []compsem.SemRef
 	LinearVars []compvar.LinearRec
+	// порожденные вычисления
+	NewExecs []ExecRec
+	// порожденные коммуникации
+	NewExchs []commexch.ExchRec
 }
 
 type ExecEff struct {
@@ -52,12 +72,55 @@ type ExecEff struct {
 type ExecSnap struct {
 	CompRef    compsem.SemRef
 	LinearVars map[symbol.ADT]compvar.LinearRec
+	// текущие типы сессий переменных
+	LinearExps map[symbol.ADT]typeexp.ExpRec
+	// ожидающие ходы по каналам переменных
+	LinearTurns map[symbol.ADT][]commturn.TurnRec
+}
+
+// запись журнала: шаг и обмены, какими он их увидел,
+// достаточны, чтобы повторить шаг без остальных вычислений
+type StepRec struct {
+	// ревизия, на которой взят шаг
+	CompRef   compsem.SemRef
+	ProcExp   termexp.ExpSpec
+	ExchSnaps []commexch.ExchSnap
+	// шаги, взятые до миграции 0009, окружения не хранят
+	EnvKeys option.ADT[EnvKeys]
+}
+
+// ключи окружения шага: ревизии термов и значения типов,
+// по которым окружение восстанавливается при повторе
+type EnvKeys struct {
+	TermDecs map[uniqsym.ADT]termsem.SemRef
+	TermDefs map[uniqsym.ADT]termsem.SemRef
+	// значения тел именованных типов
+	TypeDefs map[uniqsym.ADT]valkey.ADT
+	TypeExps []valkey.ADT
+}
+
+type ReplayRep struct {
+	CompRef compsem.SemRef
+	// число совпавших шагов
+	Replayed int
+	// первый разошедшийся шаг, если такой есть
+	Divergence *StepDiv
+}
+
+type StepDiv struct {
+	StepRef compsem.SemRef
+	ChnlPH  symbol.ADT
+	WantVK  valkey.ADT
+	GotVK   valkey.ADT
+	Reason  string
 }
 
 type Env struct {
-	TypeDefs map[uniqsym.ADT]typedef.DefRec
 	TypeExps map[valkey.ADT]typeexp.ExpRec
-	ProcDecs map[identity.ADT]termdec.DecRec
+	TermDecs map[uniqsym.ADT]termdec.DecRec
+	TermDefs map[uniqsym.ADT]termdef.DefRec
+	// тела именованных типов для развертки ссылок
+	TypeDefs typeexp.Defs
 }
 
 func ChnlPH(rec compvar.LinearRec) symbol.ADT { return rec.ChnlPH }
@@ -65,9 +128,13 @@ func ChnlPH(rec compvar.LinearRec) symbol.ADT { return rec.ChnlPH }
 type service struct {
 	compExecRepo Repo
 	commExchRepo commexch.Repo
+	commTurnRepo commturn.Repo
 	termDecRepo  termdec.Repo
+	termDefRepo  termdef.Repo
 	typeDefRepo  typedef.Repo
 	typeExpRepo  typeexp.Repo
+	fuelMeter    compfuel.Meter
+	panics       wp.Policy
 	operator     db.Operator
 	log          *slog.Logger
 }
@@ -80,117 +147,545 @@ func newAPI() API {
 func newService(
 	compExecRepo Repo,
 	commExchRepo commexch.Repo,
+	commTurnRepo commturn.Repo,
 	termDecRepo termdec.Repo,
+	termDefRepo termdef.Repo,
 	typeDefRepo typedef.Repo,
 	typeExpRepo typeexp.Repo,
+	fuelMeter compfuel.Meter,
+	panics wp.Policy,
 	operator db.Operator,
 	log *slog.Logger,
 ) *service {
 	name := slog.String("name", reflect.TypeFor[service]().Name())
 	return &service{
-		compExecRepo, commExchRepo,
-		termDecRepo, typeDefRepo, typeExpRepo,
-		operator, log.With(name),
+		compExecRepo, commExchRepo, commTurnRepo,
+		termDecRepo, termDefRepo, typeDefRepo, typeExpRepo,
+		fuelMeter, panics, operator, log.With(name),
 	}
 }
 
 func (s *service) RetrieveSnap(ref compsem.SemRef) (_ ExecSnap, err error) {
-	return ExecSnap{}, nil
+	ctx := context.Background()
+	refAttr := slog.Any("ref", ref)
+	s.log.Debug("snap retrieval started", refAttr)
+	var snap ExecSnap
+	// переменные и их обмены читаются из одного снепшота
+	err = s.operator.Explicit(ctx, func(ds db.Source) error {
+		snap, err = s.compExecRepo.GetSnapByRef(ds, ref)
+		if err != nil {
+			return err
+		}
+		ctxVKs := CollectCtx(maps.Values(snap.LinearVars))
+		typeExps, err := s.typeExpRepo.SelectEnv(ds, ctxVKs)
+		if err != nil {
+			return err
+		}
+		snap.LinearExps = make(map[symbol.ADT]typeexp.ExpRec, len(snap.LinearVars))
+		snap.LinearTurns = make(map[symbol.ADT][]commturn.TurnRec, len(snap.LinearVars))
+		for chnlPH, linearVar := range snap.LinearVars {
+			snap.LinearExps[chnlPH] = typeExps[linearVar.ExpVK]
+			exchQry := commexch.ExchQry{CommRef: linearVar.CommRef, ChnlID: option.Some(linearVar.ChnlID)}
+			exchSnap, err := s.commExchRepo.GetSnapByQry(ds, exchQry)
+			if err != nil {
+				return err
+			}
+			snap.LinearTurns[chnlPH] = exchSnap.Turns
+		}
+		return nil
+	}, db.WithIsolation(db.RepeatableRead), db.WithReadOnly())
+	if err != nil {
+		s.log.Error("snap retrieval failed", refAttr)
+		return ExecSnap{}, err
+	}
+	s.log.Debug("snap retrieval succeed", refAttr)
+	return snap, nil
+}
+
+const (
+	// сколько раз шаг берется заново при конкурентном изменении
+	stepTakingAttempts = 5
+	stepTakingBackoff  = 10 * time.Millisecond
+)
+
+func ErrStepPanicked(reason any) error {
+	return fmt.Errorf("step panicked: %v", reason)
 }
 
 func ErrMissingChnl(want symbol.ADT) error {
 	return fmt.Errorf("channel missing in cfg: %v", want)
 }
 
-func (s *service) Take(spec compstep.StepSpec) (err error) {
+func (s *service) Take(spec compstep.StepSpec) error {
 	compAttr := slog.Any("proc", spec.CompRef)
 	s.log.Debug("step taking started", compAttr, slog.Any("exp", spec.ProcExp))
-	ctx := context.Background()
-	// initial values
-	compRef := spec.CompRef
-	expSpec := spec.ProcExp
-	for expSpec != nil {
-		var execSnap ExecSnap
-		getErr1 := s.operator.Implicit(ctx, func(ds db.Source) error {
-			execSnap, err = s.compExecRepo.GetSnapByRef(ds, compRef)
+	// очередь шагов, порождаемых по ходу вычисления
+	steps := []compstep.StepSpec{spec}
+	for len(steps) > 0 {
+		step := steps[0]
+		steps = steps[1:]
+		if step.ProcExp == nil {
+			continue
+		}
+		nextSteps, err := s.takeRetrying(step)
+		if errors.Is(err, compfuel.ErrOutOfFuel) {
+			// исчерпавшее запас вычисление не мешает остальным
+			var halted bool
+			halted, err = s.halt(step)
+			if err == nil && !halted {
+				// запас пополнили между отказом и остановкой
+				steps = slices.Insert(steps, 0, step)
+				continue
+			}
+		}
+		if err != nil {
 			return err
-		})
-		if getErr1 != nil {
-			s.log.Error("step taking failed", compAttr)
-			return getErr1
 		}
-		if len(execSnap.LinearVars) == 0 {
-			panic("zero channel binds")
+		steps = append(steps, nextSteps...)
+	}
+	s.log.Debug("step taking succeed", compAttr)
+	return nil
+}
+
+// шаг без запаса откладывается до пополнения
+func (s *service) halt(spec compstep.StepSpec) (halted bool, err error) {
+	ctx := context.Background()
+	err = s.operator.Explicit(ctx, func(ds db.Source) error {
+		halted, err = s.fuelMeter.Halt(ds, spec)
+		return err
+	})
+	if err != nil {
+		s.log.Error("step halting failed", slog.Any("proc", spec.CompRef))
+		return false, err
+	}
+	return halted, nil
+}
+
+func (s *service) Refuel(spec compfuel.FuelSpec) (_ compfuel.FuelRec, err error) {
+	ctx := context.Background()
+	idAttr := slog.Any("id", spec.CompID)
+	var rec compfuel.FuelRec
+	var halted []compstep.StepSpec
+	err = s.operator.Explicit(ctx, func(ds db.Source) error {
+		rec, halted, err = s.fuelMeter.Refill(ds, spec)
+		return err
+	})
+	if err != nil {
+		s.log.Error("refueling failed", idAttr, slog.Any("realm", spec.Realm))
+		return compfuel.FuelRec{}, err
+	}
+	// шаги, которым не хватит и пополненного запаса, остановятся снова
+	for _, step := range halted {
+		err = s.Take(step)
+		if err != nil {
+			s.log.Error("refueling failed", idAttr, slog.Any("proc", step.CompRef))
+			return compfuel.FuelRec{}, err
 		}
-		decIDs := termexp.CollectEnv(expSpec)
-		var procDecs map[identity.ADT]termdec.DecRec
-		getErr2 := s.operator.Implicit(ctx, func(ds db.Source) error {
-			procDecs, err = s.termDecRepo.SelectEnv(ds, decIDs)
-			return err
-		})
-		if getErr2 != nil {
-			s.log.Error("step taking failed", compAttr, slog.Any("decs", decIDs))
-			return getErr2
-		}
-		typeQNs := termdec.CollectEnv(maps.Values(procDecs))
-		var typeDefs map[uniqsym.ADT]typedef.DefRec
-		getErr3 := s.operator.Implicit(ctx, func(ds db.Source) error {
-			typeDefs, err = s.typeDefRepo.SelectEnv(ds, typeQNs)
-			return err
-		})
-		if getErr3 != nil {
-			s.log.Error("step taking failed", compAttr, slog.Any("types", typeQNs))
-			return getErr3
-		}
-		envIDs := typedef.CollectEnv(maps.Values(typeDefs))
-		ctxIDs := CollectCtx(maps.Values(execSnap.LinearVars))
-		var typeExps map[valkey.ADT]typeexp.ExpRec
-		getErr4 := s.operator.Implicit(ctx, func(ds db.Source) error {
-			typeExps, err = s.typeExpRepo.SelectEnv(ds, append(envIDs, ctxIDs...))
-			return err
-		})
-		if getErr4 != nil {
-			s.log.Error("step taking failed", compAttr, slog.Any("env", envIDs), slog.Any("ctx", ctxIDs))
-			return getErr4
-		}
-		procEnv := Env{ProcDecs: procDecs, TypeDefs: typeDefs, TypeExps: typeExps}
-		procCtx := convertToCtx(maps.Values(execSnap.LinearVars), typeExps)
-		// type checking
-		err = s.checkType(procEnv, procCtx, execSnap, expSpec)
+	}
+	s.log.Debug("refueling succeed", idAttr, slog.Int("resumed", len(halted)))
+	return rec, nil
+}
+
+// при конкурентном изменении чтение, проверка и взятие шага
+// повторяются на свежем снепшоте
+func (s *service) takeRetrying(spec compstep.StepSpec) (steps []compstep.StepSpec, err error) {
+	for attempt := 1; ; attempt++ {
+		steps, err = s.takeStep(spec)
+		if !errors.Is(err, db.ErrConcurrentModification) || attempt == stepTakingAttempts {
+			return steps, err
+		}
+		s.log.Warn("step taking conflicted", slog.Any("proc", spec.CompRef), slog.Int("attempt", attempt))
+		time.Sleep(rand.N(time.Duration(attempt) * stepTakingBackoff))
+	}
+}
+
+// чтения, проверка и запись шага видят одно состояние базы,
+// поэтому конкурентные шаги не дают фантомных чтений
+func (s *service) takeStep(spec compstep.StepSpec) (steps []compstep.StepSpec, err error) {
+	ctx := context.Background()
+	err = s.operator.Explicit(ctx, func(ds db.Source) error {
+		steps, err = s.takeStepWith(ds, spec)
+		return err
+	}, db.WithIsolation(db.RepeatableRead))
+	if err != nil {
+		s.log.Error("step taking failed", slog.Any("proc", spec.CompRef))
+		return nil, err
+	}
+	return steps, nil
+}
+
+func (s *service) takeStepWith(ds db.Source, spec compstep.StepSpec) ([]compstep.StepSpec, error) {
+	execSnap, err := s.compExecRepo.GetSnapByRef(ds, spec.CompRef)
+	if err != nil {
+		return nil, err
+	}
+	if len(execSnap.LinearVars) == 0 {
+		panic("zero channel binds")
+	}
+	// шаг списывается до взятия: при отказе транзакция откатит и списание
+	fuelRec, err := s.fuelMeter.Burn(ds, compfuel.ProcRealm, execSnap.CompRef.CompID)
+	if err != nil {
+		return nil, err
+	}
+	procEnv, err := s.selectEnv(ds, execSnap, spec.ProcExp)
+	if err != nil {
+		return nil, err
+	}
+	procCtx := convertToCtx(maps.Values(execSnap.LinearVars), procEnv.TypeExps)
+	// type checking
+	err = s.checkType(procEnv, procCtx, execSnap, spec.ProcExp)
+	if err != nil {
+		return nil, err
+	}
+	// step taking
+	exchs := &exchRecorder{Repo: s.commExchRepo}
+	taker := *s
+	taker.commExchRepo = exchs
+	execMod, execEff, exchMod, err := taker.takeSafely(ds, procEnv, execSnap, spec.ProcExp)
+	if err != nil {
+		return nil, err
+	}
+	for _, exch := range execMod.NewExchs {
+		err = s.commExchRepo.AddRec(ds, exch)
 		if err != nil {
-			s.log.Error("step taking failed", compAttr)
-			return err
+			return nil, err
 		}
-		// step taking
-		execMod, execEff, _, err := s.takeWith(procEnv, execSnap, expSpec)
+	}
+	// ревизия и офсет обмена сверяются до ходов, которые продвигают ревизию
+	err = s.commExchRepo.ModifyRec(ds, exchMod)
+	if err != nil {
+		return nil, err
+	}
+	// ходы фиксируются вместе с шагом
+	err = s.commTurnRepo.AddRecs(ds, exchMod.Turns)
+	if err != nil {
+		return nil, err
+	}
+	err = s.compExecRepo.ModifyRec(ds, execMod)
+	if err != nil {
+		return nil, err
+	}
+	// порожденные вычисления тратят запас того же пула
+	for _, exec := range execMod.NewExecs {
+		err = s.fuelMeter.Grant(ds, exec.CompRef.CompID, fuelRec.PoolID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// шаг журналируется на ревизии, с которой он взят, после ее проверки
+	err = s.compExecRepo.AddStep(ds, StepRec{
+		CompRef:   execSnap.CompRef,
+		ProcExp:   spec.ProcExp,
+		ExchSnaps: exchs.snaps,
+		EnvKeys:   option.Some(collectKeys(procEnv)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return execEff.Steps, nil
+}
+
+func (s *service) selectEnv(ds db.Source, execSnap ExecSnap, exp termexp.ExpSpec) (Env, error) {
+	compAttr := slog.Any("proc", execSnap.CompRef)
+	termQNs := termexp.CollectEnv(exp)
+	termDecs, err := s.termDecRepo.SelectEnv(ds, termQNs)
+	if err != nil {
+		s.log.Error("env selection failed", compAttr, slog.Any("terms", termQNs))
+		return Env{}, err
+	}
+	termDefs, err := s.termDefRepo.SelectEnv(ds, termQNs)
+	if err != nil {
+		s.log.Error("env selection failed", compAttr, slog.Any("terms", termQNs))
+		return Env{}, err
+	}
+	envVKs := termdec.CollectEnv(maps.Values(termDecs))
+	ctxVKs := CollectCtx(maps.Values(execSnap.LinearVars))
+	typeExps, err := s.typeExpRepo.SelectEnv(ds, append(envVKs, ctxVKs...))
+	if err != nil {
+		s.log.Error("env selection failed", compAttr, slog.Any("env", envVKs), slog.Any("ctx", ctxVKs))
+		return Env{}, err
+	}
+	typeDefs := make(typeexp.Defs)
+	err = typedef.SelectDefs(ds, s.typeDefRepo, s.typeExpRepo, typeDefs, maps.Values(typeExps))
+	if err != nil {
+		s.log.Error("env selection failed", compAttr, slog.Any("env", envVKs), slog.Any("ctx", ctxVKs))
+		return Env{}, err
+	}
+	return Env{TypeExps: typeExps, TermDecs: termDecs, TermDefs: termDefs, TypeDefs: typeDefs}, nil
+}
+
+func collectKeys(env Env) EnvKeys {
+	keys := EnvKeys{
+		TermDecs: make(map[uniqsym.ADT]termsem.SemRef, len(env.TermDecs)),
+		TermDefs: make(map[uniqsym.ADT]termsem.SemRef, len(env.TermDefs)),
+		TypeDefs: make(map[uniqsym.ADT]valkey.ADT, len(env.TypeDefs)),
+		TypeExps: slices.Sorted(maps.Keys(env.TypeExps)),
+	}
+	for termQN, termDec := range env.TermDecs {
+		keys.TermDecs[termQN] = termDec.TermRef
+	}
+	for termQN, termDef := range env.TermDefs {
+		keys.TermDefs[termQN] = termDef.TermRef
+	}
+	for typeQN, typeExp := range env.TypeDefs {
+		keys.TypeDefs[typeQN] = typeExp.Key()
+	}
+	return keys
+}
+
+// Восстанавливает окружение шага по ключам из журнала.
+//
+// Значения типов неизменны, определения процессов хранятся во всех
+// ревизиях, а декларации не меняются, поэтому у них сверяется ревизия.
+func (s *service) selectEnvAt(ds db.Source, keys EnvKeys) (Env, error) {
+	typeVKs := append(slices.Clone(keys.TypeExps), slices.Collect(maps.Values(keys.TypeDefs))...)
+	typeExps, err := s.typeExpRepo.SelectEnv(ds, typeVKs)
+	if err != nil {
+		s.log.Error("env selection failed", slog.Any("types", typeVKs))
+		return Env{}, err
+	}
+	env := Env{
+		TypeExps: make(map[valkey.ADT]typeexp.ExpRec, len(keys.TypeExps)),
+		TermDecs: make(map[uniqsym.ADT]termdec.DecRec, len(keys.TermDecs)),
+		TermDefs: make(map[uniqsym.ADT]termdef.DefRec, len(keys.TermDefs)),
+		TypeDefs: make(typeexp.Defs, len(keys.TypeDefs)),
+	}
+	for _, expVK := range keys.TypeExps {
+		env.TypeExps[expVK] = typeExps[expVK]
+	}
+	for typeQN, expVK := range keys.TypeDefs {
+		env.TypeDefs[typeQN] = typeExps[expVK]
+	}
+	for termQN, termRef := range keys.TermDefs {
+		termDef, err := s.termDefRepo.GetRecAtRef(ds, termRef)
+		if err != nil {
+			s.log.Error("env selection failed", slog.Any("term", termRef))
+			return Env{}, err
+		}
+		env.TermDefs[termQN] = termDef
+	}
+	if len(keys.TermDecs) == 0 {
+		return env, nil
+	}
+	termIDs := make([]identity.ADT, 0, len(keys.TermDecs))
+	for _, termRef := range keys.TermDecs {
+		termIDs = append(termIDs, termRef.TermID)
+	}
+	termDecs, err := s.termDecRepo.GetRecs(ds, termIDs)
+	if err != nil {
+		s.log.Error("env selection failed", slog.Any("terms", termIDs))
+		return Env{}, err
+	}
+	for termQN, termRef := range keys.TermDecs {
+		i := slices.IndexFunc(termDecs, func(rec termdec.DecRec) bool { return rec.TermRef.TermID == termRef.TermID })
+		if i < 0 || termDecs[i].TermRef != termRef {
+			return Env{}, errTermRevMismatch(termRef)
+		}
+		env.TermDecs[termQN] = termDecs[i]
+	}
+	return env, nil
+}
+
+func errTermRevMismatch(want termsem.SemRef) error {
+	return fmt.Errorf("term revision mismatch: want %v", want)
+}
+
+// exchRecorder запоминает обмены, какими их увидел шаг
+type exchRecorder struct {
+	commexch.Repo
+	snaps []commexch.ExchSnap
+}
+
+func (r *exchRecorder) GetSnapByQry(ds db.Source, qry commexch.ExchQry) (commexch.ExchSnap, error) {
+	snap, err := r.Repo.GetSnapByQry(ds, qry)
+	if err != nil {
+		return commexch.ExchSnap{}, err
+	}
+	r.snaps = append(r.snaps, snap)
+	return snap, nil
+}
+
+// exchReplayer отдает обмены из журнала в том порядке,
+// в каком их запрашивал исходный шаг
+type exchReplayer struct {
+	commexch.Repo
+	snaps []commexch.ExchSnap
+}
+
+func (r *exchReplayer) GetSnapByQry(_ db.Source, qry commexch.ExchQry) (commexch.ExchSnap, error) {
+	if len(r.snaps) == 0 || r.snaps[0].CommRef.CommID != qry.CommRef.CommID {
+		return commexch.ExchSnap{}, ErrJournalMismatch(qry.CommRef)
+	}
+	snap := r.snaps[0]
+	r.snaps = r.snaps[1:]
+	return snap, nil
+}
+
+func ErrJournalMismatch(got commsem.SemRef) error {
+	return fmt.Errorf("exchange missing in journal: %v", got)
+}
+
+func (s *service) Replay(ref compsem.SemRef) (_ ReplayRep, err error) {
+	ctx := context.Background()
+	refAttr := slog.Any("ref", ref)
+	s.log.Debug("replay started", refAttr)
+	rep := ReplayRep{CompRef: ref}
+	// журнал, история и окружения читаются из одного снепшота,
+	// а шаги повторяются над хранилищем в памяти
+	err = s.operator.Explicit(ctx, func(ds db.Source) error {
+		steps, err := s.compExecRepo.GetSteps(ds, ref)
 		if err != nil {
-			s.log.Error("step taking failed", compAttr)
 			return err
 		}
-		err = s.operator.Explicit(ctx, func(ds db.Source) error {
-			err = s.compExecRepo.ModifyRec(ds, execMod)
+		for _, step := range steps {
+			div, err := s.replayStep(ds, step)
 			if err != nil {
-				s.log.Error("step taking failed", compAttr)
 				return err
 			}
+			if div != nil {
+				rep.Divergence = div
+				return nil
+			}
+			rep.Replayed++
+		}
+		return nil
+	}, db.WithIsolation(db.RepeatableRead), db.WithReadOnly())
+	if err != nil {
+		s.log.Error("replay failed", refAttr)
+		return ReplayRep{}, err
+	}
+	if rep.Divergence != nil {
+		s.log.Warn("replay diverged", refAttr, slog.Any("div", rep.Divergence))
+		return rep, nil
+	}
+	s.log.Debug("replay succeed", refAttr, slog.Int("steps", rep.Replayed))
+	return rep, nil
+}
+
+// Шаг повторяется на конфигурации, восстановленной по истории переменных,
+// в окружении и с обменами из журнала и сверяется с переменными,
+// которые он записал.
+//
+// Новые каналы получают новые идентификаторы, поэтому сверяются
+// заглушки, стороны и типы переменных самого вычисления.
+func (s *service) replayStep(ds db.Source, step StepRec) (*StepDiv, error) {
+	execSnap, err := s.compExecRepo.GetSnapAtRef(ds, step.CompRef)
+	if err != nil {
+		return nil, err
+	}
+	nextRef := compsem.SemRef{CompID: step.CompRef.CompID, CompRN: step.CompRef.CompRN.Next()}
+	wantVars, err := s.compExecRepo.GetVarsByRef(ds, nextRef)
+	if err != nil {
+		return nil, err
+	}
+	// шаги без ключей окружения повторяются в текущих определениях
+	var procEnv Env
+	if step.EnvKeys.IsEmpty() {
+		procEnv, err = s.selectEnv(ds, execSnap, step.ProcExp)
+	} else {
+		procEnv, err = s.selectEnvAt(ds, step.EnvKeys.Get())
+	}
+	if err != nil {
+		return nil, err
+	}
+	var div *StepDiv
+	// в хранилище заводится только конфигурация вычисления,
+	// поэтому повтор не видит и не меняет остальные вычисления
+	mem := db.NewOperatorMem()
+	memRepo := newMemDAO(s.log)
+	replayer := *s
+	replayer.compExecRepo = memRepo
+	replayer.commExchRepo = &exchReplayer{Repo: s.commExchRepo, snaps: step.ExchSnaps}
+	replayer.operator = mem
+	err = mem.Explicit(context.Background(), func(ms db.Source) error {
+		err := memRepo.putSnap(ms, execSnap)
+		if err != nil {
+			return err
+		}
+		procCtx := convertToCtx(maps.Values(execSnap.LinearVars), procEnv.TypeExps)
+		err = replayer.checkType(procEnv, procCtx, execSnap, step.ProcExp)
+		if err != nil {
+			div = &StepDiv{StepRef: step.CompRef, Reason: err.Error()}
 			return nil
-		})
+		}
+		execMod, _, _, err := replayer.takeSafely(ms, procEnv, execSnap, step.ProcExp)
+		if err != nil {
+			div = &StepDiv{StepRef: step.CompRef, Reason: err.Error()}
+			return nil
+		}
+		ownMod := ExecMod{CompRefs: []compsem.SemRef{execSnap.CompRef}}
+		for _, linearVar := range execMod.LinearVars {
+			if linearVar.CompRef.CompID == step.CompRef.CompID {
+				ownMod.LinearVars = append(ownMod.LinearVars, linearVar)
+			}
+		}
+		err = memRepo.ModifyRec(ms, ownMod)
 		if err != nil {
-			s.log.Error("step taking failed", compAttr)
 			return err
 		}
-		// next values
-		if len(execEff.Steps) > 0 {
-			nextSpec := execEff.Steps[len(execEff.Steps)-1]
-			compRef = nextSpec.CompRef
-			expSpec = nextSpec.ProcExp
+		gotVars, err := memRepo.GetVarsByRef(ms, nextRef)
+		if err != nil {
+			return err
+		}
+		div = diffVars(step.CompRef, wantVars, gotVars)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return div, nil
+}
+
+func diffVars(stepRef compsem.SemRef, want, got []compvar.LinearRec) *StepDiv {
+	wantVars := compvar.IndexBy(ChnlPH, want)
+	gotVars := compvar.IndexBy(ChnlPH, got)
+	chnlPHs := slices.Sorted(maps.Keys(wantVars))
+	for chnlPH := range gotVars {
+		if _, ok := wantVars[chnlPH]; !ok {
+			return &StepDiv{StepRef: stepRef, ChnlPH: chnlPH, GotVK: gotVars[chnlPH].ExpVK, Reason: "var missing in history"}
+		}
+	}
+	for _, chnlPH := range chnlPHs {
+		wantVar := wantVars[chnlPH]
+		gotVar, ok := gotVars[chnlPH]
+		if !ok {
+			return &StepDiv{StepRef: stepRef, ChnlPH: chnlPH, WantVK: wantVar.ExpVK, Reason: "var missing in replay"}
+		}
+		if gotVar.ExpVK != wantVar.ExpVK || gotVar.ChnlBS != wantVar.ChnlBS {
+			return &StepDiv{StepRef: stepRef, ChnlPH: chnlPH, WantVK: wantVar.ExpVK, GotVK: gotVar.ExpVK, Reason: "var mismatch"}
 		}
 	}
-	s.log.Debug("step taking succeed", compAttr)
 	return nil
 }
 
+// паника при вычислении шага становится его отказом, а не падением сервера
+func (s *service) takeSafely(
+	ds db.Source,
+	procEnv Env,
+	execSnap ExecSnap,
+	exp termexp.ExpSpec,
+) (
+	execMod ExecMod,
+	execEff ExecEff,
+	exchMod commexch.ExchMod,
+	err error,
+) {
+	// при политике crash паника доходит до исполнителя и роняет процесс
+	if !s.panics.Recovers() {
+		return s.takeWith(ds, procEnv, execSnap, exp)
+	}
+	defer func() {
+		r := recover()
+		if r != nil {
+			s.log.Error("step taking panicked", slog.Any("reason", fmt.Sprint(r)), slog.String("stack", string(debug.Stack())))
+			err = ErrStepPanicked(r)
+		}
+	}()
+	return s.takeWith(ds, procEnv, execSnap, exp)
+}
+
 func (s *service) takeWith(
+	ds db.Source,
 	procEnv Env,
 	execSnap ExecSnap,
 	exp termexp.ExpSpec,
@@ -200,7 +695,6 @@ func (s *service) takeWith(
 	exchMod commexch.ExchMod,
 	err error,
 ) {
-	ctx := context.Background()
 	compAttr := slog.Any("compRef", execSnap.CompRef)
 	execMod.CompRefs = append(execMod.CompRefs, execSnap.CompRef)
 	switch termExp := exp.(type) {
@@ -212,18 +706,15 @@ func (s *service) takeWith(
 		}
 		commAttr := slog.Any("commRef", commChnl.CommRef)
 		// получаем снепшот коммуникации
-		var commSnap commexch.ExchSnap
-		getErr := s.operator.Implicit(ctx, func(ds db.Source) error {
-			commSnap, err = s.commExchRepo.GetSnapByQry(ds, commexch.ExchQry{
-				CommRef: commChnl.CommRef,
-				ChnlID:  option.Some(commChnl.ChnlID),
-			})
-			return err
+		commSnap, getErr := s.commExchRepo.GetSnapByQry(ds, commexch.ExchQry{
+			CommRef: commChnl.CommRef,
+			ChnlID:  option.Some(commChnl.ChnlID),
 		})
 		if getErr != nil {
 			s.log.Error("step taking failed", compAttr, commAttr)
 			return execMod, execEff, exchMod, getErr
 		}
+		exchMod.CommRef = commSnap.CommRef
 		subscription := commSnap.NextTurn()
 		if subscription == nil {
 			// регистрируем подписку закрывателя
@@ -281,18 +772,15 @@ func (s *service) takeWith(
 			return execMod, execEff, exchMod, termdef.ErrMissingInCfg(termExp.ContChnlPH)
 		}
 		// получаем снепшот соединения
-		var commSnap commexch.ExchSnap
-		getErr := s.operator.Implicit(ctx, func(ds db.Source) error {
-			commSnap, err = s.commExchRepo.GetSnapByQry(ds, commexch.ExchQry{
-				CommRef: commChnl.CommRef,
-				ChnlID:  option.Some(commChnl.ChnlID),
-			})
-			return err
+		commSnap, getErr := s.commExchRepo.GetSnapByQry(ds, commexch.ExchQry{
+			CommRef: commChnl.CommRef,
+			ChnlID:  option.Some(commChnl.ChnlID),
 		})
 		if getErr != nil {
 			s.log.Error("step taking failed", compAttr)
 			return execMod, execEff, exchMod, getErr
 		}
+		exchMod.CommRef = commSnap.CommRef
 		subscription := commSnap.NextTurn()
 		if subscription == nil {
 			// регистрируем подписку наблюдателя
@@ -363,30 +851,27 @@ func (s *service) takeWith(
 			s.log.Error("step taking failed", compAttr)
 			return execMod, execEff, exchMod, err
 		}
-		typeExp, ok := procEnv.TypeExps[commChnl.ExpVK]
-		if !ok {
+		typeExp, unfoldErr := unfoldRec[typeexp.ProdRec](procEnv, commChnl.ExpVK)
+		if unfoldErr != nil {
 			s.log.Error("step taking failed", compAttr)
-			return execMod, execEff, exchMod, typedef.ErrMissingInEnv(commChnl.ExpVK)
+			return execMod, execEff, exchMod, unfoldErr
 		}
-		nextExpVK := typeExp.(typeexp.ProdRec).Next()
+		nextExpVK := typeExp.Next()
 		valChnl, ok := execSnap.LinearVars[termExp.ValChnlPH]
 		if !ok {
 			s.log.Error("step taking failed", compAttr)
 			return execMod, execEff, exchMod, termdef.ErrMissingInCfg(termExp.ValChnlPH)
 		}
 		// получаем снепшот соединения
-		var commSnap commexch.ExchSnap
-		getErr := s.operator.Implicit(ctx, func(ds db.Source) error {
-			commSnap, err = s.commExchRepo.GetSnapByQry(ds, commexch.ExchQry{
-				CommRef: commChnl.CommRef,
-				ChnlID:  option.Some(commChnl.ChnlID),
-			})
-			return err
+		commSnap, getErr := s.commExchRepo.GetSnapByQry(ds, commexch.ExchQry{
+			CommRef: commChnl.CommRef,
+			ChnlID:  option.Some(commChnl.ChnlID),
 		})
 		if getErr != nil {
 			s.log.Error("step taking failed", compAttr)
 			return execMod, execEff, exchMod, getErr
 		}
+		exchMod.CommRef = commSnap.CommRef
 		subscription := commSnap.NextTurn()
 		if subscription == nil {
 			// регистрируем подписку отправителя
@@ -461,25 +946,22 @@ func (s *service) takeWith(
 			s.log.Error("step taking failed", compAttr)
 			return execMod, execEff, exchMod, termdef.ErrMissingInCfg(termExp.CommChnlPH)
 		}
-		typeExp, ok := procEnv.TypeExps[commChnl.ExpVK]
-		if !ok {
+		typeExp, unfoldErr := unfoldRec[typeexp.ProdRec](procEnv, commChnl.ExpVK)
+		if unfoldErr != nil {
 			s.log.Error("step taking failed", compAttr)
-			return execMod, execEff, exchMod, typedef.ErrMissingInEnv(commChnl.ExpVK)
+			return execMod, execEff, exchMod, unfoldErr
 		}
-		nextExpVK := typeExp.(typeexp.ProdRec).Next()
+		nextExpVK := typeExp.Next()
 		// получаем снепшот соединения
-		var connSnap commexch.ExchSnap
-		getErr := s.operator.Implicit(ctx, func(ds db.Source) error {
-			connSnap, err = s.commExchRepo.GetSnapByQry(ds, commexch.ExchQry{
-				CommRef: commChnl.CommRef,
-				ChnlID:  option.Some(commChnl.ChnlID),
-			})
-			return err
+		connSnap, getErr := s.commExchRepo.GetSnapByQry(ds, commexch.ExchQry{
+			CommRef: commChnl.CommRef,
+			ChnlID:  option.Some(commChnl.ChnlID),
 		})
 		if getErr != nil {
 			s.log.Error("step taking failed", compAttr)
 			return execMod, execEff, exchMod, getErr
 		}
+		exchMod.CommRef = connSnap.CommRef
 		publication := connSnap.NextTurn()
 		if publication == nil {
 			newChnlID := identity.New()
@@ -547,25 +1029,22 @@ func (s *service) takeWith(
 			s.log.Error("step taking failed")
 			return execMod, execEff, exchMod, err
 		}
-		typeExp, ok := procEnv.TypeExps[commChnl.ExpVK]
-		if !ok {
+		typeExp, unfoldErr := unfoldRec[typeexp.SumRec](procEnv, commChnl.ExpVK)
+		if unfoldErr != nil {
 			s.log.Error("step taking failed", compAttr)
-			return execMod, execEff, exchMod, typedef.ErrMissingInEnv(commChnl.ExpVK)
+			return execMod, execEff, exchMod, unfoldErr
 		}
-		nextExpVK := typeExp.(typeexp.SumRec).Next(termExp.ValLabQN)
+		nextExpVK := typeExp.Next(termExp.ValLabQN)
 		// получаем снепшот соединения
-		var connSnap commexch.ExchSnap
-		getErr := s.operator.Implicit(ctx, func(ds db.Source) error {
-			connSnap, err = s.commExchRepo.GetSnapByQry(ds, commexch.ExchQry{
-				CommRef: commChnl.CommRef,
-				ChnlID:  option.Some(commChnl.ChnlID),
-			})
-			return err
+		connSnap, getErr := s.commExchRepo.GetSnapByQry(ds, commexch.ExchQry{
+			CommRef: commChnl.CommRef,
+			ChnlID:  option.Some(commChnl.ChnlID),
 		})
 		if getErr != nil {
 			s.log.Error("step taking failed", compAttr)
 			return execMod, execEff, exchMod, getErr
 		}
+		exchMod.CommRef = connSnap.CommRef
 		subscription := connSnap.NextTurn()
 		if subscription == nil {
 			newChnlID := identity.New()
@@ -633,24 +1112,21 @@ func (s *service) takeWith(
 			return execMod, execEff, exchMod, err
 		}
 		// получаем снепшот соединения
-		var caseConnSnap commexch.ExchSnap
-		getErr := s.operator.Implicit(ctx, func(ds db.Source) error {
-			caseConnSnap, err = s.commExchRepo.GetSnapByQry(ds, commexch.ExchQry{
-				CommRef: commChnl.CommRef,
-				ChnlID:  option.Some(commChnl.ChnlID),
-			})
-			return err
+		caseConnSnap, getErr := s.commExchRepo.GetSnapByQry(ds, commexch.ExchQry{
+			CommRef: commChnl.CommRef,
+			ChnlID:  option.Some(commChnl.ChnlID),
 		})
 		if getErr != nil {
 			s.log.Error("step taking failed", compAttr)
 			return execMod, execEff, exchMod, getErr
 		}
+		exchMod.CommRef = caseConnSnap.CommRef
 		publication := caseConnSnap.NextTurn()
 		if publication == nil {
 			newChnlID := identity.New()
 			// регистрируем подписку последователя
 			exchMod.Turns = append(exchMod.Turns, commturn.SubRec{
-				CommRef: commChnl.CommRef,
+				CommRef: caseConnSnap.CommRef,
 				CompRef: commChnl.CompRef,
 				ChnlID:  commChnl.ChnlID,
 				ContExp: termexp.CaseRec{
@@ -697,24 +1173,21 @@ func (s *service) takeWith(
 			s.log.Error("step taking failed")
 			return execMod, execEff, exchMod, termdef.ErrMissingInCfg(termExp.ContChnlPH)
 		}
-		typeExp, ok := procEnv.TypeExps[commChnl.ExpVK]
-		if !ok {
+		typeExp, unfoldErr := unfoldRec[typeexp.ExpRec](procEnv, commChnl.ExpVK)
+		if unfoldErr != nil {
 			s.log.Error("step taking failed", compAttr)
-			return execMod, execEff, exchMod, typedef.ErrMissingInEnv(commChnl.ExpVK)
+			return execMod, execEff, exchMod, unfoldErr
 		}
 		// получаем снепшот соединения
-		var fwdConnSnap commexch.ExchSnap
-		getErr := s.operator.Implicit(ctx, func(ds db.Source) error {
-			fwdConnSnap, err = s.commExchRepo.GetSnapByQry(ds, commexch.ExchQry{
-				CommRef: commChnl.CommRef,
-				ChnlID:  option.Some(commChnl.ChnlID),
-			})
-			return err
+		fwdConnSnap, getErr := s.commExchRepo.GetSnapByQry(ds, commexch.ExchQry{
+			CommRef: commChnl.CommRef,
+			ChnlID:  option.Some(commChnl.ChnlID),
 		})
 		if getErr != nil {
 			s.log.Error("step taking failed", compAttr)
 			return execMod, execEff, exchMod, getErr
 		}
+		exchMod.CommRef = fwdConnSnap.CommRef
 		communication := fwdConnSnap.NextTurn()
 		switch typeExp.Pol() {
 		case polarity.Pos:
@@ -835,13 +1308,399 @@ func (s *service) takeWith(
 		default:
 			panic(typeexp.ErrPolarityUnexpected(typeExp))
 		}
+	case termexp.AcqureSpec:
+		return s.shiftWith(ds, procEnv, execSnap, execMod, termExp.CommChnlPH, termExp.ContExp, acquireRule)
+	case termexp.AcceptSpec:
+		return s.shiftWith(ds, procEnv, execSnap, execMod, termExp.CommChnlPH, termExp.ContExp, acceptRule)
+	case termexp.ReleaseSpec:
+		return s.shiftWith(ds, procEnv, execSnap, execMod, termExp.CommChnlPH, termExp.ContExp, releaseRule)
+	case termexp.DetachSpec:
+		return s.shiftWith(ds, procEnv, execSnap, execMod, termExp.CommChnlPH, termExp.ContExp, detachRule)
+	case termexp.CallSpec:
+		execMod, execEff, err = s.spawnWith(procEnv, execSnap, execMod, termExp.NewChnlPH, termExp.ProcTermQN, termExp.ValChnlPHs, termExp.ContExp)
+		return execMod, execEff, exchMod, err
+	case termexp.SpawnSpec:
+		execMod, execEff, err = s.spawnWith(procEnv, execSnap, execMod, termExp.CommChnlPH, termExp.ProcTermQN, termExp.NewChnlPHs, termExp.ContExp)
+		return execMod, execEff, exchMod, err
+	case termexp.LinkSpec:
+		execMod, execEff, err = s.linkWith(procEnv, execSnap, execMod, termExp)
+		return execMod, execEff, exchMod, err
 	default:
 		panic(termexp.ErrExpTypeUnexpected(exp))
 	}
 }
 
+// shiftRule описывает сторону смены режима канала: доступ к разделяемому
+// каналу открывает линейную сессию, а возврат закрывает ее
+type shiftRule struct {
+	// шаг делает провайдер канала
+	provider bool
+	// шаг открывает сессию (aka Acquire/Accept), а не закрывает ее
+	opening bool
+	// шаг встает в очередь за такими же шагами других клиентов
+	queuing bool
+	// запись ожидания контрагента
+	halfRec func(symbol.ADT, termexp.ExpSpec) termexp.ExpRec
+	// продолжение контрагента, если запись принадлежит ему
+	peerRec func(termexp.ExpRec) (symbol.ADT, termexp.ExpSpec, bool)
+}
+
+var (
+	acquireRule = shiftRule{
+		opening: true,
+		queuing: true,
+		halfRec: func(ph symbol.ADT, cont termexp.ExpSpec) termexp.ExpRec {
+			return termexp.AcquireRec{ContChnlPH: ph, ContExp: cont}
+		},
+		peerRec: func(r termexp.ExpRec) (symbol.ADT, termexp.ExpSpec, bool) {
+			rec, ok := r.(termexp.AcceptRec)
+			return rec.ContChnlPH, rec.ContExp, ok
+		},
+	}
+	acceptRule = shiftRule{
+		provider: true,
+		opening:  true,
+		halfRec: func(ph symbol.ADT, cont termexp.ExpSpec) termexp.ExpRec {
+			return termexp.AcceptRec{ContChnlPH: ph, ContExp: cont}
+		},
+		peerRec: func(r termexp.ExpRec) (symbol.ADT, termexp.ExpSpec, bool) {
+			rec, ok := r.(termexp.AcquireRec)
+			return rec.ContChnlPH, rec.ContExp, ok
+		},
+	}
+	releaseRule = shiftRule{
+		halfRec: func(ph symbol.ADT, cont termexp.ExpSpec) termexp.ExpRec {
+			return termexp.ReleaseRec{ContChnlPH: ph, ContExp: cont}
+		},
+		peerRec: func(r termexp.ExpRec) (symbol.ADT, termexp.ExpSpec, bool) {
+			rec, ok := r.(termexp.DetachRec)
+			return rec.ContChnlPH, rec.ContExp, ok
+		},
+	}
+	detachRule = shiftRule{
+		provider: true,
+		halfRec: func(ph symbol.ADT, cont termexp.ExpSpec) termexp.ExpRec {
+			return termexp.DetachRec{ContChnlPH: ph, ContExp: cont}
+		},
+		peerRec: func(r termexp.ExpRec) (symbol.ADT, termexp.ExpSpec, bool) {
+			rec, ok := r.(termexp.ReleaseRec)
+			return rec.ContChnlPH, rec.ContExp, ok
+		},
+	}
+)
+
+// Сводит стороны смены режима канала (aka Acquire/Accept, Release/Detach).
+//
+// Открытие уводит обе стороны на новый канал сессии и сдвигает офсет
+// разделяемого канала за обслуженную запись. Закрытие возвращает
+// обе стороны на разделяемый канал и в разделяемый тип.
+func (s *service) shiftWith(
+	ds db.Source,
+	procEnv Env,
+	execSnap ExecSnap,
+	execMod ExecMod,
+	chnlPH symbol.ADT,
+	contExp termexp.ExpSpec,
+	rule shiftRule,
+) (
+	ExecMod,
+	ExecEff,
+	commexch.ExchMod,
+	error,
+) {
+	var execEff ExecEff
+	var exchMod commexch.ExchMod
+	compAttr := slog.Any("compRef", execSnap.CompRef)
+	commChnl, ok := execSnap.LinearVars[chnlPH]
+	if !ok {
+		s.log.Error("step taking failed", compAttr)
+		return execMod, execEff, exchMod, termdef.ErrMissingInCfg(chnlPH)
+	}
+	nextExpVK, err := shiftExpVK(procEnv, commChnl.ExpVK, rule.opening)
+	if err != nil {
+		s.log.Error("step taking failed", compAttr)
+		return execMod, execEff, exchMod, err
+	}
+	commSnap, err := s.commExchRepo.GetSnapByQry(ds, commexch.ExchQry{
+		CommRef: commChnl.CommRef,
+		ChnlID:  option.Some(commChnl.ChnlID),
+	})
+	if err != nil {
+		s.log.Error("step taking failed", compAttr)
+		return execMod, execEff, exchMod, err
+	}
+	exchMod.CommRef = commSnap.CommRef
+	commAttr := slog.Any("commRef", commSnap.CommRef)
+	var peer commturn.SubRec
+	var peerPH symbol.ADT
+	var peerExp termexp.ExpSpec
+	turn := commSnap.NextTurn()
+	if turn != nil {
+		peer, ok = turn.(commturn.SubRec)
+		if !ok {
+			s.log.Error("step taking failed", compAttr, commAttr)
+			return execMod, execEff, exchMod, commturn.ErrRecTypeUnexpected(turn)
+		}
+		peerPH, peerExp, ok = rule.peerRec(peer.ContExp)
+		if !ok && !rule.queuing {
+			s.log.Error("step taking failed", compAttr, commAttr)
+			return execMod, execEff, exchMod, termexp.ErrRecTypeUnexpected(peer.ContExp)
+		}
+	}
+	if turn == nil || !ok {
+		// ждем контрагента, а клиенты разделяемого канала ждут в очереди
+		exchMod.Turns = append(exchMod.Turns, commturn.SubRec{
+			CommRef: commSnap.CommRef,
+			CompRef: execSnap.CompRef,
+			ChnlID:  commChnl.ChnlID,
+			ContExp: rule.halfRec(commChnl.ChnlPH, contExp),
+		})
+		s.log.Debug("taking half done", compAttr, commAttr)
+		return execMod, execEff, exchMod, nil
+	}
+	// исходный канал обмена совпадает с его идентификатором
+	nextChnlID := commChnl.CommRef.CommID
+	if rule.opening {
+		nextChnlID = identity.New()
+		// сдвигаем офсет за обслуженную запись
+		exchMod.CommON = option.Some(peer.CommRef.CommRN)
+	}
+	// вяжем продолжение шагающего
+	execMod.LinearVars = append(execMod.LinearVars, compvar.LinearRec{
+		CompRef: commChnl.CompRef,
+		CommRef: commChnl.CommRef,
+		ChnlID:  nextChnlID,
+		ChnlPH:  commChnl.ChnlPH,
+		ChnlBS:  commChnl.ChnlBS,
+		ExpVK:   nextExpVK,
+	})
+	// вяжем продолжение контрагента
+	peerVar := compvar.LinearRec{
+		CompRef: peer.CompRef,
+		CommRef: peer.CommRef,
+		ChnlID:  nextChnlID,
+		ChnlPH:  peerPH,
+		ChnlBS:  compvar.LiabSide,
+		ExpVK:   nextExpVK,
+	}
+	if rule.provider {
+		peerVar.ChnlBS = compvar.AssetSide
+	}
+	execMod.LinearVars = append(execMod.LinearVars, peerVar)
+	execEff.Steps = append(execEff.Steps, compstep.StepSpec{
+		CompRef: execSnap.CompRef,
+		ProcExp: contExp,
+	})
+	execEff.Steps = append(execEff.Steps, compstep.StepSpec{
+		CompRef: peer.CompRef,
+		ProcExp: peerExp,
+	})
+	s.log.Debug("step taking succeed", compAttr, commAttr)
+	return execMod, execEff, exchMod, nil
+}
+
+// тип канала после смены режима: сессия продолжает ↑A типом A,
+// а ↓S возвращает канал в сам разделяемый тип S, а не в ссылку на него
+func shiftExpVK(procEnv Env, expVK valkey.ADT, opening bool) (valkey.ADT, error) {
+	if opening {
+		up, err := unfoldRec[typeexp.UpRec](procEnv, expVK)
+		if err != nil {
+			return 0, err
+		}
+		return up.Next(), nil
+	}
+	down, err := unfoldRec[typeexp.DownRec](procEnv, expVK)
+	if err != nil {
+		return 0, err
+	}
+	shared, err := typeexp.Unfold(procEnv.TypeDefs, down.Cont)
+	if err != nil {
+		return 0, err
+	}
+	return shared.Key(), nil
+}
+
+// Порождает вычисление вызываемого процесса (aka Spawn).
+//
+// Вызывающий лишается передаваемых каналов и получает новый канал,
+// по которому вызываемый несет обязательство.
+func (s *service) spawnWith(
+	procEnv Env,
+	execSnap ExecSnap,
+	execMod ExecMod,
+	newChnlPH symbol.ADT,
+	procQN uniqsym.ADT,
+	valChnlPHs []symbol.ADT,
+	contExp termexp.ExpSpec,
+) (
+	ExecMod,
+	ExecEff,
+	error,
+) {
+	var execEff ExecEff
+	compAttr := slog.Any("compRef", execSnap.CompRef)
+	qnAttr := slog.Any("qn", procQN)
+	termDec, ok := procEnv.TermDecs[procQN]
+	if !ok {
+		s.log.Error("step taking failed", compAttr, qnAttr)
+		return execMod, execEff, termdec.ErrSymMissingInEnv(procQN)
+	}
+	termDef, ok := procEnv.TermDefs[procQN]
+	if !ok {
+		s.log.Error("step taking failed", compAttr, qnAttr)
+		return execMod, execEff, termdef.ErrSymMissingInEnv(procQN)
+	}
+	newExec := ExecRec{CompRef: compsem.New(), LiabMode: compvar.LinearMode}
+	newExch := commexch.ExchRec{CommRef: commsem.New(), OffsetNr: seqnum.Zero}
+	// исходный канал обмена совпадает с его идентификатором,
+	// что позволяет вернуть канал в разделяемый режим (aka Shared)
+	newChnlID := newExch.CommRef.CommID
+	execMod.NewExecs = append(execMod.NewExecs, newExec)
+	execMod.NewExchs = append(execMod.NewExchs, newExch)
+	// вяжем обязательство вызываемого
+	execMod.LinearVars = append(execMod.LinearVars, compvar.LinearRec{
+		CompRef: newExec.CompRef,
+		CommRef: newExch.CommRef,
+		ChnlID:  newChnlID,
+		ChnlPH:  termDec.LiabVar.ChnlPH,
+		ChnlBS:  compvar.LiabSide,
+		ExpVK:   termDec.LiabVar.ExpVK,
+	})
+	for i, valChnlPH := range valChnlPHs {
+		valChnl, ok := execSnap.LinearVars[valChnlPH]
+		if !ok {
+			s.log.Error("step taking failed", compAttr, qnAttr)
+			return execMod, execEff, termdef.ErrMissingInCfg(valChnlPH)
+		}
+		// лишаем значения вызывающего
+		execMod.LinearVars = append(execMod.LinearVars, compvar.LinearRec{
+			CompRef: execSnap.CompRef,
+			CommRef: valChnl.CommRef,
+			ChnlID:  valChnl.ChnlID,
+			ChnlPH:  valChnl.ChnlPH,
+			ChnlBS:  valChnl.ChnlBS,
+			ExpVK:   valChnl.ExpVK.Invert(),
+		})
+		// вяжем значение вызываемого
+		execMod.LinearVars = append(execMod.LinearVars, compvar.LinearRec{
+			CompRef: newExec.CompRef,
+			CommRef: valChnl.CommRef,
+			ChnlID:  valChnl.ChnlID,
+			ChnlPH:  termDec.AssetVars[i].ChnlPH,
+			ChnlBS:  compvar.AssetSide,
+			ExpVK:   valChnl.ExpVK,
+		})
+	}
+	// вяжем новый канал вызывающего
+	execMod.LinearVars = append(execMod.LinearVars, compvar.LinearRec{
+		CompRef: execSnap.CompRef,
+		CommRef: newExch.CommRef,
+		ChnlID:  newChnlID,
+		ChnlPH:  newChnlPH,
+		ChnlBS:  compvar.AssetSide,
+		ExpVK:   termDec.LiabVar.ExpVK,
+	})
+	// шедулим тело вызываемого
+	execEff.Steps = append(execEff.Steps, compstep.StepSpec{
+		CompRef: newExec.CompRef,
+		ProcExp: termDef.ProcES,
+	})
+	// шедулим продолжение вызывающего
+	execEff.Steps = append(execEff.Steps, compstep.StepSpec{
+		CompRef: execSnap.CompRef,
+		ProcExp: contExp,
+	})
+	s.log.Debug("step taking succeed", compAttr, qnAttr, slog.Any("newRef", newExec.CompRef))
+	return execMod, execEff, nil
+}
+
+// Заменяет текущее вычисление телом вызываемого процесса (aka ExpName).
+//
+// Каналы переименовываются в соответствии с декларацией вызываемого.
+func (s *service) linkWith(
+	procEnv Env,
+	execSnap ExecSnap,
+	execMod ExecMod,
+	termExp termexp.LinkSpec,
+) (
+	ExecMod,
+	ExecEff,
+	error,
+) {
+	var execEff ExecEff
+	compAttr := slog.Any("compRef", execSnap.CompRef)
+	qnAttr := slog.Any("qn", termExp.ProcTermQN)
+	termDec, ok := procEnv.TermDecs[termExp.ProcTermQN]
+	if !ok {
+		s.log.Error("step taking failed", compAttr, qnAttr)
+		return execMod, execEff, termdec.ErrSymMissingInEnv(termExp.ProcTermQN)
+	}
+	termDef, ok := procEnv.TermDefs[termExp.ProcTermQN]
+	if !ok {
+		s.log.Error("step taking failed", compAttr, qnAttr)
+		return execMod, execEff, termdef.ErrSymMissingInEnv(termExp.ProcTermQN)
+	}
+	// старые имена каналов сопоставляются новым
+	oldChnlPHs := append([]symbol.ADT{termExp.CommChnlPH}, termExp.ValChnlPHs...)
+	newChnlPHs := []symbol.ADT{termDec.LiabVar.ChnlPH}
+	for _, assetVar := range termDec.AssetVars {
+		newChnlPHs = append(newChnlPHs, assetVar.ChnlPH)
+	}
+	for i, oldChnlPH := range oldChnlPHs {
+		oldChnl, ok := execSnap.LinearVars[oldChnlPH]
+		if !ok {
+			s.log.Error("step taking failed", compAttr, qnAttr)
+			return execMod, execEff, termdef.ErrMissingInCfg(oldChnlPH)
+		}
+		if oldChnlPH == newChnlPHs[i] {
+			continue
+		}
+		if !slices.Contains(newChnlPHs, oldChnlPH) {
+			// лишаем старого имени
+			execMod.LinearVars = append(execMod.LinearVars, compvar.LinearRec{
+				CompRef: execSnap.CompRef,
+				CommRef: oldChnl.CommRef,
+				ChnlID:  oldChnl.ChnlID,
+				ChnlPH:  oldChnl.ChnlPH,
+				ChnlBS:  oldChnl.ChnlBS,
+				ExpVK:   oldChnl.ExpVK.Invert(),
+			})
+		}
+		// вяжем новое имя
+		execMod.LinearVars = append(execMod.LinearVars, compvar.LinearRec{
+			CompRef: execSnap.CompRef,
+			CommRef: oldChnl.CommRef,
+			ChnlID:  oldChnl.ChnlID,
+			ChnlPH:  newChnlPHs[i],
+			ChnlBS:  oldChnl.ChnlBS,
+			ExpVK:   oldChnl.ExpVK,
+		})
+	}
+	// шедулим тело вызываемого
+	execEff.Steps = append(execEff.Steps, compstep.StepSpec{
+		CompRef: execSnap.CompRef,
+		ProcExp: termDef.ProcES,
+	})
+	s.log.Debug("step taking succeed", compAttr, qnAttr)
+	return execMod, execEff, nil
+}
+
+// ожидает ли доступодатель очередного доступополучателя
+func isAcception(turn commturn.TurnRec) bool {
+	subscription, ok := turn.(commturn.SubRec)
+	if !ok {
+		return false
+	}
+	_, ok = subscription.ContExp.(termexp.AcceptRec)
+	return ok
+}
+
 func CollectCtx(chnls iter.Seq[compvar.LinearRec]) []valkey.ADT {
-	return nil
+	expVKs := []valkey.ADT{}
+	for chnl := range chnls {
+		expVKs = append(expVKs, chnl.ExpVK)
+	}
+	return expVKs
 }
 
 func convertToCtx(chnlBinds iter.Seq[compvar.LinearRec], typeExps map[valkey.ADT]typeexp.ExpRec) typedef.Context {
@@ -863,14 +1722,88 @@ func (s *service) checkType(
 	execSnap ExecSnap,
 	expSpec termexp.ExpSpec,
 ) error {
-	chnlBR, ok := execSnap.LinearVars[expSpec.Via()]
+	switch expSpec.(type) {
+	case nil:
+		return nil
+	}
+	// именованные типы каналов разворачиваются до структуры
+	err := unfoldCtx(procEnv.TypeDefs, procCtx)
+	if err != nil {
+		s.log.Error("checking failed")
+		return err
+	}
+	switch expSpec.(type) {
+	case termexp.CallSpec, termexp.SpawnSpec:
+		// порождение вводит новый канал
+		err = s.checkClient(procEnv, procCtx, execSnap, expSpec)
+		return errAtExp(expSpec, err)
+	}
+	// сторона определяется по контексту, так как каналы
+	// могут быть введены предшествующими выражениями
+	_, ok := procCtx.Liabs[expSpec.Via()]
+	if ok {
+		err = s.checkProvider(procEnv, procCtx, execSnap, expSpec)
+		return errAtExp(expSpec, err)
+	}
+	err = s.checkClient(procEnv, procCtx, execSnap, expSpec)
+	return errAtExp(expSpec, err)
+}
+
+// ExpError points to expression rejected by checking
+type ExpError struct {
+	Exp termexp.ExpSpec
+	Err error
+}
+
+func (e *ExpError) Error() string { return e.Err.Error() }
+
+func (e *ExpError) Unwrap() error { return e.Err }
+
+// отказ привязывается к самому вложенному выражению
+func errAtExp(exp termexp.ExpSpec, err error) error {
+	var expErr *ExpError
+	if err == nil || errors.As(err, &expErr) {
+		return err
+	}
+	return &ExpError{exp, err}
+}
+
+// CheckExp проверяет выражение вне исполнения, например при офлайн-проверке модуля
+func CheckExp(procEnv Env, procCtx typedef.Context, expSpec termexp.ExpSpec) error {
+	s := &service{log: slog.New(slog.DiscardHandler)}
+	return s.checkType(procEnv, procCtx, ExecSnap{}, expSpec)
+}
+
+// unfoldRec разворачивает именованный тип канала до структуры,
+// которую ожидает шаг
+func unfoldRec[T any](procEnv Env, expVK valkey.ADT) (T, error) {
+	var want T
+	typeExp, ok := procEnv.TypeExps[expVK]
 	if !ok {
-		panic("no comm chnl in proc snap")
+		return want, typedef.ErrMissingInEnv(expVK)
 	}
-	if chnlBR.ChnlBS == compvar.LiabSide {
-		return s.checkProvider(procEnv, procCtx, execSnap, expSpec)
+	exp, err := typeexp.Unfold(procEnv.TypeDefs, typeExp)
+	if err != nil {
+		return want, err
+	}
+	want, ok = exp.(T)
+	if !ok {
+		return want, typeexp.ErrRecTypeUnexpected(exp)
 	}
-	return s.checkClient(procEnv, procCtx, execSnap, expSpec)
+	return want, nil
+}
+
+func unfoldCtx(typeDefs typeexp.Defs, procCtx typedef.Context) error {
+	for _, chnls := range []map[symbol.ADT]typeexp.ExpRec{procCtx.Assets, procCtx.Liabs} {
+		for chnlPH, rec := range chnls {
+			exp, err := typeexp.Unfold(typeDefs, rec)
+			if err != nil {
+				return err
+			}
+			chnls[chnlPH] = exp
+		}
+	}
+	return nil
 }
 
 func (s *service) checkProvider(
@@ -894,7 +1827,7 @@ func (s *service) checkProvider(
 			s.log.Error("checking failed")
 			return err
 		}
-		err := typeexp.CheckRec(gotVia, typeexp.OneRec{})
+		err := typeexp.CheckSub(procEnv.TypeDefs, gotVia, typeexp.OneRec{})
 		if err != nil {
 			s.log.Error("checking failed")
 			return err
@@ -927,7 +1860,7 @@ func (s *service) checkProvider(
 			s.log.Error("checking failed")
 			return err
 		}
-		err := typeexp.CheckRec(gotVal, wantVia.Val)
+		err := typeexp.CheckSub(procEnv.TypeDefs, gotVal, wantVia.Val)
 		if err != nil {
 			s.log.Error("checking failed")
 			return err
@@ -957,7 +1890,7 @@ func (s *service) checkProvider(
 			s.log.Error("checking failed")
 			return err
 		}
-		err := typeexp.CheckRec(gotVal, wantVia.Val)
+		err := typeexp.CheckSub(procEnv.TypeDefs, gotVal, wantVia.Val)
 		if err != nil {
 			s.log.Error("checking failed")
 			return err
@@ -1048,7 +1981,7 @@ func (s *service) checkProvider(
 			s.log.Error("checking failed")
 			return err
 		}
-		err := typeexp.CheckRec(fwdSt, viaSt)
+		err := typeexp.CheckSub(procEnv.TypeDefs, fwdSt, viaSt)
 		if err != nil {
 			s.log.Error("checking failed")
 			return err
@@ -1056,6 +1989,87 @@ func (s *service) checkProvider(
 		delete(procCtx.Liabs, expSpec.CommChnlPH)
 		delete(procCtx.Assets, expSpec.ContChnlPH)
 		return nil
+	case termexp.LinkSpec:
+		termDec, ok := procEnv.TermDecs[expSpec.ProcTermQN]
+		if !ok {
+			err := termdec.ErrSymMissingInEnv(expSpec.ProcTermQN)
+			s.log.Error("checking failed")
+			return err
+		}
+		// check vals
+		if len(procCtx.Assets) != len(termDec.AssetVars) {
+			err := fmt.Errorf("context mismatch: want %v items, got %v items", len(termDec.AssetVars), len(procCtx.Assets))
+			s.log.Error("checking failed")
+			return err
+		}
+		err := s.checkVals(procEnv, procCtx, termDec, expSpec.ValChnlPHs)
+		if err != nil {
+			s.log.Error("checking failed")
+			return err
+		}
+		// check via
+		gotVia, ok := procCtx.Liabs[expSpec.CommChnlPH]
+		if !ok {
+			err := typedef.ErrMissingInCtx(expSpec.CommChnlPH)
+			s.log.Error("checking failed")
+			return err
+		}
+		wantVia, ok := procEnv.TypeExps[termDec.LiabVar.ExpVK]
+		if !ok {
+			err := typedef.ErrMissingInEnv(termDec.LiabVar.ExpVK)
+			s.log.Error("checking failed")
+			return err
+		}
+		err = typeexp.CheckSub(procEnv.TypeDefs, gotVia, wantVia)
+		if err != nil {
+			s.log.Error("checking failed")
+			return err
+		}
+		// no cont to check
+		delete(procCtx.Liabs, expSpec.CommChnlPH)
+		return nil
+	case termexp.AcqureSpec:
+		err := termexp.ErrExpTypeMismatch(es, termexp.AcceptSpec{})
+		s.log.Error("checking failed")
+		return err
+	case termexp.AcceptSpec:
+		// check via
+		gotVia, ok := procCtx.Liabs[expSpec.CommChnlPH]
+		if !ok {
+			err := typedef.ErrMissingInCtx(expSpec.CommChnlPH)
+			s.log.Error("checking failed")
+			return err
+		}
+		wantVia, ok := gotVia.(typeexp.UpRec)
+		if !ok {
+			err := typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
+			s.log.Error("checking failed")
+			return err
+		}
+		// check cont
+		procCtx.Liabs[expSpec.CommChnlPH] = wantVia.Cont
+		return s.checkType(procEnv, procCtx, procCfg, expSpec.ContExp)
+	case termexp.ReleaseSpec:
+		err := termexp.ErrExpTypeMismatch(es, termexp.DetachSpec{})
+		s.log.Error("checking failed")
+		return err
+	case termexp.DetachSpec:
+		// check via
+		gotVia, ok := procCtx.Liabs[expSpec.CommChnlPH]
+		if !ok {
+			err := typedef.ErrMissingInCtx(expSpec.CommChnlPH)
+			s.log.Error("checking failed")
+			return err
+		}
+		wantVia, ok := gotVia.(typeexp.DownRec)
+		if !ok {
+			err := typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
+			s.log.Error("checking failed")
+			return err
+		}
+		// check cont
+		procCtx.Liabs[expSpec.CommChnlPH] = wantVia.Cont
+		return s.checkType(procEnv, procCtx, procCfg, expSpec.ContExp)
 	default:
 		panic(termexp.ErrExpTypeUnexpected(es))
 	}
@@ -1110,7 +2124,7 @@ func (s *service) checkClient(
 			s.log.Error("checking failed")
 			return err
 		}
-		err := typeexp.CheckRec(gotVal, wantVia.Val)
+		err := typeexp.CheckSub(procEnv.TypeDefs, gotVal, wantVia.Val)
 		if err != nil {
 			s.log.Error("checking failed")
 			return err
@@ -1139,7 +2153,7 @@ func (s *service) checkClient(
 			s.log.Error("checking failed")
 			return err
 		}
-		err := typeexp.CheckRec(gotVal, wantVia.Val)
+		err := typeexp.CheckSub(procEnv.TypeDefs, gotVal, wantVia.Val)
 		if err != nil {
 			s.log.Error("checking failed")
 			return err
@@ -1206,71 +2220,132 @@ func (s *service) checkClient(
 			}
 		}
 		return nil
-	// case procexp.SpawnSpecOld:
-	// 	procDec, ok := procEnv.ProcDecs[expSpec.SigID]
-	// 	if !ok {
-	// 		err := procdec.ErrRootMissingInEnv(expSpec.SigID)
-	// 		s.log.Error("checking failed")
-	// 		return err
-	// 	}
-	// 	// check vals
-	// 	if len(expSpec.Ys) != len(procDec.ClientVRs) {
-	// 		err := fmt.Errorf("context mismatch: want %v items, got %v items", len(procDec.ClientVRs), len(expSpec.Ys))
-	// 		s.log.Error("checking failed", slog.Any("want", procDec.ClientVRs), slog.Any("got", expSpec.Ys))
-	// 		return err
-	// 	}
-	// 	if len(expSpec.Ys) == 0 {
-	// 		return nil
-	// 	}
-	// 	for i, ep := range procDec.ClientVRs {
-	// 		valType, ok := procEnv.TypeDefs[ep.ImplQN]
-	// 		if !ok {
-	// 			err := typedef.ErrSymMissingInEnv(ep.ImplQN)
-	// 			s.log.Error("checking failed")
-	// 			return err
-	// 		}
-	// 		wantVal, ok := procEnv.TypeExps[valType.ExpVK]
-	// 		if !ok {
-	// 			err := typedef.ErrMissingInEnv(valType.ExpVK)
-	// 			s.log.Error("checking failed")
-	// 			return err
-	// 		}
-	// 		gotVal, ok := procCtx.Assets[expSpec.Ys[i]]
-	// 		if !ok {
-	// 			err := procdef.ErrMissingInCtx(ep.ChnlPH)
-	// 			s.log.Error("checking failed")
-	// 			return err
-	// 		}
-	// 		err := typeexp.CheckRec(gotVal, wantVal)
-	// 		if err != nil {
-	// 			s.log.Error("checking failed", slog.Any("want", wantVal), slog.Any("got", gotVal))
-	// 			return err
-	// 		}
-	// 		delete(procCtx.Assets, expSpec.Ys[i])
-	// 	}
-	// 	// check via
-	// 	viaRole, ok := procEnv.TypeDefs[procDec.ProviderVR.ImplQN]
-	// 	if !ok {
-	// 		err := typedef.ErrSymMissingInEnv(procDec.ProviderVR.ImplQN)
-	// 		s.log.Error("checking failed")
-	// 		return err
-	// 	}
-	// 	wantVia, ok := procEnv.TypeExps[viaRole.ExpVK]
-	// 	if !ok {
-	// 		err := typedef.ErrMissingInEnv(viaRole.ExpVK)
-	// 		s.log.Error("checking failed")
-	// 		return err
-	// 	}
-	// 	// check cont
-	// 	procCtx.Assets[expSpec.X] = wantVia
-	// 	return s.checkType(procEnv, procCtx, procCfg, expSpec.ContES)
+	case termexp.CallSpec:
+		return s.checkSpawn(procEnv, procCtx, procCfg, expSpec.NewChnlPH, expSpec.ProcTermQN, expSpec.ValChnlPHs, expSpec.ContExp)
+	case termexp.SpawnSpec:
+		return s.checkSpawn(procEnv, procCtx, procCfg, expSpec.CommChnlPH, expSpec.ProcTermQN, expSpec.NewChnlPHs, expSpec.ContExp)
+	case termexp.AcqureSpec:
+		// check via
+		gotVia, ok := procCtx.Assets[expSpec.CommChnlPH]
+		if !ok {
+			err := termdef.ErrMissingInCtx(expSpec.CommChnlPH)
+			s.log.Error("checking failed")
+			return err
+		}
+		wantVia, ok := gotVia.(typeexp.UpRec)
+		if !ok {
+			err := typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
+			s.log.Error("checking failed")
+			return err
+		}
+		// check cont
+		procCtx.Assets[expSpec.CommChnlPH] = wantVia.Cont
+		return s.checkType(procEnv, procCtx, procCfg, expSpec.ContExp)
+	case termexp.AcceptSpec:
+		err := termexp.ErrExpTypeMismatch(es, termexp.AcqureSpec{})
+		s.log.Error("checking failed")
+		return err
+	case termexp.ReleaseSpec:
+		// check via
+		gotVia, ok := procCtx.Assets[expSpec.CommChnlPH]
+		if !ok {
+			err := termdef.ErrMissingInCtx(expSpec.CommChnlPH)
+			s.log.Error("checking failed")
+			return err
+		}
+		wantVia, ok := gotVia.(typeexp.DownRec)
+		if !ok {
+			err := typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
+			s.log.Error("checking failed")
+			return err
+		}
+		// check cont
+		procCtx.Assets[expSpec.CommChnlPH] = wantVia.Cont
+		return s.checkType(procEnv, procCtx, procCfg, expSpec.ContExp)
+	case termexp.DetachSpec:
+		err := termexp.ErrExpTypeMismatch(es, termexp.ReleaseSpec{})
+		s.log.Error("checking failed")
+		return err
 	default:
 		panic(termexp.ErrExpTypeUnexpected(es))
 	}
 }
 
+func (s *service) checkSpawn(
+	procEnv Env,
+	procCtx typedef.Context,
+	procCfg ExecSnap,
+	newChnlPH symbol.ADT,
+	procQN uniqsym.ADT,
+	valChnlPHs []symbol.ADT,
+	contExp termexp.ExpSpec,
+) error {
+	termDec, ok := procEnv.TermDecs[procQN]
+	if !ok {
+		err := termdec.ErrSymMissingInEnv(procQN)
+		s.log.Error("checking failed")
+		return err
+	}
+	// check vals
+	err := s.checkVals(procEnv, procCtx, termDec, valChnlPHs)
+	if err != nil {
+		s.log.Error("checking failed")
+		return err
+	}
+	// check via
+	if _, ok := procCtx.Assets[newChnlPH]; ok {
+		err := fmt.Errorf("channel already in ctx: %v", newChnlPH)
+		s.log.Error("checking failed")
+		return err
+	}
+	wantVia, ok := procEnv.TypeExps[termDec.LiabVar.ExpVK]
+	if !ok {
+		err := typedef.ErrMissingInEnv(termDec.LiabVar.ExpVK)
+		s.log.Error("checking failed")
+		return err
+	}
+	// check cont
+	procCtx.Assets[newChnlPH] = wantVia
+	return s.checkType(procEnv, procCtx, procCfg, contExp)
+}
+
+// проверяет передаваемые каналы на соответствие декларации
+func (s *service) checkVals(
+	procEnv Env,
+	procCtx typedef.Context,
+	termDec termdec.DecRec,
+	valChnlPHs []symbol.ADT,
+) error {
+	if len(valChnlPHs) != len(termDec.AssetVars) {
+		err := fmt.Errorf("context mismatch: want %v items, got %v items", len(termDec.AssetVars), len(valChnlPHs))
+		s.log.Error("checking failed", slog.Any("want", termDec.AssetVars), slog.Any("got", valChnlPHs))
+		return err
+	}
+	for i, assetVar := range termDec.AssetVars {
+		wantVal, ok := procEnv.TypeExps[assetVar.ExpVK]
+		if !ok {
+			err := typedef.ErrMissingInEnv(assetVar.ExpVK)
+			s.log.Error("checking failed")
+			return err
+		}
+		gotVal, ok := procCtx.Assets[valChnlPHs[i]]
+		if !ok {
+			err := termdef.ErrMissingInCtx(valChnlPHs[i])
+			s.log.Error("checking failed")
+			return err
+		}
+		err := typeexp.CheckSub(procEnv.TypeDefs, gotVal, wantVal)
+		if err != nil {
+			s.log.Error("checking failed", slog.Any("want", wantVal), slog.Any("got", gotVal))
+			return err
+		}
+		delete(procCtx.Assets, valChnlPHs[i])
+	}
+	return nil
+}
+
 func errOptimisticUpdate(got seqnum.ADT) error {
-	return fmt.Errorf("entity concurrent modification: got revision %v", got)
+	return fmt.Errorf("%w: got revision %v", db.ErrConcurrentModification, got)
 }
 
 func errMissingPool(want uniqsym.ADT) error {
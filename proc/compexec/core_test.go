@@ -0,0 +1,606 @@
+package compexec
+
+import (
+	"log/slog"
+	"testing"
+
+	"orglang/go-engine/lib/db"
+	"orglang/go-engine/lib/wp"
+
+	"orglang/go-engine/adt/commsem"
+	"orglang/go-engine/adt/compsem"
+	"orglang/go-engine/adt/compvar"
+	"orglang/go-engine/adt/identity"
+	"orglang/go-engine/adt/seqnum"
+	"orglang/go-engine/adt/symbol"
+	"orglang/go-engine/adt/termvar"
+	"orglang/go-engine/adt/uniqsym"
+	"orglang/go-engine/adt/valkey"
+	"orglang/go-engine/proc/commexch"
+	"orglang/go-engine/proc/commturn"
+	"orglang/go-engine/proc/compfuel"
+	"orglang/go-engine/proc/compstep"
+	"orglang/go-engine/proc/termdec"
+	"orglang/go-engine/proc/termdef"
+	"orglang/go-engine/proc/termexp"
+	"orglang/go-engine/proc/typedef"
+	"orglang/go-engine/proc/typeexp"
+)
+
+// исполнитель над хранилищем в памяти; вычисления и обмены
+// заводятся напрямую, как их заводит исполнитель пулов
+type testEnv struct {
+	t          *testing.T
+	exec       *service
+	typeDefAPI typedef.API
+	termDecAPI termdec.API
+	termDefAPI termdef.API
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	log := slog.New(slog.DiscardHandler)
+	operator := db.NewOperatorMem()
+	exec := newService(
+		newMemDAO(log),
+		commexch.NewMemRepo(log),
+		commturn.NewMemRepo(log),
+		termdec.NewMemRepo(log),
+		termdef.NewMemRepo(log),
+		typedef.NewMemRepo(log),
+		typeexp.NewMemRepo(log),
+		freeFuel{},
+		wp.RecoverPolicy,
+		operator,
+		log,
+	)
+	return &testEnv{
+		t,
+		exec,
+		typedef.NewMemAPI(operator, log),
+		termdec.NewMemAPI(operator, log),
+		termdef.NewMemAPI(operator, log),
+	}
+}
+
+// defineType возвращает тело именованного типа
+func (e *testEnv) defineType(typeQN uniqsym.ADT, spec typeexp.ExpSpec) typeexp.ExpRec {
+	e.t.Helper()
+	_, err := e.typeDefAPI.Create(typedef.DefSpec{TypeQN: typeQN, TypeExp: spec})
+	if err != nil {
+		e.t.Fatalf("unexpected error %q", err)
+	}
+	return e.expRec(spec)
+}
+
+// defineProc объявляет и определяет процесс
+func (e *testEnv) defineProc(spec termdec.DecSpec, body termexp.ExpSpec) {
+	e.t.Helper()
+	_, err := e.termDecAPI.Create(spec)
+	if err != nil {
+		e.t.Fatalf("unexpected error %q", err)
+	}
+	_, err = e.termDefAPI.Create(termdef.DefSpec{ProcQN: spec.TermQN, ProcES: body})
+	if err != nil {
+		e.t.Fatalf("unexpected error %q", err)
+	}
+}
+
+// defineWorker объявляет процесс, который дожидается значения
+// и закрывает свое обязательство
+func (e *testEnv) defineWorker() uniqsym.ADT {
+	e.t.Helper()
+	unitQN := uniqsym.New(symbol.New("unit"))
+	e.defineType(unitQN, typeexp.OneSpec{})
+	workerQN := uniqsym.New(symbol.New("worker"))
+	e.defineProc(
+		termdec.DecSpec{
+			TermQN:    workerQN,
+			LiabVar:   termvar.VarSpec{ChnlPH: symbol.New("y"), TypeQN: unitQN},
+			AssetVars: []termvar.VarSpec{{ChnlPH: symbol.New("v"), TypeQN: unitQN}},
+		},
+		termexp.WaitSpec{
+			ContChnlPH: symbol.New("v"),
+			ContExp:    termexp.CloseSpec{ContChnlPH: symbol.New("y")},
+		},
+	)
+	return workerQN
+}
+
+func (e *testEnv) expRec(spec typeexp.ExpSpec) typeexp.ExpRec {
+	e.t.Helper()
+	rec, err := typeexp.ConvertSpecToRec(spec)
+	if err != nil {
+		e.t.Fatalf("unexpected error %q", err)
+	}
+	return rec
+}
+
+func (e *testEnv) newExch() commsem.SemRef {
+	e.t.Helper()
+	exch := commexch.ExchRec{CommRef: commsem.New(), OffsetNr: seqnum.Zero}
+	err := e.exec.operator.Explicit(e.t.Context(), func(ds db.Source) error {
+		return e.exec.commExchRepo.AddRec(ds, exch)
+	})
+	if err != nil {
+		e.t.Fatalf("unexpected error %q", err)
+	}
+	return exch.CommRef
+}
+
+func (e *testEnv) newExec(vars ...compvar.LinearRec) compsem.SemRef {
+	e.t.Helper()
+	newExec := ExecRec{CompRef: compsem.New(), LiabMode: compvar.LinearMode}
+	for i := range vars {
+		vars[i].CompRef = newExec.CompRef
+	}
+	err := e.exec.operator.Explicit(e.t.Context(), func(ds db.Source) error {
+		return e.exec.compExecRepo.ModifyRec(ds, ExecMod{
+			CompRefs:   []compsem.SemRef{newExec.CompRef},
+			NewExecs:   []ExecRec{newExec},
+			LinearVars: vars,
+		})
+	})
+	if err != nil {
+		e.t.Fatalf("unexpected error %q", err)
+	}
+	return newExec.CompRef
+}
+
+func (e *testEnv) take(ref compsem.SemRef, exp termexp.ExpSpec) {
+	e.t.Helper()
+	err := e.exec.Take(compstep.StepSpec{CompRef: ref, ProcExp: exp})
+	if err != nil {
+		e.t.Fatalf("unexpected error %q", err)
+	}
+}
+
+func (e *testEnv) snap(ref compsem.SemRef) ExecSnap {
+	e.t.Helper()
+	snap, err := e.exec.RetrieveSnap(ref)
+	if err != nil {
+		e.t.Fatalf("unexpected error %q", err)
+	}
+	return snap
+}
+
+func liabVar(ph string, comm commsem.SemRef, expVK valkey.ADT) compvar.LinearRec {
+	return compvar.LinearRec{
+		CommRef: comm,
+		ChnlID:  comm.CommID,
+		ChnlPH:  symbol.New(ph),
+		ChnlBS:  compvar.LiabSide,
+		ExpVK:   expVK,
+	}
+}
+
+func assetVar(ph string, comm commsem.SemRef, expVK valkey.ADT) compvar.LinearRec {
+	return compvar.LinearRec{
+		CommRef: comm,
+		ChnlID:  comm.CommID,
+		ChnlPH:  symbol.New(ph),
+		ChnlBS:  compvar.AssetSide,
+		ExpVK:   expVK,
+	}
+}
+
+// freeFuel не ограничивает шаги
+type freeFuel struct{}
+
+func (freeFuel) Burn(db.Source, compfuel.Realm, identity.ADT) (compfuel.FuelRec, error) {
+	return compfuel.FuelRec{}, nil
+}
+
+func (freeFuel) Grant(db.Source, identity.ADT, identity.ADT) error {
+	return nil
+}
+
+func (freeFuel) Halt(db.Source, compstep.StepSpec) (bool, error) {
+	return false, nil
+}
+
+func (freeFuel) Refill(db.Source, compfuel.FuelSpec) (compfuel.FuelRec, []compstep.StepSpec, error) {
+	return compfuel.FuelRec{}, nil, nil
+}
+
+func TestTakeRecursiveSendRecv(t *testing.T) {
+	env := newTestEnv(t)
+	// stream = 1 ⊗ stream
+	streamQN := uniqsym.New(symbol.New("stream"))
+	streamExp := env.defineType(streamQN, typeexp.TensorSpec{
+		Val:  typeexp.OneSpec{},
+		Cont: typeexp.LinkSpec{TypeQN: streamQN},
+	})
+	// после первого хода тип канала становится ссылкой на себя
+	streamVK := env.expRec(typeexp.LinkSpec{TypeQN: streamQN}).Key()
+	streamComm := env.newExch()
+	valComms := []commsem.SemRef{env.newExch(), env.newExch()}
+	sender := env.newExec(
+		liabVar("s", streamComm, streamExp.Key()),
+		assetVar("v0", valComms[0], valkey.One),
+		assetVar("v1", valComms[1], valkey.One),
+	)
+	// приниматель сверяет тип значения с уже связанной переменной
+	receiver := env.newExec(
+		assetVar("s", streamComm, streamExp.Key()),
+		assetVar("w", env.newExch(), valkey.One),
+	)
+	for i, valComm := range valComms {
+		env.take(receiver, termexp.RecvSpec{CommChnlPH: symbol.New("s"), NewChnlPH: symbol.New("w")})
+		env.take(sender, termexp.SendSpec{CommChnlPH: symbol.New("s"), ValChnlPH: symbol.New([]string{"v0", "v1"}[i])})
+		snap := env.snap(receiver)
+		if got := snap.LinearVars[symbol.New("s")].ExpVK; got != streamVK {
+			t.Errorf("unexpected receiver type at round %v: want %v, got %v", i, streamVK, got)
+		}
+		if got := snap.LinearVars[symbol.New("w")].ChnlID; got != valComm.CommID {
+			t.Errorf("unexpected value at round %v: want %v, got %v", i, valComm.CommID, got)
+		}
+	}
+	snap := env.snap(sender)
+	if got := snap.LinearVars[symbol.New("s")].ExpVK; got != streamVK {
+		t.Errorf("unexpected sender type: want %v, got %v", streamVK, got)
+	}
+	if len(snap.LinearVars) != 1 {
+		t.Errorf("unexpected sender vars: want 1, got %v", len(snap.LinearVars))
+	}
+}
+
+func TestTakeLabCase(t *testing.T) {
+	env := newTestEnv(t)
+	labA := uniqsym.New(symbol.New("a"))
+	labB := uniqsym.New(symbol.New("b"))
+	choiceExp := env.defineType(uniqsym.New(symbol.New("choice")), typeexp.PlusSpec{
+		Choices: map[uniqsym.ADT]typeexp.ExpSpec{labA: typeexp.OneSpec{}, labB: typeexp.OneSpec{}},
+	})
+	comm := env.newExch()
+	provider := env.newExec(liabVar("x", comm, choiceExp.Key()))
+	client := env.newExec(assetVar("x", comm, choiceExp.Key()))
+	// последователь подписывается раньше решения
+	env.take(client, termexp.CaseSpec{
+		CommChnlPH: symbol.New("x"),
+		ContExps: map[uniqsym.ADT]termexp.ExpSpec{
+			labA: termexp.WaitSpec{ContChnlPH: symbol.New("x")},
+			labB: termexp.WaitSpec{ContChnlPH: symbol.New("x")},
+		},
+	})
+	env.take(provider, termexp.LabSpec{CommChnlPH: symbol.New("x"), ValLabQN: labA})
+	providerVar := env.snap(provider).LinearVars[symbol.New("x")]
+	clientSnap := env.snap(client)
+	clientVar := clientSnap.LinearVars[symbol.New("x")]
+	if providerVar.ExpVK != valkey.One || clientVar.ExpVK != valkey.One {
+		t.Errorf("unexpected choice types: want %v, got %v and %v", valkey.One, providerVar.ExpVK, clientVar.ExpVK)
+	}
+	if providerVar.ChnlID != clientVar.ChnlID {
+		t.Errorf("unexpected channels: want equal, got %v and %v", providerVar.ChnlID, clientVar.ChnlID)
+	}
+	// продолжение выбранной ветви уже ждет закрытия
+	if got := len(clientSnap.LinearTurns[symbol.New("x")]); got != 1 {
+		t.Errorf("unexpected client turns: want 1, got %v", got)
+	}
+	env.take(provider, termexp.CloseSpec{ContChnlPH: symbol.New("x")})
+	for _, ref := range []compsem.SemRef{provider, client} {
+		if got := len(env.snap(ref).LinearVars); got != 0 {
+			t.Errorf("unexpected vars of %v: want 0, got %v", ref, got)
+		}
+	}
+}
+
+func TestTakeSpawn(t *testing.T) {
+	tests := []struct {
+		name  string
+		spawn func(uniqsym.ADT) termexp.ExpSpec
+	}{
+		{"call", func(qn uniqsym.ADT) termexp.ExpSpec {
+			return termexp.CallSpec{
+				NewChnlPH:  symbol.New("y"),
+				ProcTermQN: qn,
+				ValChnlPHs: []symbol.ADT{symbol.New("w")},
+				ContExp:    termexp.WaitSpec{ContChnlPH: symbol.New("y")},
+			}
+		}},
+		{"spawn", func(qn uniqsym.ADT) termexp.ExpSpec {
+			return termexp.SpawnSpec{
+				CommChnlPH: symbol.New("y"),
+				ProcTermQN: qn,
+				NewChnlPHs: []symbol.ADT{symbol.New("w")},
+				ContExp:    termexp.WaitSpec{ContChnlPH: symbol.New("y")},
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			workerQN := env.defineWorker()
+			valComm := env.newExch()
+			server := env.newExec(liabVar("u", valComm, valkey.One))
+			caller := env.newExec(assetVar("w", valComm, valkey.One))
+			env.take(caller, tt.spawn(workerQN))
+			// значение ушло вызываемому, взамен получен его канал
+			snap := env.snap(caller)
+			if _, ok := snap.LinearVars[symbol.New("w")]; ok {
+				t.Errorf("unexpected caller value: want deprived, got bound")
+			}
+			if got := snap.LinearVars[symbol.New("y")].ExpVK; got != valkey.One {
+				t.Errorf("unexpected callee type: want %v, got %v", valkey.One, got)
+			}
+			// закрытие значения продвигает тело вызываемого,
+			// которое закрывает канал вызывающего
+			env.take(server, termexp.CloseSpec{ContChnlPH: symbol.New("u")})
+			for _, ref := range []compsem.SemRef{server, caller} {
+				if got := len(env.snap(ref).LinearVars); got != 0 {
+					t.Errorf("unexpected vars of %v: want 0, got %v", ref, got)
+				}
+			}
+		})
+	}
+}
+
+func TestTakeLink(t *testing.T) {
+	env := newTestEnv(t)
+	workerQN := env.defineWorker()
+	outComm := env.newExch()
+	valComm := env.newExch()
+	server := env.newExec(liabVar("u", valComm, valkey.One))
+	linker := env.newExec(
+		liabVar("x", outComm, valkey.One),
+		assetVar("w", valComm, valkey.One),
+	)
+	client := env.newExec(assetVar("x", outComm, valkey.One))
+	env.take(client, termexp.WaitSpec{ContChnlPH: symbol.New("x")})
+	env.take(linker, termexp.LinkSpec{
+		CommChnlPH: symbol.New("x"),
+		ProcTermQN: workerQN,
+		ValChnlPHs: []symbol.ADT{symbol.New("w")},
+	})
+	// каналы переименованы по декларации вызываемого
+	snap := env.snap(linker)
+	if got := snap.LinearVars[symbol.New("y")].ChnlID; got != outComm.CommID {
+		t.Errorf("unexpected liab channel: want %v, got %v", outComm.CommID, got)
+	}
+	if got := snap.LinearVars[symbol.New("v")].ChnlID; got != valComm.CommID {
+		t.Errorf("unexpected asset channel: want %v, got %v", valComm.CommID, got)
+	}
+	if len(snap.LinearVars) != 2 {
+		t.Errorf("unexpected linker vars: want 2, got %v", len(snap.LinearVars))
+	}
+	env.take(server, termexp.CloseSpec{ContChnlPH: symbol.New("u")})
+	for _, ref := range []compsem.SemRef{server, linker, client} {
+		if got := len(env.snap(ref).LinearVars); got != 0 {
+			t.Errorf("unexpected vars of %v: want 0, got %v", ref, got)
+		}
+	}
+}
+
+// defineShared возвращает эквисинхронный тип shared = ↑↓shared
+func (e *testEnv) defineShared() typeexp.ExpRec {
+	e.t.Helper()
+	sharedQN := uniqsym.New(symbol.New("shared"))
+	return e.defineType(sharedQN, typeexp.UpSpec{
+		Cont: typeexp.DownSpec{Cont: typeexp.LinkSpec{TypeQN: sharedQN}},
+	})
+}
+
+func TestTakeAcquireRelease(t *testing.T) {
+	tests := []struct {
+		name        string
+		clients     int
+		acceptFirst bool
+	}{
+		{"accept first", 1, true},
+		{"acquire first", 1, false},
+		// без доступодателя клиенты встают в очередь разделяемого канала
+		{"queued clients", 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			sharedExp := env.defineShared()
+			sharedQN := uniqsym.New(symbol.New("shared"))
+			downVK := env.expRec(typeexp.DownSpec{Cont: typeexp.LinkSpec{TypeQN: sharedQN}}).Key()
+			sharedPH := symbol.New("s")
+			comm := env.newExch()
+			provider := env.newExec(liabVar("s", comm, sharedExp.Key()))
+			var clients []compsem.SemRef
+			for range tt.clients {
+				clients = append(clients, env.newExec(assetVar("s", comm, sharedExp.Key())))
+			}
+			accept := func() { env.take(provider, termexp.AcceptSpec{CommChnlPH: sharedPH}) }
+			if tt.acceptFirst {
+				accept()
+			}
+			for _, client := range clients {
+				env.take(client, termexp.AcqureSpec{CommChnlPH: sharedPH})
+			}
+			if !tt.acceptFirst {
+				if got := len(env.snap(provider).LinearTurns[sharedPH]); got != len(clients) {
+					t.Fatalf("unexpected queue: want %v, got %v", len(clients), got)
+				}
+			}
+			for i, client := range clients {
+				if i > 0 || !tt.acceptFirst {
+					accept()
+				}
+				// сессия идет по отдельному каналу
+				providerVar := env.snap(provider).LinearVars[sharedPH]
+				clientVar := env.snap(client).LinearVars[sharedPH]
+				if providerVar.ExpVK != downVK || clientVar.ExpVK != downVK {
+					t.Errorf("unexpected session types at round %v: want %v, got %v and %v", i, downVK, providerVar.ExpVK, clientVar.ExpVK)
+				}
+				if providerVar.ChnlID != clientVar.ChnlID || providerVar.ChnlID == comm.CommID {
+					t.Errorf("unexpected session channels at round %v: got %v and %v", i, providerVar.ChnlID, clientVar.ChnlID)
+				}
+				// обслуживается первый в очереди, остальные ждут на разделяемом канале
+				for _, waiting := range clients[i+1:] {
+					chnl := env.snap(waiting).LinearVars[sharedPH]
+					if chnl.ChnlID != comm.CommID || chnl.ExpVK != sharedExp.Key() {
+						t.Errorf("unexpected waiting channel at round %v: got %v of type %v", i, chnl.ChnlID, chnl.ExpVK)
+					}
+				}
+				env.take(client, termexp.ReleaseSpec{CommChnlPH: sharedPH})
+				env.take(provider, termexp.DetachSpec{CommChnlPH: sharedPH})
+				// канал возвращается в разделяемый режим и разделяемый тип
+				for _, ref := range []compsem.SemRef{provider, client} {
+					chnl := env.snap(ref).LinearVars[sharedPH]
+					if chnl.ChnlID != comm.CommID {
+						t.Errorf("unexpected channel of %v: want %v, got %v", ref, comm.CommID, chnl.ChnlID)
+					}
+					if chnl.ExpVK != sharedExp.Key() {
+						t.Errorf("unexpected type of %v: want %v, got %v", ref, sharedExp.Key(), chnl.ExpVK)
+					}
+				}
+				if got := len(env.snap(provider).LinearTurns[sharedPH]); got != len(clients)-i-1 {
+					t.Errorf("unexpected queue at round %v: want %v, got %v", i, len(clients)-i-1, got)
+				}
+			}
+		})
+	}
+}
+
+func TestViewFromExecSnap(t *testing.T) {
+	env := newTestEnv(t)
+	comm := env.newExch()
+	client := env.newExec(assetVar("x", comm, valkey.One))
+	env.take(client, termexp.WaitSpec{ContChnlPH: symbol.New("x")})
+	view, err := ViewFromExecSnap(env.snap(client))
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	if len(view.VarSnaps) != 1 || len(view.VarSnaps[0].Turns) != 1 {
+		t.Fatalf("unexpected view: %+v", view)
+	}
+	// ход описан шагом, а не строкой proc_comm_turns
+	got := view.VarSnaps[0].Turns[0]
+	want := TurnVP{
+		CommRN:  got.CommRN,
+		CompRef: got.CompRef,
+		ChnlID:  identity.ConvertToString(comm.CommID),
+		Side:    "sub",
+		Step:    "wait",
+		ChnlPH:  "x",
+	}
+	if got != want {
+		t.Errorf("unexpected turn: want %+v, got %+v", want, got)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	streamQN := uniqsym.New(symbol.New("stream"))
+	tests := []struct {
+		name string
+		// alter меняет хранилище после шагов отправителя
+		alter    func(*testEnv, typedef.DefSnap, compsem.SemRef)
+		replayed int
+		diverged bool
+	}{
+		{"history", func(*testEnv, typedef.DefSnap, compsem.SemRef) {}, 2, false},
+		// тело типа берется из журнала, а не в текущей ревизии
+		{"modified type", func(e *testEnv, snap typedef.DefSnap, _ compsem.SemRef) {
+			snap.DefSpec.TypeExp = typeexp.TensorSpec{Val: typeexp.OneSpec{}, Cont: typeexp.OneSpec{}}
+			_, err := e.typeDefAPI.Modify(snap)
+			if err != nil {
+				e.t.Fatalf("unexpected error %q", err)
+			}
+		}, 2, false},
+		// второй шаг будто записал другой тип
+		{"altered history", func(e *testEnv, _ typedef.DefSnap, sender compsem.SemRef) {
+			err := e.exec.operator.Explicit(e.t.Context(), func(source db.Source) error {
+				ds := db.MustConform[db.SourceMem](source)
+				vars := db.TableOf[varKeyDS, compvar.VarRecDS](ds, procLinearVars)
+				var last compvar.VarRecDS
+				for _, dto := range vars.Rows() {
+					if dto.CompID.String == sender.CompID.String() && dto.ChnlPH.String == "s" && dto.CompRN.Int64 > last.CompRN.Int64 {
+						last = dto
+					}
+				}
+				last.ExpVK.Int64 = int64(valkey.One)
+				vars.Put(varKeyDS{last.CompID.String, last.CompRN.Int64, last.ChnlPH.String}, last)
+				return nil
+			})
+			if err != nil {
+				e.t.Fatalf("unexpected error %q", err)
+			}
+		}, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			// stream = 1 ⊗ stream
+			typeSnap, err := env.typeDefAPI.Create(typedef.DefSpec{
+				TypeQN: streamQN,
+				TypeExp: typeexp.TensorSpec{
+					Val:  typeexp.OneSpec{},
+					Cont: typeexp.LinkSpec{TypeQN: streamQN},
+				},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			streamVK := env.expRec(typeSnap.DefSpec.TypeExp).Key()
+			streamComm := env.newExch()
+			sender := env.newExec(
+				liabVar("s", streamComm, streamVK),
+				assetVar("v0", env.newExch(), valkey.One),
+				assetVar("v1", env.newExch(), valkey.One),
+			)
+			receiver := env.newExec(
+				assetVar("s", streamComm, streamVK),
+				assetVar("w", env.newExch(), valkey.One),
+			)
+			// второй ход разворачивает ссылку на stream
+			for _, valPH := range []string{"v0", "v1"} {
+				env.take(receiver, termexp.RecvSpec{CommChnlPH: symbol.New("s"), NewChnlPH: symbol.New("w")})
+				env.take(sender, termexp.SendSpec{CommChnlPH: symbol.New("s"), ValChnlPH: symbol.New(valPH)})
+			}
+			tt.alter(env, typeSnap, sender)
+			rep, err := env.exec.Replay(sender)
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			if rep.Replayed != tt.replayed {
+				t.Errorf("unexpected replayed steps: want %v, got %v", tt.replayed, rep.Replayed)
+			}
+			if tt.diverged != (rep.Divergence != nil) {
+				t.Fatalf("unexpected divergence: %+v", rep.Divergence)
+			}
+			if tt.diverged && rep.Divergence.ChnlPH != symbol.New("s") {
+				t.Errorf("unexpected diverged var: want s, got %v", rep.Divergence.ChnlPH)
+			}
+		})
+	}
+}
+
+func TestDiffVars(t *testing.T) {
+	comm := commsem.New()
+	stepRef := compsem.New()
+	tests := []struct {
+		name   string
+		want   []compvar.LinearRec
+		got    []compvar.LinearRec
+		reason string
+	}{
+		{"equal", []compvar.LinearRec{liabVar("x", comm, valkey.One)}, []compvar.LinearRec{liabVar("x", comm, valkey.One)}, ""},
+		{"none", nil, nil, ""},
+		// новые каналы получают новые идентификаторы
+		{"new channel", []compvar.LinearRec{liabVar("x", comm, valkey.One)}, []compvar.LinearRec{liabVar("x", commsem.New(), valkey.One)}, ""},
+		{"missing in replay", []compvar.LinearRec{liabVar("x", comm, valkey.One)}, nil, "var missing in replay"},
+		{"missing in history", nil, []compvar.LinearRec{liabVar("x", comm, valkey.One)}, "var missing in history"},
+		{"type mismatch", []compvar.LinearRec{liabVar("x", comm, valkey.One)}, []compvar.LinearRec{liabVar("x", comm, valkey.One.Invert())}, "var mismatch"},
+		{"side mismatch", []compvar.LinearRec{liabVar("x", comm, valkey.One)}, []compvar.LinearRec{assetVar("x", comm, valkey.One)}, "var mismatch"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			div := diffVars(stepRef, tt.want, tt.got)
+			if tt.reason == "" {
+				if div != nil {
+					t.Fatalf("unexpected divergence: %+v", div)
+				}
+				return
+			}
+			if div == nil {
+				t.Fatalf("want divergence %q, got nil", tt.reason)
+			}
+			if div.Reason != tt.reason || div.StepRef != stepRef || div.ChnlPH != symbol.New("x") {
+				t.Errorf("unexpected divergence: %+v", div)
+			}
+		})
+	}
+}
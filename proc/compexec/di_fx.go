@@ -7,7 +7,7 @@ import (
 var Module = fx.Module("proc/compexec",
 	fx.Provide(
 		fx.Annotate(newService, fx.As(new(API))),
-		fx.Annotate(newPgxDAO, fx.As(new(Repo))),
+		newDialectDAO,
 	),
 	fx.Provide(
 		fx.Private,
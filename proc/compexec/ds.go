@@ -1,16 +1,36 @@
 package compexec
 
 import (
+	"log/slog"
+
 	"orglang/go-engine/lib/db"
 
 	"orglang/go-engine/adt/compsem"
 	"orglang/go-engine/adt/compvar"
+	"orglang/go-engine/adt/termsem"
+	"orglang/go-engine/proc/commturn"
+	"orglang/go-engine/proc/termexp"
 )
 
 type Repo interface {
 	AddRec(db.Source, ExecRec) error
 	ModifyRec(db.Source, ExecMod) error
 	GetSnapByRef(db.Source, compsem.SemRef) (ExecSnap, error)
+	// GetSnapAtRef восстанавливает конфигурацию на ревизии ссылки
+	GetSnapAtRef(db.Source, compsem.SemRef) (ExecSnap, error)
+	// GetVarsByRef возвращает переменные, записанные на ревизии ссылки
+	GetVarsByRef(db.Source, compsem.SemRef) ([]compvar.LinearRec, error)
+	AddStep(db.Source, StepRec) error
+	// GetSteps возвращает журнал вычисления в порядке ревизий
+	GetSteps(db.Source, compsem.SemRef) ([]StepRec, error)
+}
+
+// хранилищу в памяти запросы не нужны, поэтому DAO выбирается по диалекту
+func newDialectDAO(dialect db.Dialect, qb queryBuilder, log *slog.Logger) Repo {
+	if dialect == db.Memory {
+		return newMemDAO(log)
+	}
+	return newPgxDAO(qb, log)
 }
 
 type execRecDS struct {
@@ -19,7 +39,32 @@ type execRecDS struct {
 	LiabMode int16  `db:"liab_mode"`
 }
 
+type stepRecDS struct {
+	CompID string            `db:"comp_id"`
+	CompRN int64             `db:"comp_rn"`
+	Exp    termexp.ExpSpecDS `db:"exp" fieldopt:"noexpand"`
+	Exchs  []exchSnapDS      `db:"exchs"`
+	// до миграции 0009 окружение не журналировалось
+	Env *envKeysDS `db:"env"`
+}
+
+// ревизии термов и значения типов окружения шага
+type envKeysDS struct {
+	TermDecs map[string]termsem.SemRefDS `json:"term_decs"`
+	TermDefs map[string]termsem.SemRefDS `json:"term_defs"`
+	TypeDefs map[string]int64            `json:"type_defs"`
+	TypeExps []int64                     `json:"type_exps"`
+}
+
+// обмен в том виде, в каком его увидел шаг
+type exchSnapDS struct {
+	CommID string               `json:"comm_id"`
+	CommRN int64                `json:"comm_rn"`
+	Turns  []commturn.TurnRecDS `json:"turns"`
+}
+
 type execModDS struct {
 	CompRefs   []compsem.SemRefDS
 	LinearVars []compvar.VarRecDS
+	NewExecs   []execRecDS
 }
@@ -0,0 +1,228 @@
+package compexec
+
+import (
+	"database/sql"
+	"log/slog"
+	"reflect"
+
+	"orglang/go-engine/lib/db"
+	"orglang/go-engine/lib/lf"
+
+	"orglang/go-engine/adt/compsem"
+	"orglang/go-engine/adt/compvar"
+	"orglang/go-engine/adt/seqnum"
+)
+
+// Adapter
+type memDAO struct {
+	log *slog.Logger
+}
+
+// у таблицы переменных нет первичного ключа, а в памяти
+// строки различаются ревизией вычисления и заглушкой канала
+type varKeyDS struct {
+	CompID string
+	CompRN int64
+	ChnlPH string
+}
+
+func newMemDAO(log *slog.Logger) *memDAO {
+	name := slog.String("name", reflect.TypeFor[memDAO]().Name())
+	return &memDAO{log.With(name)}
+}
+
+func (dao *memDAO) AddRec(source db.Source, rec ExecRec) error {
+	ds := db.MustConform[db.SourceMem](source)
+	dto := DataFromExecRec(rec)
+	insertErr := db.TableOf[string, execRecDS](ds, compExecs).Insert(dto.CompID, dto)
+	if insertErr != nil {
+		dao.log.Error("insertion failed", slog.Any("ref", rec.CompRef))
+		return insertErr
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "insertion succeed", slog.Any("dto", dto))
+	return nil
+}
+
+func (dao *memDAO) GetSnapByRef(source db.Source, ref compsem.SemRef) (ExecSnap, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	refAttr := slog.Any("ref", ref)
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "getting started", refAttr)
+	compID := ref.CompID.String()
+	execDTO, err := db.TableOf[string, execRecDS](ds, compExecs).Find(compID)
+	if err != nil {
+		dao.log.Error("row selection failed", refAttr)
+		return ExecSnap{}, err
+	}
+	execRec, err := DataToExecRec(execDTO)
+	if err != nil {
+		dao.log.Error("model conversion failed", refAttr)
+		return ExecSnap{}, err
+	}
+	linearVars, err := compvar.DataToLinearRecs(lastVars(ds, compID, execDTO.CompRN))
+	if err != nil {
+		dao.log.Error("model conversion failed", refAttr)
+		return ExecSnap{}, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "getting succeed", refAttr)
+	return ExecSnap{
+		CompRef:    execRec.CompRef,
+		LinearVars: compvar.IndexBy(ChnlPH, linearVars),
+	}, nil
+}
+
+func (dao *memDAO) ModifyRec(source db.Source, mod ExecMod) error {
+	if len(mod.CompRefs) == 0 {
+		panic("empty locks")
+	}
+	ds := db.MustConform[db.SourceMem](source)
+	dto, err := DataFromMod(mod)
+	if err != nil {
+		dao.log.Error("model conversion failed")
+		return err
+	}
+	execs := db.TableOf[string, execRecDS](ds, compExecs)
+	// актуальные ревизии затрагиваемых вычислений
+	revisions := make(map[string]int64, len(dto.CompRefs)+len(dto.NewExecs))
+	for _, execDTO := range dto.NewExecs {
+		insertErr := execs.Insert(execDTO.CompID, execDTO)
+		if insertErr != nil {
+			dao.log.Error("insertion failed", slog.Any("dto", execDTO))
+			return insertErr
+		}
+		revisions[execDTO.CompID] = execDTO.CompRN
+	}
+	for _, refDTO := range dto.CompRefs {
+		execDTO, ok := execs.Get(refDTO.CompID)
+		if !ok || execDTO.CompRN != refDTO.CompRN {
+			dao.log.Error("update failed", slog.Any("dto", refDTO))
+			return errOptimisticUpdate(seqnum.ADT(refDTO.CompRN))
+		}
+		execDTO.CompRN++
+		execs.Put(execDTO.CompID, execDTO)
+		revisions[refDTO.CompID] = execDTO.CompRN
+	}
+	vars := db.TableOf[varKeyDS, compvar.VarRecDS](ds, procLinearVars)
+	for _, varDTO := range dto.LinearVars {
+		compID := varDTO.CompID.String
+		compRN, ok := revisions[compID]
+		if !ok {
+			// контрагент продвигается без проверки
+			execDTO, found := execs.Get(compID)
+			if !found {
+				dao.log.Error("update failed", slog.String("id", compID))
+				return db.ErrNotFound
+			}
+			execDTO.CompRN++
+			execs.Put(compID, execDTO)
+			compRN = execDTO.CompRN
+			revisions[compID] = compRN
+		}
+		varDTO.CompRN.Int64 = compRN
+		varDTO.CompRN.Valid = true
+		vars.Put(varKeyDS{compID, compRN, varDTO.ChnlPH.String}, varDTO)
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "modification succeed", slog.Any("revisions", revisions))
+	return nil
+}
+
+func (dao *memDAO) GetSnapAtRef(source db.Source, ref compsem.SemRef) (ExecSnap, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	refAttr := slog.Any("ref", ref)
+	dto := compsem.DataFromRef(ref)
+	linearVars, err := compvar.DataToLinearRecs(lastVars(ds, dto.CompID, dto.CompRN))
+	if err != nil {
+		dao.log.Error("model conversion failed", refAttr)
+		return ExecSnap{}, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "getting succeed", refAttr)
+	return ExecSnap{
+		CompRef:    ref,
+		LinearVars: compvar.IndexBy(ChnlPH, linearVars),
+	}, nil
+}
+
+func (dao *memDAO) GetVarsByRef(source db.Source, ref compsem.SemRef) ([]compvar.LinearRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	dto := compsem.DataFromRef(ref)
+	var dtos []compvar.VarRecDS
+	for _, varDTO := range db.TableOf[varKeyDS, compvar.VarRecDS](ds, procLinearVars).Rows() {
+		if varDTO.CompID.String == dto.CompID && varDTO.CompRN.Int64 == dto.CompRN {
+			dtos = append(dtos, varDTO)
+		}
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "getting succeed", slog.Any("dtos", dtos))
+	return compvar.DataToLinearRecs(dtos)
+}
+
+// putSnap заводит конфигурацию вычисления на ее ревизии для повтора шага
+func (dao *memDAO) putSnap(source db.Source, snap ExecSnap) error {
+	ds := db.MustConform[db.SourceMem](source)
+	ref := compsem.DataFromRef(snap.CompRef)
+	execDTO := execRecDS{CompID: ref.CompID, CompRN: ref.CompRN, LiabMode: int16(compvar.LinearMode)}
+	insertErr := db.TableOf[string, execRecDS](ds, compExecs).Insert(ref.CompID, execDTO)
+	if insertErr != nil {
+		dao.log.Error("insertion failed", slog.Any("ref", snap.CompRef))
+		return insertErr
+	}
+	vars := db.TableOf[varKeyDS, compvar.VarRecDS](ds, procLinearVars)
+	for _, varDTO := range compvar.DataFromLinearMap(snap.LinearVars) {
+		varDTO.CompRN = sql.NullInt64{Int64: ref.CompRN, Valid: true}
+		vars.Put(varKeyDS{ref.CompID, ref.CompRN, varDTO.ChnlPH.String}, varDTO)
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "insertion succeed", slog.Any("dto", execDTO))
+	return nil
+}
+
+func (dao *memDAO) AddStep(source db.Source, rec StepRec) error {
+	ds := db.MustConform[db.SourceMem](source)
+	refAttr := slog.Any("ref", rec.CompRef)
+	dto, err := DataFromStepRec(rec)
+	if err != nil {
+		dao.log.Error("model conversion failed", refAttr)
+		return err
+	}
+	key := compsem.SemRefDS{CompID: dto.CompID, CompRN: dto.CompRN}
+	err = db.TableOf[compsem.SemRefDS, stepRecDS](ds, compJournal).Insert(key, dto)
+	if err != nil {
+		dao.log.Error("insertion failed", refAttr)
+		return err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "insertion succeed", slog.Any("dto", dto))
+	return nil
+}
+
+func (dao *memDAO) GetSteps(source db.Source, ref compsem.SemRef) ([]StepRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	compID := ref.CompID.String()
+	var dtos []stepRecDS
+	for _, dto := range db.TableOf[compsem.SemRefDS, stepRecDS](ds, compJournal).Rows() {
+		if dto.CompID == compID {
+			dtos = append(dtos, dto)
+		}
+	}
+	// шаги вставляются в порядке ревизий вычисления
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "getting succeed", slog.Any("ref", ref), slog.Int("steps", len(dtos)))
+	return DataToStepRecs(dtos)
+}
+
+// последние на ревизии состояния переменных, исключая исчерпанные
+func lastVars(ds db.SourceMem, compID string, compRN int64) []compvar.VarRecDS {
+	lastDTOs := make(map[string]compvar.VarRecDS)
+	for _, varDTO := range db.TableOf[varKeyDS, compvar.VarRecDS](ds, procLinearVars).Rows() {
+		if varDTO.CompID.String != compID || varDTO.CompRN.Int64 > compRN {
+			continue
+		}
+		last, ok := lastDTOs[varDTO.ChnlPH.String]
+		if ok && last.CompRN.Int64 > varDTO.CompRN.Int64 {
+			continue
+		}
+		lastDTOs[varDTO.ChnlPH.String] = varDTO
+	}
+	varDTOs := make([]compvar.VarRecDS, 0, len(lastDTOs))
+	for _, varDTO := range lastDTOs {
+		if varDTO.ExpVK.Int64 > 0 {
+			varDTOs = append(varDTOs, varDTO)
+		}
+	}
+	return varDTOs
+}
@@ -48,128 +48,189 @@ func (dao *pgxDAO) AddRec(source db.Source, rec ExecRec) error {
 func (dao *pgxDAO) GetSnapByRef(source db.Source, ref compsem.SemRef) (ExecSnap, error) {
 	ds := db.MustConform[db.SourcePgx](source)
 	refAttr := slog.Any("ref", ref)
-	chnlRows, err := ds.Conn.Query(ds.Ctx, selectChnls, ref.CompID.String())
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "getting started", refAttr)
+	execSQL, execArgs := dao.qb.selectRecByID(ref.CompID.String())
+	execRows, err := ds.Conn.Query(ds.Ctx, execSQL, execArgs...)
 	if err != nil {
-		dao.log.Error("query execution failed", refAttr)
+		dao.log.Error("query execution failed", refAttr, slog.String("sql", execSQL))
 		return ExecSnap{}, err
 	}
-	defer chnlRows.Close()
-	chnlDtos, err := pgx.CollectRows(chnlRows, pgx.RowToStructByName[compvar.VarRecDS])
+	defer execRows.Close()
+	execDTO, err := pgx.CollectExactlyOneRow(execRows, pgx.RowToStructByName[execRecDS])
 	if err != nil {
-		dao.log.Error("row scanning failed", refAttr, slog.Any("t", reflect.TypeOf(chnlDtos)))
+		dao.log.Error("row scanning failed", refAttr)
 		return ExecSnap{}, err
 	}
-	chnls, err := compvar.DataToLinearRecs(chnlDtos)
+	execRec, err := DataToExecRec(execDTO)
 	if err != nil {
 		dao.log.Error("model conversion failed", refAttr)
 		return ExecSnap{}, err
 	}
-	dao.log.Debug("snap selection succeed", refAttr)
+	varSQL, varArgs := dao.qb.selectVarsByID(ref.CompID.String())
+	varRows, err := ds.Conn.Query(ds.Ctx, varSQL, varArgs...)
+	if err != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("sql", varSQL))
+		return ExecSnap{}, err
+	}
+	defer varRows.Close()
+	varDTOs, err := pgx.CollectRows(varRows, pgx.RowToStructByName[compvar.VarRecDS])
+	if err != nil {
+		dao.log.Error("rows scanning failed", refAttr)
+		return ExecSnap{}, err
+	}
+	linearVars, err := compvar.DataToLinearRecs(varDTOs)
+	if err != nil {
+		dao.log.Error("model conversion failed", refAttr)
+		return ExecSnap{}, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "getting succeed", refAttr)
 	return ExecSnap{
-		LinearVars: compvar.IndexBy(ChnlPH, chnls),
+		CompRef:    execRec.CompRef,
+		LinearVars: compvar.IndexBy(ChnlPH, linearVars),
 	}, nil
 }
 
-func (dao *pgxDAO) ModifyRec(source db.Source, mod ExecMod) (err error) {
+func (dao *pgxDAO) ModifyRec(source db.Source, mod ExecMod) error {
 	if len(mod.CompRefs) == 0 {
 		panic("empty locks")
 	}
 	ds := db.MustConform[db.SourcePgx](source)
 	dto, err := DataFromMod(mod)
 	if err != nil {
-		dao.log.Error("conversion failed")
+		dao.log.Error("model conversion failed")
 		return err
 	}
-	// binds
-	bindReq := pgx.Batch{}
-	for _, dto := range dto.LinearVars {
-		args := pgx.NamedArgs{
-			"impl_id":  dto.CompID,
-			"chnl_ph":  dto.ChnlPH,
-			"chnl_id":  dto.ChnlID,
-			"state_id": dto.ExpVK,
+	// актуальные ревизии затрагиваемых вычислений
+	revisions := make(map[string]int64, len(dto.CompRefs)+len(dto.NewExecs))
+	for _, execDTO := range dto.NewExecs {
+		sql, args := dao.qb.insertRec(execDTO)
+		_, execErr := ds.Conn.Exec(ds.Ctx, sql, args...)
+		if execErr != nil {
+			dao.log.Error("query execution failed", slog.Any("dto", execDTO), slog.String("sql", sql))
+			return execErr
 		}
-		bindReq.Queue(insertBind, args)
-	}
-	if bindReq.Len() > 0 {
-		bindRes := ds.Conn.SendBatch(ds.Ctx, &bindReq)
-		defer func() {
-			err = errors.Join(err, bindRes.Close())
-		}()
-		for _, dto := range dto.LinearVars {
-			_, err = bindRes.Exec()
-			if err != nil {
-				dao.log.Error("execution failed", slog.Any("dto", dto))
-			}
+		revisions[execDTO.CompID] = execDTO.CompRN
+	}
+	for _, refDTO := range dto.CompRefs {
+		sql, args := dao.qb.updateRec(refDTO)
+		var compRN int64
+		scanErr := ds.Conn.QueryRow(ds.Ctx, sql, args...).Scan(&compRN)
+		if errors.Is(scanErr, pgx.ErrNoRows) {
+			dao.log.Error("update failed", slog.Any("dto", refDTO))
+			return errOptimisticUpdate(seqnum.ADT(refDTO.CompRN))
 		}
-		if err != nil {
-			return err
+		if scanErr != nil {
+			dao.log.Error("query execution failed", slog.Any("dto", refDTO), slog.String("sql", sql))
+			return scanErr
 		}
+		revisions[refDTO.CompID] = compRN
 	}
-	// execs
-	execReq := pgx.Batch{}
-	for _, dto := range dto.CompRefs {
-		args := pgx.NamedArgs{
-			"impl_id": dto.CompID,
-			"impl_rn": dto.CompRN,
-		}
-		execReq.Queue(updateExec, args)
-	}
-	execRes := ds.Conn.SendBatch(ds.Ctx, &execReq)
-	defer func() {
-		err = errors.Join(err, execRes.Close())
-	}()
-	for _, dto := range dto.CompRefs {
-		ct, err := execRes.Exec()
-		if err != nil {
-			dao.log.Error("execution failed", slog.Any("dto", dto))
+	for _, varDTO := range dto.LinearVars {
+		compID := varDTO.CompID.String
+		compRN, ok := revisions[compID]
+		if !ok {
+			// контрагент продвигается без проверки
+			sql, args := dao.qb.touchRec(compID)
+			scanErr := ds.Conn.QueryRow(ds.Ctx, sql, args...).Scan(&compRN)
+			if scanErr != nil {
+				dao.log.Error("query execution failed", slog.String("id", compID), slog.String("sql", sql))
+				return scanErr
+			}
+			revisions[compID] = compRN
 		}
-		if ct.RowsAffected() == 0 {
-			dao.log.Error("update failed")
-			return errOptimisticUpdate(seqnum.ADT(dto.CompRN))
+		varDTO.CompRN.Int64 = compRN
+		varDTO.CompRN.Valid = true
+		sql, args := dao.qb.insertVar(varDTO)
+		_, execErr := ds.Conn.Exec(ds.Ctx, sql, args...)
+		if execErr != nil {
+			dao.log.Error("query execution failed", slog.Any("dto", varDTO), slog.String("sql", sql))
+			return execErr
 		}
 	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "modification succeed", slog.Any("revisions", revisions))
+	return nil
+}
+
+func (dao *pgxDAO) GetSnapAtRef(source db.Source, ref compsem.SemRef) (ExecSnap, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	refAttr := slog.Any("ref", ref)
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "getting started", refAttr)
+	sql, args := dao.qb.selectVarsAtRef(compsem.DataFromRef(ref))
+	rows, err := ds.Conn.Query(ds.Ctx, sql, args...)
 	if err != nil {
-		return err
+		dao.log.Error("query execution failed", refAttr, slog.String("sql", sql))
+		return ExecSnap{}, err
 	}
-	dao.log.Debug("update succeed")
-	return nil
+	defer rows.Close()
+	dtos, err := pgx.CollectRows(rows, pgx.RowToStructByName[compvar.VarRecDS])
+	if err != nil {
+		dao.log.Error("rows scanning failed", refAttr)
+		return ExecSnap{}, err
+	}
+	linearVars, err := compvar.DataToLinearRecs(dtos)
+	if err != nil {
+		dao.log.Error("model conversion failed", refAttr)
+		return ExecSnap{}, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "getting succeed", refAttr)
+	return ExecSnap{
+		CompRef:    ref,
+		LinearVars: compvar.IndexBy(ChnlPH, linearVars),
+	}, nil
 }
 
-const (
-	insertBind = `
-		insert into proc_binds (
-			impl_id, chnl_ph, chnl_id, state_id, impl_rn
-		) values (
-			@impl_id, @chnl_ph, @chnl_id, @state_id, @impl_rn
-		)`
+func (dao *pgxDAO) GetVarsByRef(source db.Source, ref compsem.SemRef) ([]compvar.LinearRec, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	refAttr := slog.Any("ref", ref)
+	sql, args := dao.qb.selectVarsByRef(compsem.DataFromRef(ref))
+	rows, err := ds.Conn.Query(ds.Ctx, sql, args...)
+	if err != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("sql", sql))
+		return nil, err
+	}
+	defer rows.Close()
+	dtos, err := pgx.CollectRows(rows, pgx.RowToStructByName[compvar.VarRecDS])
+	if err != nil {
+		dao.log.Error("rows scanning failed", refAttr)
+		return nil, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "getting succeed", slog.Any("dtos", dtos))
+	return compvar.DataToLinearRecs(dtos)
+}
 
-	updateExec = `
-		update proc_comp_execs
-		set impl_rn = @impl_rn + 1
-		where impl_id = @impl_id
-			and impl_rn = @impl_rn`
+func (dao *pgxDAO) AddStep(source db.Source, rec StepRec) error {
+	ds := db.MustConform[db.SourcePgx](source)
+	refAttr := slog.Any("ref", rec.CompRef)
+	dto, err := DataFromStepRec(rec)
+	if err != nil {
+		dao.log.Error("model conversion failed", refAttr)
+		return err
+	}
+	sql, args := dao.qb.insertStep(dto)
+	_, err = ds.Conn.Exec(ds.Ctx, sql, args...)
+	if err != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("sql", sql))
+		return err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "insertion succeed", slog.Any("dto", dto))
+	return nil
+}
 
-	selectChnls = `
-		with bnds as not materialized (
-			select distinct on (chnl_ph)
-				*
-			from proc_bnds
-			where proc_id = 'proc1'
-			order by chnl_ph, abs(rev) desc
-		), liabs as not materialized (
-			select distinct on (proc_id)
-				*
-			from pool_liabs
-			where proc_id = 'proc1'
-			order by proc_id, abs(rev) desc
-		)
-		select
-			bnd.*,
-			prvd.desc_id
-		from bnds bnd
-		left join liabs liab
-			on liab.proc_id = bnd.proc_id
-		left join pool_comp_execs prvd
-			on prvd.desc_id = liab.desc_id`
-)
+func (dao *pgxDAO) GetSteps(source db.Source, ref compsem.SemRef) ([]StepRec, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	refAttr := slog.Any("ref", ref)
+	sql, args := dao.qb.selectStepsByID(ref.CompID.String())
+	rows, err := ds.Conn.Query(ds.Ctx, sql, args...)
+	if err != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("sql", sql))
+		return nil, err
+	}
+	defer rows.Close()
+	dtos, err := pgx.CollectRows(rows, pgx.RowToStructByName[stepRecDS])
+	if err != nil {
+		dao.log.Error("rows scanning failed", refAttr)
+		return nil, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "getting succeed", refAttr, slog.Int("steps", len(dtos)))
+	return DataToStepRecs(dtos)
+}
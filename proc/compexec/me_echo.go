@@ -13,6 +13,7 @@ import (
 	"orglang/go-engine/lib/lf"
 
 	"orglang/go-engine/adt/compsem"
+	"orglang/go-engine/proc/compfuel"
 	"orglang/go-engine/proc/compstep"
 )
 
@@ -29,6 +30,8 @@ func newEchoController(a API, l *slog.Logger) *echoController {
 func cfgEchoController(e *echo.Echo, h *echoController) error {
 	e.GET("/api/v1/procs/:id", h.GetSnap)
 	e.POST("/api/v1/procs/:id/steps", h.PostStep)
+	e.GET("/api/v1/procs/:id/replay", h.GetReplay)
+	e.POST("/api/v1/procs/:id/fuel", h.PostFuel)
 	return nil
 }
 
@@ -48,7 +51,31 @@ func (h *echoController) GetSnap(c echo.Context) error {
 	if retrieveErr != nil {
 		return retrieveErr
 	}
-	return c.JSON(http.StatusOK, MsgFromExecSnap(snap))
+	view, convErr := ViewFromExecSnap(snap)
+	if convErr != nil {
+		h.log.Error("conversion failed", slog.Any("ref", ref))
+		return convErr
+	}
+	return c.JSON(http.StatusOK, view)
+}
+
+func (h *echoController) GetReplay(c echo.Context) error {
+	var dto sdk1.SemRef
+	bindErr := c.Bind(&dto)
+	if bindErr != nil {
+		h.log.Error("binding failed", slog.Any("dto", dto))
+		return bindErr
+	}
+	ref, convErr := compsem.MsgToRef(dto)
+	if convErr != nil {
+		h.log.Error("conversion failed", slog.Any("dto", dto))
+		return convErr
+	}
+	rep, replayErr := h.api.Replay(ref)
+	if replayErr != nil {
+		return replayErr
+	}
+	return c.JSON(http.StatusOK, ViewFromReplayRep(rep))
 }
 
 func (h *echoController) PostStep(c echo.Context) error {
@@ -76,3 +103,27 @@ func (h *echoController) PostStep(c echo.Context) error {
 	}
 	return c.NoContent(http.StatusOK)
 }
+
+func (h *echoController) PostFuel(c echo.Context) error {
+	var dto compfuel.FuelSpecVP
+	bindErr := c.Bind(&dto)
+	if bindErr != nil {
+		h.log.Error("binding failed", slog.Any("dto", reflect.TypeOf(dto)))
+		return bindErr
+	}
+	validateErr := dto.Validate()
+	if validateErr != nil {
+		h.log.Error("validation failed", slog.Any("dto", dto))
+		return validateErr
+	}
+	spec, convErr := compfuel.MsgToFuelSpec(compfuel.ProcRealm, dto)
+	if convErr != nil {
+		h.log.Error("conversion failed", slog.Any("dto", dto))
+		return convErr
+	}
+	rec, refuelErr := h.api.Refuel(spec)
+	if refuelErr != nil {
+		return refuelErr
+	}
+	return c.JSON(http.StatusOK, compfuel.ViewFromFuelRec(rec))
+}
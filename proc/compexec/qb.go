@@ -1,12 +1,27 @@
 package compexec
 
+import (
+	"orglang/go-engine/adt/compsem"
+	"orglang/go-engine/adt/compvar"
+)
+
 const (
 	implBinds      string = "proc_impl_binds "
 	compExecs      string = "proc_comp_execs "
 	procStructVars string = "proc_struct_vars "
 	procLinearVars string = "proc_linear_vars "
+	compJournal    string = "proc_comp_journal "
 )
 
 type queryBuilder interface {
 	insertRec(execRecDS) (string, []any)
+	insertVar(compvar.VarRecDS) (string, []any)
+	updateRec(compsem.SemRefDS) (string, []any)
+	touchRec(string) (string, []any)
+	selectRecByID(string) (string, []any)
+	selectVarsByID(string) (string, []any)
+	selectVarsAtRef(compsem.SemRefDS) (string, []any)
+	selectVarsByRef(compsem.SemRefDS) (string, []any)
+	insertStep(stepRecDS) (string, []any)
+	selectStepsByID(string) (string, []any)
 }
@@ -3,6 +3,7 @@ package compexec
 import (
 	"github.com/huandu/go-sqlbuilder"
 
+	"orglang/go-engine/adt/compsem"
 	"orglang/go-engine/adt/compvar"
 	"orglang/go-engine/adt/termsem"
 )
@@ -11,6 +12,7 @@ type sqlBuilder struct {
 	semBuilder  *sqlbuilder.Struct
 	execBuilder *sqlbuilder.Struct
 	varBuilder  *sqlbuilder.Struct
+	stepBuilder *sqlbuilder.Struct
 }
 
 // for compilation purposes
@@ -22,9 +24,79 @@ func newSQLBuilder() *sqlBuilder {
 	semBuilder := sqlbuilder.NewStruct(new(termsem.SemRefDS)).For(sqlbuilder.PostgreSQL)
 	execBuilder := sqlbuilder.NewStruct(new(execRecDS)).For(sqlbuilder.PostgreSQL)
 	varBuilder := sqlbuilder.NewStruct(new(compvar.VarRecDS)).For(sqlbuilder.PostgreSQL)
-	return &sqlBuilder{semBuilder, execBuilder, varBuilder}
+	stepBuilder := sqlbuilder.NewStruct(new(stepRecDS)).For(sqlbuilder.PostgreSQL)
+	return &sqlBuilder{semBuilder, execBuilder, varBuilder, stepBuilder}
 }
 
 func (qb *sqlBuilder) insertRec(rec execRecDS) (string, []any) {
 	return qb.execBuilder.InsertInto(compExecs, rec).Build()
 }
+
+func (qb *sqlBuilder) insertVar(rec compvar.VarRecDS) (string, []any) {
+	return qb.varBuilder.InsertInto(procLinearVars, rec).Build()
+}
+
+// продвигает ревизию с проверкой на конкурентное изменение
+func (qb *sqlBuilder) updateRec(ref compsem.SemRefDS) (string, []any) {
+	ub := sqlbuilder.PostgreSQL.NewUpdateBuilder()
+	return ub.Update(compExecs).
+		Set(ub.Incr("comp_rn")).
+		Where(ub.Equal("comp_id", ref.CompID), ub.Equal("comp_rn", ref.CompRN)).
+		Returning("comp_rn").
+		Build()
+}
+
+// продвигает ревизию без проверки (для вычислений-контрагентов)
+func (qb *sqlBuilder) touchRec(id string) (string, []any) {
+	ub := sqlbuilder.PostgreSQL.NewUpdateBuilder()
+	return ub.Update(compExecs).
+		Set(ub.Incr("comp_rn")).
+		Where(ub.Equal("comp_id", id)).
+		Returning("comp_rn").
+		Build()
+}
+
+func (qb *sqlBuilder) selectRecByID(id string) (string, []any) {
+	sb := qb.execBuilder.SelectFrom(compExecs + "exec")
+	return sb.Where(sb.Equal("exec.comp_id", id)).Build()
+}
+
+// последние состояния переменных, исключая исчерпанные
+func (qb *sqlBuilder) selectVarsByID(id string) (string, []any) {
+	last := sqlbuilder.PostgreSQL.NewSelectBuilder()
+	// оконная функция вместо DISTINCT ON, чтобы запрос был переносимым
+	last.Select("*", "ROW_NUMBER() OVER (PARTITION BY chnl_ph ORDER BY comp_rn DESC) AS nr").
+		From(procLinearVars).
+		Where(last.Equal("comp_id", id))
+	vars := qb.varBuilder.SelectFrom("vars")
+	return vars.With(sqlbuilder.PostgreSQL.NewCTEBuilder().With(sqlbuilder.CTEQuery("vars").As(last))).
+		Where(vars.Equal("nr", 1), vars.GreaterThan("exp_vk", 0)).
+		Build()
+}
+
+// состояния переменных на ревизии, исключая исчерпанные
+func (qb *sqlBuilder) selectVarsAtRef(ref compsem.SemRefDS) (string, []any) {
+	last := sqlbuilder.PostgreSQL.NewSelectBuilder()
+	last.Select("*", "ROW_NUMBER() OVER (PARTITION BY chnl_ph ORDER BY comp_rn DESC) AS nr").
+		From(procLinearVars).
+		Where(last.Equal("comp_id", ref.CompID), last.LessEqualThan("comp_rn", ref.CompRN))
+	vars := qb.varBuilder.SelectFrom("vars")
+	return vars.With(sqlbuilder.PostgreSQL.NewCTEBuilder().With(sqlbuilder.CTEQuery("vars").As(last))).
+		Where(vars.Equal("nr", 1), vars.GreaterThan("exp_vk", 0)).
+		Build()
+}
+
+// переменные, записанные шагом, который привел к ревизии
+func (qb *sqlBuilder) selectVarsByRef(ref compsem.SemRefDS) (string, []any) {
+	sb := qb.varBuilder.SelectFrom(procLinearVars)
+	return sb.Where(sb.Equal("comp_id", ref.CompID), sb.Equal("comp_rn", ref.CompRN)).Build()
+}
+
+func (qb *sqlBuilder) insertStep(rec stepRecDS) (string, []any) {
+	return qb.stepBuilder.InsertInto(compJournal, rec).Build()
+}
+
+func (qb *sqlBuilder) selectStepsByID(id string) (string, []any) {
+	sb := qb.stepBuilder.SelectFrom(compJournal)
+	return sb.Where(sb.Equal("comp_id", id)).OrderBy("comp_rn").Build()
+}
@@ -3,6 +3,9 @@ package compexec
 import (
 	"fmt"
 	"testing"
+
+	"orglang/go-engine/adt/compsem"
+	"orglang/go-engine/adt/compvar"
 )
 
 func TestInsertRec(t *testing.T) {
@@ -10,3 +13,57 @@ func TestInsertRec(t *testing.T) {
 	sql, _ := qb.insertRec(execRecDS{})
 	fmt.Println(sql)
 }
+
+func TestInsertVar(t *testing.T) {
+	qb := newSQLBuilder()
+	sql, _ := qb.insertVar(compvar.VarRecDS{})
+	fmt.Println(sql)
+}
+
+func TestUpdateRec(t *testing.T) {
+	qb := newSQLBuilder()
+	sql, _ := qb.updateRec(compsem.SemRefDS{})
+	fmt.Println(sql)
+}
+
+func TestTouchRec(t *testing.T) {
+	qb := newSQLBuilder()
+	sql, _ := qb.touchRec("foo")
+	fmt.Println(sql)
+}
+
+func TestSelectRecByID(t *testing.T) {
+	qb := newSQLBuilder()
+	sql, _ := qb.selectRecByID("foo")
+	fmt.Println(sql)
+}
+
+func TestSelectVarsByID(t *testing.T) {
+	qb := newSQLBuilder()
+	sql, _ := qb.selectVarsByID("foo")
+	fmt.Println(sql)
+}
+
+func TestSelectVarsAtRef(t *testing.T) {
+	qb := newSQLBuilder()
+	sql, _ := qb.selectVarsAtRef(compsem.SemRefDS{})
+	fmt.Println(sql)
+}
+
+func TestSelectVarsByRef(t *testing.T) {
+	qb := newSQLBuilder()
+	sql, _ := qb.selectVarsByRef(compsem.SemRefDS{})
+	fmt.Println(sql)
+}
+
+func TestInsertStep(t *testing.T) {
+	qb := newSQLBuilder()
+	sql, _ := qb.insertStep(stepRecDS{})
+	fmt.Println(sql)
+}
+
+func TestSelectStepsByID(t *testing.T) {
+	qb := newSQLBuilder()
+	sql, _ := qb.selectStepsByID("foo")
+	fmt.Println(sql)
+}
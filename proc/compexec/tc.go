@@ -0,0 +1,287 @@
+package compexec
+
+import (
+	"maps"
+	"slices"
+
+	"orglang/go-engine/adt/commsem"
+	"orglang/go-engine/adt/compsem"
+	"orglang/go-engine/adt/identity"
+	"orglang/go-engine/adt/option"
+	"orglang/go-engine/adt/seqnum"
+	"orglang/go-engine/adt/symbol"
+	"orglang/go-engine/adt/termsem"
+	"orglang/go-engine/adt/uniqsym"
+	"orglang/go-engine/adt/valkey"
+	"orglang/go-engine/proc/commexch"
+	"orglang/go-engine/proc/commturn"
+	"orglang/go-engine/proc/termexp"
+	"orglang/go-engine/proc/typeexp"
+)
+
+func ViewFromExecSnap(snap ExecSnap) (ExecSnapVP, error) {
+	chnlPHs := slices.Sorted(maps.Keys(snap.LinearVars))
+	varSnaps := make([]VarSnapVP, 0, len(chnlPHs))
+	for _, chnlPH := range chnlPHs {
+		linearVar := snap.LinearVars[chnlPH]
+		turns := make([]TurnVP, 0, len(snap.LinearTurns[chnlPH]))
+		for _, turn := range snap.LinearTurns[chnlPH] {
+			view, err := ViewFromTurnRec(turn)
+			if err != nil {
+				return ExecSnapVP{}, err
+			}
+			turns = append(turns, view)
+		}
+		varSnap := VarSnapVP{
+			ChnlPH: symbol.ConvertToString(chnlPH),
+			ChnlID: identity.ConvertToString(linearVar.ChnlID),
+			CommID: identity.ConvertToString(linearVar.CommRef.CommID),
+			ExpVK:  valkey.ConvertToInt(linearVar.ExpVK),
+			Turns:  turns,
+		}
+		// исчерпанный канал не имеет типа
+		expRec, ok := snap.LinearExps[chnlPH]
+		if ok && expRec != nil {
+			typeExp := typeexp.MsgFromExpSpec(typeexp.ConvertRecToSpec(expRec))
+			varSnap.TypeExp = &typeExp
+		}
+		varSnaps = append(varSnaps, varSnap)
+	}
+	return ExecSnapVP{
+		CompRef:  compsem.MsgFromRef(snap.CompRef),
+		VarSnaps: varSnaps,
+	}, nil
+}
+
+func ViewFromTurnRec(turn commturn.TurnRec) (TurnVP, error) {
+	var view TurnVP
+	var exp termexp.ExpRec
+	switch rec := turn.(type) {
+	case commturn.PubRec:
+		view = TurnVP{
+			CommRN:  seqnum.ConvertToInt(rec.CommRef.CommRN),
+			CompRef: compsem.MsgFromRef(rec.CompRef),
+			ChnlID:  identity.ConvertToString(rec.ChnlID),
+			Side:    "pub",
+		}
+		exp = rec.ValExp
+	case commturn.SubRec:
+		view = TurnVP{
+			CommRN:  seqnum.ConvertToInt(rec.CommRef.CommRN),
+			CompRef: compsem.MsgFromRef(rec.CompRef),
+			ChnlID:  identity.ConvertToString(rec.ChnlID),
+			Side:    "sub",
+		}
+		exp = rec.ContExp
+	default:
+		return TurnVP{}, commturn.ErrRecTypeUnexpected(turn)
+	}
+	step, err := stepName(exp)
+	if err != nil {
+		return TurnVP{}, err
+	}
+	view.Step = step
+	view.ChnlPH = symbol.ConvertToString(exp.Via())
+	return view, nil
+}
+
+// имя шага в том виде, в каком его пишет программист
+func stepName(exp termexp.ExpRec) (string, error) {
+	switch exp.(type) {
+	case termexp.CloseRec:
+		return "close", nil
+	case termexp.WaitRec:
+		return "wait", nil
+	case termexp.SendRec:
+		return "send", nil
+	case termexp.RecvRec:
+		return "recv", nil
+	case termexp.LabRec:
+		return "lab", nil
+	case termexp.CaseRec:
+		return "case", nil
+	case termexp.FwdRec:
+		return "fwd", nil
+	case termexp.AcquireRec:
+		return "acquire", nil
+	case termexp.AcceptRec:
+		return "accept", nil
+	case termexp.ReleaseRec:
+		return "release", nil
+	case termexp.DetachRec:
+		return "detach", nil
+	default:
+		return "", termexp.ErrRecTypeUnexpected(exp)
+	}
+}
+
+func ViewFromReplayRep(rep ReplayRep) ReplayRepVP {
+	view := ReplayRepVP{
+		CompRef:  compsem.MsgFromRef(rep.CompRef),
+		Replayed: rep.Replayed,
+	}
+	if rep.Divergence != nil {
+		view.Divergence = &StepDivVP{
+			CompRN: seqnum.ConvertToInt(rep.Divergence.StepRef.CompRN),
+			ChnlPH: symbol.ConvertToString(rep.Divergence.ChnlPH),
+			WantVK: valkey.ConvertToInt(rep.Divergence.WantVK),
+			GotVK:  valkey.ConvertToInt(rep.Divergence.GotVK),
+			Reason: rep.Divergence.Reason,
+		}
+	}
+	return view
+}
+
+func DataFromStepRec(rec StepRec) (stepRecDS, error) {
+	exp, err := termexp.DataFromExpSpec(rec.ProcExp)
+	if err != nil {
+		return stepRecDS{}, err
+	}
+	exchs := make([]exchSnapDS, 0, len(rec.ExchSnaps))
+	for _, snap := range rec.ExchSnaps {
+		turns := make([]commturn.TurnRecDS, 0, len(snap.Turns))
+		for _, turn := range snap.Turns {
+			dto, err := commturn.DataFromTurnRec(turn)
+			if err != nil {
+				return stepRecDS{}, err
+			}
+			turns = append(turns, dto)
+		}
+		commRef := commsem.DataFromRef(snap.CommRef)
+		exchs = append(exchs, exchSnapDS{CommID: commRef.CommID, CommRN: commRef.CommRN, Turns: turns})
+	}
+	var env *envKeysDS
+	if !rec.EnvKeys.IsEmpty() {
+		envDTO := dataFromEnvKeys(rec.EnvKeys.Get())
+		env = &envDTO
+	}
+	compRef := compsem.DataFromRef(rec.CompRef)
+	return stepRecDS{
+		CompID: compRef.CompID,
+		CompRN: compRef.CompRN,
+		Exp:    exp,
+		Exchs:  exchs,
+		Env:    env,
+	}, nil
+}
+
+func DataToStepRec(dto stepRecDS) (StepRec, error) {
+	compRef, err := compsem.DataToRef(compsem.SemRefDS{CompID: dto.CompID, CompRN: dto.CompRN})
+	if err != nil {
+		return StepRec{}, err
+	}
+	exp, err := termexp.DataToExpSpec(dto.Exp)
+	if err != nil {
+		return StepRec{}, err
+	}
+	snaps := make([]commexch.ExchSnap, 0, len(dto.Exchs))
+	for _, exchDTO := range dto.Exchs {
+		commID, err := identity.ConvertFromString(exchDTO.CommID)
+		if err != nil {
+			return StepRec{}, err
+		}
+		turns := make([]commturn.TurnRec, 0, len(exchDTO.Turns))
+		for _, turnDTO := range exchDTO.Turns {
+			turn, err := commturn.DataToTurnRec(turnDTO)
+			if err != nil {
+				return StepRec{}, err
+			}
+			turns = append(turns, turn)
+		}
+		snaps = append(snaps, commexch.ExchSnap{
+			CommRef: commsem.SemRef{CommID: commID, CommRN: seqnum.ConvertFromInt(exchDTO.CommRN)},
+			Turns:   turns,
+		})
+	}
+	envKeys := option.None[EnvKeys]()
+	if dto.Env != nil {
+		keys, err := dataToEnvKeys(*dto.Env)
+		if err != nil {
+			return StepRec{}, err
+		}
+		envKeys = option.Some(keys)
+	}
+	return StepRec{CompRef: compRef, ProcExp: exp, ExchSnaps: snaps, EnvKeys: envKeys}, nil
+}
+
+func DataToStepRecs(dtos []stepRecDS) ([]StepRec, error) {
+	recs := make([]StepRec, 0, len(dtos))
+	for _, dto := range dtos {
+		rec, err := DataToStepRec(dto)
+		if err != nil {
+			return nil, err
+		}
+		recs = append(recs, rec)
+	}
+	return recs, nil
+}
+
+func dataFromEnvKeys(keys EnvKeys) envKeysDS {
+	dto := envKeysDS{
+		TermDecs: make(map[string]termsem.SemRefDS, len(keys.TermDecs)),
+		TermDefs: make(map[string]termsem.SemRefDS, len(keys.TermDefs)),
+		TypeDefs: make(map[string]int64, len(keys.TypeDefs)),
+		TypeExps: make([]int64, 0, len(keys.TypeExps)),
+	}
+	for termQN, termRef := range keys.TermDecs {
+		dto.TermDecs[uniqsym.ConvertToString(termQN)] = termsem.DataFromRef(termRef)
+	}
+	for termQN, termRef := range keys.TermDefs {
+		dto.TermDefs[uniqsym.ConvertToString(termQN)] = termsem.DataFromRef(termRef)
+	}
+	for typeQN, expVK := range keys.TypeDefs {
+		dto.TypeDefs[uniqsym.ConvertToString(typeQN)] = valkey.ConvertToInt(expVK)
+	}
+	for _, expVK := range keys.TypeExps {
+		dto.TypeExps = append(dto.TypeExps, valkey.ConvertToInt(expVK))
+	}
+	return dto
+}
+
+func dataToEnvKeys(dto envKeysDS) (EnvKeys, error) {
+	termDecs, err := dataToTermRefs(dto.TermDecs)
+	if err != nil {
+		return EnvKeys{}, err
+	}
+	termDefs, err := dataToTermRefs(dto.TermDefs)
+	if err != nil {
+		return EnvKeys{}, err
+	}
+	typeDefs := make(map[uniqsym.ADT]valkey.ADT, len(dto.TypeDefs))
+	for qn, vk := range dto.TypeDefs {
+		typeQN, err := uniqsym.ConvertFromString(qn)
+		if err != nil {
+			return EnvKeys{}, err
+		}
+		expVK, err := valkey.ConvertFromInt(vk)
+		if err != nil {
+			return EnvKeys{}, err
+		}
+		typeDefs[typeQN] = expVK
+	}
+	typeExps := make([]valkey.ADT, 0, len(dto.TypeExps))
+	for _, vk := range dto.TypeExps {
+		expVK, err := valkey.ConvertFromInt(vk)
+		if err != nil {
+			return EnvKeys{}, err
+		}
+		typeExps = append(typeExps, expVK)
+	}
+	return EnvKeys{TermDecs: termDecs, TermDefs: termDefs, TypeDefs: typeDefs, TypeExps: typeExps}, nil
+}
+
+func dataToTermRefs(dtos map[string]termsem.SemRefDS) (map[uniqsym.ADT]termsem.SemRef, error) {
+	refs := make(map[uniqsym.ADT]termsem.SemRef, len(dtos))
+	for qn, dto := range dtos {
+		termQN, err := uniqsym.ConvertFromString(qn)
+		if err != nil {
+			return nil, err
+		}
+		termRef, err := termsem.DataToRef(dto)
+		if err != nil {
+			return nil, err
+		}
+		refs[termQN] = termRef
+	}
+	return refs, nil
+}
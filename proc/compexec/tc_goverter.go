@@ -1,8 +1,6 @@
 package compexec
 
 import (
-	"orglang/go-engine/adt/compsem"
-
 	"github.com/orglang/go-sdk/proc/compexec"
 )
 
@@ -10,7 +8,7 @@ import (
 // goverter:output:format assign-variable
 // goverter:extend orglang/go-engine/adt/identity:Convert.*
 var (
-	// goverter:ignore LinearVars
+	// goverter:ignore LinearVars LinearExps LinearTurns
 	MsgToExecSnap   func(compexec.ExecSnap) (ExecSnap, error)
 	MsgFromExecSnap func(ExecSnap) compexec.ExecSnap
 )
@@ -18,14 +16,13 @@ var (
 // goverter:variables
 // goverter:output:format assign-variable
 // goverter:extend orglang/go-engine/adt/identity:Convert.*
+// goverter:extend orglang/go-engine/adt/seqnum:Convert.*
 // goverter:extend orglang/go-engine/proc/commturn:Data.*
 // goverter:extend orglang/go-engine/adt/compvar:Data.*
 var (
-	// goverter:map . CompRef | dataToSemRef
+	// goverter:map . CompRef
 	DataToExecRec func(execRecDS) (ExecRec, error)
 	// goverter:autoMap CompRef
 	DataFromExecRec func(ExecRec) execRecDS
 	DataFromMod     func(ExecMod) (execModDS, error)
-	// goverter:ignore CompRN
-	dataToSemRef func(execRecDS) (compsem.SemRef, error)
 )
@@ -0,0 +1,46 @@
+package compexec
+
+import (
+	"github.com/orglang/go-sdk/adt/compsem"
+	"github.com/orglang/go-sdk/proc/typeexp"
+)
+
+type ExecSnapVP struct {
+	CompRef  compsem.SemRef `json:"ref"`
+	VarSnaps []VarSnapVP    `json:"vars"`
+}
+
+type VarSnapVP struct {
+	ChnlPH  string           `json:"chnl_ph"`
+	ChnlID  string           `json:"chnl_id"`
+	CommID  string           `json:"comm_id"`
+	ExpVK   int64            `json:"exp_vk"`
+	TypeExp *typeexp.ExpSpec `json:"type,omitempty"`
+	Turns   []TurnVP         `json:"turns"`
+}
+
+// TurnVP ожидающий ход; от схемы proc_comm_turns не зависит
+type TurnVP struct {
+	CommRN  int64          `json:"comm_rn"`
+	CompRef compsem.SemRef `json:"comp_ref"`
+	ChnlID  string         `json:"chnl_id"`
+	// pub - значение ждет получателя, sub - продолжение ждет значения
+	Side string `json:"side"`
+	// шаг, который ждет контрагента
+	Step   string `json:"step"`
+	ChnlPH string `json:"chnl_ph"`
+}
+
+type ReplayRepVP struct {
+	CompRef    compsem.SemRef `json:"ref"`
+	Replayed   int            `json:"replayed"`
+	Divergence *StepDivVP     `json:"divergence,omitempty"`
+}
+
+type StepDivVP struct {
+	CompRN int64  `json:"comp_rn"`
+	ChnlPH string `json:"chnl_ph,omitempty"`
+	WantVK int64  `json:"want_vk"`
+	GotVK  int64  `json:"got_vk"`
+	Reason string `json:"reason"`
+}
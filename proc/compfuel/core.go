@@ -0,0 +1,213 @@
+package compfuel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"time"
+
+	"orglang/go-engine/lib/db"
+
+	"orglang/go-engine/adt/identity"
+	"orglang/go-engine/proc/compstep"
+)
+
+type API interface {
+	// RetrieveRec отдает остаток запаса вычисления или пула
+	RetrieveRec(Realm, identity.ADT) (FuelRec, error)
+}
+
+// Meter ведет запасы шагов в транзакции шага
+type Meter interface {
+	// Burn списывает шаг с запаса вычисления, а у процесса в пуле
+	// еще и с общего запаса пула; если какой-то из запасов исчерпан,
+	// возвращает ErrOutOfFuel
+	Burn(db.Source, Realm, identity.ADT) (FuelRec, error)
+	// Grant заводит полный запас процесса, порожденного в пуле
+	Grant(db.Source, identity.ADT, identity.ADT) error
+	// Halt откладывает шаг процесса до пополнения запаса; если запас
+	// пополнили после отказа, шаг не откладывается и возвращается false
+	Halt(db.Source, compstep.StepSpec) (bool, error)
+	// Refill пополняет запас и забирает шаги процессов, которые
+	// были им остановлены
+	Refill(db.Source, FuelSpec) (FuelRec, []compstep.StepSpec, error)
+}
+
+// вычисления proc и pool расходуют запасы раздельно
+type Realm string
+
+const (
+	ProcRealm Realm = "proc"
+	PoolRealm Realm = "pool"
+)
+
+type FuelSpec struct {
+	Realm  Realm
+	CompID identity.ADT
+	// на сколько шагов пополняется запас
+	FuelNr int64
+}
+
+type FuelRec struct {
+	CompID identity.ADT
+	// пул, общий запас которого тратит процесс;
+	// пустой у пулов и у процессов вне пулов
+	PoolID identity.ADT
+	// сколько шагов осталось
+	FuelNr int64
+}
+
+// шаг процесса, остановленный без запаса
+type HaltRec struct {
+	HaltID   identity.ADT
+	StepSpec compstep.StepSpec
+	HaltedAt time.Time
+}
+
+// ErrOutOfFuel означает, что вычисление или его пул исчерпали запас;
+// шаг при этом не берется, а откладывается до пополнения
+var ErrOutOfFuel = errors.New("out of fuel")
+
+type service struct {
+	limits   fuelCS
+	fuelRepo Repo
+	operator db.Operator
+	log      *slog.Logger
+}
+
+// for compilation purposes
+func newAPI() API {
+	return new(service)
+}
+
+func newService(dto fuelCS, fuelRepo Repo, operator db.Operator, log *slog.Logger) *service {
+	name := slog.String("name", reflect.TypeFor[service]().Name())
+	return &service{dto, fuelRepo, operator, log.With(name)}
+}
+
+func (s *service) RetrieveRec(realm Realm, compID identity.ADT) (_ FuelRec, err error) {
+	ctx := context.Background()
+	var rec FuelRec
+	selectErr := s.operator.Implicit(ctx, func(ds db.Source) error {
+		rec, err = s.fuelRepo.GetRec(ds, realm, compID)
+		if errors.Is(err, db.ErrNotFound) {
+			// вычисление еще не начинало тратить запас
+			rec, err = FuelRec{CompID: compID, FuelNr: s.limitOf(realm)}, nil
+		}
+		return err
+	})
+	if selectErr != nil {
+		s.log.Error("retrieval failed", slog.Any("realm", realm), slog.Any("id", compID))
+		return FuelRec{}, selectErr
+	}
+	return rec, nil
+}
+
+func (s *service) Burn(ds db.Source, realm Realm, compID identity.ADT) (FuelRec, error) {
+	rec, err := s.fuelRepo.BurnRec(ds, realm, compID, s.limitOf(realm))
+	if err != nil {
+		return FuelRec{}, err
+	}
+	if realm == ProcRealm && !rec.PoolID.IsEmpty() {
+		_, err = s.fuelRepo.BurnRec(ds, PoolRealm, rec.PoolID, s.limits.Pool)
+		if err != nil {
+			return FuelRec{}, err
+		}
+	}
+	return rec, nil
+}
+
+func (s *service) Grant(ds db.Source, compID identity.ADT, poolID identity.ADT) error {
+	return s.fuelRepo.AddRec(ds, FuelRec{CompID: compID, PoolID: poolID, FuelNr: s.limits.Comp})
+}
+
+// неудачное списание уже откатилось, поэтому запас перечитывается
+// под блокировкой: пополнение, зафиксированное раньше, здесь видно,
+// а более позднее дождется остановки и заберет шаг
+func (s *service) Halt(ds db.Source, spec compstep.StepSpec) (bool, error) {
+	drained, err := s.isDrained(ds, spec.CompRef.CompID)
+	if err != nil {
+		return false, err
+	}
+	if !drained {
+		s.log.Debug("computation refueled before halting", slog.Any("ref", spec.CompRef))
+		return false, nil
+	}
+	s.log.Warn("computation out of fuel", slog.Any("ref", spec.CompRef))
+	err = s.fuelRepo.AddHalt(ds, HaltRec{
+		HaltID:   identity.New(),
+		StepSpec: spec,
+		HaltedAt: time.Now(),
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// запасы блокируются в том же порядке, в каком их списывает Burn
+func (s *service) isDrained(ds db.Source, compID identity.ADT) (bool, error) {
+	rec, err := s.fuelRepo.LockRec(ds, ProcRealm, compID)
+	if errors.Is(err, db.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if rec.FuelNr <= 0 {
+		return true, nil
+	}
+	if rec.PoolID.IsEmpty() {
+		return false, nil
+	}
+	poolRec, err := s.fuelRepo.LockRec(ds, PoolRealm, rec.PoolID)
+	if errors.Is(err, db.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return poolRec.FuelNr <= 0, nil
+}
+
+func (s *service) Refill(ds db.Source, spec FuelSpec) (FuelRec, []compstep.StepSpec, error) {
+	rec, err := s.fuelRepo.RefillRec(ds, spec, s.limitOf(spec.Realm))
+	if err != nil {
+		return FuelRec{}, nil, err
+	}
+	halts, err := s.fuelRepo.RemoveHalts(ds, spec.Realm, spec.CompID)
+	if err != nil {
+		return FuelRec{}, nil, err
+	}
+	// шаги возобновляются в том порядке, в каком были остановлены
+	slices.SortStableFunc(halts, func(a, b HaltRec) int { return a.HaltedAt.Compare(b.HaltedAt) })
+	specs := make([]compstep.StepSpec, 0, len(halts))
+	for _, halt := range halts {
+		specs = append(specs, halt.StepSpec)
+	}
+	s.log.Debug("refilling succeed", slog.Any("realm", spec.Realm), slog.Any("rec", rec), slog.Int("halts", len(halts)))
+	return rec, specs, nil
+}
+
+// запас, с которым начинает вычисление
+func (s *service) limitOf(realm Realm) int64 {
+	switch realm {
+	case ProcRealm:
+		return s.limits.Comp
+	case PoolRealm:
+		return s.limits.Pool
+	default:
+		panic(ErrRealmUnexpected(realm))
+	}
+}
+
+func errOutOfFuel(realm Realm, compID string) error {
+	return fmt.Errorf("%w: %v %v", ErrOutOfFuel, realm, compID)
+}
+
+func ErrRealmUnexpected(got Realm) error {
+	return fmt.Errorf("realm unexpected: %v", got)
+}
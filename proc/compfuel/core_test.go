@@ -0,0 +1,155 @@
+package compfuel
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"orglang/go-engine/lib/db"
+
+	"orglang/go-engine/adt/compsem"
+	"orglang/go-engine/adt/identity"
+	"orglang/go-engine/adt/symbol"
+	"orglang/go-engine/proc/compstep"
+	"orglang/go-engine/proc/termexp"
+)
+
+func TestBurnMem(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits fuelCS
+		// сколько шагов пройдет до остановки
+		burns int
+	}{
+		{"comp", fuelCS{Comp: 2, Pool: 5}, 2},
+		{"pool", fuelCS{Comp: 5, Pool: 3}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			operator := db.NewOperatorMem()
+			log := slog.New(slog.DiscardHandler)
+			s := newService(tt.limits, newMemDAO(log), operator, log)
+			poolID := identity.New()
+			compID := identity.New()
+			err := operator.Explicit(ctx, func(ds db.Source) error {
+				return s.Grant(ds, compID, poolID)
+			})
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			for i := range tt.burns {
+				err = operator.Explicit(ctx, func(ds db.Source) error {
+					_, err := s.Burn(ds, ProcRealm, compID)
+					return err
+				})
+				if err != nil {
+					t.Fatalf("unexpected error at burn %v: %q", i, err)
+				}
+			}
+			err = operator.Explicit(ctx, func(ds db.Source) error {
+				_, err := s.Burn(ds, ProcRealm, compID)
+				return err
+			})
+			if !errors.Is(err, ErrOutOfFuel) {
+				t.Fatalf("unexpected error: want %q, got %q", ErrOutOfFuel, err)
+			}
+			// неудачное списание откатывается целиком
+			rec, err := s.RetrieveRec(ProcRealm, compID)
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			want := tt.limits.Comp - int64(tt.burns)
+			if rec.FuelNr != want {
+				t.Errorf("unexpected fuel: want %v, got %v", want, rec.FuelNr)
+			}
+		})
+	}
+}
+
+func TestRefillMem(t *testing.T) {
+	ctx := context.Background()
+	operator := db.NewOperatorMem()
+	log := slog.New(slog.DiscardHandler)
+	s := newService(fuelCS{Comp: 1, Pool: 1}, newMemDAO(log), operator, log)
+	poolID := identity.New()
+	compID := identity.New()
+	spec := compstep.StepSpec{
+		CompRef: compsem.SemRef{CompID: compID},
+		ProcExp: termexp.CloseSpec{ContChnlPH: symbol.New("x")},
+	}
+	var halted bool
+	err := operator.Explicit(ctx, func(ds db.Source) error {
+		err := s.Grant(ds, compID, poolID)
+		if err != nil {
+			return err
+		}
+		_, err = s.Burn(ds, ProcRealm, compID)
+		if err != nil {
+			return err
+		}
+		halted, err = s.Halt(ds, spec)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	if !halted {
+		t.Fatal("step not halted")
+	}
+	var rec FuelRec
+	var resumed []compstep.StepSpec
+	err = operator.Explicit(ctx, func(ds db.Source) error {
+		rec, resumed, err = s.Refill(ds, FuelSpec{Realm: PoolRealm, CompID: poolID, FuelNr: 10})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	// пул потратил единственный шаг
+	if rec.FuelNr != 10 {
+		t.Errorf("unexpected fuel: want %v, got %v", 10, rec.FuelNr)
+	}
+	if len(resumed) != 1 || resumed[0].CompRef.CompID != compID {
+		t.Errorf("unexpected halts: %v", resumed)
+	}
+}
+
+// пополнение, успевшее между отказом и остановкой, шаг не теряет
+func TestHaltRefueledMem(t *testing.T) {
+	ctx := context.Background()
+	operator := db.NewOperatorMem()
+	log := slog.New(slog.DiscardHandler)
+	s := newService(fuelCS{Comp: 1, Pool: 5}, newMemDAO(log), operator, log)
+	compID := identity.New()
+	spec := compstep.StepSpec{
+		CompRef: compsem.SemRef{CompID: compID},
+		ProcExp: termexp.CloseSpec{ContChnlPH: symbol.New("x")},
+	}
+	err := operator.Explicit(ctx, func(ds db.Source) error {
+		_, err := s.Burn(ds, ProcRealm, compID)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	err = operator.Explicit(ctx, func(ds db.Source) error {
+		_, _, err := s.Refill(ds, FuelSpec{Realm: ProcRealm, CompID: compID, FuelNr: 1})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	var halted bool
+	err = operator.Explicit(ctx, func(ds db.Source) error {
+		halted, err = s.Halt(ds, spec)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	if halted {
+		t.Error("refueled step halted")
+	}
+}
@@ -0,0 +1,26 @@
+package compfuel
+
+import (
+	"orglang/go-engine/lib/kv"
+)
+
+func newFuelCS(loader kv.Loader) (fuelCS, error) {
+	dto := new(fuelCS)
+	loadingErr := loader.Load("fuel", dto)
+	if loadingErr != nil {
+		return fuelCS{}, loadingErr
+	}
+	validateErr := dto.Validate()
+	if validateErr != nil {
+		return fuelCS{}, validateErr
+	}
+	return *dto, nil
+}
+
+// запасы, с которыми начинают вычисления
+type fuelCS struct {
+	// шагов на вычисление процесса
+	Comp int64 `mapstructure:"comp"`
+	// шагов на пул вместе с его процессами
+	Pool int64 `mapstructure:"pool"`
+}
@@ -0,0 +1,21 @@
+package compfuel
+
+import (
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module("proc/compfuel",
+	fx.Provide(
+		fx.Annotate(newService, fx.As(new(API)), fx.As(new(Meter))),
+		newDialectDAO,
+	),
+	fx.Provide(
+		fx.Private,
+		newFuelCS,
+		newEchoController,
+		fx.Annotate(newSQLBuilder, fx.As(new(queryBuilder))),
+	),
+	fx.Invoke(
+		cfgEchoController,
+	),
+)
@@ -0,0 +1,51 @@
+package compfuel
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"orglang/go-engine/lib/db"
+
+	"orglang/go-engine/adt/identity"
+	"orglang/go-engine/proc/termexp"
+)
+
+type Repo interface {
+	// AddRec заводит запас процесса, если его еще нет
+	AddRec(db.Source, FuelRec) error
+	// BurnRec списывает шаг с запаса; отсутствующий запас
+	// заводится с переданным начальным значением
+	BurnRec(db.Source, Realm, identity.ADT, int64) (FuelRec, error)
+	// RefillRec пополняет запас; отсутствующий запас
+	// заводится с переданным начальным значением
+	RefillRec(db.Source, FuelSpec, int64) (FuelRec, error)
+	GetRec(db.Source, Realm, identity.ADT) (FuelRec, error)
+	// LockRec блокирует запас до конца транзакции и отдает его остаток
+	LockRec(db.Source, Realm, identity.ADT) (FuelRec, error)
+	AddHalt(db.Source, HaltRec) error
+	// RemoveHalts забирает остановленные шаги процесса,
+	// а для пула - шаги всех его процессов
+	RemoveHalts(db.Source, Realm, identity.ADT) ([]HaltRec, error)
+}
+
+// хранилищу в памяти запросы не нужны, поэтому DAO выбирается по диалекту
+func newDialectDAO(dialect db.Dialect, qb queryBuilder, log *slog.Logger) Repo {
+	if dialect == db.Memory {
+		return newMemDAO(log)
+	}
+	return newPgxDAO(qb, log)
+}
+
+type fuelRecDS struct {
+	CompID string         `db:"comp_id"`
+	PoolID sql.NullString `db:"pool_id"`
+	FuelNr int64          `db:"fuel_nr"`
+}
+
+type haltRecDS struct {
+	HaltID   string            `db:"halt_id"`
+	CompID   string            `db:"comp_id"`
+	Exp      termexp.ExpSpecDS `db:"exp" fieldopt:"noexpand"`
+	HaltedAt time.Time         `db:"halted_at"`
+}
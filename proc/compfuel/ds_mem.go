@@ -0,0 +1,122 @@
+package compfuel
+
+import (
+	"log/slog"
+	"reflect"
+
+	"orglang/go-engine/lib/db"
+	"orglang/go-engine/lib/lf"
+
+	"orglang/go-engine/adt/identity"
+)
+
+// Adapter
+type memDAO struct {
+	log *slog.Logger
+}
+
+func newMemDAO(log *slog.Logger) *memDAO {
+	name := slog.String("name", reflect.TypeFor[memDAO]().Name())
+	return &memDAO{log.With(name)}
+}
+
+func (dao *memDAO) AddRec(source db.Source, rec FuelRec) error {
+	ds := db.MustConform[db.SourceMem](source)
+	dto := dataFromFuelRec(rec)
+	fuels := db.TableOf[string, fuelRecDS](ds, procFuels)
+	_, ok := fuels.Get(dto.CompID)
+	if ok {
+		return nil
+	}
+	fuels.Put(dto.CompID, dto)
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "insertion succeed", slog.Any("dto", dto))
+	return nil
+}
+
+func (dao *memDAO) BurnRec(source db.Source, realm Realm, compID identity.ADT, limit int64) (FuelRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	fuels := db.TableOf[string, fuelRecDS](ds, string(realm)+compFuels)
+	id := identity.ConvertToString(compID)
+	dto, ok := fuels.Get(id)
+	if !ok {
+		dto = fuelRecDS{CompID: id, FuelNr: limit}
+	}
+	if dto.FuelNr <= 0 {
+		return FuelRec{}, errOutOfFuel(realm, id)
+	}
+	dto.FuelNr--
+	fuels.Put(id, dto)
+	return dataToFuelRec(dto)
+}
+
+func (dao *memDAO) RefillRec(source db.Source, spec FuelSpec, limit int64) (FuelRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	fuels := db.TableOf[string, fuelRecDS](ds, string(spec.Realm)+compFuels)
+	id := identity.ConvertToString(spec.CompID)
+	dto, ok := fuels.Get(id)
+	if !ok {
+		dto = fuelRecDS{CompID: id, FuelNr: limit}
+	}
+	dto.FuelNr += spec.FuelNr
+	fuels.Put(id, dto)
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "refilling succeed", slog.Any("dto", dto))
+	return dataToFuelRec(dto)
+}
+
+func (dao *memDAO) GetRec(source db.Source, realm Realm, compID identity.ADT) (FuelRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	dto, ok := db.TableOf[string, fuelRecDS](ds, string(realm)+compFuels).Get(identity.ConvertToString(compID))
+	if !ok {
+		return FuelRec{}, db.ErrNotFound
+	}
+	return dataToFuelRec(dto)
+}
+
+// транзакции в памяти и так сериализованы
+func (dao *memDAO) LockRec(source db.Source, realm Realm, compID identity.ADT) (FuelRec, error) {
+	return dao.GetRec(source, realm, compID)
+}
+
+func (dao *memDAO) AddHalt(source db.Source, rec HaltRec) error {
+	ds := db.MustConform[db.SourceMem](source)
+	dto, err := dataFromHaltRec(rec)
+	if err != nil {
+		dao.log.Error("model conversion failed", slog.Any("ref", rec.StepSpec.CompRef))
+		return err
+	}
+	err = db.TableOf[string, haltRecDS](ds, compHalts).Insert(dto.HaltID, dto)
+	if err != nil {
+		dao.log.Error("insertion failed", slog.Any("ref", rec.StepSpec.CompRef))
+		return err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "insertion succeed", slog.Any("dto", dto))
+	return nil
+}
+
+func (dao *memDAO) RemoveHalts(source db.Source, realm Realm, compID identity.ADT) ([]HaltRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	id := identity.ConvertToString(compID)
+	compIDs := map[string]bool{}
+	switch realm {
+	case ProcRealm:
+		compIDs[id] = true
+	case PoolRealm:
+		for _, dto := range db.TableOf[string, fuelRecDS](ds, procFuels).Rows() {
+			if dto.PoolID.Valid && dto.PoolID.String == id {
+				compIDs[dto.CompID] = true
+			}
+		}
+	default:
+		panic(ErrRealmUnexpected(realm))
+	}
+	halts := db.TableOf[string, haltRecDS](ds, compHalts)
+	var dtos []haltRecDS
+	for _, dto := range halts.Rows() {
+		if compIDs[dto.CompID] {
+			halts.Delete(dto.HaltID)
+			dtos = append(dtos, dto)
+		}
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "removal succeed", slog.Any("id", compID), slog.Int("count", len(dtos)))
+	return dataToHaltRecs(dtos)
+}
@@ -0,0 +1,126 @@
+package compfuel
+
+import (
+	"errors"
+	"log/slog"
+	"reflect"
+
+	"github.com/jackc/pgx/v5"
+
+	"orglang/go-engine/lib/db"
+	"orglang/go-engine/lib/lf"
+
+	"orglang/go-engine/adt/identity"
+)
+
+type pgxDAO struct {
+	qb  queryBuilder
+	log *slog.Logger
+}
+
+func newPgxDAO(qb queryBuilder, log *slog.Logger) *pgxDAO {
+	name := slog.String("name", reflect.TypeFor[pgxDAO]().Name())
+	return &pgxDAO{qb, log.With(name)}
+}
+
+// for compilation purposes
+func newRepo() Repo {
+	return new(pgxDAO)
+}
+
+func (dao *pgxDAO) AddRec(source db.Source, rec FuelRec) error {
+	ds := db.MustConform[db.SourcePgx](source)
+	dto := dataFromFuelRec(rec)
+	sql, args := dao.qb.insertRec(dto)
+	_, execErr := ds.Conn.Exec(ds.Ctx, sql, args...)
+	if execErr != nil {
+		dao.log.Error("query execution failed", slog.Any("id", rec.CompID), slog.String("sql", sql))
+		return execErr
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "insertion succeed", slog.Any("dto", dto))
+	return nil
+}
+
+func (dao *pgxDAO) BurnRec(source db.Source, realm Realm, compID identity.ADT, limit int64) (FuelRec, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	sql, args := dao.qb.burnRec(realm, identity.ConvertToString(compID), limit)
+	rec, err := dao.queryRec(ds, sql, args)
+	if errors.Is(err, db.ErrNotFound) {
+		return FuelRec{}, errOutOfFuel(realm, identity.ConvertToString(compID))
+	}
+	return rec, err
+}
+
+func (dao *pgxDAO) RefillRec(source db.Source, spec FuelSpec, limit int64) (FuelRec, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	sql, args := dao.qb.refillRec(spec.Realm, identity.ConvertToString(spec.CompID), limit, spec.FuelNr)
+	return dao.queryRec(ds, sql, args)
+}
+
+func (dao *pgxDAO) GetRec(source db.Source, realm Realm, compID identity.ADT) (FuelRec, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	sql, args := dao.qb.selectRec(realm, identity.ConvertToString(compID))
+	return dao.queryRec(ds, sql, args)
+}
+
+func (dao *pgxDAO) LockRec(source db.Source, realm Realm, compID identity.ADT) (FuelRec, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	sql, args := dao.qb.lockRec(realm, identity.ConvertToString(compID))
+	return dao.queryRec(ds, sql, args)
+}
+
+func (dao *pgxDAO) queryRec(ds db.SourcePgx, sql string, args []any) (FuelRec, error) {
+	rows, execErr := ds.Conn.Query(ds.Ctx, sql, args...)
+	if execErr != nil {
+		dao.log.Error("query execution failed", slog.String("sql", sql))
+		return FuelRec{}, execErr
+	}
+	defer rows.Close()
+	dto, scanErr := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[fuelRecDS])
+	if errors.Is(scanErr, pgx.ErrNoRows) {
+		return FuelRec{}, db.ConvertNoRows(scanErr)
+	}
+	if scanErr != nil {
+		dao.log.Error("row scanning failed", slog.String("sql", sql))
+		return FuelRec{}, scanErr
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "querying succeed", slog.Any("dto", dto))
+	return dataToFuelRec(dto)
+}
+
+func (dao *pgxDAO) AddHalt(source db.Source, rec HaltRec) error {
+	ds := db.MustConform[db.SourcePgx](source)
+	refAttr := slog.Any("ref", rec.StepSpec.CompRef)
+	dto, convErr := dataFromHaltRec(rec)
+	if convErr != nil {
+		dao.log.Error("model conversion failed", refAttr)
+		return convErr
+	}
+	sql, args := dao.qb.insertHalt(dto)
+	_, execErr := ds.Conn.Exec(ds.Ctx, sql, args...)
+	if execErr != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("sql", sql))
+		return execErr
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "insertion succeed", slog.Any("dto", dto))
+	return nil
+}
+
+func (dao *pgxDAO) RemoveHalts(source db.Source, realm Realm, compID identity.ADT) ([]HaltRec, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	idAttr := slog.Any("id", compID)
+	sql, args := dao.qb.deleteHalts(realm, identity.ConvertToString(compID))
+	rows, execErr := ds.Conn.Query(ds.Ctx, sql, args...)
+	if execErr != nil {
+		dao.log.Error("query execution failed", idAttr, slog.String("sql", sql))
+		return nil, execErr
+	}
+	defer rows.Close()
+	dtos, scanErr := pgx.CollectRows(rows, pgx.RowToStructByName[haltRecDS])
+	if scanErr != nil {
+		dao.log.Error("rows scanning failed", idAttr)
+		return nil, scanErr
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "removal succeed", idAttr, slog.Int("count", len(dtos)))
+	return dataToHaltRecs(dtos)
+}
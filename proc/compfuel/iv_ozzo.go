@@ -0,0 +1,19 @@
+package compfuel
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+func (dto fuelCS) Validate() error {
+	return validation.ValidateStruct(&dto,
+		validation.Field(&dto.Comp, validation.Required, validation.Min(int64(1))),
+		validation.Field(&dto.Pool, validation.Required, validation.Min(int64(1))),
+	)
+}
+
+func (dto FuelSpecVP) Validate() error {
+	return validation.ValidateStruct(&dto,
+		validation.Field(&dto.CompID, validation.Required),
+		validation.Field(&dto.FuelNr, validation.Required, validation.Min(int64(1))),
+	)
+}
@@ -0,0 +1,50 @@
+package compfuel
+
+import (
+	"log/slog"
+	"net/http"
+	"reflect"
+
+	"github.com/labstack/echo/v4"
+
+	"orglang/go-engine/adt/identity"
+)
+
+// Server-side primary adapter
+type echoController struct {
+	api API
+	log *slog.Logger
+}
+
+func newEchoController(api API, log *slog.Logger) *echoController {
+	name := slog.String("name", reflect.TypeFor[echoController]().Name())
+	return &echoController{api, log.With(name)}
+}
+
+// пополнение обслуживают исполнители, так как оно возобновляет шаги
+func cfgEchoController(server *echo.Echo, controller *echoController) error {
+	server.GET("/api/v1/procs/:id/fuel", controller.GetRec(ProcRealm))
+	server.GET("/api/v1/pools/execs/:id/fuel", controller.GetRec(PoolRealm))
+	return nil
+}
+
+func (c *echoController) GetRec(realm Realm) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		var dto FuelSpecVP
+		bindErr := ctx.Bind(&dto)
+		if bindErr != nil {
+			c.log.Error("binding failed", slog.Any("dto", reflect.TypeFor[FuelSpecVP]()))
+			return bindErr
+		}
+		compID, convErr := identity.ConvertFromString(dto.CompID)
+		if convErr != nil {
+			c.log.Error("conversion failed", slog.Any("dto", dto))
+			return convErr
+		}
+		rec, apiErr := c.api.RetrieveRec(realm, compID)
+		if apiErr != nil {
+			return apiErr
+		}
+		return ctx.JSON(http.StatusOK, ViewFromFuelRec(rec))
+	}
+}
@@ -0,0 +1,18 @@
+package compfuel
+
+const (
+	// таблицы запасов разных миров называются одинаково с точностью до префикса
+	compFuels string = "_comp_fuels"
+	procFuels string = "proc_comp_fuels"
+	compHalts string = "proc_comp_halts"
+)
+
+type queryBuilder interface {
+	insertRec(fuelRecDS) (string, []any)
+	burnRec(Realm, string, int64) (string, []any)
+	refillRec(Realm, string, int64, int64) (string, []any)
+	selectRec(Realm, string) (string, []any)
+	lockRec(Realm, string) (string, []any)
+	insertHalt(haltRecDS) (string, []any)
+	deleteHalts(Realm, string) (string, []any)
+}
@@ -0,0 +1,96 @@
+package compfuel
+
+import (
+	"github.com/huandu/go-sqlbuilder"
+)
+
+// запросы переносимы, поэтому отдельного построителя для SQLite нет
+type sqlBuilder struct {
+	fuelBuilder *sqlbuilder.Struct
+	haltBuilder *sqlbuilder.Struct
+}
+
+// for compilation purposes
+func newQueryBuilder() queryBuilder {
+	return new(sqlBuilder)
+}
+
+func newSQLBuilder() *sqlBuilder {
+	fuelBuilder := sqlbuilder.NewStruct(new(fuelRecDS)).For(sqlbuilder.PostgreSQL)
+	haltBuilder := sqlbuilder.NewStruct(new(haltRecDS)).For(sqlbuilder.PostgreSQL)
+	return &sqlBuilder{fuelBuilder, haltBuilder}
+}
+
+// запас порожденного процесса не перезаписывает уже заведенный
+func (qb *sqlBuilder) insertRec(rec fuelRecDS) (string, []any) {
+	return qb.fuelBuilder.InsertInto(procFuels, rec).
+		SQL("ON CONFLICT (comp_id) DO NOTHING").
+		Build()
+}
+
+// исчерпанный запас не обновляется, и запрос не возвращает строки
+func (qb *sqlBuilder) burnRec(realm Realm, compID string, limit int64) (string, []any) {
+	table := string(realm) + compFuels
+	return sqlbuilder.Build(`INSERT INTO `+table+` (comp_id, fuel_nr) VALUES ($?, $?)
+ON CONFLICT (comp_id) DO UPDATE SET fuel_nr = `+table+`.fuel_nr - 1
+WHERE `+table+`.fuel_nr > 0
+RETURNING comp_id, `+poolColumn(realm)+`, fuel_nr`,
+		compID, limit-1,
+	).BuildWithFlavor(sqlbuilder.PostgreSQL)
+}
+
+func (qb *sqlBuilder) refillRec(realm Realm, compID string, limit int64, fuelNr int64) (string, []any) {
+	table := string(realm) + compFuels
+	return sqlbuilder.Build(`INSERT INTO `+table+` (comp_id, fuel_nr) VALUES ($?, $?)
+ON CONFLICT (comp_id) DO UPDATE SET fuel_nr = `+table+`.fuel_nr + $?
+RETURNING comp_id, `+poolColumn(realm)+`, fuel_nr`,
+		compID, limit+fuelNr, fuelNr,
+	).BuildWithFlavor(sqlbuilder.PostgreSQL)
+}
+
+func (qb *sqlBuilder) selectRec(realm Realm, compID string) (string, []any) {
+	sb := sqlbuilder.PostgreSQL.NewSelectBuilder()
+	return sb.Select("comp_id", poolColumn(realm), "fuel_nr").
+		From(string(realm) + compFuels).
+		Where(sb.Equal("comp_id", compID)).
+		Build()
+}
+
+// пустое обновление вместо FOR UPDATE, которого нет в SQLite:
+// строка блокируется, а остаток читается последний зафиксированный
+func (qb *sqlBuilder) lockRec(realm Realm, compID string) (string, []any) {
+	table := string(realm) + compFuels
+	return sqlbuilder.Build(`UPDATE `+table+` SET fuel_nr = fuel_nr
+WHERE comp_id = $?
+RETURNING comp_id, `+poolColumn(realm)+`, fuel_nr`,
+		compID,
+	).BuildWithFlavor(sqlbuilder.PostgreSQL)
+}
+
+func (qb *sqlBuilder) insertHalt(rec haltRecDS) (string, []any) {
+	return qb.haltBuilder.InsertInto(compHalts, rec).Build()
+}
+
+func (qb *sqlBuilder) deleteHalts(realm Realm, compID string) (string, []any) {
+	del := sqlbuilder.PostgreSQL.NewDeleteBuilder()
+	del.DeleteFrom(compHalts)
+	switch realm {
+	case ProcRealm:
+		del.Where(del.Equal("comp_id", compID))
+	case PoolRealm:
+		fuels := sqlbuilder.PostgreSQL.NewSelectBuilder()
+		fuels.Select("comp_id").From(procFuels).Where(fuels.Equal("pool_id", compID))
+		del.Where(del.In("comp_id", fuels))
+	default:
+		panic(ErrRealmUnexpected(realm))
+	}
+	return del.Returning(qb.haltBuilder.Columns()...).Build()
+}
+
+// у запасов пулов нет пула
+func poolColumn(realm Realm) string {
+	if realm == PoolRealm {
+		return "CAST(NULL AS text) AS pool_id"
+	}
+	return "pool_id"
+}
@@ -0,0 +1,46 @@
+package compfuel
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestBurnRec(t *testing.T) {
+	qb := newSQLBuilder()
+	for _, realm := range []Realm{ProcRealm, PoolRealm} {
+		sql, args := qb.burnRec(realm, "foo", 10)
+		fmt.Println(sql)
+		fmt.Println(args)
+	}
+}
+
+func TestRefillRec(t *testing.T) {
+	qb := newSQLBuilder()
+	sql, args := qb.refillRec(PoolRealm, "foo", 10, 5)
+	fmt.Println(sql)
+	fmt.Println(args)
+}
+
+func TestLockRec(t *testing.T) {
+	qb := newSQLBuilder()
+	for _, realm := range []Realm{ProcRealm, PoolRealm} {
+		sql, args := qb.lockRec(realm, "foo")
+		fmt.Println(sql)
+		fmt.Println(args)
+	}
+}
+
+func TestInsertHalt(t *testing.T) {
+	qb := newSQLBuilder()
+	sql, _ := qb.insertHalt(haltRecDS{})
+	fmt.Println(sql)
+}
+
+func TestDeleteHalts(t *testing.T) {
+	qb := newSQLBuilder()
+	for _, realm := range []Realm{ProcRealm, PoolRealm} {
+		sql, args := qb.deleteHalts(realm, "foo")
+		fmt.Println(sql)
+		fmt.Println(args)
+	}
+}
@@ -0,0 +1,93 @@
+package compfuel
+
+import (
+	"orglang/go-engine/adt/compsem"
+	"orglang/go-engine/adt/identity"
+	"orglang/go-engine/proc/compstep"
+	"orglang/go-engine/proc/termexp"
+)
+
+func MsgToFuelSpec(realm Realm, dto FuelSpecVP) (FuelSpec, error) {
+	compID, err := identity.ConvertFromString(dto.CompID)
+	if err != nil {
+		return FuelSpec{}, err
+	}
+	return FuelSpec{Realm: realm, CompID: compID, FuelNr: dto.FuelNr}, nil
+}
+
+func ViewFromFuelRec(rec FuelRec) FuelRecVP {
+	return FuelRecVP{
+		CompID: identity.ConvertToString(rec.CompID),
+		PoolID: identity.ConvertToNullString(rec.PoolID).String,
+		FuelNr: rec.FuelNr,
+	}
+}
+
+func dataFromFuelRec(rec FuelRec) fuelRecDS {
+	return fuelRecDS{
+		CompID: identity.ConvertToString(rec.CompID),
+		PoolID: identity.ConvertToNullString(rec.PoolID),
+		FuelNr: rec.FuelNr,
+	}
+}
+
+func dataToFuelRec(dto fuelRecDS) (FuelRec, error) {
+	compID, err := identity.ConvertFromString(dto.CompID)
+	if err != nil {
+		return FuelRec{}, err
+	}
+	poolID, err := identity.ConvertFromNullString(dto.PoolID)
+	if err != nil {
+		return FuelRec{}, err
+	}
+	return FuelRec{CompID: compID, PoolID: poolID, FuelNr: dto.FuelNr}, nil
+}
+
+func dataFromHaltRec(rec HaltRec) (haltRecDS, error) {
+	exp, err := termexp.DataFromExpSpec(rec.StepSpec.ProcExp)
+	if err != nil {
+		return haltRecDS{}, err
+	}
+	return haltRecDS{
+		HaltID:   identity.ConvertToString(rec.HaltID),
+		CompID:   identity.ConvertToString(rec.StepSpec.CompRef.CompID),
+		Exp:      exp,
+		HaltedAt: rec.HaltedAt,
+	}, nil
+}
+
+// ревизия не хранится: шаг берется на той, что окажется последней
+func dataToHaltRec(dto haltRecDS) (HaltRec, error) {
+	haltID, err := identity.ConvertFromString(dto.HaltID)
+	if err != nil {
+		return HaltRec{}, err
+	}
+	compID, err := identity.ConvertFromString(dto.CompID)
+	if err != nil {
+		return HaltRec{}, err
+	}
+	exp, err := termexp.DataToExpSpec(dto.Exp)
+	if err != nil {
+		return HaltRec{}, err
+	}
+	return HaltRec{
+		HaltID: haltID,
+		StepSpec: compstep.StepSpec{
+			CompRef: compsem.SemRef{CompID: compID},
+			ProcExp: exp,
+		},
+		HaltedAt: dto.HaltedAt,
+	}, nil
+}
+
+func dataToHaltRecs(dtos []haltRecDS) ([]HaltRec, error) {
+	recs := make([]HaltRec, 0, len(dtos))
+	for _, dto := range dtos {
+		rec, err := dataToHaltRec(dto)
+		if err != nil {
+			return nil, err
+		}
+		recs = append(recs, rec)
+	}
+	return recs, nil
+}
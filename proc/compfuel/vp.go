@@ -0,0 +1,13 @@
+package compfuel
+
+// пополнение запаса; идентификатор берется из пути
+type FuelSpecVP struct {
+	CompID string `param:"id" json:"-"`
+	FuelNr int64  `json:"fuel_nr"`
+}
+
+type FuelRecVP struct {
+	CompID string `json:"comp_id"`
+	PoolID string `json:"pool_id,omitempty"`
+	FuelNr int64  `json:"fuel_nr"`
+}
@@ -0,0 +1,111 @@
+// Package syntax implements concrete syntax of types, declarations and processes
+//
+// Грамматика (в стиле Rast):
+//
+//	module := { "type" QN "=" type | "decl" QN ":" { var } "|-" var | "proc" QN "=" exp }
+//	var    := "(" PH ":" QN ")"
+//	type   := unary [ ( "*" | "-o" ) type ]
+//	unary  := "/\" unary | "\/" unary | "1" | QN | ( "+" | "&" ) "{" QN ":" type { "," QN ":" type } "}" | "(" type ")"
+//	exp    := "close" PH | "wait" PH ";" exp | "send" PH PH
+//	        | "case" PH "(" QN "=>" exp { "|" QN "=>" exp } ")"
+//	        | ( "acquire" | "accept" | "detach" | "release" ) PH ";" exp
+//	        | PH "<-" "recv" PH ";" exp
+//	        | PH "<-" ( "call" | "spawn" ) QN { PH } ";" exp
+//	        | PH "<-" QN { PH }
+//	        | PH "<->" PH
+//	        | PH "." QN ";" exp
+//	QN     := name { "." name }
+//
+// Комментарии начинаются с "%" и продолжаются до конца строки.
+package syntax
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"orglang/go-engine/proc/termdec"
+	"orglang/go-engine/proc/termdef"
+	"orglang/go-engine/proc/termexp"
+	"orglang/go-engine/proc/typedef"
+)
+
+// source position
+type Pos struct {
+	File string
+	Line int
+	Col  int
+}
+
+func (p Pos) String() string {
+	if p.File == "" {
+		return fmt.Sprintf("%d:%d", p.Line, p.Col)
+	}
+	return fmt.Sprintf("%v:%d:%d", p.File, p.Line, p.Col)
+}
+
+type TypeDef struct {
+	Pos  Pos
+	Spec typedef.DefSpec
+}
+
+type TermDec struct {
+	Pos  Pos
+	Spec termdec.DecSpec
+}
+
+type TermDef struct {
+	Pos  Pos
+	Spec termdef.DefSpec
+	// узлы тела в порядке завершения разбора: вложенные раньше объемлющих
+	Nodes []ExpNode
+}
+
+// process expression node with position
+type ExpNode struct {
+	Pos  Pos
+	Spec termexp.ExpSpec
+}
+
+// PosOf locates expression node within definition body
+//
+// Одинаковые поддеревья неразличимы, поэтому берется первое
+// вхождение; для чужого выражения возвращается позиция определения.
+func (d TermDef) PosOf(exp termexp.ExpSpec) Pos {
+	for _, node := range d.Nodes {
+		if reflect.DeepEqual(node.Spec, exp) {
+			return node.Pos
+		}
+	}
+	return d.Pos
+}
+
+type Module struct {
+	TypeDefs []TypeDef
+	TermDecs []TermDec
+	TermDefs []TermDef
+}
+
+// diagnostic with line and column
+type Error struct {
+	Pos Pos
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%v: %v", e.Pos, e.Msg)
+}
+
+type ErrorList []*Error
+
+func (l ErrorList) Error() string {
+	msgs := make([]string, 0, len(l))
+	for _, err := range l {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func errUnexpected(got token, want string) *Error {
+	return &Error{got.pos, fmt.Sprintf("unexpected %v, want %v", got, want)}
+}
@@ -0,0 +1,149 @@
+package syntax
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int8
+
+const (
+	eofToken tokenKind = iota
+	identToken
+	keywordToken
+	punctToken
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  Pos
+	off  int
+}
+
+// смежность нужна для сегментов квалифицированного имени
+func (t token) adjoins(next token) bool {
+	return t.off+len(t.text) == next.off
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case eofToken:
+		return "end of input"
+	case identToken:
+		return fmt.Sprintf("name %q", t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+var keywords = map[string]bool{
+	"type": true, "decl": true, "proc": true,
+	"close": true, "wait": true, "send": true, "recv": true, "case": true,
+	"call": true, "spawn": true,
+	"acquire": true, "accept": true, "detach": true, "release": true,
+}
+
+// длинные знаки проверяются раньше их префиксов
+var puncts = []string{
+	"<->", "<-", "|-", "=>", "-o", `/\`, `\/`,
+	"=", ":", ",", ";", "(", ")", "{", "}", "|", "*", "+", "&", "1", ".",
+}
+
+type lexer struct {
+	file string
+	src  string
+	off  int
+	line int
+	col  int
+}
+
+func lex(file, src string) ([]token, error) {
+	lx := &lexer{file: file, src: src, line: 1, col: 1}
+	var toks []token
+	for {
+		tok, err := lx.next()
+		if err != nil {
+			return nil, err
+		}
+		toks = append(toks, tok)
+		if tok.kind == eofToken {
+			return toks, nil
+		}
+	}
+}
+
+func (lx *lexer) next() (token, error) {
+	lx.skip()
+	pos, off := Pos{lx.file, lx.line, lx.col}, lx.off
+	if lx.off >= len(lx.src) {
+		return token{eofToken, "", pos, off}, nil
+	}
+	r, _ := utf8.DecodeRuneInString(lx.src[lx.off:])
+	if isIdentStart(r) {
+		text := lx.ident()
+		if keywords[text] {
+			return token{keywordToken, text, pos, off}, nil
+		}
+		return token{identToken, text, pos, off}, nil
+	}
+	for _, p := range puncts {
+		if strings.HasPrefix(lx.src[lx.off:], p) {
+			lx.advance(len(p))
+			return token{punctToken, p, pos, off}, nil
+		}
+	}
+	return token{}, &Error{pos, fmt.Sprintf("unexpected character %q", r)}
+}
+
+func (lx *lexer) skip() {
+	for lx.off < len(lx.src) {
+		r, _ := utf8.DecodeRuneInString(lx.src[lx.off:])
+		switch {
+		case r == '%':
+			for lx.off < len(lx.src) && lx.src[lx.off] != '\n' {
+				lx.advance(1)
+			}
+		case unicode.IsSpace(r):
+			lx.advance(utf8.RuneLen(r))
+		default:
+			return
+		}
+	}
+}
+
+// точка лексируется отдельно: квалифицированные имена
+// собирает парсер, так как "x.l" бывает и отправкой метки
+func (lx *lexer) ident() string {
+	start := lx.off
+	for lx.off < len(lx.src) {
+		r, size := utf8.DecodeRuneInString(lx.src[lx.off:])
+		if !isIdentPart(r) {
+			break
+		}
+		lx.advance(size)
+	}
+	return lx.src[start:lx.off]
+}
+
+func (lx *lexer) advance(n int) {
+	for _, r := range lx.src[lx.off : lx.off+n] {
+		if r == '\n' {
+			lx.line++
+			lx.col = 1
+		} else {
+			lx.col++
+		}
+	}
+	lx.off += n
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentPart(r rune) bool {
+	return isIdentStart(r) || unicode.IsDigit(r) || r == '\''
+}
@@ -0,0 +1,566 @@
+package syntax
+
+import (
+	"fmt"
+	"strings"
+
+	"orglang/go-engine/adt/symbol"
+	"orglang/go-engine/adt/termvar"
+	"orglang/go-engine/adt/uniqsym"
+	"orglang/go-engine/proc/termdec"
+	"orglang/go-engine/proc/termdef"
+	"orglang/go-engine/proc/termexp"
+	"orglang/go-engine/proc/typedef"
+	"orglang/go-engine/proc/typeexp"
+)
+
+type parser struct {
+	toks  []token
+	idx   int
+	nodes []ExpNode
+}
+
+// ParseModule parses sequence of type, declaration and process definitions
+//
+// После ошибки разбор продолжается со следующего определения,
+// поэтому возвращаются все найденные ошибки.
+func ParseModule(src string) (Module, error) {
+	return ParseFile("", src)
+}
+
+// ParseFile parses module and marks positions with file name
+func ParseFile(file, src string) (Module, error) {
+	toks, err := lex(file, src)
+	if err != nil {
+		return Module{}, ErrorList{err.(*Error)}
+	}
+	p := &parser{toks: toks}
+	var mod Module
+	var errs ErrorList
+	for p.peek().kind != eofToken {
+		err := p.parseDef(&mod)
+		if err != nil {
+			errs = append(errs, err)
+			p.recover()
+		}
+	}
+	if len(errs) > 0 {
+		return Module{}, errs
+	}
+	return mod, nil
+}
+
+// ParseType parses single type expression
+func ParseType(src string) (typeexp.ExpSpec, error) {
+	return parseWhole(src, (*parser).parseType)
+}
+
+// ParseExp parses single process expression
+func ParseExp(src string) (termexp.ExpSpec, error) {
+	return parseWhole(src, (*parser).parseExp)
+}
+
+func parseWhole[T any](src string, parse func(*parser) (T, *Error)) (T, error) {
+	var zero T
+	toks, err := lex("", src)
+	if err != nil {
+		return zero, err
+	}
+	p := &parser{toks: toks}
+	res, perr := parse(p)
+	if perr != nil {
+		return zero, perr
+	}
+	if p.peek().kind != eofToken {
+		return zero, errUnexpected(p.peek(), "end of input")
+	}
+	return res, nil
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.idx]
+}
+
+func (p *parser) take() token {
+	tok := p.toks[p.idx]
+	if tok.kind != eofToken {
+		p.idx++
+	}
+	return tok
+}
+
+func (p *parser) is(kind tokenKind, text string) bool {
+	tok := p.peek()
+	return tok.kind == kind && tok.text == text
+}
+
+func (p *parser) expect(kind tokenKind, text string) (token, *Error) {
+	tok := p.peek()
+	if tok.kind != kind || tok.text != text {
+		return tok, errUnexpected(tok, fmt.Sprintf("%q", text))
+	}
+	return p.take(), nil
+}
+
+// пропуск до начала следующего определения
+func (p *parser) recover() {
+	p.take()
+	for {
+		tok := p.peek()
+		if tok.kind == eofToken {
+			return
+		}
+		if tok.kind == keywordToken && (tok.text == "type" || tok.text == "decl" || tok.text == "proc") {
+			return
+		}
+		p.take()
+	}
+}
+
+func (p *parser) parseDef(mod *Module) *Error {
+	tok := p.peek()
+	if tok.kind != keywordToken {
+		return errUnexpected(tok, `"type", "decl" or "proc"`)
+	}
+	switch tok.text {
+	case "type":
+		p.take()
+		typeQN, err := p.parseQN()
+		if err != nil {
+			return err
+		}
+		_, err = p.expect(punctToken, "=")
+		if err != nil {
+			return err
+		}
+		typeExp, err := p.parseType()
+		if err != nil {
+			return err
+		}
+		mod.TypeDefs = append(mod.TypeDefs, TypeDef{tok.pos, typedef.DefSpec{TypeQN: typeQN, TypeExp: typeExp}})
+		return nil
+	case "decl":
+		p.take()
+		termQN, err := p.parseQN()
+		if err != nil {
+			return err
+		}
+		_, err = p.expect(punctToken, ":")
+		if err != nil {
+			return err
+		}
+		var assetVars []termvar.VarSpec
+		for p.is(punctToken, "(") {
+			assetVar, err := p.parseVar()
+			if err != nil {
+				return err
+			}
+			assetVars = append(assetVars, assetVar)
+		}
+		_, err = p.expect(punctToken, "|-")
+		if err != nil {
+			return err
+		}
+		liabVar, err := p.parseVar()
+		if err != nil {
+			return err
+		}
+		decSpec := termdec.DecSpec{TermQN: termQN, LiabVar: liabVar, AssetVars: assetVars}
+		mod.TermDecs = append(mod.TermDecs, TermDec{tok.pos, decSpec})
+		return nil
+	case "proc":
+		p.take()
+		procQN, err := p.parseQN()
+		if err != nil {
+			return err
+		}
+		_, err = p.expect(punctToken, "=")
+		if err != nil {
+			return err
+		}
+		p.nodes = nil
+		procExp, err := p.parseExp()
+		if err != nil {
+			return err
+		}
+		defSpec := termdef.DefSpec{ProcQN: procQN, ProcES: procExp}
+		mod.TermDefs = append(mod.TermDefs, TermDef{tok.pos, defSpec, p.nodes})
+		return nil
+	default:
+		return errUnexpected(tok, `"type", "decl" or "proc"`)
+	}
+}
+
+func (p *parser) parseVar() (termvar.VarSpec, *Error) {
+	_, err := p.expect(punctToken, "(")
+	if err != nil {
+		return termvar.VarSpec{}, err
+	}
+	chnlPH, err := p.parsePH()
+	if err != nil {
+		return termvar.VarSpec{}, err
+	}
+	_, err = p.expect(punctToken, ":")
+	if err != nil {
+		return termvar.VarSpec{}, err
+	}
+	typeQN, err := p.parseQN()
+	if err != nil {
+		return termvar.VarSpec{}, err
+	}
+	_, err = p.expect(punctToken, ")")
+	if err != nil {
+		return termvar.VarSpec{}, err
+	}
+	return termvar.VarSpec{ChnlPH: chnlPH, TypeQN: typeQN}, nil
+}
+
+func (p *parser) parseType() (typeexp.ExpSpec, *Error) {
+	val, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case p.is(punctToken, "*"):
+		p.take()
+		cont, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		return typeexp.TensorSpec{Val: val, Cont: cont}, nil
+	case p.is(punctToken, "-o"):
+		p.take()
+		cont, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		return typeexp.LolliSpec{Val: val, Cont: cont}, nil
+	default:
+		return val, nil
+	}
+}
+
+func (p *parser) parseUnary() (typeexp.ExpSpec, *Error) {
+	tok := p.peek()
+	switch {
+	case tok.kind == identToken:
+		typeQN, err := p.parseQN()
+		if err != nil {
+			return nil, err
+		}
+		return typeexp.LinkSpec{TypeQN: typeQN}, nil
+	case tok.kind != punctToken:
+		return nil, errUnexpected(tok, "type expression")
+	}
+	switch tok.text {
+	case "1":
+		p.take()
+		return typeexp.OneSpec{}, nil
+	case `/\`:
+		p.take()
+		cont, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return typeexp.UpSpec{Cont: cont}, nil
+	case `\/`:
+		p.take()
+		cont, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return typeexp.DownSpec{Cont: cont}, nil
+	case "+":
+		p.take()
+		choices, err := p.parseChoices()
+		if err != nil {
+			return nil, err
+		}
+		return typeexp.PlusSpec{Choices: choices}, nil
+	case "&":
+		p.take()
+		choices, err := p.parseChoices()
+		if err != nil {
+			return nil, err
+		}
+		return typeexp.WithSpec{Choices: choices}, nil
+	case "(":
+		p.take()
+		exp, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		_, err = p.expect(punctToken, ")")
+		if err != nil {
+			return nil, err
+		}
+		return exp, nil
+	default:
+		return nil, errUnexpected(tok, "type expression")
+	}
+}
+
+func (p *parser) parseChoices() (map[uniqsym.ADT]typeexp.ExpSpec, *Error) {
+	_, err := p.expect(punctToken, "{")
+	if err != nil {
+		return nil, err
+	}
+	choices := make(map[uniqsym.ADT]typeexp.ExpSpec)
+	seen := make(map[string]bool)
+	for {
+		labTok := p.peek()
+		labQN, err := p.parseQN()
+		if err != nil {
+			return nil, err
+		}
+		if seen[labQN.String()] {
+			return nil, &Error{labTok.pos, fmt.Sprintf("duplicate label %q", labQN)}
+		}
+		seen[labQN.String()] = true
+		_, err = p.expect(punctToken, ":")
+		if err != nil {
+			return nil, err
+		}
+		cont, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		choices[labQN] = cont
+		if !p.is(punctToken, ",") {
+			break
+		}
+		p.take()
+	}
+	_, err = p.expect(punctToken, "}")
+	if err != nil {
+		return nil, err
+	}
+	return choices, nil
+}
+
+func (p *parser) parseExp() (termexp.ExpSpec, *Error) {
+	tok := p.peek()
+	var exp termexp.ExpSpec
+	var err *Error
+	switch tok.kind {
+	case keywordToken:
+		exp, err = p.parseKeywordExp()
+	case identToken:
+		exp, err = p.parseChnlExp()
+	default:
+		return nil, errUnexpected(tok, "process expression")
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.nodes = append(p.nodes, ExpNode{tok.pos, exp})
+	return exp, nil
+}
+
+func (p *parser) parseKeywordExp() (termexp.ExpSpec, *Error) {
+	tok := p.take()
+	switch tok.text {
+	case "close":
+		chnlPH, err := p.parsePH()
+		if err != nil {
+			return nil, err
+		}
+		return termexp.CloseSpec{ContChnlPH: chnlPH}, nil
+	case "send":
+		commPH, err := p.parsePH()
+		if err != nil {
+			return nil, err
+		}
+		valPH, err := p.parsePH()
+		if err != nil {
+			return nil, err
+		}
+		return termexp.SendSpec{CommChnlPH: commPH, ValChnlPH: valPH}, nil
+	case "case":
+		return p.parseCase()
+	case "wait", "acquire", "accept", "detach", "release":
+		chnlPH, err := p.parsePH()
+		if err != nil {
+			return nil, err
+		}
+		cont, err := p.parseCont()
+		if err != nil {
+			return nil, err
+		}
+		switch tok.text {
+		case "wait":
+			return termexp.WaitSpec{ContChnlPH: chnlPH, ContExp: cont}, nil
+		case "acquire":
+			return termexp.AcqureSpec{CommChnlPH: chnlPH, ContExp: cont}, nil
+		case "accept":
+			return termexp.AcceptSpec{CommChnlPH: chnlPH, ContExp: cont}, nil
+		case "detach":
+			return termexp.DetachSpec{CommChnlPH: chnlPH, ContExp: cont}, nil
+		default:
+			return termexp.ReleaseSpec{CommChnlPH: chnlPH, ContExp: cont}, nil
+		}
+	default:
+		return nil, errUnexpected(tok, "process expression")
+	}
+}
+
+func (p *parser) parseCase() (termexp.ExpSpec, *Error) {
+	commPH, err := p.parsePH()
+	if err != nil {
+		return nil, err
+	}
+	_, err = p.expect(punctToken, "(")
+	if err != nil {
+		return nil, err
+	}
+	conts := make(map[uniqsym.ADT]termexp.ExpSpec)
+	seen := make(map[string]bool)
+	for {
+		labTok := p.peek()
+		labQN, err := p.parseQN()
+		if err != nil {
+			return nil, err
+		}
+		if seen[labQN.String()] {
+			return nil, &Error{labTok.pos, fmt.Sprintf("duplicate label %q", labQN)}
+		}
+		seen[labQN.String()] = true
+		_, err = p.expect(punctToken, "=>")
+		if err != nil {
+			return nil, err
+		}
+		cont, err := p.parseExp()
+		if err != nil {
+			return nil, err
+		}
+		conts[labQN] = cont
+		if !p.is(punctToken, "|") {
+			break
+		}
+		p.take()
+	}
+	_, err = p.expect(punctToken, ")")
+	if err != nil {
+		return nil, err
+	}
+	return termexp.CaseSpec{CommChnlPH: commPH, ContExps: conts}, nil
+}
+
+func (p *parser) parseChnlExp() (termexp.ExpSpec, *Error) {
+	chnlPH, err := p.parsePH()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case p.is(punctToken, "."):
+		// x.l ; P
+		p.take()
+		labQN, err := p.parseQN()
+		if err != nil {
+			return nil, err
+		}
+		cont, err := p.parseCont()
+		if err != nil {
+			return nil, err
+		}
+		return termexp.LabSpec{CommChnlPH: chnlPH, ValLabQN: labQN, ContExp: cont}, nil
+	case p.is(punctToken, "<->"):
+		p.take()
+		contPH, err := p.parsePH()
+		if err != nil {
+			return nil, err
+		}
+		return termexp.FwdSpec{CommChnlPH: chnlPH, ContChnlPH: contPH}, nil
+	case p.is(punctToken, "<-"):
+		p.take()
+	default:
+		return nil, errUnexpected(p.peek(), `"<-", "<->" or label`)
+	}
+	switch {
+	case p.is(keywordToken, "recv"):
+		p.take()
+		commPH, err := p.parsePH()
+		if err != nil {
+			return nil, err
+		}
+		cont, err := p.parseCont()
+		if err != nil {
+			return nil, err
+		}
+		return termexp.RecvSpec{CommChnlPH: commPH, NewChnlPH: chnlPH, ContExp: cont}, nil
+	case p.is(keywordToken, "call"), p.is(keywordToken, "spawn"):
+		kw := p.take()
+		procQN, err := p.parseQN()
+		if err != nil {
+			return nil, err
+		}
+		valPHs := p.parsePHs()
+		cont, err := p.parseCont()
+		if err != nil {
+			return nil, err
+		}
+		if kw.text == "call" {
+			return termexp.CallSpec{NewChnlPH: chnlPH, ProcTermQN: procQN, ValChnlPHs: valPHs, ContExp: cont}, nil
+		}
+		return termexp.SpawnSpec{CommChnlPH: chnlPH, ProcTermQN: procQN, NewChnlPHs: valPHs, ContExp: cont}, nil
+	default:
+		procQN, err := p.parseQN()
+		if err != nil {
+			return nil, err
+		}
+		valPHs := p.parsePHs()
+		return termexp.LinkSpec{CommChnlPH: chnlPH, ProcTermQN: procQN, ValChnlPHs: valPHs}, nil
+	}
+}
+
+func (p *parser) parseCont() (termexp.ExpSpec, *Error) {
+	_, err := p.expect(punctToken, ";")
+	if err != nil {
+		return nil, err
+	}
+	return p.parseExp()
+}
+
+func (p *parser) parseQN() (uniqsym.ADT, *Error) {
+	tok := p.peek()
+	if tok.kind != identToken {
+		return uniqsym.ADT{}, errUnexpected(tok, "qualified name")
+	}
+	p.take()
+	// сегменты пишутся слитно: "a.b", но не "a . b"
+	var text strings.Builder
+	text.WriteString(tok.text)
+	for last := tok; p.is(punctToken, ".") && last.adjoins(p.peek()); {
+		dot := p.take()
+		last = p.peek()
+		if last.kind != identToken || !dot.adjoins(last) {
+			return uniqsym.ADT{}, errUnexpected(last, "name segment")
+		}
+		p.take()
+		text.WriteString(".")
+		text.WriteString(last.text)
+	}
+	qn, err := uniqsym.ConvertFromString(text.String())
+	if err != nil {
+		return uniqsym.ADT{}, &Error{tok.pos, err.Error()}
+	}
+	return qn, nil
+}
+
+func (p *parser) parsePH() (symbol.ADT, *Error) {
+	tok := p.peek()
+	if tok.kind != identToken {
+		return symbol.Zero, errUnexpected(tok, "channel name")
+	}
+	p.take()
+	return symbol.New(tok.text), nil
+}
+
+// имена каналов до конца выражения
+func (p *parser) parsePHs() []symbol.ADT {
+	var phs []symbol.ADT
+	for p.peek().kind == identToken {
+		phs = append(phs, symbol.New(p.take().text))
+	}
+	return phs
+}
@@ -0,0 +1,200 @@
+package syntax
+
+import (
+	"reflect"
+	"testing"
+
+	"orglang/go-engine/adt/symbol"
+	"orglang/go-engine/adt/uniqsym"
+	"orglang/go-engine/proc/termexp"
+)
+
+const sample = `
+% разделяемый счетчик
+type counter = /\ &{inc : \/ counter, get : nat * \/ counter}
+type nat = +{zero : 1, succ : nat}
+type pair = (nat -o nat) * 1
+
+decl zero : |- (n : nat)
+decl succ : (m : nat) |- (n : nat)
+
+proc zero =
+  n.zero ;
+  close n
+
+proc succ =
+  n.succ ;
+  n <-> m
+
+proc server =
+  accept c ;
+  case c (
+    inc =>
+      detach c ;
+      c <- server
+  | get =>
+      z <- call zero ;
+      send c z
+  )
+
+proc client =
+  acquire c ;
+  c.inc ;
+  release c ;
+  x <- recv y ;
+  w <- spawn succ x ;
+  wait w ;
+  close y
+`
+
+func TestParseModuleRoundTrip(t *testing.T) {
+	mod, err := ParseModule(sample)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mod.TypeDefs) != 3 || len(mod.TermDecs) != 2 || len(mod.TermDefs) != 4 {
+		t.Fatalf("got %v types, %v decs, %v defs", len(mod.TypeDefs), len(mod.TermDecs), len(mod.TermDefs))
+	}
+	if mod.TypeDefs[0].Pos != (Pos{Line: 3, Col: 1}) {
+		t.Errorf("got pos %v, want 3:1", mod.TypeDefs[0].Pos)
+	}
+	printed := PrintModule(mod)
+	again, err := ParseModule(printed)
+	if err != nil {
+		t.Fatalf("reparse failed: %v\n%v", err, printed)
+	}
+	for i := range mod.TypeDefs {
+		if !reflect.DeepEqual(mod.TypeDefs[i].Spec, again.TypeDefs[i].Spec) {
+			t.Errorf("type %v differs after round trip", i)
+		}
+	}
+	for i := range mod.TermDecs {
+		if !reflect.DeepEqual(mod.TermDecs[i].Spec, again.TermDecs[i].Spec) {
+			t.Errorf("dec %v differs after round trip", i)
+		}
+	}
+	for i := range mod.TermDefs {
+		if !reflect.DeepEqual(mod.TermDefs[i].Spec, again.TermDefs[i].Spec) {
+			t.Errorf("def %v differs after round trip", i)
+		}
+	}
+	if PrintModule(again) != printed {
+		t.Errorf("printing not stable:\n%v", PrintModule(again))
+	}
+}
+
+func TestPrintType(t *testing.T) {
+	var tests = []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"right assoc", "a * b * c", "a * b * c"},
+		{"left nested", "(a -o b) * c", "(a -o b) * c"},
+		{"redundant parens", "a * (b -o c)", "a * b -o c"},
+		{"shift", `/\ (a * b)`, `/\ (a * b)`},
+		{"sorted labels", "+{b : 1, a : 1}", "+{a : 1, b : 1}"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			spec, err := ParseType(test.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := PrintType(spec)
+			if got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseModuleError(t *testing.T) {
+	var tests = []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"missing eq", "type a 1", `1:8: unexpected "1", want "="`},
+		{"bad char", "type a = #", `1:10: unexpected character '#'`},
+		{"duplicate label", "type a = +{l : 1, l : 1}", `1:19: duplicate label "l"`},
+		{"missing cont", "proc p =\n  wait x\n", `3:1: unexpected end of input, want ";"`},
+		{"spaced name", "type a = b . c", `1:12: unexpected ".", want "type", "decl" or "proc"`},
+		{"dotted chnl", "proc p = a.b <- q", `1:14: unexpected "<-", want ";"`},
+		{"two errors", "type a = \ntype b = 1\ndecl c : |- (x)", "2:1: unexpected \"type\", want type expression\n3:15: unexpected \")\", want \":\""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseModule(test.src)
+			if err == nil {
+				t.Fatal("got nil error")
+			}
+			if err.Error() != test.want {
+				t.Errorf("got %v, want %v", err, test.want)
+			}
+		})
+	}
+}
+
+func TestParseExpDotted(t *testing.T) {
+	qn := func(s string) uniqsym.ADT {
+		q, err := uniqsym.ConvertFromString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return q
+	}
+	x, y := symbol.New("x"), symbol.New("y")
+	var tests = []struct {
+		name string
+		src  string
+		want termexp.ExpSpec
+	}{
+		{"qualified label", "x.a.b ; close x", termexp.LabSpec{CommChnlPH: x, ValLabQN: qn("a.b"), ContExp: termexp.CloseSpec{ContChnlPH: x}}},
+		{"spaced label", "x . a ; close x", termexp.LabSpec{CommChnlPH: x, ValLabQN: qn("a"), ContExp: termexp.CloseSpec{ContChnlPH: x}}},
+		{"qualified proc", "x <- lib.p y", termexp.LinkSpec{CommChnlPH: x, ProcTermQN: qn("lib.p"), ValChnlPHs: []symbol.ADT{y}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseExp(test.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestTermDefPosOf(t *testing.T) {
+	mod, err := ParseModule("proc p =\n  x.l ;\n  close x\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	def := mod.TermDefs[0]
+	if len(def.Nodes) != 2 {
+		t.Fatalf("got %v nodes, want 2", len(def.Nodes))
+	}
+	x := symbol.New("x")
+	if got := def.PosOf(termexp.CloseSpec{ContChnlPH: x}); got != (Pos{Line: 3, Col: 3}) {
+		t.Errorf("got close pos %v, want 3:3", got)
+	}
+	if got := def.PosOf(def.Spec.ProcES); got != (Pos{Line: 2, Col: 3}) {
+		t.Errorf("got lab pos %v, want 2:3", got)
+	}
+	if got := def.PosOf(termexp.CloseSpec{ContChnlPH: symbol.New("y")}); got != def.Pos {
+		t.Errorf("got foreign pos %v, want %v", got, def.Pos)
+	}
+}
+
+func TestParseFileError(t *testing.T) {
+	_, err := ParseFile("a.org", "type a = #")
+	if err == nil {
+		t.Fatal("got nil error")
+	}
+	want := `a.org:1:10: unexpected character '#'`
+	if err.Error() != want {
+		t.Errorf("got %v, want %v", err, want)
+	}
+}
@@ -0,0 +1,178 @@
+package syntax
+
+import (
+	"slices"
+	"strings"
+
+	"orglang/go-engine/adt/symbol"
+	"orglang/go-engine/adt/termvar"
+	"orglang/go-engine/adt/uniqsym"
+	"orglang/go-engine/proc/termdec"
+	"orglang/go-engine/proc/termdef"
+	"orglang/go-engine/proc/termexp"
+	"orglang/go-engine/proc/typedef"
+	"orglang/go-engine/proc/typeexp"
+)
+
+const indent = "  "
+
+// PrintModule renders module so that ParseModule reads it back
+func PrintModule(mod Module) string {
+	var sb strings.Builder
+	for _, def := range mod.TypeDefs {
+		sb.WriteString(PrintTypeDef(def.Spec))
+		sb.WriteString("\n\n")
+	}
+	for _, dec := range mod.TermDecs {
+		sb.WriteString(PrintTermDec(dec.Spec))
+		sb.WriteString("\n\n")
+	}
+	for _, def := range mod.TermDefs {
+		sb.WriteString(PrintTermDef(def.Spec))
+		sb.WriteString("\n\n")
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+func PrintTypeDef(spec typedef.DefSpec) string {
+	return "type " + uniqsym.ConvertToString(spec.TypeQN) + " = " + PrintType(spec.TypeExp)
+}
+
+func PrintTermDec(spec termdec.DecSpec) string {
+	var sb strings.Builder
+	sb.WriteString("decl ")
+	sb.WriteString(uniqsym.ConvertToString(spec.TermQN))
+	sb.WriteString(" :")
+	for _, assetVar := range spec.AssetVars {
+		sb.WriteString(" ")
+		sb.WriteString(printVar(assetVar))
+	}
+	sb.WriteString(" |- ")
+	sb.WriteString(printVar(spec.LiabVar))
+	return sb.String()
+}
+
+func PrintTermDef(spec termdef.DefSpec) string {
+	return "proc " + uniqsym.ConvertToString(spec.ProcQN) + " =\n" + indent + printExp(spec.ProcES, indent)
+}
+
+func printVar(spec termvar.VarSpec) string {
+	return "(" + symbol.ConvertToString(spec.ChnlPH) + " : " + uniqsym.ConvertToString(spec.TypeQN) + ")"
+}
+
+// PrintType renders type with minimal parentheses
+func PrintType(s typeexp.ExpSpec) string {
+	switch spec := s.(type) {
+	case typeexp.TensorSpec:
+		return printOperand(spec.Val) + " * " + PrintType(spec.Cont)
+	case typeexp.LolliSpec:
+		return printOperand(spec.Val) + " -o " + PrintType(spec.Cont)
+	default:
+		return printOperand(s)
+	}
+}
+
+// произведения правоассоциативны, слева требуются скобки
+func printOperand(s typeexp.ExpSpec) string {
+	switch spec := s.(type) {
+	case typeexp.OneSpec:
+		return "1"
+	case typeexp.LinkSpec:
+		return uniqsym.ConvertToString(spec.TypeQN)
+	case typeexp.PlusSpec:
+		return "+" + printChoices(spec.Choices)
+	case typeexp.WithSpec:
+		return "&" + printChoices(spec.Choices)
+	case typeexp.UpSpec:
+		return `/\ ` + printOperand(spec.Cont)
+	case typeexp.DownSpec:
+		return `\/ ` + printOperand(spec.Cont)
+	case typeexp.TensorSpec, typeexp.LolliSpec:
+		return "(" + PrintType(s) + ")"
+	default:
+		panic(typeexp.ErrSpecTypeUnexpected(s))
+	}
+}
+
+func printChoices(choices map[uniqsym.ADT]typeexp.ExpSpec) string {
+	parts := make([]string, 0, len(choices))
+	for _, lab := range sortedLabs(choices) {
+		parts = append(parts, uniqsym.ConvertToString(lab)+" : "+PrintType(choices[lab]))
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+// PrintExp renders process expression one action per line
+func PrintExp(s termexp.ExpSpec) string {
+	return printExp(s, "")
+}
+
+func printExp(s termexp.ExpSpec, ind string) string {
+	ph := symbol.ConvertToString
+	qn := uniqsym.ConvertToString
+	seq := func(head string, cont termexp.ExpSpec) string {
+		return head + " ;\n" + ind + printExp(cont, ind)
+	}
+	switch spec := s.(type) {
+	case termexp.CloseSpec:
+		return "close " + ph(spec.ContChnlPH)
+	case termexp.WaitSpec:
+		return seq("wait "+ph(spec.ContChnlPH), spec.ContExp)
+	case termexp.SendSpec:
+		return "send " + ph(spec.CommChnlPH) + " " + ph(spec.ValChnlPH)
+	case termexp.RecvSpec:
+		return seq(ph(spec.NewChnlPH)+" <- recv "+ph(spec.CommChnlPH), spec.ContExp)
+	case termexp.LabSpec:
+		return seq(ph(spec.CommChnlPH)+"."+qn(spec.ValLabQN), spec.ContExp)
+	case termexp.CaseSpec:
+		var sb strings.Builder
+		sb.WriteString("case " + ph(spec.CommChnlPH) + " (\n")
+		for i, lab := range sortedLabs(spec.ContExps) {
+			sep := indent
+			if i > 0 {
+				sep = "| "
+			}
+			contInd := ind + indent + indent
+			sb.WriteString(ind + sep + qn(lab) + " =>\n" + contInd + printExp(spec.ContExps[lab], contInd) + "\n")
+		}
+		sb.WriteString(ind + ")")
+		return sb.String()
+	case termexp.FwdSpec:
+		return ph(spec.CommChnlPH) + " <-> " + ph(spec.ContChnlPH)
+	case termexp.LinkSpec:
+		return ph(spec.CommChnlPH) + " <- " + qn(spec.ProcTermQN) + printPHs(spec.ValChnlPHs)
+	case termexp.CallSpec:
+		return seq(ph(spec.NewChnlPH)+" <- call "+qn(spec.ProcTermQN)+printPHs(spec.ValChnlPHs), spec.ContExp)
+	case termexp.SpawnSpec:
+		return seq(ph(spec.CommChnlPH)+" <- spawn "+qn(spec.ProcTermQN)+printPHs(spec.NewChnlPHs), spec.ContExp)
+	case termexp.AcqureSpec:
+		return seq("acquire "+ph(spec.CommChnlPH), spec.ContExp)
+	case termexp.AcceptSpec:
+		return seq("accept "+ph(spec.CommChnlPH), spec.ContExp)
+	case termexp.DetachSpec:
+		return seq("detach "+ph(spec.CommChnlPH), spec.ContExp)
+	case termexp.ReleaseSpec:
+		return seq("release "+ph(spec.CommChnlPH), spec.ContExp)
+	default:
+		panic(termexp.ErrExpTypeUnexpected(s))
+	}
+}
+
+func printPHs(phs []symbol.ADT) string {
+	var sb strings.Builder
+	for _, ph := range phs {
+		sb.WriteString(" " + symbol.ConvertToString(ph))
+	}
+	return sb.String()
+}
+
+func sortedLabs[T any](choices map[uniqsym.ADT]T) []uniqsym.ADT {
+	labs := make([]uniqsym.ADT, 0, len(choices))
+	for lab := range choices {
+		labs = append(labs, lab)
+	}
+	slices.SortFunc(labs, func(a, b uniqsym.ADT) int {
+		return strings.Compare(uniqsym.ConvertToString(a), uniqsym.ConvertToString(b))
+	})
+	return labs
+}
@@ -8,11 +8,12 @@ import (
 
 	"orglang/go-engine/lib/db"
 
+	"orglang/go-engine/adt/descsem"
 	"orglang/go-engine/adt/identity"
 	"orglang/go-engine/adt/termsem"
 	"orglang/go-engine/adt/termvar"
-	"orglang/go-engine/adt/typesem"
 	"orglang/go-engine/adt/uniqsym"
+	"orglang/go-engine/adt/valkey"
 
 	"orglang/go-engine/proc/typedef"
 )
@@ -49,7 +50,7 @@ type DecSnap struct {
 type service struct {
 	termDecRepo Repo
 	typeDefRepo typedef.Repo
-	typeSemRepo typesem.Repo
+	descSemRepo descsem.Repo
 	operator    db.Operator
 	log         *slog.Logger
 }
@@ -62,11 +63,11 @@ func newAPI() API {
 func newService(
 	termDecRepo Repo,
 	typeDefRepo typedef.Repo,
-	typeSemRepo typesem.Repo,
+	descSemRepo descsem.Repo,
 	operator db.Operator,
 	log *slog.Logger,
 ) *service {
-	return &service{termDecRepo, typeDefRepo, typeSemRepo, operator, log}
+	return &service{termDecRepo, typeDefRepo, descSemRepo, operator, log}
 }
 
 func (s *service) Incept(termQN uniqsym.ADT) (_ termsem.SemRef, err error) {
@@ -97,27 +98,34 @@ func (s *service) Create(spec DecSpec) (_ DecSnap, err error) {
 	for _, spec := range spec.AssetVars {
 		assetQNs = append(assetQNs, spec.TypeQN)
 	}
-	var typeRefs map[uniqsym.ADT]typesem.SemRef
+	var typeDefs map[uniqsym.ADT]typedef.DefRec
 	getErr := s.operator.Implicit(ctx, func(ds db.Source) error {
-		typeRefs, err = s.typeSemRepo.GetRefsByQNs(ds, append(assetQNs, spec.LiabVar.TypeQN))
+		typeDefs, err = s.typeDefRepo.SelectEnv(ds, append(assetQNs, spec.LiabVar.TypeQN))
 		return err
 	})
 	if getErr != nil {
 		return DecSnap{}, getErr
 	}
 	newLiabVar := termvar.VarRec{
-		TypeRef: typeRefs[spec.LiabVar.TypeQN],
+		TypeRef: typeDefs[spec.LiabVar.TypeQN].TypeRef,
 		ChnlPH:  spec.LiabVar.ChnlPH,
+		ExpVK:   typeDefs[spec.LiabVar.TypeQN].ExpVK,
 	}
 	newAssetVars := make([]termvar.VarRec, 0, len(spec.AssetVars))
 	for _, assetVar := range spec.AssetVars {
 		newAssetVars = append(newAssetVars, termvar.VarRec{
-			TypeRef: typeRefs[assetVar.TypeQN],
+			TypeRef: typeDefs[assetVar.TypeQN].TypeRef,
 			ChnlPH:  assetVar.ChnlPH,
+			ExpVK:   typeDefs[assetVar.TypeQN].ExpVK,
 		})
 	}
 	newDec := DecRec{TermRef: termsem.New(), TermQN: spec.TermQN, LiabVar: newLiabVar, AssetVars: newAssetVars}
+	newBind := descsem.SemRec{DescQN: spec.TermQN, DescID: newDec.TermRef.TermID, Kind: descsem.TermKind}
 	transactErr := s.operator.Explicit(ctx, func(ds db.Source) error {
+		err = s.descSemRepo.AddRec(ds, newBind)
+		if err != nil {
+			return err
+		}
 		return s.termDecRepo.AddRec(ds, newDec)
 	})
 	if transactErr != nil {
@@ -154,10 +162,22 @@ func (s *service) RetreiveRefs() (refs []termsem.SemRef, err error) {
 	return refs, nil
 }
 
-func CollectEnv(recs iter.Seq[DecRec]) []uniqsym.ADT {
-	return []uniqsym.ADT{}
+// собирает ключи типов всех переменных деклараций
+func CollectEnv(recs iter.Seq[DecRec]) []valkey.ADT {
+	expVKs := []valkey.ADT{}
+	for r := range recs {
+		expVKs = append(expVKs, r.LiabVar.ExpVK)
+		for _, assetVar := range r.AssetVars {
+			expVKs = append(expVKs, assetVar.ExpVK)
+		}
+	}
+	return expVKs
 }
 
 func ErrRootMissingInEnv(rid identity.ADT) error {
 	return fmt.Errorf("root missing in env: %v", rid)
 }
+
+func ErrSymMissingInEnv(want uniqsym.ADT) error {
+	return fmt.Errorf("dec missing in env: %v", want)
+}
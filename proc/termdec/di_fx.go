@@ -1,27 +1,40 @@
 package termdec
 
 import (
+	"log/slog"
+
 	"go.uber.org/fx"
 
-	"orglang/go-engine/adt/typesem"
+	"orglang/go-engine/adt/descsem"
+	"orglang/go-engine/lib/db"
 	"orglang/go-engine/lib/te"
+	"orglang/go-engine/proc/typedef"
 )
 
 var Module = fx.Module("proc/termdec",
 	fx.Provide(
 		fx.Annotate(newService, fx.As(new(API))),
-		fx.Annotate(newPgxDAO, fx.As(new(Repo))),
+		fx.Annotate(newMcDAO, fx.ParamTags(`name:"dialect"`), fx.As(new(Repo))),
 	),
 	fx.Provide(
 		fx.Private,
+		fx.Annotate(newDialectDAO, fx.ResultTags(`name:"dialect"`)),
 		newEchoController,
 		newEchoPresenter,
 		fx.Annotate(newSQLBuilder, fx.As(new(queryBuilder))),
 		fx.Annotate(newRendererStdlib, fx.As(new(te.Renderer))),
-		fx.Annotate(typesem.NewPgxDAO(typeDefs, descBinds), fx.As(new(typesem.Repo))),
+		descsem.NewDialectDAO(descBinds),
 	),
 	fx.Invoke(
 		cfgEchoController,
 		cfgEchoPresenter,
 	),
 )
+
+// NewMemAPI собирает сервис над хранилищем в памяти для тестов
+// исполнителя, которым нужны объявления процессов
+func NewMemAPI(operator db.Operator, log *slog.Logger) API {
+	typeDefRepo := typedef.NewMemRepo(log)
+	descSemRepo := descsem.NewDialectDAO(descBinds)(db.Memory, log)
+	return newService(newMemDAO(log), typeDefRepo, descSemRepo, operator, log)
+}
@@ -1,11 +1,14 @@
 package termdec
 
 import (
+	"log/slog"
+
 	"orglang/go-engine/lib/db"
 
 	"orglang/go-engine/adt/identity"
 	"orglang/go-engine/adt/termsem"
 	"orglang/go-engine/adt/termvar"
+	"orglang/go-engine/adt/uniqsym"
 )
 
 type Repo interface {
@@ -13,7 +16,15 @@ type Repo interface {
 	GetRefs(db.Source) ([]termsem.SemRef, error)
 	GetSnap(db.Source, termsem.SemRef) (DecSnap, error)
 	GetRecs(db.Source, []identity.ADT) ([]DecRec, error)
-	SelectEnv(db.Source, []identity.ADT) (map[identity.ADT]DecRec, error)
+	SelectEnv(db.Source, []uniqsym.ADT) (map[uniqsym.ADT]DecRec, error)
+}
+
+// хранилищу в памяти запросы не нужны, поэтому DAO выбирается по диалекту
+func newDialectDAO(dialect db.Dialect, qb queryBuilder, log *slog.Logger) Repo {
+	if dialect == db.Memory {
+		return newMemDAO(log)
+	}
+	return newPgxDAO(qb, log)
 }
 
 type decRecDS struct {
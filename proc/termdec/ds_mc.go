@@ -0,0 +1,66 @@
+package termdec
+
+import (
+	"orglang/go-engine/lib/db"
+	"orglang/go-engine/lib/mc"
+
+	"orglang/go-engine/adt/identity"
+	"orglang/go-engine/adt/termsem"
+	"orglang/go-engine/adt/uniqsym"
+)
+
+// Adapter
+//
+// объявления не получают новых ревизий, поэтому кэш
+// не требует инвалидации
+type mcDAO struct {
+	repo Repo
+	decs *mc.Cache[string, DecRec]
+}
+
+func newMcDAO(repo Repo, limits mc.Limits) *mcDAO {
+	return &mcDAO{repo, mc.New[string, DecRec]("proc_term_decs", limits)}
+}
+
+func (dao *mcDAO) AddRec(source db.Source, rec DecRec) error {
+	return dao.repo.AddRec(source, rec)
+}
+
+func (dao *mcDAO) GetRefs(source db.Source) ([]termsem.SemRef, error) {
+	return dao.repo.GetRefs(source)
+}
+
+func (dao *mcDAO) GetSnap(source db.Source, ref termsem.SemRef) (DecSnap, error) {
+	return dao.repo.GetSnap(source, ref)
+}
+
+func (dao *mcDAO) GetRecs(source db.Source, ids []identity.ADT) ([]DecRec, error) {
+	return dao.repo.GetRecs(source, ids)
+}
+
+func (dao *mcDAO) SelectEnv(source db.Source, termQNs []uniqsym.ADT) (map[uniqsym.ADT]DecRec, error) {
+	env := make(map[uniqsym.ADT]DecRec, len(termQNs))
+	var missedQNs []uniqsym.ADT
+	for _, termQN := range termQNs {
+		rec, ok := dao.decs.Get(uniqsym.ConvertToString(termQN))
+		if !ok {
+			missedQNs = append(missedQNs, termQN)
+			continue
+		}
+		rec.TermQN = termQN
+		env[termQN] = rec
+	}
+	if len(missedQNs) == 0 {
+		return env, nil
+	}
+	gen := dao.decs.Gen()
+	missedEnv, err := dao.repo.SelectEnv(source, missedQNs)
+	if err != nil {
+		return nil, err
+	}
+	for termQN, rec := range missedEnv {
+		dao.decs.Put(gen, uniqsym.ConvertToString(termQN), rec)
+		env[termQN] = rec
+	}
+	return env, nil
+}
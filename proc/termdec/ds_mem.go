@@ -0,0 +1,117 @@
+package termdec
+
+import (
+	"log/slog"
+	"reflect"
+
+	"orglang/go-engine/lib/db"
+	"orglang/go-engine/lib/lf"
+
+	"orglang/go-engine/adt/descsem"
+	"orglang/go-engine/adt/identity"
+	"orglang/go-engine/adt/termsem"
+	"orglang/go-engine/adt/uniqsym"
+)
+
+// Adapter
+type memDAO struct {
+	log *slog.Logger
+}
+
+func newMemDAO(log *slog.Logger) *memDAO {
+	name := slog.String("name", reflect.TypeFor[memDAO]().Name())
+	return &memDAO{log.With(name)}
+}
+
+// NewMemRepo отдает хранилище в памяти тестам соседних модулей,
+// которые собирают сервисы без fx
+func NewMemRepo(log *slog.Logger) Repo {
+	return newMemDAO(log)
+}
+
+func (dao *memDAO) AddRec(source db.Source, rec DecRec) error {
+	ds := db.MustConform[db.SourceMem](source)
+	refAttr := slog.Any("ref", rec.TermRef)
+	dto, err := DataFromDecRec(rec)
+	if err != nil {
+		dao.log.Error("model conversion failed", refAttr)
+		return err
+	}
+	err = db.TableOf[string, decRecDS](ds, termDecs).Insert(dto.TermID, dto)
+	if err != nil {
+		dao.log.Error("insertion failed", refAttr)
+		return err
+	}
+	return nil
+}
+
+func (dao *memDAO) GetSnap(source db.Source, ref termsem.SemRef) (DecSnap, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	refAttr := slog.Any("ref", ref)
+	dto, err := db.TableOf[string, decRecDS](ds, termDecs).Find(ref.TermID.String())
+	if err != nil {
+		dao.log.Error("row selection failed", refAttr)
+		return DecSnap{}, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entitiy selection succeed", slog.Any("dto", dto))
+	return DataToDecSnap(decSnapDS(dto))
+}
+
+func (dao *memDAO) SelectEnv(source db.Source, termQNs []uniqsym.ADT) (map[uniqsym.ADT]DecRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "getting started", slog.Any("qns", termQNs))
+	binds := db.TableOf[string, descsem.SemRecDS](ds, descBinds)
+	decs := db.TableOf[string, decRecDS](ds, termDecs)
+	env := make(map[uniqsym.ADT]DecRec, len(termQNs))
+	for _, termQN := range termQNs {
+		qnAttr := slog.Any("qn", termQN)
+		bindDTO, err := binds.Find(uniqsym.ConvertToString(termQN))
+		if err != nil {
+			dao.log.Error("row selection failed", qnAttr)
+			return nil, err
+		}
+		dto, err := decs.Find(bindDTO.DescID)
+		if err != nil {
+			dao.log.Error("row selection failed", qnAttr)
+			return nil, err
+		}
+		rec, err := DataToDecRec(dto)
+		if err != nil {
+			dao.log.Error("model conversion failed", qnAttr)
+			return nil, err
+		}
+		rec.TermQN = termQN
+		env[termQN] = rec
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "getting succeed", slog.Any("qns", termQNs))
+	return env, nil
+}
+
+func (dao *memDAO) GetRecs(source db.Source, ids []identity.ADT) ([]DecRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	decs := db.TableOf[string, decRecDS](ds, termDecs)
+	dtos := make([]decRecDS, 0, len(ids))
+	for _, rid := range ids {
+		if rid.IsEmpty() {
+			return nil, identity.ErrEmpty
+		}
+		dto, err := decs.Find(rid.String())
+		if err != nil {
+			dao.log.Error("row selection failed", slog.Any("id", rid))
+			return nil, err
+		}
+		dtos = append(dtos, dto)
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "selection succeed", slog.Any("dtos", dtos))
+	return DataToDecRecs(dtos)
+}
+
+func (dao *memDAO) GetRefs(source db.Source) ([]termsem.SemRef, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	decDTOs := db.TableOf[string, decRecDS](ds, termDecs).Rows()
+	dtos := make([]termsem.SemRefDS, 0, len(decDTOs))
+	for _, dto := range decDTOs {
+		dtos = append(dtos, termsem.SemRefDS{TermID: dto.TermID, TermRN: dto.TermRN})
+	}
+	return termsem.DataToRefs(dtos)
+}
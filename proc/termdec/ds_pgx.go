@@ -12,6 +12,7 @@ import (
 
 	"orglang/go-engine/adt/identity"
 	"orglang/go-engine/adt/termsem"
+	"orglang/go-engine/adt/uniqsym"
 )
 
 type pgxDAO struct {
@@ -64,15 +65,43 @@ func (dao *pgxDAO) GetSnap(source db.Source, ref termsem.SemRef) (DecSnap, error
 	return DataToDecSnap(dto)
 }
 
-func (dao *pgxDAO) SelectEnv(source db.Source, ids []identity.ADT) (map[identity.ADT]DecRec, error) {
-	decs, err := dao.GetRecs(source, ids)
-	if err != nil {
-		return nil, err
+func (dao *pgxDAO) SelectEnv(source db.Source, termQNs []uniqsym.ADT) (_ map[uniqsym.ADT]DecRec, err error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "getting started", slog.Any("qns", termQNs))
+	if len(termQNs) == 0 {
+		return map[uniqsym.ADT]DecRec{}, nil
 	}
-	env := make(map[identity.ADT]DecRec, len(decs))
-	for _, dec := range decs {
-		env[dec.TermRef.TermID] = dec
+	batch := pgx.Batch{}
+	for _, termQN := range termQNs {
+		sql, args := dao.qb.selectRecByQN(uniqsym.ConvertToString(termQN))
+		batch.Queue(sql, args...)
+	}
+	br := ds.Conn.SendBatch(ds.Ctx, &batch)
+	defer func() {
+		err = errors.Join(err, br.Close())
+	}()
+	env := make(map[uniqsym.ADT]DecRec, len(termQNs))
+	for _, termQN := range termQNs {
+		qnAttr := slog.Any("qn", termQN)
+		rows, readErr := br.Query()
+		if readErr != nil {
+			dao.log.Error("query execution failed", qnAttr)
+			return nil, readErr
+		}
+		dto, scanErr := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[decRecDS])
+		if scanErr != nil {
+			dao.log.Error("row scanning failed", qnAttr)
+			return nil, db.ConvertNoRows(scanErr)
+		}
+		rec, convErr := DataToDecRec(dto)
+		if convErr != nil {
+			dao.log.Error("model conversion failed", qnAttr)
+			return nil, convErr
+		}
+		rec.TermQN = termQN
+		env[termQN] = rec
 	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "getting succeed", slog.Any("qns", termQNs))
 	return env, nil
 }
 
@@ -8,4 +8,5 @@ const (
 
 type queryBuilder interface {
 	insertRec(decRecDS) (string, []any)
+	selectRecByQN(string) (string, []any)
 }
@@ -21,3 +21,10 @@ func newSQLBuilder() *sqlBuilder {
 func (qb *sqlBuilder) insertRec(rec decRecDS) (string, []any) {
 	return qb.decBuilder.InsertInto(termDecs, rec).Build()
 }
+
+func (qb *sqlBuilder) selectRecByQN(qn string) (string, []any) {
+	sb := qb.decBuilder.SelectFrom(termDecs + "dec")
+	return sb.Join(descBinds+"bind", "bind.desc_id = dec.term_id").
+		Where(sb.Equal("bind.desc_qn", qn)).
+		Build()
+}
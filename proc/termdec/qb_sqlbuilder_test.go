@@ -10,3 +10,9 @@ func TestInsertRec(t *testing.T) {
 	sql, _ := qb.insertRec(decRecDS{})
 	fmt.Println(sql)
 }
+
+func TestSelectRecByQN(t *testing.T) {
+	qb := newSQLBuilder()
+	sql, _ := qb.selectRecByQN("foo")
+	fmt.Println(sql)
+}
@@ -1,40 +1,50 @@
 package termdef
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 
 	"orglang/go-engine/lib/db"
 
 	"orglang/go-engine/adt/identity"
+	"orglang/go-engine/adt/implsem"
+	"orglang/go-engine/adt/seqnum"
 	"orglang/go-engine/adt/symbol"
-	"orglang/go-engine/adt/typesem"
+	"orglang/go-engine/adt/termsem"
 	"orglang/go-engine/adt/uniqsym"
 	"orglang/go-engine/proc/termexp"
 )
 
 type API interface {
-	Create(DefSpec) (typesem.SemRef, error)
-	Retrieve(identity.ADT) (DefRec, error)
+	Incept(uniqsym.ADT) (termsem.SemRef, error)
+	Create(DefSpec) (DefSnap, error)
+	Modify(DefSnap) (DefSnap, error)
+	RetrieveSnap(termsem.SemRef) (DefSnap, error)
+	RetreiveRefs() ([]termsem.SemRef, error)
 }
 
 type DefSpec struct {
-	ProcQN uniqsym.ADT // or dec.ProcID
+	ProcQN uniqsym.ADT
 	ProcES termexp.ExpSpec
 }
 
+// aka ExpDef
 type DefRec struct {
-	Ref typesem.SemRef
+	TermRef termsem.SemRef
+	ProcES  termexp.ExpSpec
 }
 
 type DefSnap struct {
-	Ref typesem.SemRef
+	TermRef termsem.SemRef
+	DefSpec DefSpec
 }
 
 type service struct {
-	procDefs Repo
-	operator db.Operator
-	log      *slog.Logger
+	termDefRepo Repo
+	implSemRepo implsem.Repo
+	operator    db.Operator
+	log         *slog.Logger
 }
 
 // for compilation purposes
@@ -43,19 +53,98 @@ func newAPI() API {
 }
 
 func newService(
-	procs Repo,
+	termDefRepo Repo,
+	implSemRepo implsem.Repo,
 	operator db.Operator,
-	l *slog.Logger,
+	log *slog.Logger,
 ) *service {
-	return &service{procs, operator, l}
+	return &service{termDefRepo, implSemRepo, operator, log}
 }
 
-func (s *service) Create(spec DefSpec) (typesem.SemRef, error) {
-	return typesem.SemRef{}, nil
+func (s *service) Incept(procQN uniqsym.ADT) (termsem.SemRef, error) {
+	snap, err := s.Create(DefSpec{ProcQN: procQN})
+	if err != nil {
+		return termsem.SemRef{}, err
+	}
+	return snap.TermRef, nil
 }
 
-func (s *service) Retrieve(recID identity.ADT) (DefRec, error) {
-	return DefRec{}, nil
+func (s *service) Create(spec DefSpec) (_ DefSnap, err error) {
+	ctx := context.Background()
+	qnAttr := slog.Any("qn", spec.ProcQN)
+	s.log.Debug("creation started", qnAttr, slog.Any("spec", spec))
+	newDef := DefRec{TermRef: termsem.New(), ProcES: spec.ProcES}
+	newBind := implsem.SemRec{ImplQN: spec.ProcQN, ImplID: newDef.TermRef.TermID, Kind: implsem.TermKind}
+	err = s.operator.Explicit(ctx, func(ds db.Source) error {
+		err = s.implSemRepo.AddRec(ds, newBind)
+		if err != nil {
+			return err
+		}
+		return s.termDefRepo.AddRec(ds, newDef)
+	})
+	if err != nil {
+		s.log.Error("creation failed", qnAttr)
+		return DefSnap{}, err
+	}
+	s.log.Debug("creation succeed", qnAttr, slog.Any("ref", newDef.TermRef))
+	return DefSnap{TermRef: newDef.TermRef, DefSpec: spec}, nil
+}
+
+func (s *service) Modify(snap DefSnap) (_ DefSnap, err error) {
+	ctx := context.Background()
+	refAttr := slog.Any("ref", snap.TermRef)
+	s.log.Debug("modification started", refAttr)
+	var newDef DefRec
+	// проверка ревизии и вставка следующей идут в одной транзакции,
+	// а одновременную вставку той же ревизии отсекает ключ (term_id, term_rn)
+	err = s.operator.Explicit(ctx, func(ds db.Source) error {
+		rec, err := s.termDefRepo.GetRecByRef(ds, snap.TermRef)
+		if err != nil {
+			return err
+		}
+		if snap.TermRef.TermRN != rec.TermRef.TermRN {
+			return errConcurrentModification(snap.TermRef.TermRN, rec.TermRef.TermRN)
+		}
+		// каждая ревизия хранится отдельной строкой
+		newDef = DefRec{
+			TermRef: termsem.SemRef{TermID: rec.TermRef.TermID, TermRN: seqnum.Next(rec.TermRef.TermRN)},
+			ProcES:  snap.DefSpec.ProcES,
+		}
+		return s.termDefRepo.AddRec(ds, newDef)
+	})
+	if err != nil {
+		s.log.Error("modification failed", refAttr)
+		return DefSnap{}, err
+	}
+	s.log.Debug("modification succeed", refAttr, slog.Any("rn", newDef.TermRef.TermRN))
+	snap.TermRef = newDef.TermRef
+	return snap, nil
+}
+
+func (s *service) RetrieveSnap(ref termsem.SemRef) (snap DefSnap, err error) {
+	ctx := context.Background()
+	err = s.operator.Implicit(ctx, func(ds db.Source) error {
+		snap, err = s.termDefRepo.GetSnap(ds, ref)
+		return err
+	})
+	if err != nil {
+		s.log.Error("retrieval failed", slog.Any("ref", ref))
+		return DefSnap{}, err
+	}
+	return snap, nil
+}
+
+func (s *service) RetreiveRefs() (refs []termsem.SemRef, err error) {
+	ctx := context.Background()
+	err = s.operator.Implicit(ctx, func(ds db.Source) error {
+		refs, err = s.termDefRepo.GetRefs(ds)
+		return err
+	})
+	if err != nil {
+		s.log.Error("retrieval failed")
+		return nil, err
+	}
+	return refs, nil
 }
 
 func ErrDoesNotExist(want identity.ADT) error {
@@ -73,3 +162,15 @@ func ErrMissingInCfg2(want identity.ADT) error {
 func ErrMissingInCtx(want symbol.ADT) error {
 	return fmt.Errorf("channel missing in ctx: %v", want)
 }
+
+func ErrSymMissingInEnv(want uniqsym.ADT) error {
+	return fmt.Errorf("def missing in env: %v", want)
+}
+
+func errConcurrentModification(got seqnum.ADT, want seqnum.ADT) error {
+	return fmt.Errorf("%w: want revision %v, got revision %v", db.ErrConcurrentModification, want, got)
+}
+
+func errRevisionTaken(ref termsem.SemRef) error {
+	return fmt.Errorf("%w: revision already taken %v", db.ErrConcurrentModification, ref)
+}
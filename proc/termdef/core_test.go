@@ -0,0 +1,51 @@
+package termdef
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"orglang/go-engine/lib/db"
+
+	"orglang/go-engine/adt/symbol"
+	"orglang/go-engine/adt/uniqsym"
+	"orglang/go-engine/proc/termexp"
+)
+
+func TestModifyStaleRev(t *testing.T) {
+	api := NewMemAPI(db.NewOperatorMem(), slog.New(slog.DiscardHandler))
+	snap, err := api.Create(DefSpec{
+		ProcQN: uniqsym.New(symbol.New("proc")),
+		ProcES: termexp.CloseSpec{ContChnlPH: symbol.New("x")},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	// оба изменения взяты с одной ревизии: второе должно отказать
+	_, err = api.Modify(snap)
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	_, err = api.Modify(snap)
+	if !errors.Is(err, db.ErrConcurrentModification) {
+		t.Fatalf("unexpected error: want %q, got %q", db.ErrConcurrentModification, err)
+	}
+}
+
+func TestAddRecTakenRevMem(t *testing.T) {
+	ctx := context.Background()
+	log := slog.New(slog.DiscardHandler)
+	operator := db.NewOperatorMem()
+	snap, err := NewMemAPI(operator, log).Create(DefSpec{ProcQN: uniqsym.New(symbol.New("proc"))})
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	// вставка уже занятой ревизии, как при гонке двух изменений
+	err = operator.Explicit(ctx, func(ds db.Source) error {
+		return newMemDAO(log).AddRec(ds, DefRec{TermRef: snap.TermRef})
+	})
+	if !errors.Is(err, db.ErrConcurrentModification) {
+		t.Fatalf("unexpected error: want %q, got %q", db.ErrConcurrentModification, err)
+	}
+}
@@ -1,11 +1,38 @@
 package termdef
 
 import (
+	"log/slog"
+
 	"go.uber.org/fx"
+
+	"orglang/go-engine/lib/db"
+	"orglang/go-engine/lib/te"
+
+	"orglang/go-engine/adt/implsem"
 )
 
 var Module = fx.Module("proc/termdef",
 	fx.Provide(
-		fx.Annotate(newPgxDAO, fx.As(new(Repo))),
+		fx.Annotate(newService, fx.As(new(API))),
+		newDialectDAO,
+	),
+	fx.Provide(
+		fx.Private,
+		newEchoController,
+		newEchoPresenter,
+		fx.Annotate(newSQLBuilder, fx.As(new(queryBuilder))),
+		fx.Annotate(newRendererStdlib, fx.As(new(te.Renderer))),
+		implsem.NewDialectDAO(implBinds),
+	),
+	fx.Invoke(
+		cfgEchoController,
+		cfgEchoPresenter,
 	),
 )
+
+// NewMemAPI собирает сервис над хранилищем в памяти для тестов
+// исполнителя, которым нужны определения процессов
+func NewMemAPI(operator db.Operator, log *slog.Logger) API {
+	implSemRepo := implsem.NewDialectDAO(implBinds)(db.Memory, log)
+	return newService(newMemDAO(log), implSemRepo, operator, log)
+}
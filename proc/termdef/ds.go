@@ -1,128 +1,43 @@
 package termdef
 
 import (
-	"orglang/go-engine/lib/db"
-)
-
-type Repo interface {
-	InsertProc(db.Source, DefRec) error
-}
+	"log/slog"
 
-type ExpRecDS struct {
-	K     expKind     `json:"k"`
-	Close *closeRecDS `json:"close,omitempty"`
-	Wait  *waitRecDS  `json:"wait,omitempty"`
-	Send  *sendRecDS  `json:"send,omitempty"`
-	Recv  *recvRecDS  `json:"recv,omitempty"`
-	Lab   *labRecDS   `json:"lab,omitempty"`
-	Case  *caseRecDS  `json:"case,omitempty"`
-	Fwd   *fwdRecDS   `json:"fwd,omitempty"`
-}
-
-type ExpSpecDS struct {
-	K     expKind      `json:"k"`
-	Close *closeSpecDS `json:"close,omitempty"`
-	Wait  *waitSpecDS  `json:"wait,omitempty"`
-	Send  *sendSpecDS  `json:"send,omitempty"`
-	Recv  *recvSpecDS  `json:"recv,omitempty"`
-	Lab   *labSpecDS   `json:"lab,omitempty"`
-	Case  *caseSpecDS  `json:"case,omitempty"`
-	Fwd   *fwdSpecDS   `json:"fwd,omitempty"`
-}
-
-type expKind int
+	"orglang/go-engine/lib/db"
 
-const (
-	unkKind expKind = iota
-	closeExp
-	waitExp
-	sendExp
-	recvExp
-	labExp
-	caseExp
-	linkExp
-	spawnExp
-	fwdExp
+	"orglang/go-engine/adt/termsem"
+	"orglang/go-engine/adt/uniqsym"
+	"orglang/go-engine/proc/termexp"
 )
 
-type closeSpecDS struct {
-	X string `json:"x"`
-}
-
-type closeRecDS struct {
-	X string `json:"x"`
-}
-
-type waitSpecDS struct {
-	X      string    `json:"x"`
-	ContES ExpSpecDS `json:"cont"`
-}
-
-type waitRecDS struct {
-	X      string    `json:"x"`
-	ContES ExpSpecDS `json:"cont"`
-}
-
-type sendSpecDS struct {
-	X string `json:"x"`
-	Y string `json:"y"`
-}
-
-type sendRecDS struct {
-	X string `json:"x"`
-	A string `json:"a"`
-	B string `json:"b"`
-}
-
-type recvSpecDS struct {
-	X      string    `json:"x"`
-	Y      string    `json:"y"`
-	ContES ExpSpecDS `json:"cont"`
-}
-
-type recvRecDS struct {
-	X      string    `json:"x"`
-	A      string    `json:"a"`
-	Y      string    `json:"y"`
-	ContES ExpSpecDS `json:"cont"`
-}
-
-type labSpecDS struct {
-	X     string `json:"x"`
-	Label string `json:"lab"`
-}
-
-type labRecDS struct {
-	X     string `json:"x"`
-	Label string `json:"lab"`
-}
-
-type caseSpecDS struct {
-	X        string         `json:"x"`
-	Branches []branchSpecDS `json:"brs"`
-}
-
-type caseRecDS struct {
-	X        string        `json:"x"`
-	Branches []branchRecDS `json:"brs"`
-}
-
-type branchSpecDS struct {
-	Label  string    `json:"lab"`
-	ContES ExpSpecDS `json:"cont"`
-}
-
-type branchRecDS struct {
-	Label  string    `json:"lab"`
-	ContES ExpSpecDS `json:"cont"`
-}
-
-type fwdSpecDS struct {
-	X string `json:"x"`
-	Y string `json:"y"`
-}
-
-type fwdRecDS struct {
-	X string `json:"x"`
-	B string `json:"b"`
+type Repo interface {
+	AddRec(db.Source, DefRec) error
+	GetRefs(db.Source) ([]termsem.SemRef, error)
+	GetRecByRef(db.Source, termsem.SemRef) (DefRec, error)
+	// GetRecAtRef возвращает определение в ревизии ссылки, а не последней
+	GetRecAtRef(db.Source, termsem.SemRef) (DefRec, error)
+	GetSnap(db.Source, termsem.SemRef) (DefSnap, error)
+	GetRecByQN(db.Source, uniqsym.ADT) (DefRec, error)
+	SelectEnv(db.Source, []uniqsym.ADT) (map[uniqsym.ADT]DefRec, error)
+}
+
+// хранилищу в памяти запросы не нужны, поэтому DAO выбирается по диалекту
+func newDialectDAO(dialect db.Dialect, qb queryBuilder, log *slog.Logger) Repo {
+	if dialect == db.Memory {
+		return newMemDAO(log)
+	}
+	return newPgxDAO(qb, log)
+}
+
+type defRecDS struct {
+	TermID string            `db:"term_id"`
+	TermRN int64             `db:"term_rn"`
+	ProcES termexp.ExpSpecDS `db:"proc_es" fieldopt:"noexpand"`
+}
+
+type defSnapDS struct {
+	TermID string            `db:"term_id"`
+	TermRN int64             `db:"term_rn"`
+	ProcQN string            `db:"impl_qn"`
+	ProcES termexp.ExpSpecDS `db:"proc_es"`
 }
@@ -0,0 +1,197 @@
+package termdef
+
+import (
+	"errors"
+	"log/slog"
+	"reflect"
+
+	"orglang/go-engine/lib/db"
+	"orglang/go-engine/lib/lf"
+
+	"orglang/go-engine/adt/implsem"
+	"orglang/go-engine/adt/termsem"
+	"orglang/go-engine/adt/uniqsym"
+)
+
+// Adapter
+//
+// ревизии определений хранятся по паре из идентификатора и номера ревизии
+type memDAO struct {
+	log *slog.Logger
+}
+
+func newMemDAO(log *slog.Logger) *memDAO {
+	name := slog.String("name", reflect.TypeFor[memDAO]().Name())
+	return &memDAO{log.With(name)}
+}
+
+// NewMemRepo отдает хранилище в памяти тестам соседних модулей,
+// которые собирают сервисы без fx
+func NewMemRepo(log *slog.Logger) Repo {
+	return newMemDAO(log)
+}
+
+func (dao *memDAO) AddRec(source db.Source, rec DefRec) error {
+	ds := db.MustConform[db.SourceMem](source)
+	refAttr := slog.Any("ref", rec.TermRef)
+	dto, convErr := DataFromDefRec(rec)
+	if convErr != nil {
+		dao.log.Error("model conversion failed", refAttr)
+		return convErr
+	}
+	key := termsem.SemRefDS{TermID: dto.TermID, TermRN: dto.TermRN}
+	insertErr := db.TableOf[termsem.SemRefDS, defRecDS](ds, termDefs).Insert(key, dto)
+	if errors.Is(insertErr, db.ErrDuplicateKey) {
+		dao.log.Error("insertion failed", refAttr)
+		return errRevisionTaken(rec.TermRef)
+	}
+	if insertErr != nil {
+		dao.log.Error("insertion failed", refAttr)
+		return insertErr
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "addition succeed", slog.Any("dto", dto))
+	return nil
+}
+
+func (dao *memDAO) GetRefs(source db.Source) ([]termsem.SemRef, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	lastDTOs := lastRevs(ds)
+	dtos := make([]termsem.SemRefDS, 0, len(lastDTOs))
+	for _, dto := range lastDTOs {
+		dtos = append(dtos, termsem.SemRefDS{TermID: dto.TermID, TermRN: dto.TermRN})
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "getting succeed", slog.Any("dtos", dtos))
+	return termsem.DataToRefs(dtos)
+}
+
+func (dao *memDAO) GetRecByRef(source db.Source, ref termsem.SemRef) (DefRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	refAttr := slog.Any("ref", ref)
+	dto, ok := lastRevs(ds)[ref.TermID.String()]
+	if !ok {
+		dao.log.Error("row selection failed", refAttr)
+		return DefRec{}, db.ErrNotFound
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "getting succeed", slog.Any("dto", dto))
+	rec, convErr := DataToDefRec(dto)
+	if convErr != nil {
+		dao.log.Error("model conversion failed", refAttr)
+		return DefRec{}, convErr
+	}
+	return rec, nil
+}
+
+func (dao *memDAO) GetRecAtRef(source db.Source, ref termsem.SemRef) (DefRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	refAttr := slog.Any("ref", ref)
+	dto, err := db.TableOf[termsem.SemRefDS, defRecDS](ds, termDefs).Find(termsem.DataFromRef(ref))
+	if err != nil {
+		dao.log.Error("row selection failed", refAttr)
+		return DefRec{}, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "getting succeed", slog.Any("dto", dto))
+	rec, convErr := DataToDefRec(dto)
+	if convErr != nil {
+		dao.log.Error("model conversion failed", refAttr)
+		return DefRec{}, convErr
+	}
+	return rec, nil
+}
+
+func (dao *memDAO) GetSnap(source db.Source, ref termsem.SemRef) (DefSnap, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	refAttr := slog.Any("ref", ref)
+	dto, ok := lastRevs(ds)[ref.TermID.String()]
+	if !ok {
+		dao.log.Error("row selection failed", refAttr)
+		return DefSnap{}, db.ErrNotFound
+	}
+	// как и в postgres, снепшот есть только у связанного определения
+	var bindDTO implsem.SemRecDS
+	for _, bind := range db.TableOf[string, implsem.SemRecDS](ds, implBinds).Rows() {
+		if bind.ImplID == dto.TermID {
+			bindDTO = bind
+			break
+		}
+	}
+	if bindDTO.ImplID == "" {
+		dao.log.Error("row selection failed", refAttr)
+		return DefSnap{}, db.ErrNotFound
+	}
+	snapDTO := defSnapDS{
+		TermID: dto.TermID,
+		TermRN: dto.TermRN,
+		ProcQN: bindDTO.ImplQN,
+		ProcES: dto.ProcES,
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "getting succeed", slog.Any("dto", snapDTO))
+	snap, convErr := DataToDefSnap(snapDTO)
+	if convErr != nil {
+		dao.log.Error("model conversion failed", refAttr)
+		return DefSnap{}, convErr
+	}
+	return snap, nil
+}
+
+func (dao *memDAO) GetRecByQN(source db.Source, qn uniqsym.ADT) (DefRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	qnAttr := slog.Any("qn", qn)
+	dto, selectErr := lastRevByQN(ds, uniqsym.ConvertToString(qn))
+	if selectErr != nil {
+		dao.log.Error("row selection failed", qnAttr)
+		return DefRec{}, selectErr
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "getting succeed", slog.Any("dto", dto))
+	rec, convErr := DataToDefRec(dto)
+	if convErr != nil {
+		dao.log.Error("model conversion failed", qnAttr)
+		return DefRec{}, convErr
+	}
+	return rec, nil
+}
+
+func (dao *memDAO) SelectEnv(source db.Source, procQNs []uniqsym.ADT) (map[uniqsym.ADT]DefRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "getting started", slog.Any("qns", procQNs))
+	env := make(map[uniqsym.ADT]DefRec, len(procQNs))
+	for _, procQN := range procQNs {
+		qnAttr := slog.Any("qn", procQN)
+		dto, selectErr := lastRevByQN(ds, uniqsym.ConvertToString(procQN))
+		if selectErr != nil {
+			dao.log.Error("row selection failed", qnAttr)
+			return nil, selectErr
+		}
+		rec, convErr := DataToDefRec(dto)
+		if convErr != nil {
+			dao.log.Error("model conversion failed", qnAttr)
+			return nil, convErr
+		}
+		env[procQN] = rec
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "getting succeed", slog.Any("qns", procQNs))
+	return env, nil
+}
+
+// последние ревизии всех определений по идентификатору
+func lastRevs(ds db.SourceMem) map[string]defRecDS {
+	dtos := make(map[string]defRecDS)
+	for _, dto := range db.TableOf[termsem.SemRefDS, defRecDS](ds, termDefs).Rows() {
+		last, ok := dtos[dto.TermID]
+		if !ok || last.TermRN < dto.TermRN {
+			dtos[dto.TermID] = dto
+		}
+	}
+	return dtos
+}
+
+func lastRevByQN(ds db.SourceMem, qn string) (defRecDS, error) {
+	bindDTO, err := db.TableOf[string, implsem.SemRecDS](ds, implBinds).Find(qn)
+	if err != nil {
+		return defRecDS{}, err
+	}
+	dto, ok := lastRevs(ds)[bindDTO.ImplID]
+	if !ok {
+		return defRecDS{}, db.ErrNotFound
+	}
+	return dto, nil
+}
@@ -1,26 +1,205 @@
 package termdef
 
 import (
+	"errors"
 	"log/slog"
-	"orglang/go-engine/lib/db"
 	"reflect"
+
+	"github.com/jackc/pgx/v5"
+
+	"orglang/go-engine/lib/db"
+	"orglang/go-engine/lib/lf"
+
+	"orglang/go-engine/adt/termsem"
+	"orglang/go-engine/adt/uniqsym"
 )
 
-// Adapter
 type pgxDAO struct {
+	qb  queryBuilder
 	log *slog.Logger
 }
 
+func newPgxDAO(qb queryBuilder, log *slog.Logger) *pgxDAO {
+	name := slog.String("name", reflect.TypeFor[pgxDAO]().Name())
+	return &pgxDAO{qb, log.With(name)}
+}
+
 // for compilation purposes
 func newRepo() Repo {
 	return new(pgxDAO)
 }
 
-func newPgxDAO(l *slog.Logger) *pgxDAO {
-	name := slog.String("name", reflect.TypeFor[pgxDAO]().Name())
-	return &pgxDAO{l.With(name)}
+func (dao *pgxDAO) AddRec(source db.Source, rec DefRec) error {
+	ds := db.MustConform[db.SourcePgx](source)
+	refAttr := slog.Any("ref", rec.TermRef)
+	dto, convErr := DataFromDefRec(rec)
+	if convErr != nil {
+		dao.log.Error("model conversion failed", refAttr)
+		return convErr
+	}
+	sql, args := dao.qb.insertRec(dto)
+	ct, execErr := ds.Conn.Exec(ds.Ctx, sql, args...)
+	if execErr != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("sql", sql))
+		return execErr
+	}
+	if ct.RowsAffected() == 0 {
+		dao.log.Error("insertion failed", refAttr)
+		return errRevisionTaken(rec.TermRef)
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "addition succeed", slog.Any("dto", dto))
+	return nil
 }
 
-func (dao *pgxDAO) InsertProc(db.Source, DefRec) error {
-	return nil
+func (dao *pgxDAO) GetRefs(source db.Source) ([]termsem.SemRef, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	sql, args := dao.qb.selectRefs()
+	rows, execErr := ds.Conn.Query(ds.Ctx, sql, args...)
+	if execErr != nil {
+		dao.log.Error("query execution failed", slog.String("sql", sql))
+		return nil, execErr
+	}
+	defer rows.Close()
+	dtos, scanErr := pgx.CollectRows(rows, pgx.RowToStructByName[termsem.SemRefDS])
+	if scanErr != nil {
+		dao.log.Error("rows scanning failed")
+		return nil, scanErr
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "getting succeed", slog.Any("dtos", dtos))
+	return termsem.DataToRefs(dtos)
+}
+
+func (dao *pgxDAO) GetRecByRef(source db.Source, ref termsem.SemRef) (DefRec, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	refAttr := slog.Any("ref", ref)
+	sql, args := dao.qb.selectRecByID(ref.TermID.String())
+	rows, execErr := ds.Conn.Query(ds.Ctx, sql, args...)
+	if execErr != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("sql", sql))
+		return DefRec{}, execErr
+	}
+	defer rows.Close()
+	dto, scanErr := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[defRecDS])
+	if scanErr != nil {
+		dao.log.Error("row scanning failed", refAttr)
+		return DefRec{}, scanErr
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "getting succeed", slog.Any("dto", dto))
+	rec, convErr := DataToDefRec(dto)
+	if convErr != nil {
+		dao.log.Error("model conversion failed", refAttr)
+		return DefRec{}, convErr
+	}
+	return rec, nil
+}
+
+func (dao *pgxDAO) GetRecAtRef(source db.Source, ref termsem.SemRef) (DefRec, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	refAttr := slog.Any("ref", ref)
+	sql, args := dao.qb.selectRecByRef(termsem.DataFromRef(ref))
+	rows, execErr := ds.Conn.Query(ds.Ctx, sql, args...)
+	if execErr != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("sql", sql))
+		return DefRec{}, execErr
+	}
+	defer rows.Close()
+	dto, scanErr := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[defRecDS])
+	if scanErr != nil {
+		dao.log.Error("row scanning failed", refAttr)
+		return DefRec{}, scanErr
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "getting succeed", slog.Any("dto", dto))
+	rec, convErr := DataToDefRec(dto)
+	if convErr != nil {
+		dao.log.Error("model conversion failed", refAttr)
+		return DefRec{}, convErr
+	}
+	return rec, nil
+}
+
+func (dao *pgxDAO) GetSnap(source db.Source, ref termsem.SemRef) (DefSnap, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	refAttr := slog.Any("ref", ref)
+	sql, args := dao.qb.selectSnapByID(ref.TermID.String())
+	rows, execErr := ds.Conn.Query(ds.Ctx, sql, args...)
+	if execErr != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("sql", sql))
+		return DefSnap{}, execErr
+	}
+	defer rows.Close()
+	dto, scanErr := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[defSnapDS])
+	if scanErr != nil {
+		dao.log.Error("row scanning failed", refAttr)
+		return DefSnap{}, scanErr
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "getting succeed", slog.Any("dto", dto))
+	snap, convErr := DataToDefSnap(dto)
+	if convErr != nil {
+		dao.log.Error("model conversion failed", refAttr)
+		return DefSnap{}, convErr
+	}
+	return snap, nil
+}
+
+func (dao *pgxDAO) GetRecByQN(source db.Source, qn uniqsym.ADT) (DefRec, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	qnAttr := slog.Any("qn", qn)
+	sql, args := dao.qb.selectRecByQN(uniqsym.ConvertToString(qn))
+	rows, execErr := ds.Conn.Query(ds.Ctx, sql, args...)
+	if execErr != nil {
+		dao.log.Error("query execution failed", qnAttr, slog.String("sql", sql))
+		return DefRec{}, execErr
+	}
+	defer rows.Close()
+	dto, scanErr := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[defRecDS])
+	if scanErr != nil {
+		dao.log.Error("row scanning failed", qnAttr)
+		return DefRec{}, db.ConvertNoRows(scanErr)
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "getting succeed", slog.Any("dto", dto))
+	rec, convErr := DataToDefRec(dto)
+	if convErr != nil {
+		dao.log.Error("model conversion failed", qnAttr)
+		return DefRec{}, convErr
+	}
+	return rec, nil
+}
+
+func (dao *pgxDAO) SelectEnv(source db.Source, procQNs []uniqsym.ADT) (_ map[uniqsym.ADT]DefRec, err error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "getting started", slog.Any("qns", procQNs))
+	if len(procQNs) == 0 {
+		return map[uniqsym.ADT]DefRec{}, nil
+	}
+	batch := pgx.Batch{}
+	for _, procQN := range procQNs {
+		sql, args := dao.qb.selectRecByQN(uniqsym.ConvertToString(procQN))
+		batch.Queue(sql, args...)
+	}
+	br := ds.Conn.SendBatch(ds.Ctx, &batch)
+	defer func() {
+		err = errors.Join(err, br.Close())
+	}()
+	env := make(map[uniqsym.ADT]DefRec, len(procQNs))
+	for _, procQN := range procQNs {
+		qnAttr := slog.Any("qn", procQN)
+		rows, readErr := br.Query()
+		if readErr != nil {
+			dao.log.Error("query execution failed", qnAttr)
+			return nil, readErr
+		}
+		dto, scanErr := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[defRecDS])
+		if scanErr != nil {
+			dao.log.Error("row scanning failed", qnAttr)
+			return nil, scanErr
+		}
+		rec, convErr := DataToDefRec(dto)
+		if convErr != nil {
+			dao.log.Error("model conversion failed", qnAttr)
+			return nil, convErr
+		}
+		env[procQN] = rec
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "getting succeed", slog.Any("qns", procQNs))
+	return env, nil
 }
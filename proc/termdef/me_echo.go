@@ -0,0 +1,109 @@
+package termdef
+
+import (
+	"log/slog"
+	"net/http"
+	"reflect"
+
+	"github.com/labstack/echo/v4"
+
+	sdk "github.com/orglang/go-sdk/adt/termsem"
+	"github.com/orglang/go-sdk/proc/termdef"
+
+	"orglang/go-engine/lib/lf"
+
+	"orglang/go-engine/adt/termsem"
+)
+
+// Server-side primary adapter
+type echoController struct {
+	api API
+	log *slog.Logger
+}
+
+func newEchoController(a API, l *slog.Logger) *echoController {
+	name := slog.String("name", reflect.TypeFor[echoController]().Name())
+	return &echoController{a, l.With(name)}
+}
+
+func cfgEchoController(e *echo.Echo, h *echoController) error {
+	e.POST("/api/v1/procs/defs", h.PostSpec)
+	e.GET("/api/v1/procs/defs/:id", h.GetSnap)
+	e.PATCH("/api/v1/procs/defs/:id", h.PatchOne)
+	return nil
+}
+
+func (h *echoController) PostSpec(c echo.Context) error {
+	var dto termdef.DefSpec
+	bindErr := c.Bind(&dto)
+	if bindErr != nil {
+		h.log.Error("binding failed", slog.Any("dto", reflect.TypeOf(dto)))
+		return bindErr
+	}
+	validateErr := dto.Validate()
+	if validateErr != nil {
+		h.log.Error("validation failed", slog.Any("dto", dto))
+		return validateErr
+	}
+	spec, convErr := MsgToDefSpec(dto)
+	if convErr != nil {
+		h.log.Error("conversion failed", slog.Any("dto", dto))
+		return convErr
+	}
+	snap, createErr := h.api.Create(spec)
+	if createErr != nil {
+		return createErr
+	}
+	return c.JSON(http.StatusCreated, MsgFromDefSnap(snap))
+}
+
+func (h *echoController) GetSnap(c echo.Context) error {
+	var dto sdk.SemRef
+	bindErr := c.Bind(&dto)
+	if bindErr != nil {
+		h.log.Error("binding failed", slog.Any("dto", reflect.TypeOf(dto)))
+		return bindErr
+	}
+	validateErr := dto.Validate()
+	if validateErr != nil {
+		h.log.Error("validation failed", slog.Any("dto", dto))
+		return validateErr
+	}
+	ref, convErr := termsem.MsgToRef(dto)
+	if convErr != nil {
+		h.log.Error("conversion failed", slog.Any("dto", dto))
+		return convErr
+	}
+	snap, retrieveErr := h.api.RetrieveSnap(ref)
+	if retrieveErr != nil {
+		return retrieveErr
+	}
+	return c.JSON(http.StatusOK, MsgFromDefSnap(snap))
+}
+
+func (h *echoController) PatchOne(c echo.Context) error {
+	var dto termdef.DefSnap
+	bindErr := c.Bind(&dto)
+	if bindErr != nil {
+		h.log.Error("binding failed", slog.Any("dto", reflect.TypeOf(dto)))
+		return bindErr
+	}
+	ctx := c.Request().Context()
+	h.log.Log(ctx, lf.LevelTrace, "patching started", slog.Any("dto", dto))
+	validateErr := dto.Validate()
+	if validateErr != nil {
+		h.log.Error("validation failed", slog.Any("dto", dto))
+		return validateErr
+	}
+	reqSnap, convErr := MsgToDefSnap(dto)
+	if convErr != nil {
+		h.log.Error("conversion failed", slog.Any("dto", dto))
+		return convErr
+	}
+	resSnap, modificationErr := h.api.Modify(reqSnap)
+	if modificationErr != nil {
+		return modificationErr
+	}
+	h.log.Log(ctx, lf.LevelTrace, "patching succeed", slog.Any("ref", resSnap.TermRef))
+	return c.JSON(http.StatusOK, MsgFromDefSnap(resSnap))
+}
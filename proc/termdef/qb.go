@@ -0,0 +1,19 @@
+package termdef
+
+import (
+	"orglang/go-engine/adt/termsem"
+)
+
+const (
+	implBinds string = "proc_impl_binds "
+	termDefs  string = "proc_term_defs "
+)
+
+type queryBuilder interface {
+	insertRec(defRecDS) (string, []any)
+	selectRefs() (string, []any)
+	selectRecByID(string) (string, []any)
+	selectRecByRef(termsem.SemRefDS) (string, []any)
+	selectRecByQN(string) (string, []any)
+	selectSnapByID(string) (string, []any)
+}
@@ -0,0 +1,76 @@
+package termdef
+
+import (
+	"github.com/huandu/go-sqlbuilder"
+
+	"orglang/go-engine/adt/termsem"
+)
+
+type sqlBuilder struct {
+	defBuilder  *sqlbuilder.Struct
+	snapBuilder *sqlbuilder.Struct
+}
+
+// for compilation purposes
+func newQueryBuilder() queryBuilder {
+	return new(sqlBuilder)
+}
+
+func newSQLBuilder() *sqlBuilder {
+	defBuilder := sqlbuilder.NewStruct(new(defRecDS)).For(sqlbuilder.PostgreSQL)
+	snapBuilder := sqlbuilder.NewStruct(new(defSnapDS)).For(sqlbuilder.PostgreSQL)
+	return &sqlBuilder{defBuilder, snapBuilder}
+}
+
+// занятую ревизию вставка пропускает, и DAO сообщает о конфликте
+func (qb *sqlBuilder) insertRec(rec defRecDS) (string, []any) {
+	return qb.defBuilder.InsertInto(termDefs, rec).
+		SQL("ON CONFLICT (term_id, term_rn) DO NOTHING").
+		Build()
+}
+
+// последние ревизии всех определений
+func (qb *sqlBuilder) selectRefs() (string, []any) {
+	sb := sqlbuilder.PostgreSQL.NewSelectBuilder()
+	return sb.Select("term_id", sb.As("max(term_rn)", "term_rn")).
+		From(termDefs).
+		GroupBy("term_id").
+		Build()
+}
+
+func (qb *sqlBuilder) selectRecByID(id string) (string, []any) {
+	sb := qb.defBuilder.SelectFrom(termDefs + "def")
+	return sb.Where(sb.Equal("def.term_id", id)).
+		OrderByDesc("def.term_rn").
+		Limit(1).
+		Build()
+}
+
+// ревизия определения, на которой взят шаг
+func (qb *sqlBuilder) selectRecByRef(ref termsem.SemRefDS) (string, []any) {
+	sb := qb.defBuilder.SelectFrom(termDefs + "def")
+	return sb.Where(
+		sb.Equal("def.term_id", ref.TermID),
+		sb.Equal("def.term_rn", ref.TermRN),
+	).Build()
+}
+
+func (qb *sqlBuilder) selectRecByQN(qn string) (string, []any) {
+	sb := qb.defBuilder.SelectFrom(termDefs + "def")
+	return sb.Join(implBinds+"bind", "bind.impl_id = def.term_id").
+		Where(sb.Equal("bind.impl_qn", qn)).
+		OrderByDesc("def.term_rn").
+		Limit(1).
+		Build()
+}
+
+func (qb *sqlBuilder) selectSnapByID(id string) (string, []any) {
+	sb := sqlbuilder.PostgreSQL.NewSelectBuilder()
+	return sb.Select("def.term_id", "def.term_rn", "bind.impl_qn", "def.proc_es").
+		From(termDefs+"def").
+		Join(implBinds+"bind", "bind.impl_id = def.term_id").
+		Where(sb.Equal("def.term_id", id)).
+		OrderByDesc("def.term_rn").
+		Limit(1).
+		Build()
+}
@@ -0,0 +1,38 @@
+package termdef
+
+import (
+	"fmt"
+	"testing"
+
+	"orglang/go-engine/adt/termsem"
+)
+
+func TestInsertRec(t *testing.T) {
+	qb := newSQLBuilder()
+	sql, _ := qb.insertRec(defRecDS{})
+	fmt.Println(sql)
+}
+
+func TestSelectRefs(t *testing.T) {
+	qb := newSQLBuilder()
+	sql, _ := qb.selectRefs()
+	fmt.Println(sql)
+}
+
+func TestSelectRecByRef(t *testing.T) {
+	qb := newSQLBuilder()
+	sql, _ := qb.selectRecByRef(termsem.SemRefDS{TermID: "id", TermRN: 1})
+	fmt.Println(sql)
+}
+
+func TestSelectRecByQN(t *testing.T) {
+	qb := newSQLBuilder()
+	sql, _ := qb.selectRecByQN("qn")
+	fmt.Println(sql)
+}
+
+func TestSelectSnapByID(t *testing.T) {
+	qb := newSQLBuilder()
+	sql, _ := qb.selectSnapByID("id")
+	fmt.Println(sql)
+}
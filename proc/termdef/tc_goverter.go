@@ -0,0 +1,43 @@
+package termdef
+
+import (
+	"github.com/orglang/go-sdk/proc/termdef"
+)
+
+// goverter:variables
+// goverter:output:format assign-variable
+// goverter:extend orglang/go-engine/adt/identity:Convert.*
+// goverter:extend orglang/go-engine/adt/uniqsym:Convert.*
+// goverter:extend orglang/go-engine/proc/termexp:Msg.*
+var (
+	MsgToDefSpec    func(termdef.DefSpec) (DefSpec, error)
+	MsgFromDefSpec  func(DefSpec) termdef.DefSpec
+	MsgToDefSnap    func(termdef.DefSnap) (DefSnap, error)
+	MsgFromDefSnap  func(DefSnap) termdef.DefSnap
+	MsgFromDefSnaps func([]DefSnap) []termdef.DefSnap
+)
+
+// goverter:variables
+// goverter:output:format assign-variable
+// goverter:extend orglang/go-engine/adt/identity:Convert.*
+// goverter:extend orglang/go-engine/adt/uniqsym:Convert.*
+// goverter:extend orglang/go-engine/proc/termexp:Msg.*
+var (
+	ViewFromDefSnap func(DefSnap) DefSnapVP
+)
+
+// goverter:variables
+// goverter:output:format assign-variable
+// goverter:extend orglang/go-engine/adt/identity:Convert.*
+// goverter:extend orglang/go-engine/adt/seqnum:Convert.*
+// goverter:extend orglang/go-engine/adt/uniqsym:Convert.*
+// goverter:extend orglang/go-engine/proc/termexp:Data.*
+var (
+	// goverter:map . TermRef
+	DataToDefRec func(defRecDS) (DefRec, error)
+	// goverter:autoMap TermRef
+	DataFromDefRec func(DefRec) (defRecDS, error)
+	// goverter:map . TermRef
+	// goverter:map . DefSpec
+	DataToDefSnap func(defSnapDS) (DefSnap, error)
+)
@@ -0,0 +1,16 @@
+package termdef
+
+import (
+	"github.com/orglang/go-sdk/adt/termsem"
+	"github.com/orglang/go-sdk/proc/termexp"
+)
+
+type DefSpecVP struct {
+	ProcQN string           `form:"proc_qn" json:"proc_qn"`
+	ProcES *termexp.ExpSpec `json:"proc_es,omitempty"`
+}
+
+type DefSnapVP struct {
+	TermRef termsem.SemRef `json:"ref"`
+	DefSpec DefSpecVP      `json:"spec"`
+}
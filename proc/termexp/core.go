@@ -2,6 +2,7 @@ package termexp
 
 import (
 	"fmt"
+	"slices"
 
 	"orglang/go-engine/adt/commsem"
 	"orglang/go-engine/adt/identity"
@@ -59,12 +60,12 @@ func (s CaseSpec) Via() symbol.ADT { return s.CommChnlPH }
 
 // aka ExpName
 type LinkSpec struct {
+	CommChnlPH symbol.ADT // передаваемое обязательство
 	ProcTermQN uniqsym.ADT
-	X          identity.ADT
-	Ys         []identity.ADT
+	ValChnlPHs []symbol.ADT // channel bulk
 }
 
-func (s LinkSpec) Via() symbol.ADT { return "" }
+func (s LinkSpec) Via() symbol.ADT { return s.CommChnlPH }
 
 type FwdSpec struct {
 	CommChnlPH symbol.ADT // old via | from | x
@@ -108,12 +109,14 @@ func (s AcceptSpec) Via() symbol.ADT { return s.CommChnlPH }
 
 type DetachSpec struct {
 	CommChnlPH symbol.ADT
+	ContExp    ExpSpec
 }
 
 func (s DetachSpec) Via() symbol.ADT { return s.CommChnlPH }
 
 type ReleaseSpec struct {
 	CommChnlPH symbol.ADT
+	ContExp    ExpSpec
 }
 
 func (s ReleaseSpec) Via() symbol.ADT { return s.CommChnlPH }
@@ -193,24 +196,86 @@ func (r FwdRec) Via() symbol.ADT { return r.CommChnlPH }
 
 func (FwdRec) impl() {}
 
-func CollectEnv(spec ExpSpec) []identity.ADT {
-	return collectEnvRec(spec, []identity.ADT{})
+type AcquireRec struct {
+	ContChnlPH symbol.ADT
+	ContExp    ExpSpec
 }
 
-func collectEnvRec(s ExpSpec, env []identity.ADT) []identity.ADT {
+func (r AcquireRec) Via() symbol.ADT { return r.ContChnlPH }
+
+func (AcquireRec) impl() {}
+
+type AcceptRec struct {
+	ContChnlPH symbol.ADT
+	ContExp    ExpSpec
+}
+
+func (r AcceptRec) Via() symbol.ADT { return r.ContChnlPH }
+
+func (AcceptRec) impl() {}
+
+type ReleaseRec struct {
+	ContChnlPH symbol.ADT
+	ContExp    ExpSpec
+}
+
+func (r ReleaseRec) Via() symbol.ADT { return r.ContChnlPH }
+
+func (ReleaseRec) impl() {}
+
+type DetachRec struct {
+	ContChnlPH symbol.ADT
+	ContExp    ExpSpec
+}
+
+func (r DetachRec) Via() symbol.ADT { return r.ContChnlPH }
+
+func (DetachRec) impl() {}
+
+// собирает имена процессов, на которые ссылается выражение
+func CollectEnv(spec ExpSpec) []uniqsym.ADT {
+	return collectEnvRec(spec, []uniqsym.ADT{})
+}
+
+func collectEnvRec(s ExpSpec, env []uniqsym.ADT) []uniqsym.ADT {
 	switch spec := s.(type) {
+	case WaitSpec:
+		return collectEnvRec(spec.ContExp, env)
 	case RecvSpec:
 		return collectEnvRec(spec.ContExp, env)
+	case LabSpec:
+		return collectEnvRec(spec.ContExp, env)
 	case CaseSpec:
 		for _, cont := range spec.ContExps {
 			env = collectEnvRec(cont, env)
 		}
 		return env
+	case CallSpec:
+		return collectEnvRec(spec.ContExp, appendQN(env, spec.ProcTermQN))
+	case SpawnSpec:
+		return collectEnvRec(spec.ContExp, appendQN(env, spec.ProcTermQN))
+	case LinkSpec:
+		return appendQN(env, spec.ProcTermQN)
+	case AcqureSpec:
+		return collectEnvRec(spec.ContExp, env)
+	case AcceptSpec:
+		return collectEnvRec(spec.ContExp, env)
+	case ReleaseSpec:
+		return collectEnvRec(spec.ContExp, env)
+	case DetachSpec:
+		return collectEnvRec(spec.ContExp, env)
 	default:
 		return env
 	}
 }
 
+func appendQN(env []uniqsym.ADT, qn uniqsym.ADT) []uniqsym.ADT {
+	if slices.Contains(env, qn) {
+		return env
+	}
+	return append(env, qn)
+}
+
 func ErrExpTypeUnexpected(got ExpSpec) error {
 	return fmt.Errorf("exp spec unexpected: %T", got)
 }
@@ -17,6 +17,13 @@ type ExpSpecDS struct {
 	Lab   *labSpecDS   `json:"lab,omitempty"`
 	Case  *caseSpecDS  `json:"case,omitempty"`
 	Fwd   *fwdSpecDS   `json:"fwd,omitempty"`
+	Call  *callSpecDS  `json:"call,omitempty"`
+	Spawn *spawnSpecDS `json:"spawn,omitempty"`
+	Link  *linkSpecDS  `json:"link,omitempty"`
+	Acq   *shiftSpecDS `json:"acq,omitempty"`
+	Acc   *shiftSpecDS `json:"acc,omitempty"`
+	Rel   *shiftSpecDS `json:"rel,omitempty"`
+	Det   *shiftSpecDS `json:"det,omitempty"`
 }
 
 type ExpRecDS struct {
@@ -28,6 +35,10 @@ type ExpRecDS struct {
 	Lab   *labRecDS   `json:"lab,omitempty"`
 	Case  *caseRecDS  `json:"case,omitempty"`
 	Fwd   *fwdRecDS   `json:"fwd,omitempty"`
+	Acq   *shiftRecDS `json:"acq,omitempty"`
+	Acc   *shiftRecDS `json:"acc,omitempty"`
+	Rel   *shiftRecDS `json:"rel,omitempty"`
+	Det   *shiftRecDS `json:"det,omitempty"`
 }
 
 type expKind int
@@ -43,6 +54,11 @@ const (
 	linkExp
 	spawnExp
 	fwdExp
+	callExp
+	acquireExp
+	acceptExp
+	releaseExp
+	detachExp
 )
 
 type closeSpecDS struct {
@@ -88,12 +104,14 @@ type recvRecDS struct {
 }
 
 type labSpecDS struct {
-	X     string `json:"x"`
-	Label string `json:"lab"`
+	X      string    `json:"x"`
+	Label  string    `json:"lab"`
+	ContES ExpSpecDS `json:"cont"`
 }
 
 type labRecDS struct {
 	X     string `json:"x"`
+	A     string `json:"a"`
 	Label string `json:"lab"`
 }
 
@@ -104,6 +122,7 @@ type caseSpecDS struct {
 
 type caseRecDS struct {
 	X        string        `json:"x"`
+	A        string        `json:"a"`
 	Branches []branchRecDS `json:"brs"`
 }
 
@@ -126,3 +145,33 @@ type fwdRecDS struct {
 	X string `json:"x"`
 	B string `json:"b"`
 }
+
+type callSpecDS struct {
+	X      string    `json:"x"`
+	QN     string    `json:"qn"`
+	Ys     []string  `json:"ys"`
+	ContES ExpSpecDS `json:"cont"`
+}
+
+type spawnSpecDS struct {
+	X      string    `json:"x"`
+	QN     string    `json:"qn"`
+	Ys     []string  `json:"ys"`
+	ContES ExpSpecDS `json:"cont"`
+}
+
+type linkSpecDS struct {
+	X  string   `json:"x"`
+	QN string   `json:"qn"`
+	Ys []string `json:"ys"`
+}
+
+type shiftSpecDS struct {
+	X      string    `json:"x"`
+	ContES ExpSpecDS `json:"cont"`
+}
+
+type shiftRecDS struct {
+	X      string    `json:"x"`
+	ContES ExpSpecDS `json:"cont"`
+}
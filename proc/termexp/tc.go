@@ -199,7 +199,7 @@ func DataFromExpRec(r ExpRec) (ExpRecDS, error) {
 			Close: &closeRecDS{symbol.ConvertToString(rec.ContChnlPH)},
 		}, nil
 	case WaitRec:
-		dto, err := dataFromExpSpec(rec.ContExp)
+		dto, err := DataFromExpSpec(rec.ContExp)
 		if err != nil {
 			return ExpRecDS{}, err
 		}
@@ -220,7 +220,7 @@ func DataFromExpRec(r ExpRec) (ExpRecDS, error) {
 			},
 		}, nil
 	case RecvRec:
-		dto, err := dataFromExpSpec(rec.ContExp)
+		dto, err := DataFromExpSpec(rec.ContExp)
 		if err != nil {
 			return ExpRecDS{}, err
 		}
@@ -234,13 +234,17 @@ func DataFromExpRec(r ExpRec) (ExpRecDS, error) {
 		}, nil
 	case LabRec:
 		return ExpRecDS{
-			K:   labExp,
-			Lab: &labRecDS{symbol.ConvertToString(rec.CommChnlPH), uniqsym.ConvertToString(rec.ValLabQN)},
+			K: labExp,
+			Lab: &labRecDS{
+				X:     symbol.ConvertToString(rec.CommChnlPH),
+				A:     identity.ConvertToString(rec.ContChnlID),
+				Label: uniqsym.ConvertToString(rec.ValLabQN),
+			},
 		}, nil
 	case CaseRec:
 		brs := []branchRecDS{}
 		for l, cont := range rec.ContExps {
-			dto, err := dataFromExpSpec(cont)
+			dto, err := DataFromExpSpec(cont)
 			if err != nil {
 				return ExpRecDS{}, err
 			}
@@ -250,6 +254,7 @@ func DataFromExpRec(r ExpRec) (ExpRecDS, error) {
 			K: caseExp,
 			Case: &caseRecDS{
 				X:        symbol.ConvertToString(rec.CommChnlPH),
+				A:        identity.ConvertToString(rec.ContChnlID),
 				Branches: brs,
 			},
 		}, nil
@@ -261,6 +266,30 @@ func DataFromExpRec(r ExpRec) (ExpRecDS, error) {
 				B: identity.ConvertToString(rec.ContChnlID),
 			},
 		}, nil
+	case AcquireRec:
+		dto, err := dataFromShiftRec(rec.ContChnlPH, rec.ContExp)
+		if err != nil {
+			return ExpRecDS{}, err
+		}
+		return ExpRecDS{K: acquireExp, Acq: dto}, nil
+	case AcceptRec:
+		dto, err := dataFromShiftRec(rec.ContChnlPH, rec.ContExp)
+		if err != nil {
+			return ExpRecDS{}, err
+		}
+		return ExpRecDS{K: acceptExp, Acc: dto}, nil
+	case ReleaseRec:
+		dto, err := dataFromShiftRec(rec.ContChnlPH, rec.ContExp)
+		if err != nil {
+			return ExpRecDS{}, err
+		}
+		return ExpRecDS{K: releaseExp, Rel: dto}, nil
+	case DetachRec:
+		dto, err := dataFromShiftRec(rec.ContChnlPH, rec.ContExp)
+		if err != nil {
+			return ExpRecDS{}, err
+		}
+		return ExpRecDS{K: detachExp, Det: dto}, nil
 	default:
 		panic(ErrExpTypeUnexpected(rec))
 	}
@@ -279,7 +308,7 @@ func DataToExpRec(dto ExpRecDS) (ExpRec, error) {
 		if err != nil {
 			return nil, err
 		}
-		cont, err := dataToExpSpec(dto.Wait.ContES)
+		cont, err := DataToExpSpec(dto.Wait.ContES)
 		if err != nil {
 			return nil, err
 		}
@@ -303,7 +332,7 @@ func DataToExpRec(dto ExpRecDS) (ExpRec, error) {
 		if err != nil {
 			return nil, err
 		}
-		cont, err := dataToExpSpec(dto.Recv.ContES)
+		cont, err := DataToExpSpec(dto.Recv.ContES)
 		if err != nil {
 			return nil, err
 		}
@@ -313,29 +342,37 @@ func DataToExpRec(dto ExpRecDS) (ExpRec, error) {
 		if err != nil {
 			return nil, err
 		}
+		b, err := identity.ConvertFromString(dto.Lab.A)
+		if err != nil {
+			return nil, err
+		}
 		label, err := uniqsym.ConvertFromString(dto.Lab.Label)
 		if err != nil {
 			return nil, err
 		}
-		return LabRec{CommChnlPH: a, ValLabQN: label}, nil
+		return LabRec{CommChnlPH: a, ContChnlID: b, ValLabQN: label}, nil
 	case caseExp:
 		x, err := symbol.ConvertFromString(dto.Case.X)
 		if err != nil {
 			return nil, err
 		}
+		b, err := identity.ConvertFromString(dto.Case.A)
+		if err != nil {
+			return nil, err
+		}
 		conts := make(map[uniqsym.ADT]ExpSpec, len(dto.Case.Branches))
 		for _, branch := range dto.Case.Branches {
-			cont, err := dataToExpSpec(branch.ContES)
+			cont, err := DataToExpSpec(branch.ContES)
 			if err != nil {
 				return nil, err
 			}
-			label, err := uniqsym.ConvertFromString(dto.Lab.Label)
+			label, err := uniqsym.ConvertFromString(branch.Label)
 			if err != nil {
 				return nil, err
 			}
 			conts[label] = cont
 		}
-		return CaseRec{CommChnlPH: x, ContExps: conts}, nil
+		return CaseRec{CommChnlPH: x, ContChnlID: b, ContExps: conts}, nil
 	case fwdExp:
 		x, err := symbol.ConvertFromString(dto.Fwd.X)
 		if err != nil {
@@ -346,20 +383,46 @@ func DataToExpRec(dto ExpRecDS) (ExpRec, error) {
 			return nil, err
 		}
 		return FwdRec{CommChnlPH: x, ContChnlID: b}, nil
+	case acquireExp:
+		x, cont, err := dataToShiftRec(dto.Acq)
+		if err != nil {
+			return nil, err
+		}
+		return AcquireRec{ContChnlPH: x, ContExp: cont}, nil
+	case acceptExp:
+		x, cont, err := dataToShiftRec(dto.Acc)
+		if err != nil {
+			return nil, err
+		}
+		return AcceptRec{ContChnlPH: x, ContExp: cont}, nil
+	case releaseExp:
+		x, cont, err := dataToShiftRec(dto.Rel)
+		if err != nil {
+			return nil, err
+		}
+		return ReleaseRec{ContChnlPH: x, ContExp: cont}, nil
+	case detachExp:
+		x, cont, err := dataToShiftRec(dto.Det)
+		if err != nil {
+			return nil, err
+		}
+		return DetachRec{ContChnlPH: x, ContExp: cont}, nil
 	default:
 		panic(errUnexpectedExpKind(dto.K))
 	}
 }
 
-func dataFromExpSpec(s ExpSpec) (ExpSpecDS, error) {
+func DataFromExpSpec(s ExpSpec) (ExpSpecDS, error) {
 	switch spec := s.(type) {
+	case nil:
+		return ExpSpecDS{K: nonExp}, nil
 	case CloseSpec:
 		return ExpSpecDS{
 			K:     closeExp,
 			Close: &closeSpecDS{symbol.ConvertToString(spec.ContChnlPH)},
 		}, nil
 	case WaitSpec:
-		dto, err := dataFromExpSpec(spec.ContExp)
+		dto, err := DataFromExpSpec(spec.ContExp)
 		if err != nil {
 			return ExpSpecDS{}, err
 		}
@@ -379,7 +442,7 @@ func dataFromExpSpec(s ExpSpec) (ExpSpecDS, error) {
 			},
 		}, nil
 	case RecvSpec:
-		dto, err := dataFromExpSpec(spec.ContExp)
+		dto, err := DataFromExpSpec(spec.ContExp)
 		if err != nil {
 			return ExpSpecDS{}, err
 		}
@@ -387,19 +450,27 @@ func dataFromExpSpec(s ExpSpec) (ExpSpecDS, error) {
 			K: recvExp,
 			Recv: &recvSpecDS{
 				X:      symbol.ConvertToString(spec.CommChnlPH),
-				Y:      symbol.ConvertToString(spec.CommChnlPH),
+				Y:      symbol.ConvertToString(spec.NewChnlPH),
 				ContES: dto,
 			},
 		}, nil
 	case LabSpec:
+		dto, err := DataFromExpSpec(spec.ContExp)
+		if err != nil {
+			return ExpSpecDS{}, err
+		}
 		return ExpSpecDS{
-			K:   labExp,
-			Lab: &labSpecDS{symbol.ConvertToString(spec.CommChnlPH), uniqsym.ConvertToString(spec.ValLabQN)},
+			K: labExp,
+			Lab: &labSpecDS{
+				X:      symbol.ConvertToString(spec.CommChnlPH),
+				Label:  uniqsym.ConvertToString(spec.ValLabQN),
+				ContES: dto,
+			},
 		}, nil
 	case CaseSpec:
 		brs := []branchSpecDS{}
 		for l, cont := range spec.ContExps {
-			dto, err := dataFromExpSpec(cont)
+			dto, err := DataFromExpSpec(cont)
 			if err != nil {
 				return ExpSpecDS{}, err
 			}
@@ -420,13 +491,76 @@ func dataFromExpSpec(s ExpSpec) (ExpSpecDS, error) {
 				Y: symbol.ConvertToString(spec.ContChnlPH),
 			},
 		}, nil
+	case CallSpec:
+		dto, err := DataFromExpSpec(spec.ContExp)
+		if err != nil {
+			return ExpSpecDS{}, err
+		}
+		return ExpSpecDS{
+			K: callExp,
+			Call: &callSpecDS{
+				X:      symbol.ConvertToString(spec.NewChnlPH),
+				QN:     uniqsym.ConvertToString(spec.ProcTermQN),
+				Ys:     symbol.ConvertToStrings(spec.ValChnlPHs),
+				ContES: dto,
+			},
+		}, nil
+	case SpawnSpec:
+		dto, err := DataFromExpSpec(spec.ContExp)
+		if err != nil {
+			return ExpSpecDS{}, err
+		}
+		return ExpSpecDS{
+			K: spawnExp,
+			Spawn: &spawnSpecDS{
+				X:      symbol.ConvertToString(spec.CommChnlPH),
+				QN:     uniqsym.ConvertToString(spec.ProcTermQN),
+				Ys:     symbol.ConvertToStrings(spec.NewChnlPHs),
+				ContES: dto,
+			},
+		}, nil
+	case LinkSpec:
+		return ExpSpecDS{
+			K: linkExp,
+			Link: &linkSpecDS{
+				X:  symbol.ConvertToString(spec.CommChnlPH),
+				QN: uniqsym.ConvertToString(spec.ProcTermQN),
+				Ys: symbol.ConvertToStrings(spec.ValChnlPHs),
+			},
+		}, nil
+	case AcqureSpec:
+		dto, err := dataFromShiftSpec(spec.CommChnlPH, spec.ContExp)
+		if err != nil {
+			return ExpSpecDS{}, err
+		}
+		return ExpSpecDS{K: acquireExp, Acq: dto}, nil
+	case AcceptSpec:
+		dto, err := dataFromShiftSpec(spec.CommChnlPH, spec.ContExp)
+		if err != nil {
+			return ExpSpecDS{}, err
+		}
+		return ExpSpecDS{K: acceptExp, Acc: dto}, nil
+	case ReleaseSpec:
+		dto, err := dataFromShiftSpec(spec.CommChnlPH, spec.ContExp)
+		if err != nil {
+			return ExpSpecDS{}, err
+		}
+		return ExpSpecDS{K: releaseExp, Rel: dto}, nil
+	case DetachSpec:
+		dto, err := dataFromShiftSpec(spec.CommChnlPH, spec.ContExp)
+		if err != nil {
+			return ExpSpecDS{}, err
+		}
+		return ExpSpecDS{K: detachExp, Det: dto}, nil
 	default:
 		panic(ErrExpTypeUnexpected(spec))
 	}
 }
 
-func dataToExpSpec(dto ExpSpecDS) (ExpSpec, error) {
+func DataToExpSpec(dto ExpSpecDS) (ExpSpec, error) {
 	switch dto.K {
+	case nonExp:
+		return nil, nil
 	case closeExp:
 		a, err := symbol.ConvertFromString(dto.Close.X)
 		if err != nil {
@@ -438,7 +572,7 @@ func dataToExpSpec(dto ExpSpecDS) (ExpSpec, error) {
 		if err != nil {
 			return nil, err
 		}
-		cont, err := dataToExpSpec(dto.Wait.ContES)
+		cont, err := DataToExpSpec(dto.Wait.ContES)
 		if err != nil {
 			return nil, err
 		}
@@ -462,7 +596,7 @@ func dataToExpSpec(dto ExpSpecDS) (ExpSpec, error) {
 		if err != nil {
 			return nil, err
 		}
-		cont, err := dataToExpSpec(dto.Recv.ContES)
+		cont, err := DataToExpSpec(dto.Recv.ContES)
 		if err != nil {
 			return nil, err
 		}
@@ -476,7 +610,11 @@ func dataToExpSpec(dto ExpSpecDS) (ExpSpec, error) {
 		if err != nil {
 			return nil, err
 		}
-		return LabSpec{CommChnlPH: x, ValLabQN: label}, nil
+		cont, err := DataToExpSpec(dto.Lab.ContES)
+		if err != nil {
+			return nil, err
+		}
+		return LabSpec{CommChnlPH: x, ValLabQN: label, ContExp: cont}, nil
 	case caseExp:
 		x, err := symbol.ConvertFromString(dto.Case.X)
 		if err != nil {
@@ -484,11 +622,11 @@ func dataToExpSpec(dto ExpSpecDS) (ExpSpec, error) {
 		}
 		conts := make(map[uniqsym.ADT]ExpSpec, len(dto.Case.Branches))
 		for _, b := range dto.Case.Branches {
-			cont, err := dataToExpSpec(b.ContES)
+			cont, err := DataToExpSpec(b.ContES)
 			if err != nil {
 				return nil, err
 			}
-			label, err := uniqsym.ConvertFromString(dto.Lab.Label)
+			label, err := uniqsym.ConvertFromString(b.Label)
 			if err != nil {
 				return nil, err
 			}
@@ -505,11 +643,125 @@ func dataToExpSpec(dto ExpSpecDS) (ExpSpec, error) {
 			return nil, err
 		}
 		return FwdSpec{CommChnlPH: x, ContChnlPH: y}, nil
+	case callExp:
+		x, err := symbol.ConvertFromString(dto.Call.X)
+		if err != nil {
+			return nil, err
+		}
+		qn, err := uniqsym.ConvertFromString(dto.Call.QN)
+		if err != nil {
+			return nil, err
+		}
+		ys, err := symbol.ConvertFromStrings(dto.Call.Ys)
+		if err != nil {
+			return nil, err
+		}
+		cont, err := DataToExpSpec(dto.Call.ContES)
+		if err != nil {
+			return nil, err
+		}
+		return CallSpec{NewChnlPH: x, ProcTermQN: qn, ValChnlPHs: ys, ContExp: cont}, nil
+	case spawnExp:
+		x, err := symbol.ConvertFromString(dto.Spawn.X)
+		if err != nil {
+			return nil, err
+		}
+		qn, err := uniqsym.ConvertFromString(dto.Spawn.QN)
+		if err != nil {
+			return nil, err
+		}
+		ys, err := symbol.ConvertFromStrings(dto.Spawn.Ys)
+		if err != nil {
+			return nil, err
+		}
+		cont, err := DataToExpSpec(dto.Spawn.ContES)
+		if err != nil {
+			return nil, err
+		}
+		return SpawnSpec{CommChnlPH: x, ProcTermQN: qn, NewChnlPHs: ys, ContExp: cont}, nil
+	case linkExp:
+		x, err := symbol.ConvertFromString(dto.Link.X)
+		if err != nil {
+			return nil, err
+		}
+		qn, err := uniqsym.ConvertFromString(dto.Link.QN)
+		if err != nil {
+			return nil, err
+		}
+		ys, err := symbol.ConvertFromStrings(dto.Link.Ys)
+		if err != nil {
+			return nil, err
+		}
+		return LinkSpec{CommChnlPH: x, ProcTermQN: qn, ValChnlPHs: ys}, nil
+	case acquireExp:
+		x, cont, err := dataToShiftSpec(dto.Acq)
+		if err != nil {
+			return nil, err
+		}
+		return AcqureSpec{CommChnlPH: x, ContExp: cont}, nil
+	case acceptExp:
+		x, cont, err := dataToShiftSpec(dto.Acc)
+		if err != nil {
+			return nil, err
+		}
+		return AcceptSpec{CommChnlPH: x, ContExp: cont}, nil
+	case releaseExp:
+		x, cont, err := dataToShiftSpec(dto.Rel)
+		if err != nil {
+			return nil, err
+		}
+		return ReleaseSpec{CommChnlPH: x, ContExp: cont}, nil
+	case detachExp:
+		x, cont, err := dataToShiftSpec(dto.Det)
+		if err != nil {
+			return nil, err
+		}
+		return DetachSpec{CommChnlPH: x, ContExp: cont}, nil
 	default:
 		panic(errUnexpectedExpKind(dto.K))
 	}
 }
 
+func dataFromShiftSpec(x symbol.ADT, cont ExpSpec) (*shiftSpecDS, error) {
+	dto, err := DataFromExpSpec(cont)
+	if err != nil {
+		return nil, err
+	}
+	return &shiftSpecDS{X: symbol.ConvertToString(x), ContES: dto}, nil
+}
+
+func dataToShiftSpec(dto *shiftSpecDS) (symbol.ADT, ExpSpec, error) {
+	x, err := symbol.ConvertFromString(dto.X)
+	if err != nil {
+		return x, nil, err
+	}
+	cont, err := DataToExpSpec(dto.ContES)
+	if err != nil {
+		return x, nil, err
+	}
+	return x, cont, nil
+}
+
+func dataFromShiftRec(x symbol.ADT, cont ExpSpec) (*shiftRecDS, error) {
+	dto, err := DataFromExpSpec(cont)
+	if err != nil {
+		return nil, err
+	}
+	return &shiftRecDS{X: symbol.ConvertToString(x), ContES: dto}, nil
+}
+
+func dataToShiftRec(dto *shiftRecDS) (symbol.ADT, ExpSpec, error) {
+	x, err := symbol.ConvertFromString(dto.X)
+	if err != nil {
+		return x, nil, err
+	}
+	cont, err := DataToExpSpec(dto.ContES)
+	if err != nil {
+		return x, nil, err
+	}
+	return x, cont, nil
+}
+
 func errUnexpectedExpKind(k expKind) error {
 	return fmt.Errorf("unexpected term kind: %v", k)
 }
@@ -3,7 +3,6 @@ package termexp
 // goverter:variables
 // goverter:output:format assign-variable
 // goverter:extend Data.*
-// goverter:extend data.*
 var (
 	DataToExpSpecs   func([]ExpSpecDS) ([]ExpSpec, error)
 	DataFromExpSpecs func([]ExpSpec) ([]ExpSpecDS, error)
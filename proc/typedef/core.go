@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"iter"
 	"log/slog"
+	"maps"
+	"slices"
 
 	"orglang/go-engine/lib/db"
 
@@ -20,6 +22,7 @@ import (
 
 type API interface {
 	Create(DefSpec) (DefSnap, error)
+	CreateAll([]DefSpec) ([]DefSnap, error)
 	Modify(DefSnap) (DefSnap, error)
 	RetrieveSnap(typesem.SemRef) (DefSnap, error)
 	retrieveSnap(DefRec) (DefSnap, error)
@@ -70,36 +73,82 @@ func newService(
 	return &service{typeDefRepo, typeExpRepo, descSemRepo, operator, log}
 }
 
-func (s *service) Create(spec DefSpec) (_ DefSnap, err error) {
-	ctx := context.Background()
-	qnAttr := slog.Any("qn", spec.TypeQN)
-	s.log.Debug("creation started", qnAttr, slog.Any("spec", spec))
-	newExp, err := typeexp.ConvertSpecToRec(spec.TypeExp)
+func (s *service) Create(spec DefSpec) (DefSnap, error) {
+	snaps, err := s.CreateAll([]DefSpec{spec})
 	if err != nil {
 		return DefSnap{}, err
 	}
-	newDef := DefRec{TypeRef: typesem.New(), ExpVK: newExp.Key()}
-	newDesc := descsem.SemRec{DescQN: spec.TypeQN, DescID: newDef.TypeRef.TypeID, Kind: descsem.TypeKind}
-	err = s.operator.Explicit(ctx, func(ds db.Source) error {
-		err = s.descSemRepo.AddRec(ds, newDesc)
+	return snaps[0], nil
+}
+
+// CreateAll creates definitions checked against each other
+//
+// Ссылки внутри группы разрешаются в новые тела, поэтому так
+// создаются взаимно рекурсивные типы.
+func (s *service) CreateAll(specs []DefSpec) (_ []DefSnap, err error) {
+	ctx := context.Background()
+	qnsAttr := slog.Any("qns", collectQNs(specs))
+	s.log.Debug("creation started", qnsAttr, slog.Any("specs", specs))
+	newDefs := make(typeexp.Defs, len(specs))
+	newExps := make([]typeexp.ExpRec, 0, len(specs))
+	for _, spec := range specs {
+		if _, ok := newDefs[spec.TypeQN]; ok {
+			s.log.Error("creation failed", qnsAttr)
+			return nil, errDuplicateQN(spec.TypeQN)
+		}
+		newExp, err := typeexp.ConvertSpecToRec(spec.TypeExp)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		err = s.typeExpRepo.AddRec(ds, newExp)
+		newDefs[spec.TypeQN] = newExp
+		newExps = append(newExps, newExp)
+	}
+	snaps := make([]DefSnap, 0, len(specs))
+	err = s.operator.Explicit(ctx, func(ds db.Source) error {
+		defs := maps.Clone(newDefs)
+		err := SelectDefs(ds, s.typeDefRepo, s.typeExpRepo, defs, slices.Values(newExps))
 		if err != nil {
 			return err
 		}
-		return s.typeDefRepo.AddRec(ds, newDef)
+		for _, spec := range specs {
+			err = typeexp.CheckDef(defs, spec.TypeQN, newDefs[spec.TypeQN])
+			if err != nil {
+				return err
+			}
+		}
+		for i, spec := range specs {
+			newDef := DefRec{TypeRef: typesem.New(), ExpVK: newExps[i].Key()}
+			newDesc := descsem.SemRec{DescQN: spec.TypeQN, DescID: newDef.TypeRef.TypeID, Kind: descsem.TypeKind}
+			err = s.descSemRepo.AddRec(ds, newDesc)
+			if err != nil {
+				return err
+			}
+			err = s.typeExpRepo.AddRec(ds, newExps[i])
+			if err != nil {
+				return err
+			}
+			err = s.typeDefRepo.AddRec(ds, newDef)
+			if err != nil {
+				return err
+			}
+			snaps = append(snaps, DefSnap{TypeRef: newDef.TypeRef, DefSpec: spec})
+		}
+		return nil
 	})
 	if err != nil {
-		s.log.Error("creation failed", qnAttr)
-		return DefSnap{}, err
+		s.log.Error("creation failed", qnsAttr)
+		return nil, err
 	}
-	s.log.Debug("creation succeed", qnAttr, slog.Any("ref", newDef.TypeRef))
-	return DefSnap{
-		TypeRef: newDef.TypeRef,
-		DefSpec: spec,
-	}, nil
+	s.log.Debug("creation succeed", qnsAttr)
+	return snaps, nil
+}
+
+func collectQNs(specs []DefSpec) []uniqsym.ADT {
+	typeQNs := make([]uniqsym.ADT, 0, len(specs))
+	for _, spec := range specs {
+		typeQNs = append(typeQNs, spec.TypeQN)
+	}
+	return typeQNs
 }
 
 func (s *service) Modify(snap DefSnap) (_ DefSnap, err error) {
@@ -131,6 +180,10 @@ func (s *service) Modify(snap DefSnap) (_ DefSnap, err error) {
 			if err != nil {
 				return err
 			}
+			err = s.checkDefWith(ds, snap.DefSpec.TypeQN, newExp)
+			if err != nil {
+				return err
+			}
 			err = s.typeExpRepo.AddRec(ds, newExp)
 			if err != nil {
 				return err
@@ -154,6 +207,24 @@ func (s *service) Modify(snap DefSnap) (_ DefSnap, err error) {
 	return snap, nil
 }
 
+// определение проверяется на фоне всего сохраненного окружения
+func (s *service) checkDef(typeQN uniqsym.ADT, newExp typeexp.ExpRec) error {
+	ctx := context.Background()
+	return s.operator.Implicit(ctx, func(ds db.Source) error {
+		return s.checkDefWith(ds, typeQN, newExp)
+	})
+}
+
+func (s *service) checkDefWith(ds db.Source, typeQN uniqsym.ADT, newExp typeexp.ExpRec) error {
+	// ссылки на себя разрешаются в новое тело
+	defs := typeexp.Defs{typeQN: newExp}
+	err := SelectDefs(ds, s.typeDefRepo, s.typeExpRepo, defs, slices.Values([]typeexp.ExpRec{newExp}))
+	if err != nil {
+		return err
+	}
+	return typeexp.CheckDef(defs, typeQN, newExp)
+}
+
 func (s *service) RetrieveSnap(ref typesem.SemRef) (_ DefSnap, err error) {
 	ctx := context.Background()
 	var rec DefRec
@@ -206,16 +277,61 @@ func CollectEnv(recs iter.Seq[DefRec]) []valkey.ADT {
 	return expIDs
 }
 
+// SelectDefs extends defs with bodies of linked types transitively
+//
+// Отсутствующие в хранилище имена пропускаются, их обнаруживает проверка.
+func SelectDefs(
+	ds db.Source,
+	typeDefRepo Repo,
+	typeExpRepo typeexp.Repo,
+	defs typeexp.Defs,
+	recs iter.Seq[typeexp.ExpRec],
+) error {
+	var triedQNs []uniqsym.ADT
+	typeQNs := typeexp.CollectLinks(recs)
+	for len(typeQNs) > 0 {
+		var newQNs []uniqsym.ADT
+		for _, typeQN := range typeQNs {
+			isSame := func(qn uniqsym.ADT) bool { return qn.Equal(typeQN) }
+			if slices.ContainsFunc(triedQNs, isSame) || slices.ContainsFunc(slices.Collect(maps.Keys(defs)), isSame) {
+				continue
+			}
+			triedQNs = append(triedQNs, typeQN)
+			newQNs = append(newQNs, typeQN)
+		}
+		if len(newQNs) == 0 {
+			return nil
+		}
+		defRecs, err := typeDefRepo.SelectEnv(ds, newQNs)
+		if err != nil {
+			return err
+		}
+		defExps, err := typeExpRepo.SelectEnv(ds, CollectEnv(maps.Values(defRecs)))
+		if err != nil {
+			return err
+		}
+		for typeQN, defRec := range defRecs {
+			defs[typeQN] = defExps[defRec.ExpVK]
+		}
+		typeQNs = typeexp.CollectLinks(maps.Values(defExps))
+	}
+	return nil
+}
+
 func ErrSymMissingInEnv(want uniqsym.ADT) error {
 	return fmt.Errorf("root missing in env: %v", want)
 }
 
+func errDuplicateQN(got uniqsym.ADT) error {
+	return fmt.Errorf("type defined twice: %v", got)
+}
+
 func errConcurrentModification(got seqnum.ADT, want seqnum.ADT) error {
-	return fmt.Errorf("entity concurrent modification: want revision %v, got revision %v", want, got)
+	return fmt.Errorf("%w: want revision %v, got revision %v", db.ErrConcurrentModification, want, got)
 }
 
 func errOptimisticUpdate(got seqnum.ADT) error {
-	return fmt.Errorf("entity concurrent modification: got revision %v", got)
+	return fmt.Errorf("%w: got revision %v", db.ErrConcurrentModification, got)
 }
 
 func ErrDoesNotExist(want identity.ADT) error {
@@ -0,0 +1,47 @@
+package typedef
+
+import (
+	"log/slog"
+	"testing"
+
+	"orglang/go-engine/lib/db"
+
+	"orglang/go-engine/adt/symbol"
+	"orglang/go-engine/adt/uniqsym"
+	"orglang/go-engine/proc/typeexp"
+)
+
+func TestCreateAllMutual(t *testing.T) {
+	qn := func(s string) uniqsym.ADT { return uniqsym.New(symbol.New(s)) }
+	choice := func(typeQN uniqsym.ADT) typeexp.ExpSpec {
+		return typeexp.PlusSpec{Choices: map[uniqsym.ADT]typeexp.ExpSpec{
+			qn("stop"): typeexp.OneSpec{},
+			qn("next"): typeexp.LinkSpec{TypeQN: typeQN},
+		}}
+	}
+	// even = +{stop : 1, next : odd}, odd = +{stop : 1, next : even}
+	even := DefSpec{TypeQN: qn("even"), TypeExp: choice(qn("odd"))}
+	odd := DefSpec{TypeQN: qn("odd"), TypeExp: choice(qn("even"))}
+	api := NewMemAPI(db.NewOperatorMem(), slog.New(slog.DiscardHandler))
+	_, err := api.Create(even)
+	if err == nil {
+		t.Fatal("dangling link accepted")
+	}
+	snaps, err := api.CreateAll([]DefSpec{even, odd})
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	if len(snaps) != 2 {
+		t.Fatalf("got %v snaps, want 2", len(snaps))
+	}
+	for _, snap := range snaps {
+		_, err = api.RetrieveSnap(snap.TypeRef)
+		if err != nil {
+			t.Fatalf("unexpected error %q", err)
+		}
+	}
+	_, err = api.CreateAll([]DefSpec{even, even})
+	if err == nil {
+		t.Fatal("duplicate name accepted")
+	}
+}
@@ -1,17 +1,23 @@
 package typedef
 
 import (
+	"log/slog"
+
 	"go.uber.org/fx"
 
+	"orglang/go-engine/lib/db"
 	"orglang/go-engine/lib/te"
 
 	"orglang/go-engine/adt/descsem"
+	"orglang/go-engine/proc/typeexp"
 )
 
 var Module = fx.Module("adproct/typedef",
 	fx.Provide(
 		fx.Annotate(newService, fx.As(new(API))),
-		fx.Annotate(newPgxDAO, fx.As(new(Repo))),
+		// имена разрешаются в ревизии внутри транзакции шага, без кэша:
+		// кэшируются лишь неизменные выражения по ключу содержимого
+		newDialectDAO,
 	),
 	fx.Provide(
 		fx.Private,
@@ -19,10 +25,18 @@ var Module = fx.Module("adproct/typedef",
 		newEchoPresenter,
 		fx.Annotate(newSQLBuilder, fx.As(new(queryBuilder))),
 		fx.Annotate(newRendererStdlib, fx.As(new(te.Renderer))),
-		fx.Annotate(descsem.NewPgxDAO(descBinds), fx.As(new(descsem.Repo))),
+		descsem.NewDialectDAO(descBinds),
 	),
 	fx.Invoke(
 		cfgEchoController,
 		cfgEchoPresenter,
 	),
 )
+
+// NewMemAPI собирает сервис над хранилищем в памяти для тестов
+// исполнителя, которым нужны именованные типы
+func NewMemAPI(operator db.Operator, log *slog.Logger) API {
+	typeExpRepo := typeexp.NewMemRepo(log)
+	descSemRepo := descsem.NewDialectDAO(descBinds)(db.Memory, log)
+	return newService(newMemDAO(log), typeExpRepo, descSemRepo, operator, log)
+}
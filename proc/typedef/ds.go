@@ -1,6 +1,8 @@
 package typedef
 
 import (
+	"log/slog"
+
 	"orglang/go-engine/lib/db"
 
 	"orglang/go-engine/adt/typesem"
@@ -18,6 +20,14 @@ type Repo interface {
 	SelectEnv(db.Source, []uniqsym.ADT) (map[uniqsym.ADT]DefRec, error)
 }
 
+// хранилищу в памяти запросы не нужны, поэтому DAO выбирается по диалекту
+func newDialectDAO(dialect db.Dialect, qb queryBuilder, log *slog.Logger) Repo {
+	if dialect == db.Memory {
+		return newMemDAO(log)
+	}
+	return newPgxDAO(qb, log)
+}
+
 type defRecDS struct {
 	TypeID string `db:"type_id"`
 	TypeRN int64  `db:"type_rn"`
@@ -0,0 +1,164 @@
+package typedef
+
+import (
+	"log/slog"
+	"reflect"
+
+	"orglang/go-engine/lib/db"
+	"orglang/go-engine/lib/lf"
+
+	"orglang/go-engine/adt/descsem"
+	"orglang/go-engine/adt/identity"
+	"orglang/go-engine/adt/typesem"
+	"orglang/go-engine/adt/uniqsym"
+)
+
+// Adapter
+type memDAO struct {
+	log *slog.Logger
+}
+
+func newMemDAO(log *slog.Logger) *memDAO {
+	name := slog.String("name", reflect.TypeFor[memDAO]().Name())
+	return &memDAO{log.With(name)}
+}
+
+// NewMemRepo отдает хранилище в памяти тестам соседних модулей,
+// которые собирают сервисы без fx
+func NewMemRepo(log *slog.Logger) Repo {
+	return newMemDAO(log)
+}
+
+func (dao *memDAO) AddRec(source db.Source, rec DefRec) error {
+	ds := db.MustConform[db.SourceMem](source)
+	idAttr := slog.Any("typeID", rec.TypeRef.TypeID)
+	dto, err := DataFromDefRec(rec)
+	if err != nil {
+		dao.log.Error("model conversion failed", idAttr)
+		return err
+	}
+	err = db.TableOf[string, defRecDS](ds, typeDefs).Insert(dto.TypeID, dto)
+	if err != nil {
+		dao.log.Error("insertion failed", idAttr)
+		return err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "addition succeed", idAttr)
+	return nil
+}
+
+// новая ревизия записывается, только если хранимая на единицу младше
+func (dao *memDAO) ModifyRec(source db.Source, rec DefRec) error {
+	ds := db.MustConform[db.SourceMem](source)
+	idAttr := slog.Any("typeID", rec.TypeRef.TypeID)
+	dto, err := DataFromDefRec(rec)
+	if err != nil {
+		dao.log.Error("model conversion failed", idAttr)
+		return err
+	}
+	defs := db.TableOf[string, defRecDS](ds, typeDefs)
+	prev, ok := defs.Get(dto.TypeID)
+	if !ok || prev.TypeRN != dto.TypeRN-1 {
+		dao.log.Error("entity update failed", idAttr)
+		return errOptimisticUpdate(rec.TypeRef.TypeRN - 1)
+	}
+	defs.Put(dto.TypeID, dto)
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "modification succeed", idAttr)
+	return nil
+}
+
+func (dao *memDAO) GetRefs(source db.Source) ([]typesem.SemRef, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	defDTOs := db.TableOf[string, defRecDS](ds, typeDefs).Rows()
+	dtos := make([]typesem.SemRefDS, 0, len(defDTOs))
+	for _, dto := range defDTOs {
+		dtos = append(dtos, typesem.SemRefDS{TypeID: dto.TypeID, TypeRN: dto.TypeRN})
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entities selection succeed", slog.Any("dtos", dtos))
+	return typesem.DataToRefs(dtos)
+}
+
+func (dao *memDAO) GetRecByRef(source db.Source, ref typesem.SemRef) (DefRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	refAttr := slog.Any("ref", ref)
+	dto, err := db.TableOf[string, defRecDS](ds, typeDefs).Find(ref.TypeID.String())
+	if err != nil {
+		dao.log.Error("row selection failed", refAttr)
+		return DefRec{}, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity selection succeed", refAttr)
+	return DataToDefRec(dto)
+}
+
+func (dao *memDAO) GetRecByQN(source db.Source, typeQN uniqsym.ADT) (DefRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	qnAttr := slog.Any("typeQN", typeQN)
+	dto, err := findRecByQN(ds, typeQN)
+	if err != nil {
+		dao.log.Error("row selection failed", qnAttr)
+		return DefRec{}, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity selection succeed", qnAttr)
+	return DataToDefRec(dto)
+}
+
+func (dao *memDAO) GetRecsByRefs(source db.Source, refs []typesem.SemRef) ([]DefRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	defs := db.TableOf[string, defRecDS](ds, typeDefs)
+	dtos := make([]defRecDS, 0, len(refs))
+	for _, ref := range refs {
+		if ref.TypeID.IsEmpty() {
+			return nil, identity.ErrEmpty
+		}
+		dto, err := defs.Find(ref.TypeID.String())
+		if err != nil {
+			dao.log.Error("row selection failed", slog.Any("defRef", ref))
+			return nil, err
+		}
+		dtos = append(dtos, dto)
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entities selection succeed", slog.Any("dtos", dtos))
+	return DataToDefRecs(dtos)
+}
+
+// отсутствующие имена в окружение не попадают
+func (dao *memDAO) SelectEnv(source db.Source, typeQNs []uniqsym.ADT) (map[uniqsym.ADT]DefRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	env := make(map[uniqsym.ADT]DefRec, len(typeQNs))
+	for _, typeQN := range typeQNs {
+		dto, err := findRecByQN(ds, typeQN)
+		if err != nil {
+			continue
+		}
+		rec, err := DataToDefRec(dto)
+		if err != nil {
+			dao.log.Error("model conversion failed", slog.Any("typeQN", typeQN))
+			return nil, err
+		}
+		env[typeQN] = rec
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entities selection succeed", slog.Any("env", env))
+	return env, nil
+}
+
+func (dao *memDAO) GetRecsByQNs(source db.Source, typeQNs []uniqsym.ADT) ([]DefRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	dtos := make([]defRecDS, 0, len(typeQNs))
+	for _, typeQN := range typeQNs {
+		dto, err := findRecByQN(ds, typeQN)
+		if err != nil {
+			dao.log.Error("row selection failed", slog.Any("typeQN", typeQN))
+			return nil, err
+		}
+		dtos = append(dtos, dto)
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entities selection succeed", slog.Any("dtos", dtos))
+	return DataToDefRecs(dtos)
+}
+
+func findRecByQN(ds db.SourceMem, typeQN uniqsym.ADT) (defRecDS, error) {
+	bindDTO, err := db.TableOf[string, descsem.SemRecDS](ds, descBinds).Find(uniqsym.ConvertToString(typeQN))
+	if err != nil {
+		return defRecDS{}, err
+	}
+	return db.TableOf[string, defRecDS](ds, typeDefs).Find(bindDTO.DescID)
+}
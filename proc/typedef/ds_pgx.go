@@ -163,15 +163,45 @@ func (dao *pgxDAO) GetRecsByRefs(source db.Source, refs []typesem.SemRef) (_ []D
 	return DataToDefRecs(dtos)
 }
 
-func (dao *pgxDAO) SelectEnv(source db.Source, typeQNs []uniqsym.ADT) (map[uniqsym.ADT]DefRec, error) {
-	recs, err := dao.GetRecsByQNs(source, typeQNs)
-	if err != nil {
-		return nil, err
+// отсутствующие имена в окружение не попадают
+func (dao *pgxDAO) SelectEnv(source db.Source, typeQNs []uniqsym.ADT) (_ map[uniqsym.ADT]DefRec, err error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	env := make(map[uniqsym.ADT]DefRec, len(typeQNs))
+	if len(typeQNs) == 0 {
+		return env, nil
+	}
+	query := dao.qb.selectRecByQN()
+	batch := pgx.Batch{}
+	for _, typeQN := range typeQNs {
+		batch.Queue(query, uniqsym.ConvertToString(typeQN))
 	}
-	env := make(map[uniqsym.ADT]DefRec, len(recs))
-	for i, root := range recs {
-		env[typeQNs[i]] = root
+	br := ds.Conn.SendBatch(ds.Ctx, &batch)
+	defer func() {
+		err = errors.Join(err, br.Close())
+	}()
+	for _, typeQN := range typeQNs {
+		qnAttr := slog.Any("typeQN", typeQN)
+		rows, err := br.Query()
+		if err != nil {
+			dao.log.Error("query execution failed", qnAttr, slog.String("sql", query))
+			return nil, err
+		}
+		dtos, err := pgx.CollectRows(rows, pgx.RowToStructByName[defRecDS])
+		if err != nil {
+			dao.log.Error("rows scanning failed", qnAttr)
+			return nil, err
+		}
+		if len(dtos) == 0 {
+			continue
+		}
+		rec, err := DataToDefRec(dtos[0])
+		if err != nil {
+			dao.log.Error("model conversion failed", qnAttr)
+			return nil, err
+		}
+		env[typeQN] = rec
 	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entities selection succeed", slog.Any("env", env))
 	return env, nil
 }
 
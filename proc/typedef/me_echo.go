@@ -28,6 +28,7 @@ func newEchoController(a API, l *slog.Logger) *echoController {
 
 func cfgEchoController(e *echo.Echo, h *echoController) error {
 	e.POST("/api/v1/types", h.PostSpec)
+	e.POST("/api/v1/types/batch", h.PostSpecs)
 	e.GET("/api/v1/types/:id", h.GetSnap)
 	e.PATCH("/api/v1/types/:id", h.PatchOne)
 	return nil
@@ -60,6 +61,36 @@ func (h *echoController) PostSpec(c echo.Context) error {
 	return c.JSON(http.StatusCreated, MsgFromDefSnap(snap))
 }
 
+// взаимно рекурсивные типы создаются одной группой
+func (h *echoController) PostSpecs(c echo.Context) error {
+	var dtos []typedef.DefSpec
+	bindErr := c.Bind(&dtos)
+	if bindErr != nil {
+		h.log.Error("binding failed", slog.Any("dto", reflect.TypeOf(dtos)))
+		return bindErr
+	}
+	ctx := c.Request().Context()
+	h.log.Log(ctx, lf.LevelTrace, "posting started", slog.Any("dtos", dtos))
+	for _, dto := range dtos {
+		validateErr := dto.Validate()
+		if validateErr != nil {
+			h.log.Error("validation failed", slog.Any("dto", dto))
+			return validateErr
+		}
+	}
+	specs, convErr := MsgToDefSpecs(dtos)
+	if convErr != nil {
+		h.log.Error("conversion failed", slog.Any("dtos", dtos))
+		return convErr
+	}
+	snaps, createErr := h.api.CreateAll(specs)
+	if createErr != nil {
+		return createErr
+	}
+	h.log.Log(ctx, lf.LevelTrace, "posting succeed", slog.Int("count", len(snaps)))
+	return c.JSON(http.StatusCreated, MsgFromDefSnaps(snaps))
+}
+
 func (h *echoController) GetSnap(c echo.Context) error {
 	var dto sdk.SemRef
 	bindErr := c.Bind(&dto)
@@ -12,6 +12,8 @@ import (
 var (
 	MsgFromDefSpec  func(DefSpec) typedef.DefSpec
 	MsgToDefSpec    func(typedef.DefSpec) (DefSpec, error)
+	MsgFromDefSpecs func([]DefSpec) []typedef.DefSpec
+	MsgToDefSpecs   func([]typedef.DefSpec) ([]DefSpec, error)
 	MsgFromDefSnap  func(DefSnap) typedef.DefSnap
 	MsgToDefSnap    func(typedef.DefSnap) (DefSnap, error)
 	MsgFromDefSnaps func([]DefSnap) []typedef.DefSnap
@@ -2,6 +2,10 @@ package typeexp
 
 import (
 	"fmt"
+	"iter"
+	"maps"
+	"slices"
+	"strings"
 
 	"orglang/go-engine/adt/polarity"
 	"orglang/go-engine/adt/seqnum"
@@ -108,13 +112,13 @@ type UpRef struct {
 	ExpVK valkey.ADT
 }
 
-func (r UpRef) Ident() valkey.ADT { return r.ExpVK }
+func (r UpRef) Key() valkey.ADT { return r.ExpVK }
 
 type DownRef struct {
 	ExpVK valkey.ADT
 }
 
-func (r DownRef) Ident() valkey.ADT { return r.ExpVK }
+func (r DownRef) Key() valkey.ADT { return r.ExpVK }
 
 // aka Stype
 type ExpRec interface {
@@ -217,6 +221,8 @@ func (UpRec) spec() {}
 
 func (r UpRec) Key() valkey.ADT { return r.ExpVK }
 
+func (r UpRec) Next() valkey.ADT { return r.Cont.Key() }
+
 func (UpRec) Pol() polarity.ADT { return polarity.Zero }
 
 type DownRec struct {
@@ -228,6 +234,8 @@ func (DownRec) spec() {}
 
 func (r DownRec) Key() valkey.ADT { return r.ExpVK }
 
+func (r DownRec) Next() valkey.ADT { return r.Cont.Key() }
+
 func (DownRec) Pol() polarity.ADT { return polarity.Zero }
 
 type Context struct {
@@ -321,6 +329,18 @@ func CheckSpec(got, want ExpSpec) error {
 			}
 		}
 		return nil
+	case UpSpec:
+		gotSpec, ok := got.(UpSpec)
+		if !ok {
+			return ErrSpecTypeMismatch(got, want)
+		}
+		return CheckSpec(gotSpec.Cont, wantSpec.Cont)
+	case DownSpec:
+		gotSpec, ok := got.(DownSpec)
+		if !ok {
+			return ErrSpecTypeMismatch(got, want)
+		}
+		return CheckSpec(gotSpec.Cont, wantSpec.Cont)
 	default:
 		panic(ErrSpecTypeUnexpected(want))
 	}
@@ -393,11 +413,468 @@ func CheckRec(got, want ExpRec) error {
 			}
 		}
 		return nil
+	case UpRec:
+		gotRec, ok := got.(UpRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		return CheckRec(gotRec.Cont, wantRec.Cont)
+	case DownRec:
+		gotRec, ok := got.(DownRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		return CheckRec(gotRec.Cont, wantRec.Cont)
 	default:
 		panic(ErrRecTypeUnexpected(want))
 	}
 }
 
+// окружение определений для развертки ссылок на типы
+type Defs map[uniqsym.ADT]ExpRec
+
+type relation int8
+
+const (
+	equality relation = iota
+	subtyping
+)
+
+// коиндуктивная проверка: пара, уже взятая в рассмотрение,
+// считается выполненной, поэтому рекурсивные типы не зацикливают
+type checker struct {
+	defs Defs
+	rel  relation
+	seen map[[2]valkey.ADT]struct{}
+}
+
+// CheckEqual decides type equality up to unfolding of links
+//
+// aka eqtp
+func CheckEqual(defs Defs, got, want ExpRec) error {
+	c := checker{defs, equality, make(map[[2]valkey.ADT]struct{})}
+	return c.check(got, want)
+}
+
+// CheckSub decides whether got is subtype of want up to unfolding of links
+//
+// Выбор допускает ширину и глубину: внутренний выбор got
+// может предлагать меньше меток, внешний выбор got больше.
+func CheckSub(defs Defs, got, want ExpRec) error {
+	c := checker{defs, subtyping, make(map[[2]valkey.ADT]struct{})}
+	return c.check(got, want)
+}
+
+func (c *checker) check(got, want ExpRec) error {
+	if got == nil || want == nil {
+		return ErrSnapTypeMismatch(got, want)
+	}
+	// структурные ключи совпадают только у одинаковых выражений
+	if got.Key() == want.Key() {
+		return nil
+	}
+	pair := [2]valkey.ADT{got.Key(), want.Key()}
+	_, ok := c.seen[pair]
+	if ok {
+		return nil
+	}
+	c.seen[pair] = struct{}{}
+	gotLink, ok := got.(LinkRec)
+	if ok {
+		gotExp, err := c.unfold(gotLink)
+		if err != nil {
+			return err
+		}
+		return c.check(gotExp, want)
+	}
+	wantLink, ok := want.(LinkRec)
+	if ok {
+		wantExp, err := c.unfold(wantLink)
+		if err != nil {
+			return err
+		}
+		return c.check(got, wantExp)
+	}
+	switch wantRec := want.(type) {
+	case OneRec:
+		_, ok := got.(OneRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		return nil
+	case TensorRec:
+		gotRec, ok := got.(TensorRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		err := c.check(gotRec.Val, wantRec.Val)
+		if err != nil {
+			return err
+		}
+		return c.check(gotRec.Cont, wantRec.Cont)
+	case LolliRec:
+		gotRec, ok := got.(LolliRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		// получаемое значение контравариантно
+		err := c.check(wantRec.Val, gotRec.Val)
+		if err != nil {
+			return err
+		}
+		return c.check(gotRec.Cont, wantRec.Cont)
+	case PlusRec:
+		gotRec, ok := got.(PlusRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		return c.checkChoices(gotRec.Choices, wantRec.Choices, false)
+	case WithRec:
+		gotRec, ok := got.(WithRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		// предлагаемые метки контравариантны по ширине
+		return c.checkChoices(gotRec.Choices, wantRec.Choices, true)
+	case UpRec:
+		gotRec, ok := got.(UpRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		return c.check(gotRec.Cont, wantRec.Cont)
+	case DownRec:
+		gotRec, ok := got.(DownRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		return c.check(gotRec.Cont, wantRec.Cont)
+	default:
+		panic(ErrRecTypeUnexpected(want))
+	}
+}
+
+func (c *checker) checkChoices(got, want map[uniqsym.ADT]ExpRec, offered bool) error {
+	// метки узкой стороны обязаны быть и в широкой
+	narrow, wide := got, want
+	if offered {
+		narrow, wide = want, got
+	}
+	if c.rel == equality && len(got) != len(want) {
+		return fmt.Errorf("choices mismatch: want %v items, got %v items", len(want), len(got))
+	}
+	for lab := range narrow {
+		_, ok := wide[lab]
+		if !ok {
+			return fmt.Errorf("label mismatch: want %v, got nothing", lab)
+		}
+		err := c.check(got[lab], want[lab])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *checker) unfold(link LinkRec) (ExpRec, error) {
+	return c.defs.unfold(link.TypeQN)
+}
+
+func (defs Defs) unfold(typeQN uniqsym.ADT) (ExpRec, error) {
+	exp, ok := defs[typeQN]
+	if ok {
+		return exp, nil
+	}
+	// квалифицированные имена сравниваются по значению
+	for defQN, exp := range defs {
+		if defQN.Equal(typeQN) {
+			return exp, nil
+		}
+	}
+	return nil, ErrSymMissingInEnv(typeQN)
+}
+
+// Unfold resolves top-level links
+func Unfold(defs Defs, rec ExpRec) (ExpRec, error) {
+	seen := make(map[valkey.ADT]struct{})
+	for {
+		link, ok := rec.(LinkRec)
+		if !ok {
+			return rec, nil
+		}
+		_, ok = seen[link.Key()]
+		if ok {
+			return nil, fmt.Errorf("link cycle: %v", link.TypeQN)
+		}
+		seen[link.Key()] = struct{}{}
+		exp, err := defs.unfold(link.TypeQN)
+		if err != nil {
+			return nil, err
+		}
+		rec = exp
+	}
+}
+
+type mode int8
+
+const (
+	linearMode mode = iota
+	sharedMode
+)
+
+func (m mode) String() string {
+	if m == sharedMode {
+		return "shared"
+	}
+	return "linear"
+}
+
+// CheckDef validates type definition against environment
+//
+// Проверяются сжимаемость, разрешимость ссылок, согласованность
+// режимов и эквисинхронность разделяемых типов. Ошибки указывают
+// путь до нарушения внутри типа.
+func CheckDef(defs Defs, typeQN uniqsym.ADT, rec ExpRec) error {
+	path := []string{"$"}
+	if typeQN != (uniqsym.ADT{}) {
+		path = []string{uniqsym.ConvertToString(typeQN)}
+	}
+	// aka contractive
+	_, ok := rec.(LinkRec)
+	if ok {
+		return errAtPath(path, fmt.Errorf("type not contractive"))
+	}
+	err := checkLinks(defs, path, rec)
+	if err != nil {
+		return err
+	}
+	err = checkMode(defs, path, rec, modeOf(defs, rec))
+	if err != nil {
+		return err
+	}
+	return checkSync(defs, path, rec)
+}
+
+func checkLinks(defs Defs, path []string, r ExpRec) error {
+	return walk(path, r, func(path []string, r ExpRec) error {
+		link, ok := r.(LinkRec)
+		if !ok {
+			return nil
+		}
+		_, err := defs.unfold(link.TypeQN)
+		if err != nil {
+			return errAtPath(path, fmt.Errorf("link unresolved: %v", link.TypeQN))
+		}
+		return nil
+	})
+}
+
+func modeOf(defs Defs, r ExpRec) mode {
+	rec, err := Unfold(defs, r)
+	if err != nil {
+		return linearMode
+	}
+	switch rec.(type) {
+	case UpRec:
+		return sharedMode
+	default:
+		return linearMode
+	}
+}
+
+func checkMode(defs Defs, path []string, r ExpRec, want mode) error {
+	got := modeOf(defs, r)
+	if got != want {
+		return errAtPath(path, fmt.Errorf("mode mismatch: want %v, got %v", want, got))
+	}
+	switch rec := r.(type) {
+	case TensorRec:
+		// передавать можно каналы любого режима
+		err := checkMode(defs, append(path, "val"), rec.Val, modeOf(defs, rec.Val))
+		if err != nil {
+			return err
+		}
+		return checkMode(defs, append(path, "cont"), rec.Cont, linearMode)
+	case LolliRec:
+		err := checkMode(defs, append(path, "val"), rec.Val, modeOf(defs, rec.Val))
+		if err != nil {
+			return err
+		}
+		return checkMode(defs, append(path, "cont"), rec.Cont, linearMode)
+	case PlusRec:
+		return checkChoiceModes(defs, path, rec.Choices)
+	case WithRec:
+		return checkChoiceModes(defs, path, rec.Choices)
+	case UpRec:
+		return checkMode(defs, append(path, "cont"), rec.Cont, linearMode)
+	case DownRec:
+		return checkMode(defs, append(path, "cont"), rec.Cont, sharedMode)
+	default:
+		// тела ссылок проверены при их записи
+		return nil
+	}
+}
+
+func checkChoiceModes(defs Defs, path []string, choices map[uniqsym.ADT]ExpRec) error {
+	for _, lab := range sortedLabs(choices) {
+		err := checkMode(defs, append(path, uniqsym.ConvertToString(lab)), choices[lab], linearMode)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// aka esync
+//
+// Каждый путь линейной части разделяемого типа должен
+// освобождать сессию в тот же разделяемый тип.
+func checkSync(defs Defs, path []string, r ExpRec) error {
+	return walk(path, r, func(path []string, r ExpRec) error {
+		up, ok := r.(UpRec)
+		if !ok {
+			return nil
+		}
+		seen := make(map[valkey.ADT]struct{})
+		return syncWith(defs, append(path, "cont"), up.Cont, up, seen)
+	})
+}
+
+func syncWith(defs Defs, path []string, r ExpRec, shared UpRec, seen map[valkey.ADT]struct{}) error {
+	_, ok := seen[r.Key()]
+	if ok {
+		return nil
+	}
+	seen[r.Key()] = struct{}{}
+	switch rec := r.(type) {
+	case OneRec:
+		return errAtPath(path, fmt.Errorf("shared session not released"))
+	case LinkRec:
+		exp, err := defs.unfold(rec.TypeQN)
+		if err != nil {
+			return errAtPath(path, err)
+		}
+		return syncWith(defs, path, exp, shared, seen)
+	case TensorRec:
+		return syncWith(defs, append(path, "cont"), rec.Cont, shared, seen)
+	case LolliRec:
+		return syncWith(defs, append(path, "cont"), rec.Cont, shared, seen)
+	case PlusRec:
+		return syncChoices(defs, path, rec.Choices, shared, seen)
+	case WithRec:
+		return syncChoices(defs, path, rec.Choices, shared, seen)
+	case DownRec:
+		err := CheckEqual(defs, rec.Cont, shared)
+		if err != nil {
+			return errAtPath(append(path, "cont"), fmt.Errorf("type not equi-synchronizing: %w", err))
+		}
+		return nil
+	default:
+		return errAtPath(path, ErrRecTypeUnexpected(r))
+	}
+}
+
+func syncChoices(defs Defs, path []string, choices map[uniqsym.ADT]ExpRec, shared UpRec, seen map[valkey.ADT]struct{}) error {
+	for _, lab := range sortedLabs(choices) {
+		err := syncWith(defs, append(path, uniqsym.ConvertToString(lab)), choices[lab], shared, seen)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// обход без развертки ссылок
+func walk(path []string, r ExpRec, visit func([]string, ExpRec) error) error {
+	err := visit(path, r)
+	if err != nil {
+		return err
+	}
+	switch rec := r.(type) {
+	case TensorRec:
+		err := walk(append(path, "val"), rec.Val, visit)
+		if err != nil {
+			return err
+		}
+		return walk(append(path, "cont"), rec.Cont, visit)
+	case LolliRec:
+		err := walk(append(path, "val"), rec.Val, visit)
+		if err != nil {
+			return err
+		}
+		return walk(append(path, "cont"), rec.Cont, visit)
+	case PlusRec:
+		for _, lab := range sortedLabs(rec.Choices) {
+			err := walk(append(path, uniqsym.ConvertToString(lab)), rec.Choices[lab], visit)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case WithRec:
+		for _, lab := range sortedLabs(rec.Choices) {
+			err := walk(append(path, uniqsym.ConvertToString(lab)), rec.Choices[lab], visit)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case UpRec:
+		return walk(append(path, "cont"), rec.Cont, visit)
+	case DownRec:
+		return walk(append(path, "cont"), rec.Cont, visit)
+	default:
+		return nil
+	}
+}
+
+func sortedLabs(choices map[uniqsym.ADT]ExpRec) []uniqsym.ADT {
+	labs := slices.Collect(maps.Keys(choices))
+	slices.SortFunc(labs, func(a, b uniqsym.ADT) int {
+		return strings.Compare(uniqsym.ConvertToString(a), uniqsym.ConvertToString(b))
+	})
+	return labs
+}
+
+func errAtPath(path []string, err error) error {
+	return fmt.Errorf("%v: %w", strings.Join(path, "/"), err)
+}
+
+// CollectLinks gathers type names to unfold
+func CollectLinks(recs iter.Seq[ExpRec]) []uniqsym.ADT {
+	var typeQNs []uniqsym.ADT
+	for rec := range recs {
+		typeQNs = collectLinks(rec, typeQNs)
+	}
+	return typeQNs
+}
+
+func collectLinks(r ExpRec, typeQNs []uniqsym.ADT) []uniqsym.ADT {
+	switch rec := r.(type) {
+	case LinkRec:
+		return append(typeQNs, rec.TypeQN)
+	case TensorRec:
+		return collectLinks(rec.Cont, collectLinks(rec.Val, typeQNs))
+	case LolliRec:
+		return collectLinks(rec.Cont, collectLinks(rec.Val, typeQNs))
+	case PlusRec:
+		for _, cont := range rec.Choices {
+			typeQNs = collectLinks(cont, typeQNs)
+		}
+		return typeQNs
+	case WithRec:
+		for _, cont := range rec.Choices {
+			typeQNs = collectLinks(cont, typeQNs)
+		}
+		return typeQNs
+	case UpRec:
+		return collectLinks(rec.Cont, typeQNs)
+	case DownRec:
+		return collectLinks(rec.Cont, typeQNs)
+	default:
+		return typeQNs
+	}
+}
+
 func ErrSpecTypeUnexpected(got ExpSpec) error {
 	return fmt.Errorf("spec type unexpected: %T", got)
 }
@@ -410,6 +887,10 @@ func ErrRecTypeUnexpected(got ExpRec) error {
 	return fmt.Errorf("rec type unexpected: %T", got)
 }
 
+func ErrKeyCollision(got valkey.ADT) error {
+	return fmt.Errorf("key taken by another spec: %v", got)
+}
+
 func ErrDoesNotExist(want valkey.ADT) error {
 	return fmt.Errorf("root doesn't exist: %v", want)
 }
@@ -0,0 +1,152 @@
+package typeexp
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"strings"
+	"testing"
+
+	"orglang/go-engine/lib/db"
+
+	"orglang/go-engine/adt/symbol"
+	"orglang/go-engine/adt/uniqsym"
+	"orglang/go-engine/adt/valkey"
+)
+
+func TestCheckRec(t *testing.T) {
+	qn := func(s string) uniqsym.ADT { return uniqsym.New(symbol.New(s)) }
+	rec := func(spec ExpSpec) ExpRec {
+		r, err := ConvertSpecToRec(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	one := OneSpec{}
+	// stream = 1 * stream
+	stream := TensorSpec{one, LinkSpec{qn("stream")}}
+	// stream2 = 1 * 1 * stream2
+	stream2 := TensorSpec{one, TensorSpec{one, LinkSpec{qn("stream2")}}}
+	// alias = stream
+	defs := Defs{
+		qn("stream"):  rec(stream),
+		qn("stream2"): rec(stream2),
+		qn("alias"):   rec(LinkSpec{qn("stream")}),
+		qn("menu"):    rec(WithSpec{map[uniqsym.ADT]ExpSpec{qn("a"): one, qn("b"): one}}),
+	}
+	plusA := PlusSpec{map[uniqsym.ADT]ExpSpec{qn("a"): one}}
+	plusAB := PlusSpec{map[uniqsym.ADT]ExpSpec{qn("a"): one, qn("b"): one}}
+	withA := WithSpec{map[uniqsym.ADT]ExpSpec{qn("a"): one}}
+	withAB := WithSpec{map[uniqsym.ADT]ExpSpec{qn("a"): one, qn("b"): one}}
+	var tests = []struct {
+		name      string
+		got, want ExpSpec
+		eq, sub   bool
+	}{
+		{"unfolding", LinkSpec{qn("stream")}, stream, true, true},
+		{"different periods", LinkSpec{qn("stream")}, LinkSpec{qn("stream2")}, true, true},
+		{"alias", LinkSpec{qn("alias")}, stream2, true, true},
+		{"named choice", LinkSpec{qn("menu")}, withAB, true, true},
+		{"plus width", plusA, plusAB, false, true},
+		{"plus width reversed", plusAB, plusA, false, false},
+		{"with width", withAB, withA, false, true},
+		{"with width reversed", withA, withAB, false, false},
+		{"depth", TensorSpec{plusA, one}, TensorSpec{plusAB, one}, false, true},
+		{"lolli contravariance", LolliSpec{plusAB, one}, LolliSpec{plusA, one}, false, true},
+		{"lolli covariance", LolliSpec{plusA, one}, LolliSpec{plusAB, one}, false, false},
+		{"tensor vs lolli", TensorSpec{one, one}, LolliSpec{one, one}, false, false},
+		{"stream vs one", LinkSpec{qn("stream")}, one, false, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, want := rec(test.got), rec(test.want)
+			err := CheckEqual(defs, got, want)
+			if (err == nil) != test.eq {
+				t.Errorf("equality: got %v, want %v", err, test.eq)
+			}
+			err = CheckSub(defs, got, want)
+			if (err == nil) != test.sub {
+				t.Errorf("subtyping: got %v, want %v", err, test.sub)
+			}
+		})
+	}
+}
+
+func TestCheckDef(t *testing.T) {
+	qn := func(s string) uniqsym.ADT { return uniqsym.New(symbol.New(s)) }
+	rec := func(spec ExpSpec) ExpRec {
+		r, err := ConvertSpecToRec(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	one := OneSpec{}
+	with := func(lab string, cont ExpSpec) ExpSpec {
+		return WithSpec{map[uniqsym.ADT]ExpSpec{qn(lab): cont}}
+	}
+	defs := Defs{
+		qn("stream"): rec(TensorSpec{one, LinkSpec{qn("stream")}}),
+		qn("other"):  rec(UpSpec{PlusSpec{map[uniqsym.ADT]ExpSpec{qn("b"): DownSpec{LinkSpec{qn("other")}}}}}),
+	}
+	var tests = []struct {
+		name string
+		spec ExpSpec
+		// пустой путь означает отсутствие ошибки
+		path string
+	}{
+		{"linear recursion", TensorSpec{one, LinkSpec{qn("stream")}}, ""},
+		{"equi-synchronizing", UpSpec{with("a", DownSpec{LinkSpec{qn("t")}})}, ""},
+		{"not contractive", LinkSpec{qn("t")}, "t"},
+		{"link unresolved", TensorSpec{one, LinkSpec{qn("missing")}}, "t/cont"},
+		{"up under up", UpSpec{UpSpec{one}}, "t/cont"},
+		{"down to linear", DownSpec{one}, "t/cont"},
+		{"linear cont shared", TensorSpec{one, UpSpec{one}}, "t/cont"},
+		{"shared not released", UpSpec{with("a", one)}, "t/cont/a"},
+		{"released elsewhere", UpSpec{with("a", DownSpec{LinkSpec{qn("other")}})}, "t/cont/a/cont"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			typeRec := rec(test.spec)
+			env := maps.Clone(defs)
+			env[qn("t")] = typeRec
+			err := CheckDef(env, qn("t"), typeRec)
+			if test.path == "" {
+				if err != nil {
+					t.Errorf("got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.HasPrefix(err.Error(), test.path+": ") {
+				t.Errorf("got %v, want path %v", err, test.path)
+			}
+		})
+	}
+}
+
+func TestAddRecCollisionMem(t *testing.T) {
+	ctx := context.Background()
+	operator := db.NewOperatorMem()
+	repo := NewMemRepo(slog.New(slog.DiscardHandler))
+	add := func(rec ExpRec) error {
+		return operator.Explicit(ctx, func(ds db.Source) error {
+			return repo.AddRec(ds, rec)
+		})
+	}
+	// разные спецификации под одним ключом, как при усеченном дайджесте
+	key := valkey.FromString("collision")
+	first := LinkRec{ExpVK: key, TypeQN: uniqsym.New(symbol.New("a"))}
+	err := add(first)
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	err = add(first)
+	if err != nil {
+		t.Fatalf("repeat rejected: %q", err)
+	}
+	err = add(LinkRec{ExpVK: key, TypeQN: uniqsym.New(symbol.New("b"))})
+	if err == nil {
+		t.Fatal("collision accepted")
+	}
+}
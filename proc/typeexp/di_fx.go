@@ -6,10 +6,11 @@ import (
 
 var Module = fx.Module("proc/typeexp",
 	fx.Provide(
-		fx.Annotate(newPgxDAO, fx.As(new(Repo))),
+		fx.Annotate(newMcDAO, fx.ParamTags(`name:"dialect"`), fx.As(new(Repo))),
 	),
 	fx.Provide(
 		fx.Private,
+		fx.Annotate(newDialectDAO, fx.ResultTags(`name:"dialect"`)),
 		fx.Annotate(newSQLBuilder, fx.As(new(queryBuilder))),
 	),
 )
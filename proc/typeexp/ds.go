@@ -1,6 +1,8 @@
 package typeexp
 
 import (
+	"log/slog"
+
 	"orglang/go-engine/lib/db"
 
 	"orglang/go-engine/adt/valkey"
@@ -13,6 +15,14 @@ type Repo interface {
 	SelectEnv(db.Source, []valkey.ADT) (map[valkey.ADT]ExpRec, error)
 }
 
+// хранилищу в памяти запросы не нужны, поэтому DAO выбирается по диалекту
+func newDialectDAO(dialect db.Dialect, qb queryBuilder, log *slog.Logger) Repo {
+	if dialect == db.Memory {
+		return newMemDAO(log)
+	}
+	return newPgxDAO(qb, log)
+}
+
 type expKindDS int
 
 const (
@@ -23,6 +33,8 @@ const (
 	lolliKind
 	plusKind
 	withKind
+	upKind
+	downKind
 )
 
 type expRefDS struct {
@@ -43,11 +55,13 @@ type stateDS struct {
 }
 
 type expSpecDS struct {
-	Link   string  `json:"link,omitempty"`
-	Tensor *prodDS `json:"tensor,omitempty"`
-	Lolli  *prodDS `json:"lolli,omitempty"`
-	Plus   []sumDS `json:"plus,omitempty"`
-	With   []sumDS `json:"with,omitempty"`
+	Link   string   `json:"link,omitempty"`
+	Tensor *prodDS  `json:"tensor,omitempty"`
+	Lolli  *prodDS  `json:"lolli,omitempty"`
+	Plus   []sumDS  `json:"plus,omitempty"`
+	With   []sumDS  `json:"with,omitempty"`
+	Up     *shiftDS `json:"up,omitempty"`
+	Down   *shiftDS `json:"down,omitempty"`
 }
 
 type prodDS struct {
@@ -59,3 +73,7 @@ type sumDS struct {
 	LabQN     string `json:"on"`
 	ContExpVK int64  `json:"to"`
 }
+
+type shiftDS struct {
+	ContExpVK int64 `json:"to"`
+}
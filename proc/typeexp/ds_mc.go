@@ -0,0 +1,69 @@
+package typeexp
+
+import (
+	"orglang/go-engine/lib/db"
+	"orglang/go-engine/lib/mc"
+
+	"orglang/go-engine/adt/valkey"
+)
+
+// Adapter
+//
+// выражения неизменны по ключу содержимого, поэтому кэш
+// не требует инвалидации
+type mcDAO struct {
+	repo Repo
+	exps *mc.Cache[valkey.ADT, ExpRec]
+}
+
+func newMcDAO(repo Repo, limits mc.Limits) *mcDAO {
+	return &mcDAO{repo, mc.New[valkey.ADT, ExpRec]("proc_type_exps", limits)}
+}
+
+func (dao *mcDAO) AddRec(source db.Source, rec ExpRec) error {
+	return dao.repo.AddRec(source, rec)
+}
+
+func (dao *mcDAO) SelectRecByVK(source db.Source, expVK valkey.ADT) (ExpRec, error) {
+	rec, ok := dao.exps.Get(expVK)
+	if ok {
+		return rec, nil
+	}
+	gen := dao.exps.Gen()
+	rec, err := dao.repo.SelectRecByVK(source, expVK)
+	if err != nil {
+		return nil, err
+	}
+	dao.exps.Put(gen, expVK, rec)
+	return rec, nil
+}
+
+func (dao *mcDAO) SelectRecsByVKs(source db.Source, expVKs []valkey.ADT) ([]ExpRec, error) {
+	return dao.repo.SelectRecsByVKs(source, expVKs)
+}
+
+func (dao *mcDAO) SelectEnv(source db.Source, expVKs []valkey.ADT) (map[valkey.ADT]ExpRec, error) {
+	env := make(map[valkey.ADT]ExpRec, len(expVKs))
+	var missedVKs []valkey.ADT
+	for _, expVK := range expVKs {
+		rec, ok := dao.exps.Get(expVK)
+		if !ok {
+			missedVKs = append(missedVKs, expVK)
+			continue
+		}
+		env[expVK] = rec
+	}
+	if len(missedVKs) == 0 {
+		return env, nil
+	}
+	gen := dao.exps.Gen()
+	missedEnv, err := dao.repo.SelectEnv(source, missedVKs)
+	if err != nil {
+		return nil, err
+	}
+	for expVK, rec := range missedEnv {
+		dao.exps.Put(gen, expVK, rec)
+		env[expVK] = rec
+	}
+	return env, nil
+}
@@ -0,0 +1,137 @@
+package typeexp
+
+import (
+	"log/slog"
+	"reflect"
+
+	"orglang/go-engine/lib/db"
+	"orglang/go-engine/lib/lf"
+
+	"orglang/go-engine/adt/valkey"
+)
+
+// Adapter
+//
+// состояния хранятся по ключу содержимого, поэтому общие
+// подвыражения разных выражений хранятся однажды
+type memDAO struct {
+	log *slog.Logger
+}
+
+func newMemDAO(log *slog.Logger) *memDAO {
+	name := slog.String("name", reflect.TypeFor[memDAO]().Name())
+	return &memDAO{log.With(name)}
+}
+
+// NewMemRepo отдает хранилище в памяти тестам соседних модулей,
+// которые собирают сервисы без fx
+func NewMemRepo(log *slog.Logger) Repo {
+	return newMemDAO(log)
+}
+
+func (dao *memDAO) AddRec(source db.Source, rec ExpRec) error {
+	ds := db.MustConform[db.SourceMem](source)
+	dto := dataFromExpRec(rec)
+	states := db.TableOf[int64, stateDS](ds, typeExps)
+	for _, st := range dto.States {
+		// как и ON CONFLICT DO UPDATE ... WHERE
+		prev, ok := states.Get(st.ExpVK)
+		if ok && prev.K == st.K && reflect.DeepEqual(prev.Spec, st.Spec) {
+			continue
+		}
+		if ok {
+			dao.log.Error("key collision detected", slog.Any("expVK", rec.Key()), slog.Int64("stateVK", st.ExpVK))
+			return ErrKeyCollision(valkey.ADT(st.ExpVK))
+		}
+		states.Put(st.ExpVK, st)
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "addition succeed", slog.Any("expVK", rec.Key()))
+	return nil
+}
+
+func (dao *memDAO) SelectRecByVK(source db.Source, expVK valkey.ADT) (ExpRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	vkAttr := slog.Any("expVK", expVK)
+	dto, ok := selectTree(db.TableOf[int64, stateDS](ds, typeExps), valkey.ConvertToInt(expVK))
+	if !ok {
+		dao.log.Error("entity selection failed", vkAttr)
+		return nil, ErrDoesNotExist(expVK)
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity selection succeed", slog.Any("dto", dto))
+	return dataToExpRec(dto)
+}
+
+func (dao *memDAO) SelectEnv(source db.Source, expVKs []valkey.ADT) (map[valkey.ADT]ExpRec, error) {
+	recs, err := dao.SelectRecsByVKs(source, expVKs)
+	if err != nil {
+		return nil, err
+	}
+	env := make(map[valkey.ADT]ExpRec, len(recs))
+	for _, rec := range recs {
+		env[rec.Key()] = rec
+	}
+	return env, nil
+}
+
+func (dao *memDAO) SelectRecsByVKs(source db.Source, expVKs []valkey.ADT) ([]ExpRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	states := db.TableOf[int64, stateDS](ds, typeExps)
+	recs := make([]ExpRec, 0, len(expVKs))
+	for _, expVK := range expVKs {
+		vkAttr := slog.Any("expVK", expVK)
+		dto, ok := selectTree(states, valkey.ConvertToInt(expVK))
+		if !ok {
+			dao.log.Error("entity selection failed", vkAttr)
+			return nil, ErrDoesNotExist(expVK)
+		}
+		rec, err := dataToExpRec(dto)
+		if err != nil {
+			dao.log.Error("model conversion failed", vkAttr)
+			return nil, err
+		}
+		recs = append(recs, rec)
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entities selection succeed", slog.Any("recs", recs))
+	return recs, nil
+}
+
+// состояния, достижимые из корня по ссылкам спецификаций
+func selectTree(states db.TableMem[int64, stateDS], expVK int64) (expRecDS, bool) {
+	root, ok := states.Get(expVK)
+	if !ok {
+		return expRecDS{}, false
+	}
+	dto := expRecDS{ExpVK: expVK, States: []stateDS{root}}
+	for i := 0; i < len(dto.States); i++ {
+		for _, subVK := range subExpVKs(dto.States[i].Spec) {
+			st, ok := states.Get(subVK)
+			if ok {
+				dto.States = append(dto.States, st)
+			}
+		}
+	}
+	return dto, true
+}
+
+func subExpVKs(spec expSpecDS) []int64 {
+	var vks []int64
+	if spec.Tensor != nil {
+		vks = append(vks, spec.Tensor.ValExpVK, spec.Tensor.ContExpVK)
+	}
+	if spec.Lolli != nil {
+		vks = append(vks, spec.Lolli.ValExpVK, spec.Lolli.ContExpVK)
+	}
+	for _, ch := range spec.Plus {
+		vks = append(vks, ch.ContExpVK)
+	}
+	for _, ch := range spec.With {
+		vks = append(vks, ch.ContExpVK)
+	}
+	if spec.Up != nil {
+		vks = append(vks, spec.Up.ContExpVK)
+	}
+	if spec.Down != nil {
+		vks = append(vks, spec.Down.ContExpVK)
+	}
+	return vks
+}
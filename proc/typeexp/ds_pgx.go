@@ -42,12 +42,16 @@ func (dao *pgxDAO) AddRec(source db.Source, rec ExpRec) (err error) {
 	defer func() {
 		err = errors.Join(err, br.Close())
 	}()
-	for range dto.States {
-		_, readErr := br.Exec()
+	for _, st := range dto.States {
+		ct, readErr := br.Exec()
 		if readErr != nil {
 			dao.log.Error("query execution failed", vkAttr)
 			return readErr
 		}
+		if ct.RowsAffected() == 0 {
+			dao.log.Error("key collision detected", vkAttr, slog.Int64("stateVK", st.ExpVK))
+			return ErrKeyCollision(valkey.ADT(st.ExpVK))
+		}
 	}
 	return nil
 }
@@ -2,6 +2,7 @@ package typeexp
 
 const (
 	xactExps string = "pool_type_exps"
+	typeExps string = "proc_type_exps"
 )
 
 type queryBuilder interface {
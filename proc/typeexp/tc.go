@@ -2,6 +2,7 @@ package typeexp
 
 import (
 	"fmt"
+	"slices"
 
 	"golang.org/x/exp/maps"
 
@@ -19,10 +20,9 @@ func ConvertSpecToRec(s ExpSpec) (ExpRec, error) {
 	case OneSpec:
 		return OneRec{ExpVK: valkey.One}, nil
 	case LinkSpec:
-		expVK, err := spec.TypeQN.Key()
-		if err != nil {
-			return nil, err
-		}
+		expVK := valkey.NewHasher(int16(linkKind)).
+			WriteString(uniqsym.ConvertToString(spec.TypeQN)).
+			Sum()
 		return LinkRec{ExpVK: expVK, TypeQN: spec.TypeQN}, nil
 	case TensorSpec:
 		val, err := ConvertSpecToRec(spec.Val)
@@ -33,8 +33,11 @@ func ConvertSpecToRec(s ExpSpec) (ExpRec, error) {
 		if err != nil {
 			return nil, err
 		}
-		key := val.Key() + cont.Key()
-		return TensorRec{ExpVK: key, Val: val, Cont: cont}, nil
+		expVK := valkey.NewHasher(int16(tensorKind)).
+			WriteKey(val.Key()).
+			WriteKey(cont.Key()).
+			Sum()
+		return TensorRec{ExpVK: expVK, Val: val, Cont: cont}, nil
 	case LolliSpec:
 		val, err := ConvertSpecToRec(spec.Val)
 		if err != nil {
@@ -44,53 +47,71 @@ func ConvertSpecToRec(s ExpSpec) (ExpRec, error) {
 		if err != nil {
 			return nil, err
 		}
-		key := val.Key() + cont.Key()
-		return LolliRec{ExpVK: key, Val: val, Cont: cont}, nil
+		expVK := valkey.NewHasher(int16(lolliKind)).
+			WriteKey(val.Key()).
+			WriteKey(cont.Key()).
+			Sum()
+		return LolliRec{ExpVK: expVK, Val: val, Cont: cont}, nil
 	case WithSpec:
-		conts := make(map[uniqsym.ADT]ExpRec, len(spec.Choices))
-		keys := make([]valkey.ADT, len(spec.Choices)*2)
-		for lab, spec := range spec.Choices {
-			cont, err := ConvertSpecToRec(spec)
-			if err != nil {
-				return nil, err
-			}
-			conts[lab] = cont
-			labVK, err := lab.Key()
-			if err != nil {
-				return nil, err
-			}
-			keys = append(keys, labVK, cont.Key())
-		}
-		expVK, err := valkey.Compose(keys...)
+		conts, expVK, err := convertChoicesToRec(withKind, spec.Choices)
 		if err != nil {
 			return nil, err
 		}
 		return WithRec{ExpVK: expVK, Choices: conts}, nil
 	case PlusSpec:
-		conts := make(map[uniqsym.ADT]ExpRec, len(spec.Choices))
-		keys := make([]valkey.ADT, len(spec.Choices)*2)
-		for lab, spec := range spec.Choices {
-			cont, err := ConvertSpecToRec(spec)
-			if err != nil {
-				return nil, err
-			}
-			conts[lab] = cont
-			labVK, err := lab.Key()
-			if err != nil {
-				return nil, err
-			}
-			keys = append(keys, labVK, cont.Key())
-		}
-		expVK, err := valkey.Compose(keys...)
+		conts, expVK, err := convertChoicesToRec(plusKind, spec.Choices)
 		if err != nil {
 			return nil, err
 		}
 		return PlusRec{ExpVK: expVK, Choices: conts}, nil
+	case UpSpec:
+		cont, err := ConvertSpecToRec(spec.Cont)
+		if err != nil {
+			return nil, err
+		}
+		expVK := valkey.NewHasher(int16(upKind)).
+			WriteKey(cont.Key()).
+			Sum()
+		return UpRec{ExpVK: expVK, Cont: cont}, nil
+	case DownSpec:
+		cont, err := ConvertSpecToRec(spec.Cont)
+		if err != nil {
+			return nil, err
+		}
+		expVK := valkey.NewHasher(int16(downKind)).
+			WriteKey(cont.Key()).
+			Sum()
+		return DownRec{ExpVK: expVK, Cont: cont}, nil
 	default:
 		panic(ErrSpecTypeUnexpected(spec))
 	}
 }
 
+// метки хешируются в лексикографическом порядке,
+// поэтому ключ не зависит от порядка обхода словаря
+func convertChoicesToRec(
+	k expKindDS,
+	specs map[uniqsym.ADT]ExpSpec,
+) (map[uniqsym.ADT]ExpRec, valkey.ADT, error) {
+	conts := make(map[uniqsym.ADT]ExpRec, len(specs))
+	labs := make(map[string]uniqsym.ADT, len(specs))
+	for lab, spec := range specs {
+		cont, err := ConvertSpecToRec(spec)
+		if err != nil {
+			return nil, valkey.Zero, err
+		}
+		conts[lab] = cont
+		labs[uniqsym.ConvertToString(lab)] = lab
+	}
+	h := valkey.NewHasher(int16(k))
+	labQNs := maps.Keys(labs)
+	slices.Sort(labQNs)
+	for _, labQN := range labQNs {
+		h.WriteString(labQN).WriteKey(conts[labs[labQN]].Key())
+	}
+	return conts, h.Sum(), nil
+}
+
 func ConvertRecToSpec(r ExpRec) ExpSpec {
 	if r == nil {
 		return nil
@@ -122,6 +143,10 @@ func ConvertRecToSpec(r ExpRec) ExpSpec {
 			choices[lab] = ConvertRecToSpec(cont)
 		}
 		return PlusSpec{Choices: choices}
+	case UpRec:
+		return UpSpec{Cont: ConvertRecToSpec(rec.Cont)}
+	case DownRec:
+		return DownSpec{Cont: ConvertRecToSpec(rec.Cont)}
 	default:
 		panic(ErrRecTypeUnexpected(rec))
 	}
@@ -298,6 +323,10 @@ func dataFromExpRef(ref ExpRef) expRefDS {
 		return expRefDS{K: plusKind, ExpVK: expVK}
 	case WithRef, WithRec:
 		return expRefDS{K: withKind, ExpVK: expVK}
+	case UpRef, UpRec:
+		return expRefDS{K: upKind, ExpVK: expVK}
+	case DownRef, DownRec:
+		return expRefDS{K: downKind, ExpVK: expVK}
 	default:
 		panic(ErrRefTypeUnexpected(ref))
 	}
@@ -321,6 +350,10 @@ func dataToExpRef(dto expRefDS) (ExpRef, error) {
 		return PlusRef{expVK}, nil
 	case withKind:
 		return WithRef{expVK}, nil
+	case upKind:
+		return UpRef{expVK}, nil
+	case downKind:
+		return DownRef{expVK}, nil
 	default:
 		panic(errUnexpectedKind(dto.K))
 	}
@@ -408,6 +441,18 @@ func statesToExpRec(states map[int64]stateDS, st stateDS) (ExpRec, error) {
 			choices[label] = choice
 		}
 		return WithRec{ExpVK: expVK, Choices: choices}, nil
+	case upKind:
+		cont, err := statesToExpRec(states, states[st.Spec.Up.ContExpVK])
+		if err != nil {
+			return nil, err
+		}
+		return UpRec{ExpVK: expVK, Cont: cont}, nil
+	case downKind:
+		cont, err := statesToExpRec(states, states[st.Spec.Down.ContExpVK])
+		if err != nil {
+			return nil, err
+		}
+		return DownRec{ExpVK: expVK, Cont: cont}, nil
 	default:
 		panic(errUnexpectedKind(st.K))
 	}
@@ -485,6 +530,26 @@ func statesFromExpRec(fromID int64, r ExpRec, dto *expRecDS) int64 {
 		}
 		dto.States = append(dto.States, st)
 		return expVK
+	case UpRec:
+		cont := statesFromExpRec(expVK, rec.Cont, dto)
+		st := stateDS{
+			ExpVK:    expVK,
+			K:        upKind,
+			SupExpVK: fromID,
+			Spec:     expSpecDS{Up: &shiftDS{cont}},
+		}
+		dto.States = append(dto.States, st)
+		return expVK
+	case DownRec:
+		cont := statesFromExpRec(expVK, rec.Cont, dto)
+		st := stateDS{
+			ExpVK:    expVK,
+			K:        downKind,
+			SupExpVK: fromID,
+			Spec:     expSpecDS{Down: &shiftDS{cont}},
+		}
+		dto.States = append(dto.States, st)
+		return expVK
 	default:
 		panic(ErrRecTypeUnexpected(r))
 	}
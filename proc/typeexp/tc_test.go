@@ -0,0 +1,144 @@
+package typeexp
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+
+	"orglang/go-engine/adt/symbol"
+	"orglang/go-engine/adt/uniqsym"
+	"orglang/go-engine/adt/valkey"
+)
+
+func TestConvertSpecToRecDistinct(t *testing.T) {
+	a := LinkSpec{uniqsym.New(symbol.New("a"))}
+	b := LinkSpec{uniqsym.New(symbol.New("b"))}
+	l1 := uniqsym.New(symbol.New("l1"))
+	l2 := uniqsym.New(symbol.New("l2"))
+	var tests = []struct {
+		name string
+		x, y ExpSpec
+	}{
+		{"tensor vs lolli", TensorSpec{a, b}, LolliSpec{a, b}},
+		{"field order", TensorSpec{a, b}, TensorSpec{b, a}},
+		{"up vs down", UpSpec{a}, DownSpec{a}},
+		{"plus vs with", PlusSpec{map[uniqsym.ADT]ExpSpec{l1: a}}, WithSpec{map[uniqsym.ADT]ExpSpec{l1: a}}},
+		{
+			"label binding",
+			PlusSpec{map[uniqsym.ADT]ExpSpec{l1: a, l2: b}},
+			PlusSpec{map[uniqsym.ADT]ExpSpec{l1: b, l2: a}},
+		},
+		{"link anagram", LinkSpec{uniqsym.New(symbol.New("ab"))}, LinkSpec{uniqsym.New(symbol.New("ba"))}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			x, err := ConvertSpecToRec(test.x)
+			if err != nil {
+				t.Fatal(err)
+			}
+			y, err := ConvertSpecToRec(test.y)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if x.Key() == y.Key() {
+				t.Errorf("got collision %v", x.Key())
+			}
+		})
+	}
+}
+
+// ключи совпадают тогда и только тогда, когда совпадает структура
+func TestConvertSpecToRecProperty(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	seen := make(map[valkey.ADT]string)
+	for range 20000 {
+		spec := genExpSpec(r, 4)
+		rec, err := ConvertSpecToRec(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		again, err := ConvertSpecToRec(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rec.Key() != again.Key() {
+			t.Fatalf("unstable key for %v", renderExpSpec(spec))
+		}
+		got := renderExpSpec(spec)
+		want, ok := seen[rec.Key()]
+		if ok && want != got {
+			t.Fatalf("collision %v: %v vs %v", rec.Key(), want, got)
+		}
+		seen[rec.Key()] = got
+	}
+}
+
+func genExpSpec(r *rand.Rand, depth int) ExpSpec {
+	syms := []string{"a", "b", "c", "ab", "ba"}
+	if depth == 0 {
+		if r.IntN(2) == 0 {
+			return OneSpec{}
+		}
+		return LinkSpec{uniqsym.New(symbol.New(syms[r.IntN(len(syms))]))}
+	}
+	switch r.IntN(8) {
+	case 0:
+		return OneSpec{}
+	case 1:
+		return LinkSpec{uniqsym.New(symbol.New(syms[r.IntN(len(syms))]))}
+	case 2:
+		return TensorSpec{genExpSpec(r, depth-1), genExpSpec(r, depth-1)}
+	case 3:
+		return LolliSpec{genExpSpec(r, depth-1), genExpSpec(r, depth-1)}
+	case 4:
+		return PlusSpec{genChoices(r, depth-1, syms)}
+	case 5:
+		return WithSpec{genChoices(r, depth-1, syms)}
+	case 6:
+		return UpSpec{genExpSpec(r, depth-1)}
+	default:
+		return DownSpec{genExpSpec(r, depth-1)}
+	}
+}
+
+func genChoices(r *rand.Rand, depth int, syms []string) map[uniqsym.ADT]ExpSpec {
+	choices := make(map[uniqsym.ADT]ExpSpec)
+	for range 1 + r.IntN(3) {
+		choices[uniqsym.New(symbol.New(syms[r.IntN(len(syms))]))] = genExpSpec(r, depth)
+	}
+	return choices
+}
+
+func renderExpSpec(s ExpSpec) string {
+	switch spec := s.(type) {
+	case OneSpec:
+		return "1"
+	case LinkSpec:
+		return uniqsym.ConvertToString(spec.TypeQN)
+	case TensorSpec:
+		return fmt.Sprintf("(%v * %v)", renderExpSpec(spec.Val), renderExpSpec(spec.Cont))
+	case LolliSpec:
+		return fmt.Sprintf("(%v -o %v)", renderExpSpec(spec.Val), renderExpSpec(spec.Cont))
+	case PlusSpec:
+		return "+" + renderChoices(spec.Choices)
+	case WithSpec:
+		return "&" + renderChoices(spec.Choices)
+	case UpSpec:
+		return fmt.Sprintf("up(%v)", renderExpSpec(spec.Cont))
+	case DownSpec:
+		return fmt.Sprintf("down(%v)", renderExpSpec(spec.Cont))
+	default:
+		panic(ErrSpecTypeUnexpected(spec))
+	}
+}
+
+func renderChoices(choices map[uniqsym.ADT]ExpSpec) string {
+	parts := make([]string, 0, len(choices))
+	for lab, cont := range choices {
+		parts = append(parts, uniqsym.ConvertToString(lab)+":"+renderExpSpec(cont))
+	}
+	slices.Sort(parts)
+	return "{" + strings.Join(parts, ", ") + "}"
+}